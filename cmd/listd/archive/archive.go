@@ -0,0 +1,555 @@
+// Package archive exports everything kept by the list daemon as a single versioned
+// document, and restores such documents, so that lists can be backed up and moved
+// between environments.
+//
+// An archive holds every folder and list, along with the items on each list, their
+// tags and the comments on them. Ids in an archive are those of the environment it
+// was exported from, they are only used to refer to folders and items within the
+// archive and are remapped on import. Attachments and webhooks are not archived,
+// the contents of attachments live in a separate store and webhooks hold secrets.
+package archive
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+	"unicode/utf8"
+
+	"github.com/george-e-shaw-iv/integration-tests-example/cmd/listd/comment"
+	"github.com/george-e-shaw-iv/integration-tests-example/cmd/listd/folder"
+	"github.com/george-e-shaw-iv/integration-tests-example/cmd/listd/item"
+	"github.com/george-e-shaw-iv/integration-tests-example/cmd/listd/list"
+	"github.com/jmoiron/sqlx"
+	"github.com/pkg/errors"
+)
+
+// Version is the version of the archive format written by Export. Import only reads
+// archives of this version.
+const Version = 1
+
+// maxNameLength is the maximum length of the name of a list, in characters.
+const maxNameLength = 255
+
+// Archive is a versioned document holding everything kept by the list daemon.
+type Archive struct {
+	Version  int             `json:"version"`
+	Exported time.Time       `json:"exported"`
+	Folders  []folder.Folder `json:"folders"`
+	Lists    []List          `json:"lists"`
+}
+
+// List is an archived list along with its items.
+type List struct {
+	list.List
+	Items []Item `json:"items"`
+}
+
+// Item is an archived item, sub-items included, along with the comments on it.
+type Item struct {
+	item.Item
+	Comments []comment.Comment `json:"comments,omitempty"`
+}
+
+// InvalidError is returned by Import for archives that are not well formed, such as
+// ones referring to folders or items they don't hold.
+type InvalidError string
+
+// Error implements the error interface.
+func (i InvalidError) Error() string {
+	return string(i)
+}
+
+// Strategy is what Import does with a list in an archive named the same as a list
+// that already exists.
+type Strategy string
+
+// Strategies for importing lists whose names are taken.
+const (
+	// StrategySkip leaves the existing list as it is and doesn't import the list.
+	StrategySkip Strategy = "skip"
+
+	// StrategyRename imports the list under its name followed by the first free
+	// number, such as "Grocery (2)".
+	StrategyRename Strategy = "rename"
+
+	// StrategyOverwrite replaces the fields and items of the existing list with those
+	// of the imported list. The existing list keeps its id and webhooks.
+	StrategyOverwrite Strategy = "overwrite"
+)
+
+// Statuses of the lists in a Report.
+const (
+	StatusCreated     = "created"
+	StatusRenamed     = "renamed"
+	StatusOverwritten = "overwritten"
+	StatusSkipped     = "skipped"
+)
+
+// Report reports what Import did with an archive. Folders is the number of folders
+// created, folders are reused when a folder with the same name already exists in
+// the same place.
+type Report struct {
+	Folders int      `json:"folders"`
+	Lists   []Result `json:"lists"`
+}
+
+// Result is what Import did with a list. ArchiveID is the id of the list in the
+// archive and ID its id after the import, which is 0 for skipped lists. Items is the
+// number of items imported onto it.
+type Result struct {
+	ArchiveID int    `json:"archiveID"`
+	ID        int    `json:"id"`
+	Name      string `json:"name"`
+	Status    string `json:"status"`
+	Items     int    `json:"items"`
+}
+
+// Export reads everything kept by the list daemon into an archive. It must be the
+// first statement of tx, so that the archive is read from a single snapshot of the
+// database.
+func Export(tx *sqlx.Tx) (Archive, error) {
+	if _, err := tx.Exec(snapshot); err != nil {
+		return Archive{}, errors.Wrap(err, "set transaction snapshot")
+	}
+
+	a := Archive{
+		Version:  Version,
+		Exported: time.Now().UTC(),
+		Folders:  make([]folder.Folder, 0),
+		Lists:    make([]List, 0),
+	}
+
+	if err := tx.Select(&a.Folders, selectFolders); err != nil {
+		return Archive{}, errors.Wrap(err, "select all rows from folder table")
+	}
+
+	var lists []list.List
+	if err := tx.Select(&lists, selectLists); err != nil {
+		return Archive{}, errors.Wrap(err, "select all rows from list table")
+	}
+
+	var items []item.Item
+	if err := tx.Select(&items, selectItems); err != nil {
+		return Archive{}, errors.Wrap(err, "select all rows from item table")
+	}
+
+	var comments []comment.Comment
+	if err := tx.Select(&comments, selectComments); err != nil {
+		return Archive{}, errors.Wrap(err, "select all rows from item_comment table")
+	}
+
+	commentsOf := make(map[int][]comment.Comment)
+	for _, c := range comments {
+		commentsOf[c.ItemID] = append(commentsOf[c.ItemID], c)
+	}
+
+	itemsOf := make(map[int][]Item)
+	for _, i := range items {
+		itemsOf[i.ListID] = append(itemsOf[i.ListID], Item{Item: i, Comments: commentsOf[i.ID]})
+	}
+
+	for _, l := range lists {
+		archived := List{
+			List:  l,
+			Items: itemsOf[l.ID],
+		}
+
+		if archived.Items == nil {
+			archived.Items = make([]Item, 0)
+		}
+
+		a.Lists = append(a.Lists, archived)
+	}
+
+	return a, nil
+}
+
+// Import restores the folders and lists of an archive, along with their items, in
+// tx. Lists named the same as an existing list are imported following s. Fields of
+// the archived folders, lists, items and comments are expected to have been
+// validated by the caller, an InvalidError is returned when the archive as a whole
+// isn't well formed. Lists, items and comments are created anew, their created
+// times are those of the import.
+func Import(tx *sqlx.Tx, a Archive, s Strategy) (Report, error) {
+	switch s {
+	case StrategySkip, StrategyRename, StrategyOverwrite:
+	default:
+		return Report{}, InvalidError(fmt.Sprintf("unknown strategy %q, expected skip, rename or overwrite", s))
+	}
+
+	a, err := prepare(a)
+	if err != nil {
+		return Report{}, err
+	}
+
+	r := Report{
+		Lists: make([]Result, 0, len(a.Lists)),
+	}
+
+	folders := make(map[int]int)
+	for _, f := range a.Folders {
+		var created bool
+		if folders[f.ID], created, err = importFolder(tx, f, folders); err != nil {
+			return Report{}, errors.Wrapf(err, "import folder %d", f.ID)
+		}
+
+		if created {
+			r.Folders++
+		}
+	}
+
+	// Names of the archived lists are kept free of renamed lists, so that a list
+	// renamed to "Grocery (2)" is never taken for an archived list of that name.
+	reserved := make(map[string]bool)
+	for _, l := range a.Lists {
+		reserved[l.Name] = true
+	}
+
+	for _, l := range a.Lists {
+		res, err := importList(tx, l, folders, s, reserved)
+		if err != nil {
+			return Report{}, errors.Wrapf(err, "import list %d", l.ID)
+		}
+
+		r.Lists = append(r.Lists, res)
+	}
+
+	return r, nil
+}
+
+// prepare checks that the folders and items of an archive form trees, that the
+// lists of an archive are in archived folders and that they are named uniquely. It
+// returns the archive with the folders and items of each list ordered so that
+// parents come before their children.
+func prepare(a Archive) (Archive, error) {
+	if a.Version != Version {
+		return Archive{}, InvalidError(fmt.Sprintf("unsupported archive version %d, expected %d", a.Version, Version))
+	}
+
+	folders := make(map[int]folder.Folder, len(a.Folders))
+	parents := make(map[int]*int, len(a.Folders))
+	ids := make([]int, 0, len(a.Folders))
+	for _, f := range a.Folders {
+		if _, ok := folders[f.ID]; ok {
+			return Archive{}, InvalidError(fmt.Sprintf("folder %d is archived more than once", f.ID))
+		}
+
+		folders[f.ID] = f
+		parents[f.ID] = f.ParentID
+		ids = append(ids, f.ID)
+	}
+
+	ordered, err := order("folder", ids, parents)
+	if err != nil {
+		return Archive{}, err
+	}
+
+	prepared := Archive{
+		Version:  a.Version,
+		Exported: a.Exported,
+		Folders:  make([]folder.Folder, 0, len(ordered)),
+		Lists:    make([]List, 0, len(a.Lists)),
+	}
+
+	for _, id := range ordered {
+		prepared.Folders = append(prepared.Folders, folders[id])
+	}
+
+	names := make(map[string]bool)
+	for _, l := range a.Lists {
+		if names[l.Name] {
+			return Archive{}, InvalidError(fmt.Sprintf("more than one list is named %q", l.Name))
+		}
+		names[l.Name] = true
+
+		if l.FolderID != nil {
+			if _, ok := folders[*l.FolderID]; !ok {
+				return Archive{}, InvalidError(fmt.Sprintf("folder %d of list %q is not in the archive", *l.FolderID, l.Name))
+			}
+		}
+
+		items := make(map[int]Item, len(l.Items))
+		parents := make(map[int]*int, len(l.Items))
+		ids := make([]int, 0, len(l.Items))
+		for _, i := range l.Items {
+			if _, ok := items[i.ID]; ok {
+				return Archive{}, InvalidError(fmt.Sprintf("item %d of list %q is archived more than once", i.ID, l.Name))
+			}
+
+			items[i.ID] = i
+			parents[i.ID] = i.ParentID
+			ids = append(ids, i.ID)
+		}
+
+		ordered, err := order("item", ids, parents)
+		if err != nil {
+			return Archive{}, InvalidError(fmt.Sprintf("list %q: %v", l.Name, err))
+		}
+
+		p := List{
+			List:  l.List,
+			Items: make([]Item, 0, len(ordered)),
+		}
+
+		for _, id := range ordered {
+			p.Items = append(p.Items, items[id])
+		}
+
+		prepared.Lists = append(prepared.Lists, p)
+	}
+
+	return prepared, nil
+}
+
+// order returns ids ordered so that parents come before their children, parents
+// mapping each id to the id of its parent. Ids are otherwise kept in their order.
+// An InvalidError naming kind is returned when a parent isn't one of the ids or the
+// ids form a cycle.
+func order(kind string, ids []int, parents map[int]*int) ([]int, error) {
+	const (
+		visiting = 1
+		visited  = 2
+	)
+
+	state := make(map[int]int, len(ids))
+	ordered := make([]int, 0, len(ids))
+
+	var visit func(id int) error
+	visit = func(id int) error {
+		switch state[id] {
+		case visiting:
+			return InvalidError(fmt.Sprintf("%s %d is inside of itself", kind, id))
+		case visited:
+			return nil
+		}
+
+		state[id] = visiting
+		if p := parents[id]; p != nil {
+			if _, ok := parents[*p]; !ok {
+				return InvalidError(fmt.Sprintf("parent %d of %s %d is not in the archive", *p, kind, id))
+			}
+
+			if err := visit(*p); err != nil {
+				return err
+			}
+		}
+
+		state[id] = visited
+		ordered = append(ordered, id)
+		return nil
+	}
+
+	for _, id := range ids {
+		if err := visit(id); err != nil {
+			return nil, err
+		}
+	}
+
+	return ordered, nil
+}
+
+// importFolder imports an archived folder into the folder mapped to by its parent,
+// reusing a folder of the same name already there. ids maps the ids of archived
+// folders to the ids of the folders imported. The id of the folder is returned along
+// with whether it was created.
+func importFolder(tx *sqlx.Tx, f folder.Folder, ids map[int]int) (int, bool, error) {
+	var parentID *int
+	if f.ParentID != nil {
+		id := ids[*f.ParentID]
+		parentID = &id
+	}
+
+	var id int
+	err := tx.QueryRow(selectFolderByName, parentID, f.Name).Scan(&id)
+	if err == nil {
+		return id, false, nil
+	}
+	if err != sql.ErrNoRows {
+		return 0, false, errors.Wrap(err, "select folder row by name")
+	}
+
+	created, err := folder.CreateFolder(tx, folder.Folder{ParentID: parentID, Name: f.Name})
+	if err != nil {
+		return 0, false, err
+	}
+
+	return created.ID, true, nil
+}
+
+// importList imports an archived list, along with its items, into the folder mapped
+// to by folders. A list named the same as an existing list is imported following s.
+// A list renamed takes a name not in reserved, which is then reserved.
+func importList(tx *sqlx.Tx, l List, folders map[int]int, s Strategy, reserved map[string]bool) (Result, error) {
+	res := Result{
+		ArchiveID: l.ID,
+		Name:      l.Name,
+		Status:    StatusCreated,
+	}
+
+	payload := list.List{
+		Name:           l.Name,
+		Notes:          l.Notes,
+		Budget:         l.Budget,
+		BudgetCurrency: l.BudgetCurrency,
+	}
+
+	if l.FolderID != nil {
+		id := folders[*l.FolderID]
+		payload.FolderID = &id
+	}
+
+	var existing list.List
+	err := tx.QueryRowx(selectListByName, l.Name).StructScan(&existing)
+	if err != nil && err != sql.ErrNoRows {
+		return Result{}, errors.Wrap(err, "select list row by name")
+	}
+
+	switch {
+	case err == sql.ErrNoRows:
+		created, err := list.CreateList(tx, payload)
+		if err != nil {
+			return Result{}, err
+		}
+		res.ID = created.ID
+
+	case s == StrategySkip:
+		res.Status = StatusSkipped
+		return res, nil
+
+	case s == StrategyRename:
+		if payload.Name, err = freeName(tx, l.Name, reserved); err != nil {
+			return Result{}, err
+		}
+
+		created, err := list.CreateList(tx, payload)
+		if err != nil {
+			return Result{}, err
+		}
+		res.ID = created.ID
+		res.Name = created.Name
+		res.Status = StatusRenamed
+
+	case s == StrategyOverwrite:
+		if err := overwriteList(tx, existing, payload); err != nil {
+			return Result{}, err
+		}
+		res.ID = existing.ID
+		res.Status = StatusOverwritten
+	}
+
+	ids := make(map[int]int, len(l.Items))
+	for _, i := range l.Items {
+		var err error
+		if ids[i.ID], err = importItem(tx, res.ID, i, ids); err != nil {
+			return Result{}, errors.Wrapf(err, "import item %d", i.ID)
+		}
+		res.Items++
+	}
+
+	return res, nil
+}
+
+// freeName returns name followed by the first number, from 2, that makes it a name
+// neither taken by a list nor in reserved, and reserves it.
+func freeName(tx *sqlx.Tx, name string, reserved map[string]bool) (string, error) {
+	for n := 2; ; n++ {
+		suffix := fmt.Sprintf(" (%d)", n)
+
+		base := name
+		for utf8.RuneCountInString(base)+len(suffix) > maxNameLength {
+			_, size := utf8.DecodeLastRuneInString(base)
+			base = base[:len(base)-size]
+		}
+
+		candidate := base + suffix
+		if reserved[candidate] {
+			continue
+		}
+
+		var taken list.List
+		err := tx.QueryRowx(selectListByName, candidate).StructScan(&taken)
+		if err == sql.ErrNoRows {
+			reserved[candidate] = true
+			return candidate, nil
+		}
+		if err != nil {
+			return "", errors.Wrap(err, "select list row by name")
+		}
+	}
+}
+
+// overwriteList replaces the fields of the existing list with those of payload,
+// moving it into the folder of payload, and deletes every item on it.
+func overwriteList(tx *sqlx.Tx, existing, payload list.List) error {
+	payload.ID = existing.ID
+	if err := list.UpdateList(tx, payload); err != nil {
+		return err
+	}
+
+	if !sameFolder(existing.FolderID, payload.FolderID) {
+		if _, err := folder.MoveList(tx, existing.ID, payload.FolderID); err != nil {
+			return err
+		}
+	}
+
+	var items []int
+	if err := tx.Select(&items, selectTopLevelItems, existing.ID); err != nil {
+		return errors.Wrap(err, "select top level item rows of list")
+	}
+
+	// Sub-items are deleted along with the items they are inside of.
+	for _, id := range items {
+		if err := item.DeleteItem(tx, id, existing.ID); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// sameFolder reports whether two optional folder ids are the same.
+func sameFolder(a, b *int) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+
+	return *a == *b
+}
+
+// importItem creates an archived item, along with its tags and comments, on the list
+// given by listID. ids maps the ids of archived items to the ids of the items
+// imported, the parent of the item must have been imported already. The id of the
+// item is returned.
+func importItem(tx *sqlx.Tx, listID int, i Item, ids map[int]int) (int, error) {
+	payload := i.Item
+	payload.ListID = listID
+
+	if i.ParentID != nil {
+		id := ids[*i.ParentID]
+		payload.ParentID = &id
+	}
+
+	created, err := item.CreateItem(tx, payload)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, tag := range i.Tags {
+		if _, err := item.AddTag(tx, created.ID, listID, tag); err != nil {
+			return 0, errors.Wrapf(err, "tag item with %q", tag)
+		}
+	}
+
+	for _, c := range i.Comments {
+		author, err := comment.NormalizeUser(c.Author)
+		if err != nil {
+			return 0, errors.Wrapf(err, "author %q of comment %d", c.Author, c.ID)
+		}
+
+		if _, err := comment.CreateComment(tx, comment.Comment{ItemID: created.ID, Author: author, Body: c.Body}, listID); err != nil {
+			return 0, errors.Wrap(err, "create comment")
+		}
+	}
+
+	return created.ID, nil
+}
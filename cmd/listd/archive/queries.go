@@ -0,0 +1,42 @@
+package archive
+
+import (
+	"github.com/george-e-shaw-iv/integration-tests-example/cmd/listd/comment"
+	"github.com/george-e-shaw-iv/integration-tests-example/cmd/listd/folder"
+	"github.com/george-e-shaw-iv/integration-tests-example/cmd/listd/item"
+	"github.com/george-e-shaw-iv/integration-tests-example/cmd/listd/list"
+)
+
+// PostgreSQL queries used in the archive package.
+const (
+	// snapshot is a statement that makes every query of the transaction it starts
+	// read the same snapshot of the database, without writing to it.
+	snapshot = "SET TRANSACTION ISOLATION LEVEL REPEATABLE READ, READ ONLY;"
+
+	// selectFolders is a query that selects all rows from the folder table.
+	selectFolders = "SELECT " + folder.Columns + " FROM folder ORDER BY folder_id;"
+
+	// selectLists is a query that selects all rows from the list table.
+	selectLists = "SELECT " + list.Columns + " FROM list ORDER BY list_id;"
+
+	// selectItems is a query that selects all rows from the item table, grouped by
+	// list_id.
+	selectItems = "SELECT " + item.Columns + " FROM item ORDER BY list_id, item_id;"
+
+	// selectComments is a query that selects all rows from the item_comment table,
+	// grouped by item_id and oldest first.
+	selectComments = "SELECT " + comment.Columns + " FROM item_comment c ORDER BY c.item_id, c.created, c.comment_id;"
+
+	// selectFolderByName is a query that selects the folder_id of the oldest row in
+	// the folder table named $2 directly inside of the folder given by $1, or at the
+	// top level when it is null.
+	selectFolderByName = "SELECT folder_id FROM folder WHERE parent_id IS NOT DISTINCT FROM $1 AND name = $2 ORDER BY folder_id LIMIT 1;"
+
+	// selectListByName is a query that selects and locks the row in the list table
+	// with the given name.
+	selectListByName = "SELECT " + list.Columns + " FROM list WHERE name = $1 FOR UPDATE;"
+
+	// selectTopLevelItems is a query that selects the item_id of every row in the
+	// item table on the list given by list_id that is not a sub-item.
+	selectTopLevelItems = "SELECT item_id FROM item WHERE list_id = $1 AND parent_id IS NULL ORDER BY item_id;"
+)
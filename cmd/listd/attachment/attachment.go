@@ -0,0 +1,177 @@
+// Package attachment keeps track of the files attached to items. The contents of
+// an attachment are kept in a blob.Store, only its metadata is kept in Postgres.
+//
+// Deleting an attachment row, directly or through the item or list it belongs to
+// being deleted, queues its blob for deletion in the blob_orphan table. Queued
+// blobs are deleted from the store by a Sweeper, so a blob is never deleted while
+// the transaction that deleted its row is still able to roll back.
+package attachment
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/george-e-shaw-iv/integration-tests-example/internal/platform/blob"
+	"github.com/george-e-shaw-iv/integration-tests-example/internal/platform/db"
+	"github.com/jmoiron/sqlx"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+// MaxPerItem is the maximum number of attachments a single item can have.
+const MaxPerItem = 20
+
+// ErrTooMany is returned when an attachment is added to an item that already has
+// MaxPerItem attachments.
+var ErrTooMany = errors.New("items can't have more than 20 attachments")
+
+// Attachment is a type that contains the proper struct tags for both
+// a JSON and Postgres representation of a file attached to an item.
+type Attachment struct {
+	ID          int       `json:"id" db:"attachment_id"`
+	ItemID      int       `json:"itemID" db:"item_id"`
+	Name        string    `json:"name" db:"name"`
+	ContentType string    `json:"contentType" db:"content_type"`
+	Size        int64     `json:"size" db:"size"`
+	Key         string    `json:"-" db:"blob_key"`
+	Created     time.Time `json:"created" db:"created"`
+}
+
+// SelectAttachments selects all rows from the attachment table given an item_id and
+// the list_id of the item.
+func SelectAttachments(dbc db.Executor, itemID, listID int) ([]Attachment, error) {
+	attachments := make([]Attachment, 0)
+
+	if err := dbc.Select(&attachments, selectAll, itemID, listID); err != nil {
+		return nil, errors.Wrap(err, "select all rows from attachment table given an item_id")
+	}
+
+	return attachments, nil
+}
+
+// SelectAttachment selects a single row from the attachment table based off of a
+// given attachment_id, item_id and the list_id of the item.
+func SelectAttachment(dbc db.Executor, id, itemID, listID int) (Attachment, error) {
+	var a Attachment
+
+	if err := dbc.QueryRowx(selectByID, id, itemID, listID).StructScan(&a); err != nil {
+		return Attachment{}, errors.Wrap(err, "select singular row from attachment table")
+	}
+
+	return a, nil
+}
+
+// CreateAttachment inserts a new row into the attachment table for an item of the
+// list given by listID. The blob of the attachment must already be stored under
+// r.Key. sql.ErrNoRows is returned when the item does not exist.
+func CreateAttachment(tx *sqlx.Tx, r Attachment, listID int) (Attachment, error) {
+	if err := tx.QueryRow(lockItem, r.ItemID, listID).Scan(&r.ItemID); err != nil {
+		if errors.Cause(err) == sql.ErrNoRows {
+			return Attachment{}, sql.ErrNoRows
+		}
+
+		return Attachment{}, errors.Wrap(err, "lock item row")
+	}
+
+	var n int
+	if err := tx.Get(&n, count, r.ItemID); err != nil {
+		return Attachment{}, errors.Wrap(err, "count attachments of item")
+	}
+
+	if n >= MaxPerItem {
+		return Attachment{}, ErrTooMany
+	}
+
+	r.Created = time.Now()
+
+	if err := tx.QueryRow(insert, r.ItemID, r.Name, r.ContentType, r.Size, r.Key, r.Created).Scan(&r.ID); err != nil {
+		return Attachment{}, errors.Wrap(err, "get inserted row id")
+	}
+
+	return r, nil
+}
+
+// DeleteAttachment deletes a row in the attachment table based off of
+// attachment_id, item_id and the list_id of the item, queueing its blob for
+// deletion.
+func DeleteAttachment(tx *sqlx.Tx, id, itemID, listID int) error {
+	if _, err := SelectAttachment(tx, id, itemID, listID); errors.Cause(err) == sql.ErrNoRows {
+		return sql.ErrNoRows
+	} else if err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec(del, id); err != nil {
+		return errors.Wrap(err, "delete attachment row")
+	}
+
+	return nil
+}
+
+// Sweeper deletes the blobs of deleted attachments from a blob.Store. A blob that
+// fails to be deleted is retried on the next run.
+type Sweeper struct {
+	DB        *sqlx.DB
+	Store     blob.Store
+	BatchSize int
+}
+
+// Run deletes orphaned blobs every interval until ctx is cancelled.
+func (s *Sweeper) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := s.Sweep(ctx); err != nil {
+				log.WithError(err).Error("sweep orphaned blobs")
+			}
+		}
+	}
+}
+
+// Sweep deletes one batch of orphaned blobs and returns how many were deleted.
+func (s *Sweeper) Sweep(ctx context.Context) (int, error) {
+	tx, err := s.DB.Beginx()
+	if err != nil {
+		return 0, errors.Wrap(err, "begin transaction")
+	}
+
+	defer func() {
+		if err := tx.Rollback(); err != nil && err != sql.ErrTxDone {
+			log.WithError(errors.Wrap(err, "rollback transaction")).Info("sweep orphaned blobs")
+		}
+	}()
+
+	var keys []string
+	if err := tx.Select(&keys, claimOrphans, s.BatchSize); err != nil {
+		return 0, errors.Wrap(err, "claim orphaned blobs")
+	}
+
+	var deleted int
+	for _, key := range keys {
+		if err := s.Store.Delete(ctx, key); err != nil {
+			log.WithFields(log.Fields{
+				"error": err,
+				"key":   key,
+			}).Error("delete orphaned blob")
+			continue
+		}
+
+		if _, err := tx.Exec(delOrphan, key); err != nil {
+			return 0, errors.Wrap(err, "delete blob_orphan row")
+		}
+
+		deleted++
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, errors.Wrap(err, "commit transaction")
+	}
+
+	return deleted, nil
+}
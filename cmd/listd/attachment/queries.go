@@ -0,0 +1,39 @@
+package attachment
+
+// PostgreSQL queries for the attachment and blob_orphan tables.
+const (
+	// selectAll is a query that selects all rows in the attachment table filtered
+	// by item_id and the list_id of the item.
+	selectAll = `SELECT a.* FROM attachment a JOIN item i ON i.item_id = a.item_id
+		WHERE a.item_id = $1 AND i.list_id = $2 ORDER BY a.attachment_id;`
+
+	// selectByID is a query that selects a row in the attachment table filtered by
+	// attachment_id, item_id and the list_id of the item.
+	selectByID = `SELECT a.* FROM attachment a JOIN item i ON i.item_id = a.item_id
+		WHERE a.attachment_id = $1 AND a.item_id = $2 AND i.list_id = $3;`
+
+	// lockItem is a query that locks the row in the item table given by item_id and
+	// list_id, serializing the uploads of attachments to the item.
+	lockItem = "SELECT item_id FROM item WHERE item_id = $1 AND list_id = $2 FOR NO KEY UPDATE;"
+
+	// count is a query that counts the rows in the attachment table given an item_id.
+	count = "SELECT count(*) FROM attachment WHERE item_id = $1;"
+
+	// insert is a query that inserts a row into the attachment table using the
+	// values given in order for item_id, name, content_type, size, blob_key and
+	// created.
+	insert = `INSERT INTO attachment (item_id, name, content_type, size, blob_key, created)
+		VALUES ($1, $2, $3, $4, $5, $6) RETURNING attachment_id;`
+
+	// del is a query that deletes a row in the attachment table given an
+	// attachment_id. The blob is queued for deletion by a trigger.
+	del = "DELETE FROM attachment WHERE attachment_id = $1;"
+
+	// claimOrphans is a query that selects and locks at most $1 rows of the
+	// blob_orphan table, skipping rows locked by other sweepers.
+	claimOrphans = "SELECT blob_key FROM blob_orphan ORDER BY created LIMIT $1 FOR UPDATE SKIP LOCKED;"
+
+	// delOrphan is a query that deletes a row in the blob_orphan table given a
+	// blob_key.
+	delOrphan = "DELETE FROM blob_orphan WHERE blob_key = $1;"
+)
@@ -0,0 +1,85 @@
+// Package calendar serves the items of a list that have due dates as an iCalendar
+// feed, so that calendar apps can subscribe to the deadlines of a list. Calendar
+// apps can't send credentials of their own, so each list has a secret token that is
+// given in the URL of its feed.
+package calendar
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"time"
+
+	"github.com/george-e-shaw-iv/integration-tests-example/cmd/listd/list"
+	"github.com/george-e-shaw-iv/integration-tests-example/internal/platform/db"
+	"github.com/pkg/errors"
+)
+
+// Token is the secret granting access to the calendar feed of a list. Only a hash
+// of it is stored, so it is only ever returned when it is created.
+type Token struct {
+	ListID  int       `json:"listID"`
+	Token   string    `json:"token"`
+	Created time.Time `json:"created"`
+}
+
+// CreateToken generates a new token for the calendar feed of a list, replacing the
+// token it had, if any, so that the feed is no longer served to anyone given it.
+func CreateToken(dbc db.Executor, listID int) (Token, error) {
+	if _, err := list.SelectList(dbc, listID); errors.Cause(err) == sql.ErrNoRows {
+		return Token{}, sql.ErrNoRows
+	}
+
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return Token{}, errors.Wrap(err, "generate calendar token")
+	}
+
+	t := Token{
+		ListID:  listID,
+		Token:   hex.EncodeToString(b),
+		Created: time.Now(),
+	}
+
+	if _, err := dbc.Exec(upsert, t.ListID, hashToken(t.Token), t.Created); err != nil {
+		return Token{}, errors.Wrap(err, "upsert calendar token row")
+	}
+
+	return t, nil
+}
+
+// DeleteToken deletes the token for the calendar feed of a list, after which the
+// feed isn't served until a new token is created.
+func DeleteToken(dbc db.Executor, listID int) error {
+	res, err := dbc.Exec(del, listID)
+	if err != nil {
+		return errors.Wrap(err, "delete calendar token row")
+	}
+
+	if n, err := res.RowsAffected(); err != nil {
+		return errors.Wrap(err, "get deleted rows")
+	} else if n == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+// CheckToken reports whether token grants access to the calendar feed of the list
+// given by listID.
+func CheckToken(dbc db.Executor, listID int, token string) (bool, error) {
+	var ok bool
+	if err := dbc.Get(&ok, check, listID, hashToken(token)); err != nil {
+		return false, errors.Wrap(err, "check calendar token")
+	}
+
+	return ok, nil
+}
+
+// hashToken returns the hex encoded SHA-256 hash of a token, which is what is
+// stored. Tokens are random, so they need no salt.
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
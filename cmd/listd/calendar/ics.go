@@ -0,0 +1,189 @@
+package calendar
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/george-e-shaw-iv/integration-tests-example/cmd/listd/item"
+	"github.com/george-e-shaw-iv/integration-tests-example/cmd/listd/list"
+)
+
+// ContentType is the media type of an iCalendar feed.
+const ContentType = "text/calendar"
+
+// The components items can be written as. Events are shown by every calendar app,
+// while to-dos carry whether an item was completed but aren't supported by all of
+// them.
+const (
+	ComponentEvent = "VEVENT"
+	ComponentTodo  = "VTODO"
+)
+
+// ValidComponent reports whether c is a component items can be written as.
+func ValidComponent(c string) bool {
+	return c == ComponentEvent || c == ComponentTodo
+}
+
+// maxLineLength is the length in bytes that lines are folded at, as required by
+// RFC 5545.
+const maxLineLength = 75
+
+// completedMark prefixes the summary of events of completed items, as events have
+// no completion status.
+const completedMark = "✓ "
+
+// UID returns the unique identifier of the calendar component of an item. It only
+// depends on the id of the item, so calendar apps keep track of an item across
+// updates of the feed.
+func UID(itemID int) string {
+	return fmt.Sprintf("item-%d@listd", itemID)
+}
+
+// Write writes the items of a list that have a due time as an iCalendar feed of
+// components of the given kind, named after the list. Items due at midnight UTC,
+// which is how due dates without a time are kept, are written as all day.
+func Write(w io.Writer, l list.List, items []item.Item, component string) error {
+	c := contentWriter{
+		w: bufio.NewWriter(w),
+	}
+
+	c.line("BEGIN", "VCALENDAR")
+	c.line("VERSION", "2.0")
+	c.line("PRODID", "-//listd//listd//EN")
+	c.line("CALSCALE", "GREGORIAN")
+	c.line("METHOD", "PUBLISH")
+	c.line("X-WR-CALNAME", escapeText(l.Name))
+
+	for _, i := range items {
+		if i.DueAt == nil {
+			continue
+		}
+
+		c.line("BEGIN", component)
+		c.line("UID", UID(i.ID))
+		c.line("DTSTAMP", formatDateTime(i.Modified))
+		c.line("CREATED", formatDateTime(i.Created))
+		c.line("LAST-MODIFIED", formatDateTime(i.Modified))
+
+		summary := i.Name
+		if component == ComponentEvent && i.CompletedAt != nil {
+			summary = completedMark + summary
+		}
+		c.line("SUMMARY", escapeText(summary))
+		c.line("DESCRIPTION", escapeText(description(i)))
+
+		if len(i.Tags) > 0 {
+			tags := make([]string, len(i.Tags))
+			for j, tag := range i.Tags {
+				tags[j] = escapeText(tag)
+			}
+			c.line("CATEGORIES", strings.Join(tags, ","))
+		}
+
+		due := i.DueAt.UTC()
+		allDay := due.Equal(due.Truncate(24 * time.Hour))
+
+		if component == ComponentTodo {
+			if allDay {
+				c.line("DUE;VALUE=DATE", due.Format("20060102"))
+			} else {
+				c.line("DUE", formatDateTime(due))
+			}
+
+			if i.ParentID != nil {
+				c.line("RELATED-TO", UID(*i.ParentID))
+			}
+
+			if i.CompletedAt != nil {
+				c.line("STATUS", "COMPLETED")
+				c.line("COMPLETED", formatDateTime(*i.CompletedAt))
+				c.line("PERCENT-COMPLETE", "100")
+			} else {
+				c.line("STATUS", "NEEDS-ACTION")
+			}
+		} else {
+			if allDay {
+				c.line("DTSTART;VALUE=DATE", due.Format("20060102"))
+				c.line("DTEND;VALUE=DATE", due.AddDate(0, 0, 1).Format("20060102"))
+			} else {
+				c.line("DTSTART", formatDateTime(due))
+			}
+
+			// Deadlines don't take up any time, so events never show as busy.
+			c.line("TRANSP", "TRANSPARENT")
+		}
+
+		c.line("END", component)
+	}
+
+	c.line("END", "VCALENDAR")
+
+	if c.err != nil {
+		return c.err
+	}
+
+	return c.w.Flush()
+}
+
+// description returns the quantity of an item followed by its notes.
+func description(i item.Item) string {
+	d := "Quantity: " + i.Quantity.String()
+	if i.Unit != "" {
+		d += " " + i.Unit
+	}
+
+	if i.Notes != "" {
+		d += "\n\n" + i.Notes
+	}
+
+	return d
+}
+
+// formatDateTime formats t as an iCalendar date-time in UTC.
+func formatDateTime(t time.Time) string {
+	return t.UTC().Format("20060102T150405Z")
+}
+
+// escapeText escapes the characters of an iCalendar text value that would
+// otherwise be read as part of the content line.
+var escapeText = strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`, "\r", `\n`).Replace
+
+// contentWriter writes iCalendar content lines, keeping the first error that
+// occurred so that it only needs checking once all lines are written.
+type contentWriter struct {
+	w   *bufio.Writer
+	err error
+}
+
+// line writes a content line of the given name and value, which must already be
+// escaped. Lines longer than maxLineLength bytes are folded onto lines starting
+// with a space, never within a UTF-8 encoded character.
+func (c *contentWriter) line(name, value string) {
+	if c.err != nil {
+		return
+	}
+
+	s := name + ":" + value
+
+	limit := maxLineLength
+	for len(s) > limit {
+		i := limit
+		for i > 0 && !utf8.RuneStart(s[i]) {
+			i--
+		}
+
+		if _, c.err = c.w.WriteString(s[:i] + "\r\n "); c.err != nil {
+			return
+		}
+		s = s[i:]
+
+		// Continuation lines start with a space, which counts towards their length.
+		limit = maxLineLength - 1
+	}
+
+	_, c.err = c.w.WriteString(s + "\r\n")
+}
@@ -0,0 +1,18 @@
+package calendar
+
+// PostgreSQL queries for the calendar_token table.
+const (
+	// upsert is a query that inserts a row into the calendar_token table, or
+	// replaces the token of the list if it already has one, using the values given
+	// in order for list_id, token_hash and created.
+	upsert = `INSERT INTO calendar_token (list_id, token_hash, created) VALUES ($1, $2, $3)
+		ON CONFLICT (list_id) DO UPDATE SET token_hash = EXCLUDED.token_hash, created = EXCLUDED.created;`
+
+	// del is a query that deletes a row in the calendar_token table given a
+	// list_id.
+	del = "DELETE FROM calendar_token WHERE list_id = $1;"
+
+	// check is a query that reports whether a row exists in the calendar_token
+	// table for the given list_id and token_hash.
+	check = "SELECT EXISTS (SELECT 1 FROM calendar_token WHERE list_id = $1 AND token_hash = $2);"
+)
@@ -0,0 +1,187 @@
+// Package comment keeps the discussion on items. A comment belongs to the user who
+// wrote it, and only they can edit or delete it. Users mentioned in the body of a
+// comment as @name are recorded so that they can find the comments mentioning them.
+package comment
+
+import (
+	"database/sql"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/george-e-shaw-iv/integration-tests-example/internal/platform/db"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/pkg/errors"
+)
+
+// Errors returned when a comment can't be written.
+var (
+	ErrInvalidUser = errors.New("user names must be 1 to 64 letters, digits, '_', '.' or '-', starting and ending with a letter, digit or '_'")
+	ErrNotAuthor   = errors.New("only the author of a comment can change it")
+)
+
+// userRE matches a normalized user name.
+var userRE = regexp.MustCompile(`^[a-z0-9_](?:[a-z0-9_.-]{0,62}[a-z0-9_])?$`)
+
+// mentionRE matches an @name mention of a user. A mention has to start the body
+// or follow a character that can't be part of a name, so that email addresses
+// aren't taken for mentions.
+var mentionRE = regexp.MustCompile(`(?:^|[^A-Za-z0-9_.@-])@([A-Za-z0-9_](?:[A-Za-z0-9_.-]{0,62}[A-Za-z0-9_])?)`)
+
+// Comment is a type that contains the proper struct tags for both a JSON and
+// Postgres representation of a comment on an item. Mentions are parsed from the
+// body, they are ignored when creating or updating a comment.
+type Comment struct {
+	ID       int            `json:"id" db:"comment_id"`
+	ItemID   int            `json:"itemID" db:"item_id"`
+	Author   string         `json:"author" db:"author"`
+	Body     string         `json:"body" db:"body"`
+	Mentions pq.StringArray `json:"mentions" db:"mentions"`
+	Created  time.Time      `json:"created" db:"created"`
+	Modified time.Time      `json:"modified" db:"modified"`
+}
+
+// NormalizeUser returns the canonical, lower case, form of a user name, or
+// ErrInvalidUser when it is not a valid user name.
+func NormalizeUser(name string) (string, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if !userRE.MatchString(name) {
+		return "", ErrInvalidUser
+	}
+
+	return name, nil
+}
+
+// ParseMentions returns the normalized names of the users mentioned in body, in
+// alphabetical order and without duplicates.
+func ParseMentions(body string) []string {
+	seen := make(map[string]bool)
+	mentions := make([]string, 0)
+
+	for _, m := range mentionRE.FindAllStringSubmatch(body, -1) {
+		name := strings.ToLower(m[1])
+		if !seen[name] {
+			seen[name] = true
+			mentions = append(mentions, name)
+		}
+	}
+
+	sort.Strings(mentions)
+
+	return mentions
+}
+
+// SelectComments selects all rows from the item_comment table given an item_id and
+// the list_id of the item, oldest first.
+func SelectComments(dbc db.Executor, itemID, listID int) ([]Comment, error) {
+	comments := make([]Comment, 0)
+
+	if err := dbc.Select(&comments, selectAll, itemID, listID); err != nil {
+		return nil, errors.Wrap(err, "select all rows from item_comment table given an item_id")
+	}
+
+	return comments, nil
+}
+
+// SelectMentions selects the limit most recent comments mentioning user, newest
+// first.
+func SelectMentions(dbc db.Executor, user string, limit int) ([]Comment, error) {
+	comments := make([]Comment, 0)
+
+	if err := dbc.Select(&comments, selectByMention, user, limit); err != nil {
+		return nil, errors.Wrap(err, "select rows from item_comment table given a mentioned user")
+	}
+
+	return comments, nil
+}
+
+// CreateComment inserts a new row into the item_comment table for an item of the
+// list given by listID, recording the users it mentions. sql.ErrNoRows is returned
+// when the item does not exist.
+func CreateComment(tx *sqlx.Tx, r Comment, listID int) (Comment, error) {
+	if err := tx.QueryRow(lockItem, r.ItemID, listID).Scan(&r.ItemID); err != nil {
+		if errors.Cause(err) == sql.ErrNoRows {
+			return Comment{}, sql.ErrNoRows
+		}
+
+		return Comment{}, errors.Wrap(err, "lock item row")
+	}
+
+	r.Created = time.Now()
+	r.Modified = r.Created
+
+	if err := tx.QueryRow(insert, r.ItemID, r.Author, r.Body, r.Created, r.Modified).Scan(&r.ID); err != nil {
+		return Comment{}, errors.Wrap(err, "get inserted row id")
+	}
+
+	r.Mentions = ParseMentions(r.Body)
+	if _, err := tx.Exec(insertMentions, r.ID, r.Mentions); err != nil {
+		return Comment{}, errors.Wrap(err, "insert item_comment_mention rows")
+	}
+
+	return r, nil
+}
+
+// UpdateComment replaces the body of a comment, along with the users it mentions,
+// on behalf of r.Author. ErrNotAuthor is returned when the comment was written by
+// someone else.
+func UpdateComment(tx *sqlx.Tx, r Comment, listID int) (Comment, error) {
+	c, err := lockComment(tx, r.ID, r.ItemID, listID, r.Author)
+	if err != nil {
+		return Comment{}, err
+	}
+
+	c.Body = r.Body
+	c.Modified = time.Now()
+
+	if _, err := tx.Exec(update, c.Body, c.Modified, c.ID); err != nil {
+		return Comment{}, errors.Wrap(err, "update item_comment row")
+	}
+
+	if _, err := tx.Exec(delMentions, c.ID); err != nil {
+		return Comment{}, errors.Wrap(err, "delete item_comment_mention rows")
+	}
+
+	c.Mentions = ParseMentions(c.Body)
+	if _, err := tx.Exec(insertMentions, c.ID, c.Mentions); err != nil {
+		return Comment{}, errors.Wrap(err, "insert item_comment_mention rows")
+	}
+
+	return c, nil
+}
+
+// DeleteComment deletes a row in the item_comment table based off of comment_id,
+// item_id and the list_id of the item on behalf of author. ErrNotAuthor is returned
+// when the comment was written by someone else.
+func DeleteComment(tx *sqlx.Tx, id, itemID, listID int, author string) error {
+	if _, err := lockComment(tx, id, itemID, listID, author); err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec(del, id); err != nil {
+		return errors.Wrap(err, "delete item_comment row")
+	}
+
+	return nil
+}
+
+// lockComment selects and locks the row of a comment in the item_comment table,
+// checking that it was written by author.
+func lockComment(tx *sqlx.Tx, id, itemID, listID int, author string) (Comment, error) {
+	var c Comment
+	if err := tx.QueryRowx(selectForUpdate, id, itemID, listID).StructScan(&c); err != nil {
+		if errors.Cause(err) == sql.ErrNoRows {
+			return Comment{}, sql.ErrNoRows
+		}
+
+		return Comment{}, errors.Wrap(err, "lock item_comment row")
+	}
+
+	if c.Author != author {
+		return Comment{}, ErrNotAuthor
+	}
+
+	return c, nil
+}
@@ -0,0 +1,50 @@
+package comment
+
+// Columns are the columns selected for a comment, along with the users it mentions.
+const Columns = `c.comment_id, c.item_id, c.author, c.body, c.created, c.modified,
+	ARRAY(SELECT m.username FROM item_comment_mention m WHERE m.comment_id = c.comment_id ORDER BY m.username) AS mentions`
+
+// PostgreSQL queries for the item_comment and item_comment_mention tables.
+const (
+	// selectAll is a query that selects all rows in the item_comment table filtered
+	// by item_id and the list_id of the item, oldest first.
+	selectAll = `SELECT ` + Columns + ` FROM item_comment c JOIN item i ON i.item_id = c.item_id
+		WHERE c.item_id = $1 AND i.list_id = $2 ORDER BY c.created, c.comment_id;`
+
+	// selectForUpdate is a query that selects and locks a row in the item_comment
+	// table filtered by comment_id, item_id and the list_id of the item.
+	selectForUpdate = `SELECT ` + Columns + ` FROM item_comment c JOIN item i ON i.item_id = c.item_id
+		WHERE c.comment_id = $1 AND c.item_id = $2 AND i.list_id = $3 FOR UPDATE OF c;`
+
+	// selectByMention is a query that selects the most recent rows in the
+	// item_comment table mentioning the user given by $1, newest first.
+	selectByMention = `SELECT ` + Columns + ` FROM item_comment c
+		JOIN item_comment_mention cm ON cm.comment_id = c.comment_id
+		WHERE cm.username = $1 ORDER BY c.created DESC, c.comment_id DESC LIMIT $2;`
+
+	// lockItem is a query that locks the row in the item table given by item_id and
+	// list_id for the duration of a comment being added to it.
+	lockItem = "SELECT item_id FROM item WHERE item_id = $1 AND list_id = $2 FOR KEY SHARE;"
+
+	// insert is a query that inserts a row into the item_comment table using the
+	// values given in order for item_id, author, body, created and modified.
+	insert = `INSERT INTO item_comment (item_id, author, body, created, modified)
+		VALUES ($1, $2, $3, $4, $5) RETURNING comment_id;`
+
+	// update is a query that updates the body and modified time of a row in the
+	// item_comment table given a comment_id.
+	update = "UPDATE item_comment SET body = $1, modified = $2 WHERE comment_id = $3;"
+
+	// del is a query that deletes a row in the item_comment table given a
+	// comment_id, its mentions are deleted along with it.
+	del = "DELETE FROM item_comment WHERE comment_id = $1;"
+
+	// insertMentions is a query that records the users given by $2 as mentioned by
+	// the comment given by $1.
+	insertMentions = `INSERT INTO item_comment_mention (comment_id, username)
+		SELECT $1, u FROM unnest($2::text[]) AS u ON CONFLICT DO NOTHING;`
+
+	// delMentions is a query that deletes all rows in the item_comment_mention table
+	// given a comment_id.
+	delMentions = "DELETE FROM item_comment_mention WHERE comment_id = $1;"
+)
@@ -0,0 +1,152 @@
+// Package csvlist reads and writes the items of a list as CSV, so that lists can be
+// moved to and from spreadsheets.
+//
+// The first row of a file is a header naming the item field held by each column.
+// Headers are matched to fields by name, ignoring case, spaces, dashes and
+// underscores, along with a few common synonyms, such as qty for quantity. Columns
+// whose header doesn't name a field are ignored. Prices are written and read in
+// the major unit of their currency, such as 12.34, multiple tags of an item are
+// separated by semicolons and times are RFC 3339 timestamps.
+package csvlist
+
+import (
+	"encoding/csv"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/george-e-shaw-iv/integration-tests-example/cmd/listd/item"
+	"github.com/george-e-shaw-iv/integration-tests-example/internal/platform/money"
+	"github.com/pkg/errors"
+)
+
+// Fields of an item that columns are able to hold.
+const (
+	FieldName        = "name"
+	FieldQuantity    = "quantity"
+	FieldUnit        = "unit"
+	FieldPrice       = "price"
+	FieldCurrency    = "currency"
+	FieldNotes       = "notes"
+	FieldDueAt       = "due_at"
+	FieldRemindAt    = "remind_at"
+	FieldRecurrence  = "recurrence"
+	FieldCompletedAt = "completed_at"
+	FieldTags        = "tags"
+)
+
+// Header is the header row written by Export, holding every field.
+var Header = []string{
+	FieldName, FieldQuantity, FieldUnit, FieldPrice, FieldCurrency, FieldNotes,
+	FieldDueAt, FieldRemindAt, FieldRecurrence, FieldCompletedAt, FieldTags,
+}
+
+// synonyms maps the normalized headers matched to a field other than the field's
+// own name to the field.
+var synonyms = map[string]string{
+	"item":        FieldName,
+	"title":       FieldName,
+	"qty":         FieldQuantity,
+	"amount":      FieldQuantity,
+	"units":       FieldUnit,
+	"unitprice":   FieldPrice,
+	"description": FieldNotes,
+	"note":        FieldNotes,
+	"due":         FieldDueAt,
+	"duedate":     FieldDueAt,
+	"remind":      FieldRemindAt,
+	"reminder":    FieldRemindAt,
+	"repeat":      FieldRecurrence,
+	"rrule":       FieldRecurrence,
+	"completed":   FieldCompletedAt,
+	"done":        FieldCompletedAt,
+	"tag":         FieldTags,
+	"labels":      FieldTags,
+}
+
+// tagSeparator separates the tags of an item within a cell.
+const tagSeparator = ";"
+
+// Field returns the field named by a header, or false if it doesn't name one.
+func Field(header string) (string, bool) {
+	h := strings.Map(func(r rune) rune {
+		switch r {
+		case ' ', '-', '_', '\t':
+			return -1
+		}
+		return r
+	}, strings.ToLower(header))
+
+	for _, f := range Header {
+		if h == strings.Replace(f, "_", "", -1) {
+			return f, true
+		}
+	}
+
+	f, ok := synonyms[h]
+	return f, ok
+}
+
+// Export writes the header and a row for every item to w. Sub-items are written as
+// rows of their own, the tree they form is not kept.
+func Export(w io.Writer, items []item.Item) error {
+	cw := csv.NewWriter(w)
+
+	if err := cw.Write(Header); err != nil {
+		return errors.Wrap(err, "write header")
+	}
+
+	row := make([]string, len(Header))
+	for _, i := range items {
+		row[0] = escapeFormula(i.Name)
+		row[1] = i.Quantity.String()
+		row[2] = i.Unit
+		row[3] = ""
+		if i.Price != nil {
+			row[3] = money.Format(*i.Price, i.Currency)
+		}
+		row[4] = i.Currency
+		row[5] = escapeFormula(i.Notes)
+		row[6] = formatTime(i.DueAt)
+		row[7] = formatTime(i.RemindAt)
+		row[8] = i.Recurrence
+		row[9] = formatTime(i.CompletedAt)
+		row[10] = escapeFormula(strings.Join(i.Tags, tagSeparator))
+
+		if err := cw.Write(row); err != nil {
+			return errors.Wrapf(err, "write row of item %d", i.ID)
+		}
+	}
+
+	cw.Flush()
+	return errors.Wrap(cw.Error(), "flush rows")
+}
+
+// formatTime formats an optional time as an RFC 3339 timestamp in UTC.
+func formatTime(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+
+	return t.UTC().Format(time.RFC3339)
+}
+
+// escapeFormula prefixes text starting with a character spreadsheets take as the
+// start of a formula with an apostrophe, so that opening an export never runs one.
+// unescapeFormula reverses it on import.
+func escapeFormula(s string) string {
+	if s != "" && strings.ContainsRune("=+-@\t\r", rune(s[0])) {
+		return "'" + s
+	}
+
+	return s
+}
+
+// unescapeFormula removes the apostrophe added by escapeFormula.
+func unescapeFormula(s string) string {
+	if len(s) > 1 && s[0] == '\'' && strings.ContainsRune("=+-@\t\r", rune(s[1])) {
+		return s[1:]
+	}
+
+	return s
+}
@@ -0,0 +1,352 @@
+package csvlist
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/george-e-shaw-iv/integration-tests-example/cmd/listd/item"
+	"github.com/george-e-shaw-iv/integration-tests-example/cmd/listd/list"
+	"github.com/george-e-shaw-iv/integration-tests-example/internal/platform/money"
+	"github.com/george-e-shaw-iv/integration-tests-example/internal/platform/units"
+	"github.com/jmoiron/sqlx"
+	"github.com/pkg/errors"
+)
+
+// MaxRowErrors is the number of invalid rows reported by an ImportError, further
+// invalid rows are only counted.
+const MaxRowErrors = 100
+
+// RowError is an error in a row of a file. Line is the line the row starts on,
+// the header being line 1.
+type RowError struct {
+	Line int
+	Err  error
+}
+
+// Error implements the error interface.
+func (r *RowError) Error() string {
+	return fmt.Sprintf("line %d: %v", r.Line, r.Err)
+}
+
+// ImportError is returned by Import when rows of a file are invalid. Rows holds up
+// to MaxRowErrors of them and Omitted the number of invalid rows left out.
+type ImportError struct {
+	Rows    []*RowError
+	Omitted int
+}
+
+// Error implements the error interface.
+func (i *ImportError) Error() string {
+	msg := fmt.Sprintf("%d invalid rows", len(i.Rows)+i.Omitted)
+	if len(i.Rows) > 0 {
+		msg += ", first " + i.Rows[0].Error()
+	}
+
+	return msg
+}
+
+// Row is an item read from a row of a file, along with the names of its tags.
+type Row struct {
+	Line int
+	Item item.Item
+	Tags []string
+}
+
+// Decoder reads the items of a list from CSV one row at a time, so that files of
+// any size are read without holding them in memory.
+type Decoder struct {
+	r       *csv.Reader
+	columns []string
+	line    int
+}
+
+// NewDecoder reads the header of the CSV held by r and returns a decoder of the
+// rows that follow. mapping maps headers to the fields their columns hold, in
+// place of matching them by name, ignoring case. Mapping a header to no field
+// ignores its column. A name column is required.
+func NewDecoder(r io.Reader, mapping map[string]string) (*Decoder, error) {
+	d := Decoder{
+		r: csv.NewReader(r),
+	}
+
+	// Rows are allowed to leave out trailing empty cells, which spreadsheets often
+	// do, so their lengths aren't checked against the header.
+	d.r.FieldsPerRecord = -1
+	d.r.ReuseRecord = true
+
+	header, err := d.read()
+	if err == io.EOF {
+		return nil, errors.New("file is empty, a header row is required")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	override := make(map[string]string)
+	for h, f := range mapping {
+		override[strings.ToLower(strings.TrimSpace(h))] = f
+	}
+
+	seen := make(map[string]string)
+	for i, h := range header {
+		// Spreadsheets commonly save a byte order mark at the start of the file.
+		if i == 0 {
+			h = strings.TrimPrefix(h, "\ufeff")
+		}
+
+		f, mapped := override[strings.ToLower(strings.TrimSpace(h))]
+		if mapped && f != "" {
+			field, ok := Field(f)
+			if !ok {
+				return nil, errors.Errorf("header %q is mapped to unknown field %q", h, f)
+			}
+			f = field
+		} else if !mapped {
+			f, _ = Field(h)
+		}
+
+		if f != "" {
+			if other, ok := seen[f]; ok {
+				return nil, errors.Errorf("headers %q and %q both hold the %s field", other, h, f)
+			}
+			seen[f] = h
+		}
+
+		d.columns = append(d.columns, f)
+	}
+
+	if _, ok := seen[FieldName]; !ok {
+		return nil, errors.New("no header holds the name field")
+	}
+
+	return &d, nil
+}
+
+// read reads the next record, counting the lines it spans.
+func (d *Decoder) read() ([]string, error) {
+	record, err := d.r.Read()
+	if err != nil {
+		if perr, ok := err.(*csv.ParseError); ok {
+			return nil, &RowError{Line: perr.Line, Err: perr.Err}
+		}
+
+		return nil, err
+	}
+
+	d.line++
+	for _, cell := range record {
+		d.line += strings.Count(cell, "\n")
+	}
+
+	return record, nil
+}
+
+// Next returns the item read from the next row, or io.EOF once every row has been
+// read. An invalid row is returned as a *RowError, after which reading carries on
+// with the next row. Other errors end the file.
+func (d *Decoder) Next() (Row, error) {
+	start := d.line + 1
+
+	record, err := d.read()
+	if err != nil {
+		return Row{}, err
+	}
+
+	row := Row{
+		Line: start,
+		Item: item.Item{
+			Quantity: units.Int(1),
+		},
+	}
+
+	var price string
+	for i, cell := range record {
+		if i >= len(d.columns) {
+			break
+		}
+
+		cell = strings.TrimSpace(cell)
+		if cell == "" {
+			continue
+		}
+
+		if err := decodeCell(&row, d.columns[i], cell, &price); err != nil {
+			return Row{}, &RowError{Line: start, Err: err}
+		}
+	}
+
+	if price != "" {
+		if row.Item.Currency == "" {
+			return Row{}, &RowError{Line: start, Err: errors.New("price must be given along with a currency")}
+		}
+
+		currency, err := money.Currency(row.Item.Currency)
+		if err != nil {
+			return Row{}, &RowError{Line: start, Err: err}
+		}
+
+		p, err := money.Parse(price, currency)
+		if err != nil {
+			return Row{}, &RowError{Line: start, Err: errors.Wrap(err, "price")}
+		}
+
+		row.Item.Price = &p
+	}
+
+	return row, nil
+}
+
+// decodeCell decodes a non-empty cell holding field f into row. The price is only
+// kept as text, as it can't be parsed before the currency is known.
+func decodeCell(row *Row, f, cell string, price *string) error {
+	var err error
+
+	switch f {
+	case FieldName:
+		row.Item.Name = unescapeFormula(cell)
+	case FieldQuantity:
+		row.Item.Quantity, err = units.ParseAmount(cell)
+	case FieldUnit:
+		row.Item.Unit = cell
+	case FieldPrice:
+		*price = cell
+	case FieldCurrency:
+		row.Item.Currency = cell
+	case FieldNotes:
+		row.Item.Notes = unescapeFormula(cell)
+	case FieldDueAt:
+		row.Item.DueAt, err = parseTime(cell)
+	case FieldRemindAt:
+		row.Item.RemindAt, err = parseTime(cell)
+	case FieldRecurrence:
+		row.Item.Recurrence = cell
+	case FieldCompletedAt:
+		switch strings.ToLower(cell) {
+		case "true", "yes", "x":
+			now := time.Now()
+			row.Item.CompletedAt = &now
+		case "false", "no":
+		default:
+			row.Item.CompletedAt, err = parseTime(cell)
+		}
+	case FieldTags:
+		for _, tag := range strings.Split(unescapeFormula(cell), tagSeparator) {
+			if strings.TrimSpace(tag) == "" {
+				continue
+			}
+
+			var name string
+			if name, err = item.NormalizeTag(tag); err != nil {
+				break
+			}
+			row.Tags = append(row.Tags, name)
+		}
+	}
+	if err != nil {
+		return errors.Wrap(err, strings.Replace(f, "_", " ", -1))
+	}
+
+	return nil
+}
+
+// parseTime parses an RFC 3339 timestamp or a date, which is taken as midnight UTC.
+func parseTime(s string) (*time.Time, error) {
+	for _, layout := range []string{time.RFC3339, "2006-01-02"} {
+		if t, err := time.Parse(layout, s); err == nil {
+			return &t, nil
+		}
+	}
+
+	return nil, errors.Errorf("%q is neither an RFC 3339 timestamp nor a YYYY-MM-DD date", s)
+}
+
+// Import creates the list l holding the items read from d, all in tx. Every row is
+// checked with validate before its item is created. If any row is invalid, an
+// *ImportError reporting the invalid rows is returned once the whole file has been
+// read and tx must be rolled back, so that either every item is imported or none.
+// The created list and the number of items imported are returned.
+func Import(tx *sqlx.Tx, l list.List, d *Decoder, validate func(item.Item) error) (list.List, int, error) {
+	l, err := list.CreateList(tx, l)
+	if err != nil {
+		return list.List{}, 0, err
+	}
+
+	var (
+		n       int
+		invalid ImportError
+	)
+
+	for {
+		row, err := d.Next()
+		if err == io.EOF {
+			break
+		}
+
+		if err == nil {
+			if verr := validate(row.Item); verr != nil {
+				err = &RowError{Line: row.Line, Err: verr}
+			}
+		}
+
+		if rerr, ok := err.(*RowError); ok {
+			if len(invalid.Rows) < MaxRowErrors {
+				invalid.Rows = append(invalid.Rows, rerr)
+			} else {
+				invalid.Omitted++
+			}
+
+			// The reader can't carry on after a malformed file.
+			if isSyntaxError(rerr.Err) {
+				break
+			}
+			continue
+		}
+		if err != nil {
+			return list.List{}, 0, errors.Wrap(err, "read csv row")
+		}
+
+		// Once a row is invalid nothing more is created, the transaction is rolled
+		// back, but the remaining rows are still checked to report them all.
+		if len(invalid.Rows) > 0 {
+			continue
+		}
+
+		if err := importRow(tx, l.ID, row); err != nil {
+			return list.List{}, 0, errors.Wrapf(err, "import line %d", row.Line)
+		}
+		n++
+	}
+
+	if len(invalid.Rows) > 0 {
+		return list.List{}, 0, &invalid
+	}
+
+	return l, n, nil
+}
+
+// importRow creates the item read from a row on the list given by listID.
+func importRow(tx *sqlx.Tx, listID int, row Row) error {
+	row.Item.ListID = listID
+
+	i, err := item.CreateItem(tx, row.Item)
+	if err != nil {
+		return err
+	}
+
+	for _, tag := range row.Tags {
+		if _, err := item.AddTag(tx, i.ID, listID, tag); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// isSyntaxError reports whether err is one of the errors the CSV reader returns for
+// malformed files.
+func isSyntaxError(err error) bool {
+	return err == csv.ErrBareQuote || err == csv.ErrQuote || err == csv.ErrFieldCount
+}
@@ -0,0 +1,26 @@
+package delta
+
+import (
+	"github.com/george-e-shaw-iv/integration-tests-example/cmd/listd/item"
+	"github.com/george-e-shaw-iv/integration-tests-example/cmd/listd/list"
+)
+
+// PostgreSQL queries for the changes made to the list and item tables.
+const (
+	// horizon is a query that selects the id of the oldest transaction that is
+	// still in progress. Every transaction with a lower id has either committed or
+	// aborted, so its changes can no longer appear behind a sync token.
+	horizon = "SELECT txid_snapshot_xmin(txid_current_snapshot());"
+
+	// selectLists is a query that selects the rows in the list table changed by
+	// transactions with an id in the range [$1, $2).
+	selectLists = "SELECT " + list.Columns + " FROM list WHERE version >= $1 AND version < $2 ORDER BY version, list_id;"
+
+	// selectItems is a query that selects the rows in the item table changed by
+	// transactions with an id in the range [$1, $2).
+	selectItems = "SELECT " + item.Columns + " FROM item WHERE version >= $1 AND version < $2 ORDER BY version, item_id;"
+
+	// selectTombstones is a query that selects the rows in the tombstone table
+	// written by transactions with an id in the range [$1, $2).
+	selectTombstones = "SELECT kind, id, list_id, deleted FROM tombstone WHERE version >= $1 AND version < $2 ORDER BY version;"
+)
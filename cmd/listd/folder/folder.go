@@ -0,0 +1,270 @@
+// Package folder groups lists into a hierarchy of folders. A list belongs to at
+// most one folder, lists and folders that belong to none are at the top level.
+package folder
+
+import (
+	"database/sql"
+	"strconv"
+	"time"
+
+	"github.com/george-e-shaw-iv/integration-tests-example/cmd/listd/list"
+	"github.com/george-e-shaw-iv/integration-tests-example/internal/platform/db"
+	"github.com/george-e-shaw-iv/integration-tests-example/internal/platform/outbox"
+	"github.com/jmoiron/sqlx"
+	"github.com/pkg/errors"
+)
+
+// MaxDepth is the maximum number of levels of nested folders, counting top level
+// folders as the first level.
+const MaxDepth = 8
+
+// TreeError is returned when a change would leave the folders in a shape that is
+// not a tree of at most MaxDepth levels.
+type TreeError string
+
+// Error implements the error interface.
+func (t TreeError) Error() string {
+	return string(t)
+}
+
+// Errors describing why a folder can't be used as a parent.
+const (
+	ErrParentNotFound TreeError = "folder does not exist"
+	ErrCycle          TreeError = "a folder can't be moved into itself or one of its sub-folders"
+	ErrTooDeep        TreeError = "folders can't be nested more than 8 levels deep"
+)
+
+// Folder is a type that contains the proper struct tags for both
+// a JSON and Postgres representation of a folder.
+type Folder struct {
+	ID       int       `json:"id" db:"folder_id"`
+	ParentID *int      `json:"parentID,omitempty" db:"parent_id"`
+	Name     string    `json:"name" db:"name"`
+	Created  time.Time `json:"created" db:"created"`
+	Modified time.Time `json:"modified" db:"modified"`
+}
+
+// Contents are the folders and lists directly inside of a folder, or at the top
+// level when Folder is nil.
+type Contents struct {
+	Folder  *Folder     `json:"folder"`
+	Folders []Folder    `json:"folders"`
+	Lists   []list.List `json:"lists"`
+}
+
+// SelectFolder selects a single row from the folder table based off of a given
+// folder_id.
+func SelectFolder(dbc db.Executor, id int) (Folder, error) {
+	var f Folder
+
+	if err := dbc.QueryRowx(selectByID, id).StructScan(&f); err != nil {
+		return Folder{}, errors.Wrap(err, "select singular row from folder table")
+	}
+
+	return f, nil
+}
+
+// SelectContents selects the folders and lists directly inside of the folder given
+// by id, or at the top level when id is nil.
+func SelectContents(dbc db.Executor, id *int) (Contents, error) {
+	var c Contents
+
+	if id != nil {
+		f, err := SelectFolder(dbc, *id)
+		if err != nil {
+			return Contents{}, err
+		}
+		c.Folder = &f
+	}
+
+	c.Folders = make([]Folder, 0)
+	if err := dbc.Select(&c.Folders, selectChildren, id); err != nil {
+		return Contents{}, errors.Wrap(err, "select child rows from folder table")
+	}
+
+	c.Lists = make([]list.List, 0)
+	if err := dbc.Select(&c.Lists, selectLists, id); err != nil {
+		return Contents{}, errors.Wrap(err, "select rows from list table given a folder_id")
+	}
+
+	return c, nil
+}
+
+// CreateFolder inserts a new row into the folder table.
+func CreateFolder(tx *sqlx.Tx, r Folder) (Folder, error) {
+	r.Created = time.Now()
+	r.Modified = time.Now()
+
+	if _, err := tx.Exec(lock); err != nil {
+		return Folder{}, errors.Wrap(err, "lock folder table")
+	}
+
+	if err := checkParent(tx, 0, r.ParentID); err != nil {
+		return Folder{}, err
+	}
+
+	if err := tx.QueryRow(insert, r.ParentID, r.Name, r.Created, r.Modified).Scan(&r.ID); err != nil {
+		return Folder{}, errors.Wrap(err, "get inserted row id")
+	}
+
+	return r, nil
+}
+
+// UpdateFolder updates a row in the folder table based off of a folder_id. The only
+// field able to be updated is the name field, folders are moved with MoveFolder.
+func UpdateFolder(tx *sqlx.Tx, r Folder) (Folder, error) {
+	current, err := SelectFolder(tx, r.ID)
+	if err != nil {
+		return Folder{}, err
+	}
+
+	current.Name = r.Name
+	current.Modified = time.Now()
+
+	if _, err := tx.Exec(update, current.Name, current.Modified, current.ID); err != nil {
+		return Folder{}, errors.Wrap(err, "update folder row")
+	}
+
+	return current, nil
+}
+
+// MoveFolder moves the folder given by id, along with everything inside of it, into
+// the folder given by parentID. A nil parentID moves it to the top level.
+func MoveFolder(tx *sqlx.Tx, id int, parentID *int) (Folder, error) {
+	if _, err := tx.Exec(lock); err != nil {
+		return Folder{}, errors.Wrap(err, "lock folder table")
+	}
+
+	if _, err := SelectFolder(tx, id); errors.Cause(err) == sql.ErrNoRows {
+		return Folder{}, sql.ErrNoRows
+	}
+
+	if err := checkParent(tx, id, parentID); err != nil {
+		return Folder{}, err
+	}
+
+	var f Folder
+	if err := tx.QueryRowx(move, parentID, time.Now(), id).StructScan(&f); err != nil {
+		return Folder{}, errors.Wrap(err, "move folder row")
+	}
+
+	return f, nil
+}
+
+// DeleteFolder deletes a row in the folder table based off of folder_id. The folders
+// and lists inside of it are moved into its parent rather than deleted.
+func DeleteFolder(tx *sqlx.Tx, id int) error {
+	if _, err := tx.Exec(lock); err != nil {
+		return errors.Wrap(err, "lock folder table")
+	}
+
+	f, err := SelectFolder(tx, id)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+
+	if _, err := tx.Exec(moveChildren, id, f.ParentID, now); err != nil {
+		return errors.Wrap(err, "move child folder rows to parent")
+	}
+
+	var moved []list.List
+	if err := tx.Select(&moved, moveChildLists, id, f.ParentID, now); err != nil {
+		return errors.Wrap(err, "move child list rows to parent")
+	}
+
+	for _, l := range moved {
+		if err := outbox.Write(tx, list.EventUpdated, strconv.Itoa(l.ID), l); err != nil {
+			return errors.Wrap(err, "write list updated event")
+		}
+	}
+
+	if _, err := tx.Exec(del, id); err != nil {
+		return errors.Wrap(err, "delete folder row")
+	}
+
+	return nil
+}
+
+// MoveList moves the list given by listID into the folder given by folderID. A nil
+// folderID moves it to the top level.
+func MoveList(tx *sqlx.Tx, listID int, folderID *int) (list.List, error) {
+	if _, err := tx.Exec(lock); err != nil {
+		return list.List{}, errors.Wrap(err, "lock folder table")
+	}
+
+	if _, err := list.SelectList(tx, listID); errors.Cause(err) == sql.ErrNoRows {
+		return list.List{}, sql.ErrNoRows
+	}
+
+	if err := CheckFolder(tx, folderID); err != nil {
+		return list.List{}, err
+	}
+
+	var l list.List
+	if err := tx.QueryRowx(moveList, folderID, time.Now(), listID).StructScan(&l); err != nil {
+		return list.List{}, errors.Wrap(err, "move list row")
+	}
+
+	if err := outbox.Write(tx, list.EventUpdated, strconv.Itoa(l.ID), l); err != nil {
+		return list.List{}, errors.Wrap(err, "write list updated event")
+	}
+
+	return l, nil
+}
+
+// CheckFolder returns ErrParentNotFound when the folder given by id does not exist.
+// A nil id is the top level, which always exists.
+func CheckFolder(dbc db.Executor, id *int) error {
+	if id == nil {
+		return nil
+	}
+
+	if _, err := SelectFolder(dbc, *id); err != nil {
+		if errors.Cause(err) == sql.ErrNoRows {
+			return ErrParentNotFound
+		}
+
+		return err
+	}
+
+	return nil
+}
+
+// checkParent verifies that the folder given by id, which is 0 for a folder not
+// created yet, can be placed inside of parentID without forming a cycle or
+// exceeding MaxDepth.
+func checkParent(tx *sqlx.Tx, id int, parentID *int) error {
+	if parentID == nil {
+		return nil
+	}
+
+	var ancestors []int
+	if err := tx.Select(&ancestors, selectAncestors, *parentID, MaxDepth); err != nil {
+		return errors.Wrap(err, "select ancestors of parent folder")
+	}
+
+	if len(ancestors) == 0 {
+		return ErrParentNotFound
+	}
+
+	for _, a := range ancestors {
+		if a == id {
+			return ErrCycle
+		}
+	}
+
+	height := 1
+	if id != 0 {
+		if err := tx.QueryRow(selectHeight, id).Scan(&height); err != nil {
+			return errors.Wrap(err, "select height of folder subtree")
+		}
+	}
+
+	if len(ancestors)+height > MaxDepth {
+		return ErrTooDeep
+	}
+
+	return nil
+}
@@ -0,0 +1,71 @@
+package folder
+
+import "github.com/george-e-shaw-iv/integration-tests-example/cmd/listd/list"
+
+// PostgreSQL queries for the folder table and the folder_id of rows in the list
+// table, all used in the folder package.
+const (
+	// Columns are the columns of the folder table that map onto the Folder type, for
+	// use in place of * in queries scanning rows into a Folder.
+	Columns = "folder_id, parent_id, name, created, modified"
+
+	// selectByID is a query that selects a row from the folder table based off of
+	// the given folder_id.
+	selectByID = "SELECT " + Columns + " FROM folder WHERE folder_id = $1;"
+
+	// selectChildren is a query that selects the rows in the folder table directly
+	// inside of the given folder_id, or at the top level when it is null.
+	selectChildren = "SELECT " + Columns + " FROM folder WHERE parent_id IS NOT DISTINCT FROM $1 ORDER BY name, folder_id;"
+
+	// selectLists is a query that selects the rows in the list table directly
+	// inside of the given folder_id, or at the top level when it is null.
+	selectLists = "SELECT " + list.Columns + " FROM list WHERE folder_id IS NOT DISTINCT FROM $1 ORDER BY name;"
+
+	// insert is a query that inserts a new row in the folder table using the values
+	// given in order for parent_id, name, created, and modified.
+	insert = "INSERT INTO folder (parent_id, name, created, modified) VALUES ($1, $2, $3, $4) RETURNING folder_id;"
+
+	// update is a query that updates the name and modified time of a row in the
+	// folder table based off of folder_id.
+	update = "UPDATE folder SET name = $1, modified = $2 WHERE folder_id = $3;"
+
+	// move is a query that sets the parent_id and modified time of a row in the
+	// folder table based off of folder_id.
+	move = "UPDATE folder SET parent_id = $1, modified = $2 WHERE folder_id = $3 RETURNING " + Columns + ";"
+
+	// moveList is a query that sets the folder_id and modified time of a row in the
+	// list table based off of list_id, setting the version to the id of the current
+	// transaction.
+	moveList = "UPDATE list SET folder_id = $1, modified = $2, version = txid_current() WHERE list_id = $3 RETURNING " + list.Columns + ";"
+
+	// moveChildren is a query that moves the rows in the folder table directly
+	// inside of the folder given by $1 into the folder given by $2.
+	moveChildren = "UPDATE folder SET parent_id = $2, modified = $3 WHERE parent_id = $1;"
+
+	// moveChildLists is a query that moves the rows in the list table directly
+	// inside of the folder given by $1 into the folder given by $2, returning them.
+	moveChildLists = "UPDATE list SET folder_id = $2, modified = $3, version = txid_current() WHERE folder_id = $1 RETURNING " + list.Columns + ";"
+
+	// del is a query that deletes a row in the folder table given a folder_id.
+	del = "DELETE FROM folder WHERE folder_id = $1;"
+
+	// lock is a query that locks the folder table against concurrent changes while
+	// still allowing it to be read, so that two moves can't form a cycle together.
+	lock = "LOCK TABLE folder IN SHARE ROW EXCLUSIVE MODE;"
+
+	// selectAncestors is a query that selects the folder_id of a row in the folder
+	// table and each of its ancestors given a folder_id, nearest first, stopping
+	// after one more than the number of levels given.
+	selectAncestors = `WITH RECURSIVE ancestors AS (
+		SELECT folder_id, parent_id, 1 AS depth FROM folder WHERE folder_id = $1
+		UNION ALL SELECT f.folder_id, f.parent_id, a.depth + 1 FROM folder f
+		JOIN ancestors a ON f.folder_id = a.parent_id WHERE a.depth <= $2
+	) SELECT folder_id FROM ancestors ORDER BY depth;`
+
+	// selectHeight is a query that selects the number of levels of the subtree of
+	// the folder table rooted at the given folder_id.
+	selectHeight = `WITH RECURSIVE subtree AS (
+		SELECT folder_id, 1 AS depth FROM folder WHERE folder_id = $1
+		UNION ALL SELECT f.folder_id, s.depth + 1 FROM folder f JOIN subtree s ON f.parent_id = s.folder_id
+	) SELECT max(depth) FROM subtree;`
+)
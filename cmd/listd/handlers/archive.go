@@ -0,0 +1,139 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"mime"
+	"net/http"
+
+	"github.com/george-e-shaw-iv/integration-tests-example/cmd/listd/archive"
+	"github.com/george-e-shaw-iv/integration-tests-example/cmd/listd/comment"
+	"github.com/george-e-shaw-iv/integration-tests-example/cmd/listd/folder"
+	"github.com/george-e-shaw-iv/integration-tests-example/cmd/listd/item"
+	"github.com/george-e-shaw-iv/integration-tests-example/internal/platform/db"
+	"github.com/george-e-shaw-iv/integration-tests-example/internal/platform/web"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+// exportArchive is a handler that writes every folder and list, along with their
+// items, as a versioned JSON archive.
+func (a *Application) exportArchive(w http.ResponseWriter, r *http.Request) {
+	var ar archive.Archive
+	err := db.Transact(a.DB, func(tx *sqlx.Tx) error {
+		var err error
+		ar, err = archive.Export(tx)
+		return err
+	})
+	if err != nil {
+		web.RespondError(w, r, http.StatusInternalServerError, errors.Wrap(err, "export archive"))
+		return
+	}
+
+	name := fmt.Sprintf("listd-%s.json", ar.Exported.Format("20060102-150405"))
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": name}))
+	w.WriteHeader(http.StatusOK)
+
+	if err := json.NewEncoder(w).Encode(ar); err != nil {
+		log.WithError(errors.Wrap(err, "write archive")).Info("export archive")
+	}
+}
+
+// importArchive is a handler that restores the folders and lists of a JSON archive
+// written by exportArchive, along with their items. The onConflict URL query
+// parameter is what is done with lists named the same as an existing list, one of
+// skip, the default, rename or overwrite. Nothing is imported when any part of the
+// archive is invalid.
+func (a *Application) importArchive(w http.ResponseWriter, r *http.Request) {
+	strategy := archive.StrategySkip
+	if s := r.URL.Query().Get("onConflict"); s != "" {
+		strategy = archive.Strategy(s)
+	}
+
+	var payload archive.Archive
+	if err := json.NewDecoder(&limitedReader{r: r.Body, n: maxImportSize}).Decode(&payload); err != nil {
+		if errors.Cause(err) == errImportTooLarge {
+			web.RespondError(w, r, http.StatusRequestEntityTooLarge, err)
+			return
+		}
+
+		web.RespondError(w, r, http.StatusBadRequest, errors.Wrap(err, "unmarshal archive"))
+		return
+	}
+
+	if errs := validateArchive(payload); len(errs) > 0 {
+		web.Respond(w, r, http.StatusBadRequest, nil, errs...)
+		return
+	}
+
+	var report archive.Report
+	err := db.Transact(a.DB, func(tx *sqlx.Tx) error {
+		var err error
+		report, err = archive.Import(tx, payload, strategy)
+		return err
+	})
+	if err != nil {
+		switch cause := errors.Cause(err).(type) {
+		case archive.InvalidError, folder.TreeError, item.TreeError:
+			web.RespondError(w, r, http.StatusBadRequest, cause)
+			return
+		case *pq.Error:
+			if string(cause.Code) == db.PSQLErrUniqueConstraint {
+				web.RespondError(w, r, http.StatusConflict, errors.Wrap(err, "a list was created with the same name during the import"))
+				return
+			}
+		}
+
+		web.RespondError(w, r, http.StatusInternalServerError, errors.Wrap(err, "import archive"))
+		return
+	}
+
+	web.Respond(w, r, http.StatusOK, report)
+}
+
+// validateArchive validates the fields of every folder, list, item and comment of an
+// archive the way they are validated when created, returning an error for each
+// invalid one. Whether the archive as a whole is well formed is checked on import.
+func validateArchive(ar archive.Archive) []error {
+	var errs []error
+
+	for _, f := range ar.Folders {
+		if f.Name == "" {
+			errs = append(errs, errors.Errorf("folder %d: name key is required", f.ID))
+		}
+	}
+
+	for _, l := range ar.Lists {
+		if err := validateList(l.List); err != nil {
+			errs = append(errs, errors.Wrapf(err, "list %d", l.ID))
+		}
+
+		for _, i := range l.Items {
+			if err := validateItem(i.Item); err != nil {
+				errs = append(errs, errors.Wrapf(err, "list %d, item %d", l.ID, i.ID))
+			}
+
+			for _, tag := range i.Tags {
+				if _, err := item.NormalizeTag(tag); err != nil {
+					errs = append(errs, errors.Wrapf(err, "list %d, item %d, tag %q", l.ID, i.ID, tag))
+				}
+			}
+
+			for _, c := range i.Comments {
+				if _, err := comment.NormalizeUser(c.Author); err != nil {
+					errs = append(errs, errors.Wrapf(err, "list %d, item %d, comment %d", l.ID, i.ID, c.ID))
+				}
+
+				if err := validateCommentBody(c.Body); err != nil {
+					errs = append(errs, errors.Wrapf(err, "list %d, item %d, comment %d", l.ID, i.ID, c.ID))
+				}
+			}
+		}
+	}
+
+	return errs
+}
@@ -0,0 +1,333 @@
+package handlers
+
+import (
+	"database/sql"
+	"io"
+	"io/ioutil"
+	"mime"
+	"net/http"
+	"path"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/george-e-shaw-iv/integration-tests-example/cmd/listd/attachment"
+	"github.com/george-e-shaw-iv/integration-tests-example/cmd/listd/item"
+	"github.com/george-e-shaw-iv/integration-tests-example/internal/platform/blob"
+	"github.com/george-e-shaw-iv/integration-tests-example/internal/platform/db"
+	"github.com/george-e-shaw-iv/integration-tests-example/internal/platform/web"
+	"github.com/jmoiron/sqlx"
+	"github.com/julienschmidt/httprouter"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+// Limits on the size of uploaded attachments. The request body is allowed to be
+// somewhat larger than the file to leave room for the multipart headers.
+const (
+	maxAttachmentSize      = 10 << 20
+	maxAttachmentOverhead  = 1 << 20
+	maxAttachmentNameRunes = 255
+)
+
+// attachmentTypes are the media types, as sniffed from their contents, that files
+// are allowed to have to be attached to an item.
+var attachmentTypes = map[string]bool{
+	"image/jpeg":      true,
+	"image/png":       true,
+	"image/gif":       true,
+	"image/webp":      true,
+	"application/pdf": true,
+	"text/plain":      true,
+}
+
+// attachmentIDs returns the list, item and attachment ids given by the lid, iid
+// and aid URL parameters. The attachment id is zero when there is no aid URL
+// parameter.
+func attachmentIDs(r *http.Request) (listID, itemID, attachmentID int, err error) {
+	params := httprouter.ParamsFromContext(r.Context())
+
+	if listID, err = strconv.Atoi(params.ByName("lid")); err != nil {
+		return 0, 0, 0, errors.Wrap(err, "convert list id to integer")
+	}
+
+	if itemID, err = strconv.Atoi(params.ByName("iid")); err != nil {
+		return 0, 0, 0, errors.Wrap(err, "convert item id to integer")
+	}
+
+	if aid := params.ByName("aid"); aid != "" {
+		if attachmentID, err = strconv.Atoi(aid); err != nil {
+			return 0, 0, 0, errors.Wrap(err, "convert attachment id to integer")
+		}
+	}
+
+	return listID, itemID, attachmentID, nil
+}
+
+// getAttachments is a handler that returns the attachments of the item given by the
+// lid and iid URL parameters.
+func (a *Application) getAttachments(w http.ResponseWriter, r *http.Request) {
+	listID, itemID, _, err := attachmentIDs(r)
+	if err != nil {
+		web.RespondError(w, r, http.StatusInternalServerError, err)
+		return
+	}
+
+	if _, err := item.SelectItem(a.DB, itemID, listID); err != nil {
+		if errors.Cause(err) == sql.ErrNoRows {
+			web.RespondError(w, r, http.StatusNotFound, errors.New(http.StatusText(http.StatusNotFound)))
+			return
+		}
+
+		web.RespondError(w, r, http.StatusInternalServerError, errors.Wrap(err, "select item by id"))
+		return
+	}
+
+	attachments, err := attachment.SelectAttachments(a.DB, itemID, listID)
+	if err != nil {
+		web.RespondError(w, r, http.StatusInternalServerError, errors.Wrap(err, "select all attachment rows"))
+		return
+	}
+
+	web.Respond(w, r, http.StatusOK, attachments)
+}
+
+// createAttachment is a handler that attaches the file uploaded in the file part of
+// a multipart/form-data request to the item given by the lid and iid URL parameters.
+// The type of the file is sniffed from its contents rather than trusting the type
+// given by the client.
+func (a *Application) createAttachment(w http.ResponseWriter, r *http.Request) {
+	listID, itemID, _, err := attachmentIDs(r)
+	if err != nil {
+		web.RespondError(w, r, http.StatusInternalServerError, err)
+		return
+	}
+
+	if a.Blobs == nil {
+		web.RespondError(w, r, http.StatusNotImplemented, errors.New("attachments are not configured"))
+		return
+	}
+
+	if _, err := item.SelectItem(a.DB, itemID, listID); err != nil {
+		if errors.Cause(err) == sql.ErrNoRows {
+			web.RespondError(w, r, http.StatusNotFound, errors.New(http.StatusText(http.StatusNotFound)))
+			return
+		}
+
+		web.RespondError(w, r, http.StatusInternalServerError, errors.Wrap(err, "select item by id"))
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxAttachmentSize+maxAttachmentOverhead)
+
+	mr, err := r.MultipartReader()
+	if err != nil {
+		web.RespondError(w, r, http.StatusBadRequest, errors.Wrap(err, "read multipart request"))
+		return
+	}
+
+	var (
+		name string
+		data []byte
+	)
+	for {
+		part, err := mr.NextPart()
+		if err == io.EOF {
+			web.RespondError(w, r, http.StatusBadRequest, errors.New("file part is required"))
+			return
+		}
+		if err != nil {
+			web.RespondError(w, r, http.StatusBadRequest, errors.Wrap(err, "read multipart request"))
+			return
+		}
+
+		if part.FormName() != "file" {
+			continue
+		}
+
+		name = attachmentName(part.FileName())
+		data, err = ioutil.ReadAll(io.LimitReader(part, maxAttachmentSize+1))
+		if err != nil {
+			web.RespondError(w, r, http.StatusBadRequest, errors.Wrap(err, "read file part"))
+			return
+		}
+
+		break
+	}
+
+	if len(data) == 0 {
+		web.RespondError(w, r, http.StatusBadRequest, errors.New("file is empty"))
+		return
+	}
+
+	if len(data) > maxAttachmentSize {
+		web.RespondError(w, r, http.StatusRequestEntityTooLarge, errors.Errorf("file must not be larger than %d bytes", maxAttachmentSize))
+		return
+	}
+
+	contentType := http.DetectContentType(data)
+	if mediaType, _, err := mime.ParseMediaType(contentType); err != nil || !attachmentTypes[mediaType] {
+		web.RespondError(w, r, http.StatusUnsupportedMediaType, errors.Errorf("files of type %s can't be attached", contentType))
+		return
+	}
+
+	key, err := blob.NewKey()
+	if err != nil {
+		web.RespondError(w, r, http.StatusInternalServerError, err)
+		return
+	}
+
+	if err := a.Blobs.Put(r.Context(), key, data, contentType); err != nil {
+		web.RespondError(w, r, http.StatusInternalServerError, errors.Wrap(err, "store attachment blob"))
+		return
+	}
+
+	var at attachment.Attachment
+	err = db.Transact(a.DB, func(tx *sqlx.Tx) error {
+		var err error
+		at, err = attachment.CreateAttachment(tx, attachment.Attachment{
+			ItemID:      itemID,
+			Name:        name,
+			ContentType: contentType,
+			Size:        int64(len(data)),
+			Key:         key,
+		}, listID)
+		return err
+	})
+	if err != nil {
+		// The blob was never referenced, so it is safe to delete right away.
+		if err := a.Blobs.Delete(r.Context(), key); err != nil {
+			log.WithError(err).WithField("key", key).Error("delete unreferenced attachment blob")
+		}
+
+		switch errors.Cause(err) {
+		case sql.ErrNoRows:
+			web.RespondError(w, r, http.StatusNotFound, errors.New(http.StatusText(http.StatusNotFound)))
+		case attachment.ErrTooMany:
+			web.RespondError(w, r, http.StatusBadRequest, err)
+		default:
+			web.RespondError(w, r, http.StatusInternalServerError, errors.Wrap(err, "insert row into attachment table"))
+		}
+		return
+	}
+
+	web.Respond(w, r, http.StatusCreated, at)
+}
+
+// getAttachment is a handler that responds with the contents of the attachment given
+// by the lid, iid and aid URL parameters. Images are displayed inline, every other
+// type of file is downloaded.
+func (a *Application) getAttachment(w http.ResponseWriter, r *http.Request) {
+	listID, itemID, attachmentID, err := attachmentIDs(r)
+	if err != nil {
+		web.RespondError(w, r, http.StatusInternalServerError, err)
+		return
+	}
+
+	if a.Blobs == nil {
+		web.RespondError(w, r, http.StatusNotImplemented, errors.New("attachments are not configured"))
+		return
+	}
+
+	at, err := attachment.SelectAttachment(a.DB, attachmentID, itemID, listID)
+	if err != nil {
+		if errors.Cause(err) == sql.ErrNoRows {
+			web.RespondError(w, r, http.StatusNotFound, errors.New(http.StatusText(http.StatusNotFound)))
+			return
+		}
+
+		web.RespondError(w, r, http.StatusInternalServerError, errors.Wrap(err, "select attachment by id"))
+		return
+	}
+
+	rc, err := a.Blobs.Get(r.Context(), at.Key)
+	if err != nil {
+		if errors.Cause(err) == blob.ErrNotFound {
+			web.RespondError(w, r, http.StatusNotFound, errors.Wrap(err, "get attachment blob"))
+			return
+		}
+
+		web.RespondError(w, r, http.StatusInternalServerError, errors.Wrap(err, "get attachment blob"))
+		return
+	}
+	defer rc.Close()
+
+	disposition := "attachment"
+	if strings.HasPrefix(at.ContentType, "image/") {
+		disposition = "inline"
+	}
+
+	if d := mime.FormatMediaType(disposition, map[string]string{"filename": asciiName(at.Name)}); d != "" {
+		disposition = d
+	}
+
+	w.Header().Set("Content-Type", at.ContentType)
+	w.Header().Set("Content-Length", strconv.FormatInt(at.Size, 10))
+	w.Header().Set("Content-Disposition", disposition)
+	w.Header().Set("Content-Security-Policy", "default-src 'none'; sandbox")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(http.StatusOK)
+
+	if _, err := io.Copy(w, rc); err != nil {
+		log.WithError(errors.Wrap(err, "write attachment")).Info("get attachment")
+	}
+}
+
+// deleteAttachment is a handler that deletes the attachment given by the lid, iid
+// and aid URL parameters. Its blob is deleted from the store in the background.
+func (a *Application) deleteAttachment(w http.ResponseWriter, r *http.Request) {
+	listID, itemID, attachmentID, err := attachmentIDs(r)
+	if err != nil {
+		web.RespondError(w, r, http.StatusInternalServerError, err)
+		return
+	}
+
+	err = db.Transact(a.DB, func(tx *sqlx.Tx) error {
+		return attachment.DeleteAttachment(tx, attachmentID, itemID, listID)
+	})
+	if err != nil {
+		if errors.Cause(err) == sql.ErrNoRows {
+			web.RespondError(w, r, http.StatusNotFound, errors.New(http.StatusText(http.StatusNotFound)))
+			return
+		}
+
+		web.RespondError(w, r, http.StatusInternalServerError, errors.Wrap(err, "delete attachment by id"))
+		return
+	}
+
+	web.Respond(w, r, http.StatusNoContent, nil)
+}
+
+// attachmentName returns the base name of a file name given by a client, without
+// control characters and cut down to maxAttachmentNameRunes.
+func attachmentName(name string) string {
+	name = path.Base(strings.Replace(name, "\\", "/", -1))
+
+	name = strings.TrimSpace(strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) || r == unicode.ReplacementChar {
+			return -1
+		}
+		return r
+	}, name))
+
+	if runes := []rune(name); len(runes) > maxAttachmentNameRunes {
+		name = string(runes[:maxAttachmentNameRunes])
+	}
+
+	if name == "" || name == "." || name == "/" {
+		return "attachment"
+	}
+
+	return name
+}
+
+// asciiName returns name with every character that is not printable ASCII replaced,
+// so that it can be used as the file name of a Content-Disposition header.
+func asciiName(name string) string {
+	return strings.Map(func(r rune) rune {
+		if r < ' ' || r > '~' || r == '"' || r == '\\' {
+			return '_'
+		}
+		return r
+	}, name)
+}
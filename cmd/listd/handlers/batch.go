@@ -0,0 +1,369 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"net/http"
+
+	"github.com/george-e-shaw-iv/integration-tests-example/cmd/listd/folder"
+	"github.com/george-e-shaw-iv/integration-tests-example/cmd/listd/item"
+	"github.com/george-e-shaw-iv/integration-tests-example/cmd/listd/list"
+	"github.com/george-e-shaw-iv/integration-tests-example/internal/platform/db"
+	"github.com/george-e-shaw-iv/integration-tests-example/internal/platform/web"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+// maxBatchOperations is the maximum number of operations accepted by a single batch.
+const maxBatchOperations = 500
+
+// batchRequest is the payload of a batch of operations on lists and items.
+type batchRequest struct {
+	// Atomic, when true or left out, applies every operation in a single
+	// transaction that is rolled back if any operation fails. When false every
+	// operation is applied on its own.
+	Atomic     *bool            `json:"atomic"`
+	Operations []batchOperation `json:"operations"`
+}
+
+// batchOperation is a single create, update or delete of a list or item. A
+// create may name its result with Ref so that later operations in the same batch
+// can use that name in place of the id, which is not known to the client yet.
+type batchOperation struct {
+	Op     string          `json:"op"`
+	Type   string          `json:"type"`
+	Ref    string          `json:"ref"`
+	ID     batchReference  `json:"id"`
+	ListID batchReference  `json:"listID"`
+	Body   json.RawMessage `json:"body"`
+}
+
+// batchReference is an id that is either given directly as a number or as a
+// string naming the Ref of an earlier create operation.
+type batchReference struct {
+	ID  int
+	Ref string
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface for the batchReference type.
+func (b *batchReference) UnmarshalJSON(data []byte) error {
+	if err := json.Unmarshal(data, &b.Ref); err == nil {
+		return nil
+	}
+
+	return json.Unmarshal(data, &b.ID)
+}
+
+// batchResult is the outcome of a single operation, aligned by index with the
+// operations of the request.
+type batchResult struct {
+	Status int         `json:"status"`
+	ID     int         `json:"id,omitempty"`
+	Result interface{} `json:"result,omitempty"`
+	Error  string      `json:"error,omitempty"`
+}
+
+// batchError is an error raised by a batch operation along with the status code
+// describing it.
+type batchError struct {
+	code int
+	err  error
+}
+
+// Error implements the error interface.
+func (b batchError) Error() string {
+	return b.err.Error()
+}
+
+// batch is a handler that applies an ordered list of operations on lists and
+// items, returning the result of each operation in the same order.
+func (a *Application) batch(w http.ResponseWriter, r *http.Request) {
+	var payload batchRequest
+	if err := web.Decode(r, &payload); err != nil {
+		web.RespondError(w, r, http.StatusInternalServerError, errors.Wrap(err, "unmarshal request payload"))
+		return
+	}
+
+	if len(payload.Operations) == 0 {
+		web.RespondError(w, r, http.StatusBadRequest, errors.New("operations must contain at least one operation"))
+		return
+	}
+
+	if len(payload.Operations) > maxBatchOperations {
+		web.RespondError(w, r, http.StatusBadRequest, errors.Errorf("a batch is limited to %d operations", maxBatchOperations))
+		return
+	}
+
+	refs := make(map[string]int)
+	results := make([]batchResult, len(payload.Operations))
+
+	if payload.Atomic != nil && !*payload.Atomic {
+		for i, op := range payload.Operations {
+			err := db.Transact(a.DB, func(tx *sqlx.Tx) error {
+				var err error
+				results[i], err = applyOperation(tx, refs, op)
+				return err
+			})
+			if err != nil {
+				results[i] = failedResult(err)
+			}
+		}
+
+		web.Respond(w, r, http.StatusOK, results)
+		return
+	}
+
+	failed := -1
+	err := db.Transact(a.DB, func(tx *sqlx.Tx) error {
+		for i, op := range payload.Operations {
+			var err error
+			if results[i], err = applyOperation(tx, refs, op); err != nil {
+				failed = i
+				return err
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		if failed == -1 {
+			web.RespondError(w, r, http.StatusInternalServerError, errors.Wrap(err, "apply batch operations"))
+			return
+		}
+
+		// Nothing was applied, so every operation other than the one that failed is
+		// reported as such.
+		for i := range results {
+			results[i] = batchResult{
+				Status: http.StatusFailedDependency,
+				Error:  "not applied, the batch was rolled back",
+			}
+		}
+		results[failed] = failedResult(err)
+
+		log.WithFields(log.Fields{
+			"error":     err,
+			"operation": failed,
+		}).Error("batch rolled back")
+
+		web.Respond(w, r, results[failed].Status, results, errors.Errorf("operation %d failed: %s", failed, results[failed].Error))
+		return
+	}
+
+	web.Respond(w, r, http.StatusOK, results)
+}
+
+// failedResult returns the result of an operation that failed with err. Errors
+// that are not recognised are reported as a generic internal server error.
+func failedResult(err error) batchResult {
+	if berr, ok := errors.Cause(err).(batchError); ok {
+		return batchResult{
+			Status: berr.code,
+			Error:  berr.Error(),
+		}
+	}
+
+	if conflict, ok := errors.Cause(err).(*item.ConflictError); ok {
+		return batchResult{
+			Status: http.StatusConflict,
+			Result: conflict,
+			Error:  conflict.Error(),
+		}
+	}
+
+	if terr, ok := errors.Cause(err).(item.TreeError); ok {
+		return batchResult{
+			Status: http.StatusBadRequest,
+			Error:  terr.Error(),
+		}
+	}
+
+	if terr, ok := errors.Cause(err).(folder.TreeError); ok {
+		return batchResult{
+			Status: http.StatusBadRequest,
+			Error:  terr.Error(),
+		}
+	}
+
+	if errors.Cause(err) == sql.ErrNoRows {
+		return batchResult{
+			Status: http.StatusNotFound,
+			Error:  http.StatusText(http.StatusNotFound),
+		}
+	}
+
+	if pgerr, ok := errors.Cause(err).(*pq.Error); ok {
+		if string(pgerr.Code) == db.PSQLErrUniqueConstraint {
+			return batchResult{
+				Status: http.StatusBadRequest,
+				Error:  "attempting to break unique name constraint",
+			}
+		}
+	}
+
+	return batchResult{
+		Status: http.StatusInternalServerError,
+		Error:  http.StatusText(http.StatusInternalServerError),
+	}
+}
+
+// resolve returns the id named by a reference, looking up temporary ids in refs.
+func resolve(refs map[string]int, ref batchReference, field string) (int, error) {
+	if ref.Ref != "" {
+		id, ok := refs[ref.Ref]
+		if !ok {
+			return 0, batchError{http.StatusBadRequest, errors.Errorf("%s refers to unknown ref: %s", field, ref.Ref)}
+		}
+
+		return id, nil
+	}
+
+	if ref.ID == 0 {
+		return 0, batchError{http.StatusBadRequest, errors.Errorf("%s is a required field", field)}
+	}
+
+	return ref.ID, nil
+}
+
+// applyOperation applies a single batch operation as part of tx. The id of any
+// created list or item is recorded in refs under the operation's Ref.
+func applyOperation(tx *sqlx.Tx, refs map[string]int, op batchOperation) (batchResult, error) {
+	if op.Op == "create" && op.Ref != "" {
+		if _, ok := refs[op.Ref]; ok {
+			return batchResult{}, batchError{http.StatusBadRequest, errors.Errorf("ref is already in use: %s", op.Ref)}
+		}
+	}
+
+	switch op.Type {
+	case "list":
+		return applyListOperation(tx, refs, op)
+	case "item":
+		return applyItemOperation(tx, refs, op)
+	}
+
+	return batchResult{}, batchError{http.StatusBadRequest, errors.Errorf("unknown type: %s", op.Type)}
+}
+
+// applyListOperation applies a single batch operation on a list.
+func applyListOperation(tx *sqlx.Tx, refs map[string]int, op batchOperation) (batchResult, error) {
+	var payload list.List
+	if op.Op == "create" || op.Op == "update" {
+		if err := json.Unmarshal(op.Body, &payload); err != nil {
+			return batchResult{}, batchError{http.StatusBadRequest, errors.Wrap(err, "unmarshal operation body")}
+		}
+
+		if err := validateList(payload); err != nil {
+			return batchResult{}, batchError{http.StatusBadRequest, err}
+		}
+	}
+
+	switch op.Op {
+	case "create":
+		if err := folder.CheckFolder(tx, payload.FolderID); err != nil {
+			return batchResult{}, err
+		}
+
+		l, err := list.CreateList(tx, payload)
+		if err != nil {
+			return batchResult{}, err
+		}
+
+		if op.Ref != "" {
+			refs[op.Ref] = l.ID
+		}
+
+		return batchResult{Status: http.StatusCreated, ID: l.ID, Result: l}, nil
+
+	case "update":
+		id, err := resolve(refs, op.ID, "id")
+		if err != nil {
+			return batchResult{}, err
+		}
+
+		payload.ID = id
+		if err := list.UpdateList(tx, payload); err != nil {
+			return batchResult{}, err
+		}
+
+		return batchResult{Status: http.StatusOK, ID: id, Result: payload}, nil
+
+	case "delete":
+		id, err := resolve(refs, op.ID, "id")
+		if err != nil {
+			return batchResult{}, err
+		}
+
+		if err := list.DeleteList(tx, id); err != nil {
+			return batchResult{}, err
+		}
+
+		return batchResult{Status: http.StatusNoContent, ID: id}, nil
+	}
+
+	return batchResult{}, batchError{http.StatusBadRequest, errors.Errorf("unknown op: %s", op.Op)}
+}
+
+// applyItemOperation applies a single batch operation on an item.
+func applyItemOperation(tx *sqlx.Tx, refs map[string]int, op batchOperation) (batchResult, error) {
+	listID, err := resolve(refs, op.ListID, "listID")
+	if err != nil {
+		return batchResult{}, err
+	}
+
+	var payload itemUpdate
+	if op.Op == "create" || op.Op == "update" {
+		if err := json.Unmarshal(op.Body, &payload); err != nil {
+			return batchResult{}, batchError{http.StatusBadRequest, errors.Wrap(err, "unmarshal operation body")}
+		}
+
+		if err := validateItem(payload.Item); err != nil {
+			return batchResult{}, batchError{http.StatusBadRequest, err}
+		}
+	}
+
+	payload.ListID = listID
+
+	switch op.Op {
+	case "create":
+		i, err := item.CreateItem(tx, payload.Item)
+		if err != nil {
+			return batchResult{}, err
+		}
+
+		if op.Ref != "" {
+			refs[op.Ref] = i.ID
+		}
+
+		return batchResult{Status: http.StatusCreated, ID: i.ID, Result: i}, nil
+
+	case "update":
+		id, err := resolve(refs, op.ID, "id")
+		if err != nil {
+			return batchResult{}, err
+		}
+
+		payload.ID = id
+		i, err := item.UpdateItem(tx, payload.Item, payload.BaseVersion)
+		if err != nil {
+			return batchResult{}, err
+		}
+
+		return batchResult{Status: http.StatusOK, ID: id, Result: i}, nil
+
+	case "delete":
+		id, err := resolve(refs, op.ID, "id")
+		if err != nil {
+			return batchResult{}, err
+		}
+
+		if err := item.DeleteItem(tx, id, listID); err != nil {
+			return batchResult{}, err
+		}
+
+		return batchResult{Status: http.StatusNoContent, ID: id}, nil
+	}
+
+	return batchResult{}, batchError{http.StatusBadRequest, errors.Errorf("unknown op: %s", op.Op)}
+}
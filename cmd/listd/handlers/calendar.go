@@ -0,0 +1,132 @@
+package handlers
+
+import (
+	"database/sql"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/george-e-shaw-iv/integration-tests-example/cmd/listd/calendar"
+	"github.com/george-e-shaw-iv/integration-tests-example/cmd/listd/item"
+	"github.com/george-e-shaw-iv/integration-tests-example/cmd/listd/list"
+	"github.com/george-e-shaw-iv/integration-tests-example/internal/platform/web"
+	"github.com/julienschmidt/httprouter"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+// getCalendar is a handler that writes the items with due times on the list given
+// by the lid URL parameter as an iCalendar feed. The feed is only served given the
+// calendar token of the list, either as the token URL query parameter, which is
+// what calendar apps subscribing to the feed use, or as a bearer token. The
+// component URL query parameter selects whether items are written as VEVENT, the
+// default, or VTODO components.
+func (a *Application) getCalendar(w http.ResponseWriter, r *http.Request) {
+	listID, err := strconv.Atoi(httprouter.ParamsFromContext(r.Context()).ByName("lid"))
+	if err != nil {
+		web.RespondError(w, r, http.StatusInternalServerError, errors.Wrap(err, "convert list id to integer"))
+		return
+	}
+
+	component := strings.ToUpper(r.URL.Query().Get("component"))
+	if component == "" {
+		component = calendar.ComponentEvent
+	}
+
+	if !calendar.ValidComponent(component) {
+		web.RespondError(w, r, http.StatusBadRequest, errors.Errorf("component must be %s or %s", calendar.ComponentEvent, calendar.ComponentTodo))
+		return
+	}
+
+	token := r.URL.Query().Get("token")
+	if h := r.Header.Get("Authorization"); token == "" && strings.HasPrefix(h, "Bearer ") {
+		token = strings.TrimPrefix(h, "Bearer ")
+	}
+
+	if token == "" {
+		web.RespondError(w, r, http.StatusUnauthorized, errors.New("a calendar token is required"))
+		return
+	}
+
+	// Lists that don't exist have no token either, so an invalid token doesn't tell
+	// whether the list exists.
+	ok, err := calendar.CheckToken(a.DB, listID, token)
+	if err != nil {
+		web.RespondError(w, r, http.StatusInternalServerError, errors.Wrap(err, "check calendar token"))
+		return
+	}
+
+	if !ok {
+		web.RespondError(w, r, http.StatusUnauthorized, errors.New("invalid calendar token"))
+		return
+	}
+
+	l, err := list.SelectList(a.DB, listID)
+	if err != nil {
+		if errors.Cause(err) == sql.ErrNoRows {
+			web.RespondError(w, r, http.StatusNotFound, errors.New(http.StatusText(http.StatusNotFound)))
+			return
+		}
+
+		web.RespondError(w, r, http.StatusInternalServerError, errors.Wrap(err, "select list by id"))
+		return
+	}
+
+	items, err := item.SelectItems(a.DB, listID)
+	if err != nil {
+		web.RespondError(w, r, http.StatusInternalServerError, errors.Wrap(err, "select items of list"))
+		return
+	}
+
+	w.Header().Set("Content-Type", calendar.ContentType+"; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+
+	if err := calendar.Write(w, l, items, component); err != nil {
+		log.WithError(errors.Wrap(err, "write calendar")).Info("get calendar")
+	}
+}
+
+// createCalendarToken is a handler that creates a new calendar token for the list
+// given by the lid URL parameter, replacing the token it had, if any.
+func (a *Application) createCalendarToken(w http.ResponseWriter, r *http.Request) {
+	listID, err := strconv.Atoi(httprouter.ParamsFromContext(r.Context()).ByName("lid"))
+	if err != nil {
+		web.RespondError(w, r, http.StatusInternalServerError, errors.Wrap(err, "convert list id to integer"))
+		return
+	}
+
+	t, err := calendar.CreateToken(a.DB, listID)
+	if err != nil {
+		if errors.Cause(err) == sql.ErrNoRows {
+			web.RespondError(w, r, http.StatusNotFound, errors.New(http.StatusText(http.StatusNotFound)))
+			return
+		}
+
+		web.RespondError(w, r, http.StatusInternalServerError, errors.Wrap(err, "create calendar token"))
+		return
+	}
+
+	web.Respond(w, r, http.StatusCreated, t)
+}
+
+// deleteCalendarToken is a handler that deletes the calendar token of the list given
+// by the lid URL parameter, which stops its calendar feed from being served.
+func (a *Application) deleteCalendarToken(w http.ResponseWriter, r *http.Request) {
+	listID, err := strconv.Atoi(httprouter.ParamsFromContext(r.Context()).ByName("lid"))
+	if err != nil {
+		web.RespondError(w, r, http.StatusInternalServerError, errors.Wrap(err, "convert list id to integer"))
+		return
+	}
+
+	if err := calendar.DeleteToken(a.DB, listID); err != nil {
+		if errors.Cause(err) == sql.ErrNoRows {
+			web.RespondError(w, r, http.StatusNotFound, errors.New(http.StatusText(http.StatusNotFound)))
+			return
+		}
+
+		web.RespondError(w, r, http.StatusInternalServerError, errors.Wrap(err, "delete calendar token"))
+		return
+	}
+
+	web.Respond(w, r, http.StatusNoContent, nil)
+}
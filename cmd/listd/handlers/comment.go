@@ -0,0 +1,273 @@
+package handlers
+
+import (
+	"database/sql"
+	"net/http"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/george-e-shaw-iv/integration-tests-example/cmd/listd/comment"
+	"github.com/george-e-shaw-iv/integration-tests-example/cmd/listd/item"
+	"github.com/george-e-shaw-iv/integration-tests-example/internal/platform/db"
+	"github.com/george-e-shaw-iv/integration-tests-example/internal/platform/web"
+	"github.com/jmoiron/sqlx"
+	"github.com/julienschmidt/httprouter"
+	"github.com/pkg/errors"
+)
+
+// maxCommentLength is the maximum number of characters in the body of a comment.
+const maxCommentLength = 5000
+
+// Bounds of the number of comments returned by getMentions.
+const (
+	defaultMentionLimit = 20
+	maxMentionLimit     = 100
+)
+
+// requestUser returns the normalized name of the user a request is made on behalf
+// of, as given by the UserHeader of the application. The header is only as
+// trustworthy as the proxy setting it, and no request is taken to be made on
+// behalf of anyone when no UserHeader is configured.
+func (a *Application) requestUser(r *http.Request) (string, error) {
+	if a.UserHeader == "" {
+		return "", errors.New("users are not identified by this deployment")
+	}
+
+	name := r.Header.Get(a.UserHeader)
+	if name == "" {
+		return "", errors.New("the " + a.UserHeader + " header is required")
+	}
+
+	return comment.NormalizeUser(name)
+}
+
+// commentIDs returns the list, item and comment ids given by the lid, iid and cid
+// URL parameters. The comment id is zero when there is no cid URL parameter.
+func commentIDs(r *http.Request) (listID, itemID, commentID int, err error) {
+	params := httprouter.ParamsFromContext(r.Context())
+
+	if listID, err = strconv.Atoi(params.ByName("lid")); err != nil {
+		return 0, 0, 0, errors.Wrap(err, "convert list id to integer")
+	}
+
+	if itemID, err = strconv.Atoi(params.ByName("iid")); err != nil {
+		return 0, 0, 0, errors.Wrap(err, "convert item id to integer")
+	}
+
+	if cid := params.ByName("cid"); cid != "" {
+		if commentID, err = strconv.Atoi(cid); err != nil {
+			return 0, 0, 0, errors.Wrap(err, "convert comment id to integer")
+		}
+	}
+
+	return listID, itemID, commentID, nil
+}
+
+// decodeComment decodes the comment in the body of r, validating its body.
+func decodeComment(r *http.Request) (comment.Comment, int, error) {
+	var payload comment.Comment
+	if err := web.Decode(r, &payload); err != nil {
+		return comment.Comment{}, http.StatusInternalServerError, errors.Wrap(err, "unmarshal request payload")
+	}
+
+	if err := validateCommentBody(payload.Body); err != nil {
+		return comment.Comment{}, http.StatusBadRequest, err
+	}
+
+	return payload, 0, nil
+}
+
+// validateCommentBody returns an error when the body of a comment is empty or too
+// long.
+func validateCommentBody(body string) error {
+	if strings.TrimSpace(body) == "" {
+		return errors.New("body key is required")
+	}
+
+	if utf8.RuneCountInString(body) > maxCommentLength {
+		return errors.Errorf("body must not be longer than %d characters", maxCommentLength)
+	}
+
+	return nil
+}
+
+// getComments is a handler that returns the comments on the item given by the lid
+// and iid URL parameters, oldest first.
+func (a *Application) getComments(w http.ResponseWriter, r *http.Request) {
+	listID, itemID, _, err := commentIDs(r)
+	if err != nil {
+		web.RespondError(w, r, http.StatusInternalServerError, err)
+		return
+	}
+
+	if _, err := item.SelectItem(a.DB, itemID, listID); err != nil {
+		if errors.Cause(err) == sql.ErrNoRows {
+			web.RespondError(w, r, http.StatusNotFound, errors.New(http.StatusText(http.StatusNotFound)))
+			return
+		}
+
+		web.RespondError(w, r, http.StatusInternalServerError, errors.Wrap(err, "select item by id"))
+		return
+	}
+
+	comments, err := comment.SelectComments(a.DB, itemID, listID)
+	if err != nil {
+		web.RespondError(w, r, http.StatusInternalServerError, errors.Wrap(err, "select all comment rows"))
+		return
+	}
+
+	web.Respond(w, r, http.StatusOK, comments)
+}
+
+// createComment is a handler that adds a comment, written by the user the request
+// is made on behalf of, to the item given by the lid and iid URL parameters.
+func (a *Application) createComment(w http.ResponseWriter, r *http.Request) {
+	listID, itemID, _, err := commentIDs(r)
+	if err != nil {
+		web.RespondError(w, r, http.StatusInternalServerError, err)
+		return
+	}
+
+	user, err := a.requestUser(r)
+	if err != nil {
+		web.RespondError(w, r, http.StatusUnauthorized, err)
+		return
+	}
+
+	payload, code, err := decodeComment(r)
+	if err != nil {
+		web.RespondError(w, r, code, err)
+		return
+	}
+
+	payload.ItemID = itemID
+	payload.Author = user
+
+	var c comment.Comment
+	err = db.Transact(a.DB, func(tx *sqlx.Tx) error {
+		var err error
+		c, err = comment.CreateComment(tx, payload, listID)
+		return err
+	})
+	if err != nil {
+		if errors.Cause(err) == sql.ErrNoRows {
+			web.RespondError(w, r, http.StatusNotFound, errors.New(http.StatusText(http.StatusNotFound)))
+			return
+		}
+
+		web.RespondError(w, r, http.StatusInternalServerError, errors.Wrap(err, "insert row into item_comment table"))
+		return
+	}
+
+	web.Respond(w, r, http.StatusCreated, c)
+}
+
+// updateComment is a handler that replaces the body of the comment given by the
+// lid, iid and cid URL parameters. Only requests made on behalf of the author of
+// the comment, as identified by the UserHeader of the application, can update it.
+func (a *Application) updateComment(w http.ResponseWriter, r *http.Request) {
+	listID, itemID, commentID, err := commentIDs(r)
+	if err != nil {
+		web.RespondError(w, r, http.StatusInternalServerError, err)
+		return
+	}
+
+	user, err := a.requestUser(r)
+	if err != nil {
+		web.RespondError(w, r, http.StatusUnauthorized, err)
+		return
+	}
+
+	payload, code, err := decodeComment(r)
+	if err != nil {
+		web.RespondError(w, r, code, err)
+		return
+	}
+
+	payload.ID = commentID
+	payload.ItemID = itemID
+	payload.Author = user
+
+	var c comment.Comment
+	err = db.Transact(a.DB, func(tx *sqlx.Tx) error {
+		var err error
+		c, err = comment.UpdateComment(tx, payload, listID)
+		return err
+	})
+	if err != nil {
+		switch errors.Cause(err) {
+		case sql.ErrNoRows:
+			web.RespondError(w, r, http.StatusNotFound, errors.New(http.StatusText(http.StatusNotFound)))
+		case comment.ErrNotAuthor:
+			web.RespondError(w, r, http.StatusForbidden, err)
+		default:
+			web.RespondError(w, r, http.StatusInternalServerError, errors.Wrap(err, "update row in item_comment table"))
+		}
+		return
+	}
+
+	web.Respond(w, r, http.StatusOK, c)
+}
+
+// deleteComment is a handler that deletes the comment given by the lid, iid and cid
+// URL parameters. Only requests made on behalf of the author of the comment, as
+// identified by the UserHeader of the application, can delete it.
+func (a *Application) deleteComment(w http.ResponseWriter, r *http.Request) {
+	listID, itemID, commentID, err := commentIDs(r)
+	if err != nil {
+		web.RespondError(w, r, http.StatusInternalServerError, err)
+		return
+	}
+
+	user, err := a.requestUser(r)
+	if err != nil {
+		web.RespondError(w, r, http.StatusUnauthorized, err)
+		return
+	}
+
+	err = db.Transact(a.DB, func(tx *sqlx.Tx) error {
+		return comment.DeleteComment(tx, commentID, itemID, listID, user)
+	})
+	if err != nil {
+		switch errors.Cause(err) {
+		case sql.ErrNoRows:
+			web.RespondError(w, r, http.StatusNotFound, errors.New(http.StatusText(http.StatusNotFound)))
+		case comment.ErrNotAuthor:
+			web.RespondError(w, r, http.StatusForbidden, err)
+		default:
+			web.RespondError(w, r, http.StatusInternalServerError, errors.Wrap(err, "delete comment by id"))
+		}
+		return
+	}
+
+	web.Respond(w, r, http.StatusNoContent, nil)
+}
+
+// getMentions is a handler that returns the most recent comments mentioning the
+// user the request is made on behalf of, newest first. The limit URL query parameter
+// caps the number of comments returned.
+func (a *Application) getMentions(w http.ResponseWriter, r *http.Request) {
+	user, err := a.requestUser(r)
+	if err != nil {
+		web.RespondError(w, r, http.StatusUnauthorized, err)
+		return
+	}
+
+	limit := defaultMentionLimit
+	if l := r.URL.Query().Get("limit"); l != "" {
+		limit, err = strconv.Atoi(l)
+		if err != nil || limit < 1 || limit > maxMentionLimit {
+			web.RespondError(w, r, http.StatusBadRequest, errors.Errorf("limit must be an integer between 1 and %d", maxMentionLimit))
+			return
+		}
+	}
+
+	comments, err := comment.SelectMentions(a.DB, user, limit)
+	if err != nil {
+		web.RespondError(w, r, http.StatusInternalServerError, errors.Wrap(err, "select comments mentioning user"))
+		return
+	}
+
+	web.Respond(w, r, http.StatusOK, comments)
+}
@@ -0,0 +1,173 @@
+package handlers
+
+import (
+	"database/sql"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/george-e-shaw-iv/integration-tests-example/cmd/listd/csvlist"
+	"github.com/george-e-shaw-iv/integration-tests-example/cmd/listd/item"
+	"github.com/george-e-shaw-iv/integration-tests-example/cmd/listd/list"
+	"github.com/george-e-shaw-iv/integration-tests-example/internal/platform/db"
+	"github.com/george-e-shaw-iv/integration-tests-example/internal/platform/web"
+	"github.com/jmoiron/sqlx"
+	"github.com/julienschmidt/httprouter"
+	"github.com/lib/pq"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+// maxImportSize is the maximum size in bytes of an imported file.
+const maxImportSize = 32 << 20
+
+// errImportTooLarge is returned when reading an imported file larger than
+// maxImportSize.
+var errImportTooLarge = errors.Errorf("imported files must not be larger than %d MiB", maxImportSize>>20)
+
+// limitedReader reads from r, returning errImportTooLarge once more than n bytes
+// have been read.
+type limitedReader struct {
+	r io.Reader
+	n int64
+}
+
+// Read implements the io.Reader interface for the limitedReader type.
+func (l *limitedReader) Read(p []byte) (int, error) {
+	if int64(len(p)) > l.n+1 {
+		p = p[:l.n+1]
+	}
+
+	n, err := l.r.Read(p)
+	if l.n -= int64(n); l.n < 0 {
+		return 0, errImportTooLarge
+	}
+
+	return n, err
+}
+
+// exportCSV is a handler that writes the items on the list given by the lid URL
+// parameter as a CSV file.
+func (a *Application) exportCSV(w http.ResponseWriter, r *http.Request) {
+	listID, err := strconv.Atoi(httprouter.ParamsFromContext(r.Context()).ByName("lid"))
+	if err != nil {
+		web.RespondError(w, r, http.StatusInternalServerError, errors.Wrap(err, "convert list id to integer"))
+		return
+	}
+
+	l, err := list.SelectList(a.DB, listID)
+	if err != nil {
+		if errors.Cause(err) == sql.ErrNoRows {
+			web.RespondError(w, r, http.StatusNotFound, errors.New(http.StatusText(http.StatusNotFound)))
+			return
+		}
+
+		web.RespondError(w, r, http.StatusInternalServerError, errors.Wrap(err, "select list by id"))
+		return
+	}
+
+	items, err := item.SelectItems(a.DB, listID)
+	if err != nil {
+		web.RespondError(w, r, http.StatusInternalServerError, errors.Wrap(err, "select items of list"))
+		return
+	}
+
+	disposition := "attachment"
+	if d := mime.FormatMediaType(disposition, map[string]string{"filename": asciiName(l.Name) + ".csv"}); d != "" {
+		disposition = d
+	}
+
+	w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+	w.Header().Set("Content-Disposition", disposition)
+	w.WriteHeader(http.StatusOK)
+
+	if err := csvlist.Export(w, items); err != nil {
+		log.WithError(errors.Wrap(err, "write csv export")).Info("export csv")
+	}
+}
+
+// csvImport is the response to importing a CSV file.
+type csvImport struct {
+	List  list.List `json:"list"`
+	Items int       `json:"items"`
+}
+
+// importCSV is a handler that creates a list holding the items of a CSV file. The
+// list is named by the name URL query parameter. Repeating the map URL query
+// parameter as header:field maps the column with the given header to an item
+// field, or ignores the column when no field is given. Every row is validated and
+// the list is only created when all of them are valid, otherwise the invalid rows
+// are reported by line.
+func (a *Application) importCSV(w http.ResponseWriter, r *http.Request) {
+	payload := list.List{
+		Name: r.URL.Query().Get("name"),
+	}
+
+	if err := validateList(payload); err != nil {
+		web.RespondError(w, r, http.StatusBadRequest, err)
+		return
+	}
+
+	mapping := make(map[string]string)
+	for _, m := range r.URL.Query()["map"] {
+		i := strings.LastIndex(m, ":")
+		if i < 0 {
+			web.RespondError(w, r, http.StatusBadRequest, errors.New("map must be given as header:field"))
+			return
+		}
+
+		mapping[m[:i]] = m[i+1:]
+	}
+
+	d, err := csvlist.NewDecoder(&limitedReader{r: r.Body, n: maxImportSize}, mapping)
+	if err != nil {
+		if errors.Cause(err) == errImportTooLarge {
+			web.RespondError(w, r, http.StatusRequestEntityTooLarge, err)
+			return
+		}
+
+		web.RespondError(w, r, http.StatusBadRequest, errors.Wrap(err, "read csv header"))
+		return
+	}
+
+	var res csvImport
+	err = db.Transact(a.DB, func(tx *sqlx.Tx) error {
+		var err error
+		res.List, res.Items, err = csvlist.Import(tx, payload, d, validateItem)
+		return err
+	})
+	if err != nil {
+		if ierr, ok := errors.Cause(err).(*csvlist.ImportError); ok {
+			errs := make([]error, 0, len(ierr.Rows)+1)
+			for _, row := range ierr.Rows {
+				errs = append(errs, row)
+			}
+
+			if ierr.Omitted > 0 {
+				errs = append(errs, errors.Errorf("%d more invalid rows", ierr.Omitted))
+			}
+
+			web.Respond(w, r, http.StatusBadRequest, nil, errs...)
+			return
+		}
+
+		if errors.Cause(err) == errImportTooLarge {
+			web.RespondError(w, r, http.StatusRequestEntityTooLarge, err)
+			return
+		}
+
+		if pgerr, ok := errors.Cause(err).(*pq.Error); ok {
+			if string(pgerr.Code) == db.PSQLErrUniqueConstraint {
+				web.RespondError(w, r, http.StatusBadRequest, errors.Wrap(err, "attempting to break unique name constraint"))
+				return
+			}
+		}
+
+		web.RespondError(w, r, http.StatusInternalServerError, errors.Wrap(err, "import csv"))
+		return
+	}
+
+	web.Respond(w, r, http.StatusCreated, res)
+}
@@ -0,0 +1,26 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/george-e-shaw-iv/integration-tests-example/cmd/listd/delta"
+	"github.com/george-e-shaw-iv/integration-tests-example/internal/platform/web"
+	"github.com/pkg/errors"
+)
+
+// getChanges is a handler that returns every list and item created, modified or
+// deleted since the sync token given by the since URL query parameter.
+func (a *Application) getChanges(w http.ResponseWriter, r *http.Request) {
+	changes, err := delta.Since(a.DB, r.URL.Query().Get("since"))
+	if err != nil {
+		if errors.Cause(err) == delta.ErrInvalidToken {
+			web.RespondError(w, r, http.StatusBadRequest, err)
+			return
+		}
+
+		web.RespondError(w, r, http.StatusInternalServerError, errors.Wrap(err, "select changes since sync token"))
+		return
+	}
+
+	web.Respond(w, r, http.StatusOK, changes)
+}
@@ -0,0 +1,183 @@
+package handlers
+
+import (
+	"database/sql"
+	"net/http"
+	"strconv"
+
+	"github.com/george-e-shaw-iv/integration-tests-example/cmd/listd/folder"
+	"github.com/george-e-shaw-iv/integration-tests-example/internal/platform/db"
+	"github.com/george-e-shaw-iv/integration-tests-example/internal/platform/web"
+	"github.com/jmoiron/sqlx"
+	"github.com/julienschmidt/httprouter"
+	"github.com/pkg/errors"
+)
+
+// getFolder is a handler that returns the folders and lists directly inside of the
+// folder given by the fid URL parameter, or at the top level when it is left out.
+func (a *Application) getFolder(w http.ResponseWriter, r *http.Request) {
+	var folderID *int
+	if fid := httprouter.ParamsFromContext(r.Context()).ByName("fid"); fid != "" {
+		id, err := strconv.Atoi(fid)
+		if err != nil {
+			web.RespondError(w, r, http.StatusInternalServerError, errors.Wrap(err, "convert folder id to integer"))
+			return
+		}
+		folderID = &id
+	}
+
+	c, err := folder.SelectContents(a.DB, folderID)
+	if err != nil {
+		if errors.Cause(err) == sql.ErrNoRows {
+			web.RespondError(w, r, http.StatusNotFound, errors.New(http.StatusText(http.StatusNotFound)))
+			return
+		}
+
+		web.RespondError(w, r, http.StatusInternalServerError, errors.Wrap(err, "select folder contents"))
+		return
+	}
+
+	web.Respond(w, r, http.StatusOK, c)
+}
+
+// createFolder is a handler that inserts a new row into the folder table.
+func (a *Application) createFolder(w http.ResponseWriter, r *http.Request) {
+	var payload folder.Folder
+	if err := web.Decode(r, &payload); err != nil {
+		web.RespondError(w, r, http.StatusInternalServerError, errors.Wrap(err, "unmarshal request payload"))
+		return
+	}
+
+	if payload.Name == "" {
+		web.RespondError(w, r, http.StatusBadRequest, errors.New("name key is required"))
+		return
+	}
+
+	var f folder.Folder
+	err := db.Transact(a.DB, func(tx *sqlx.Tx) error {
+		var err error
+		f, err = folder.CreateFolder(tx, payload)
+		return err
+	})
+	if err != nil {
+		if terr, ok := errors.Cause(err).(folder.TreeError); ok {
+			web.RespondError(w, r, http.StatusBadRequest, terr)
+			return
+		}
+
+		web.RespondError(w, r, http.StatusInternalServerError, errors.Wrap(err, "insert row into folder table"))
+		return
+	}
+
+	web.Respond(w, r, http.StatusCreated, f)
+}
+
+// updateFolder is a handler that renames the folder given by the fid URL parameter.
+func (a *Application) updateFolder(w http.ResponseWriter, r *http.Request) {
+	folderID, err := strconv.Atoi(httprouter.ParamsFromContext(r.Context()).ByName("fid"))
+	if err != nil {
+		web.RespondError(w, r, http.StatusInternalServerError, errors.Wrap(err, "convert folder id to integer"))
+		return
+	}
+
+	var payload folder.Folder
+	if err := web.Decode(r, &payload); err != nil {
+		web.RespondError(w, r, http.StatusInternalServerError, errors.Wrap(err, "unmarshal request payload"))
+		return
+	}
+
+	payload.ID = folderID
+
+	if payload.Name == "" {
+		web.RespondError(w, r, http.StatusBadRequest, errors.New("name key is required"))
+		return
+	}
+
+	var f folder.Folder
+	err = db.Transact(a.DB, func(tx *sqlx.Tx) error {
+		var err error
+		f, err = folder.UpdateFolder(tx, payload)
+		return err
+	})
+	if err != nil {
+		if errors.Cause(err) == sql.ErrNoRows {
+			web.RespondError(w, r, http.StatusNotFound, errors.New(http.StatusText(http.StatusNotFound)))
+			return
+		}
+
+		web.RespondError(w, r, http.StatusInternalServerError, errors.Wrap(err, "update row in folder table"))
+		return
+	}
+
+	web.Respond(w, r, http.StatusOK, f)
+}
+
+// deleteFolder is a handler that deletes the folder given by the fid URL parameter,
+// moving everything inside of it up into its parent.
+func (a *Application) deleteFolder(w http.ResponseWriter, r *http.Request) {
+	folderID, err := strconv.Atoi(httprouter.ParamsFromContext(r.Context()).ByName("fid"))
+	if err != nil {
+		web.RespondError(w, r, http.StatusInternalServerError, errors.Wrap(err, "convert folder id to integer"))
+		return
+	}
+
+	err = db.Transact(a.DB, func(tx *sqlx.Tx) error {
+		return folder.DeleteFolder(tx, folderID)
+	})
+	if err != nil {
+		if errors.Cause(err) == sql.ErrNoRows {
+			web.RespondError(w, r, http.StatusNotFound, errors.New(http.StatusText(http.StatusNotFound)))
+			return
+		}
+
+		web.RespondError(w, r, http.StatusInternalServerError, errors.Wrap(err, "delete folder by id"))
+		return
+	}
+
+	web.Respond(w, r, http.StatusNoContent, nil)
+}
+
+// folderMove is the payload of a folder move. ParentID is the folder to move the
+// folder into, leaving it out moves the folder to the top level.
+type folderMove struct {
+	ParentID *int `json:"parentID"`
+}
+
+// moveFolder is a handler that moves the folder given by the fid URL parameter,
+// along with everything inside of it, into another folder.
+func (a *Application) moveFolder(w http.ResponseWriter, r *http.Request) {
+	folderID, err := strconv.Atoi(httprouter.ParamsFromContext(r.Context()).ByName("fid"))
+	if err != nil {
+		web.RespondError(w, r, http.StatusInternalServerError, errors.Wrap(err, "convert folder id to integer"))
+		return
+	}
+
+	var payload folderMove
+	if err := web.Decode(r, &payload); err != nil {
+		web.RespondError(w, r, http.StatusInternalServerError, errors.Wrap(err, "unmarshal request payload"))
+		return
+	}
+
+	var f folder.Folder
+	err = db.Transact(a.DB, func(tx *sqlx.Tx) error {
+		var err error
+		f, err = folder.MoveFolder(tx, folderID, payload.ParentID)
+		return err
+	})
+	if err != nil {
+		if errors.Cause(err) == sql.ErrNoRows {
+			web.RespondError(w, r, http.StatusNotFound, errors.New(http.StatusText(http.StatusNotFound)))
+			return
+		}
+
+		if terr, ok := errors.Cause(err).(folder.TreeError); ok {
+			web.RespondError(w, r, http.StatusBadRequest, terr)
+			return
+		}
+
+		web.RespondError(w, r, http.StatusInternalServerError, errors.Wrap(err, "move folder"))
+		return
+	}
+
+	web.Respond(w, r, http.StatusOK, f)
+}
@@ -0,0 +1,151 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/george-e-shaw-iv/integration-tests-example/cmd/listd/folder"
+	"github.com/george-e-shaw-iv/integration-tests-example/cmd/listd/item"
+	"github.com/george-e-shaw-iv/integration-tests-example/cmd/listd/listdpb"
+	"github.com/george-e-shaw-iv/integration-tests-example/internal/platform/db"
+	"github.com/golang/protobuf/ptypes"
+	"github.com/golang/protobuf/ptypes/timestamp"
+	"github.com/golang/protobuf/ptypes/wrappers"
+	"github.com/lib/pq"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// NewGRPCServer returns a gRPC server serving the Lists and Items services defined
+// in listdpb, which mirror the list and item routes of the application and share
+// its database connection.
+func NewGRPCServer(a *Application, opts ...grpc.ServerOption) *grpc.Server {
+	s := grpc.NewServer(append(opts, grpc.UnaryInterceptor(logRPC))...)
+
+	listdpb.RegisterListsServer(s, listsServer{a})
+	listdpb.RegisterItemsServer(s, itemsServer{a})
+
+	return s
+}
+
+// logRPC is a unary server interceptor that logs the end of each call, as the
+// RequestMW middleware does for requests.
+func logRPC(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	st := time.Now()
+
+	resp, err := handler(ctx, req)
+
+	log.WithFields(log.Fields{
+		"method":      info.FullMethod,
+		"requestTime": time.Since(st),
+		"status":      status.Code(err).String(),
+	}).Info("completed call")
+
+	return resp, err
+}
+
+// grpcError converts an error returned by the list and item packages into a gRPC
+// status error. Errors without a more specific code are logged, wrapped with msg,
+// and responded to as INTERNAL without their message.
+func grpcError(err error, msg string) error {
+	cause := errors.Cause(err)
+
+	if cause == sql.ErrNoRows {
+		return status.Error(codes.NotFound, "not found")
+	}
+
+	if pgerr, ok := cause.(*pq.Error); ok && string(pgerr.Code) == db.PSQLErrUniqueConstraint {
+		return status.Error(codes.AlreadyExists, "attempting to break unique name constraint")
+	}
+
+	switch cause := cause.(type) {
+	case *item.ConflictError:
+		return status.Error(codes.Aborted, cause.Error())
+	case item.TreeError, folder.TreeError:
+		return status.Error(codes.InvalidArgument, cause.Error())
+	}
+
+	log.WithFields(log.Fields{
+		"error": errors.Wrap(err, msg),
+	}).Error("error while serving call")
+
+	return status.Error(codes.Internal, "internal error")
+}
+
+// timestampProto converts a time to a protobuf timestamp. Times read from the
+// database are always within the range of timestamps.
+func timestampProto(t time.Time) *timestamp.Timestamp {
+	ts, _ := ptypes.TimestampProto(t)
+	return ts
+}
+
+// optionalTimestampProto converts an optional time to a protobuf timestamp, which
+// is nil when the time is.
+func optionalTimestampProto(t *time.Time) *timestamp.Timestamp {
+	if t == nil {
+		return nil
+	}
+
+	return timestampProto(*t)
+}
+
+// optionalTime converts an optional protobuf timestamp to a time, which is nil when
+// the timestamp is.
+func optionalTime(ts *timestamp.Timestamp, field string) (*time.Time, error) {
+	if ts == nil {
+		return nil, nil
+	}
+
+	t, err := ptypes.Timestamp(ts)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "%s: %v", field, err)
+	}
+
+	return &t, nil
+}
+
+// int64Value converts an optional integer to a protobuf wrapper, which is nil when
+// the integer is.
+func int64Value(i *int64) *wrappers.Int64Value {
+	if i == nil {
+		return nil
+	}
+
+	return &wrappers.Int64Value{Value: *i}
+}
+
+// idValue converts an optional id to a protobuf wrapper, which is nil when the id
+// is.
+func idValue(id *int) *wrappers.Int64Value {
+	if id == nil {
+		return nil
+	}
+
+	return &wrappers.Int64Value{Value: int64(*id)}
+}
+
+// optionalInt64 converts a protobuf wrapper to an optional integer, which is nil
+// when the wrapper is.
+func optionalInt64(v *wrappers.Int64Value) *int64 {
+	if v == nil {
+		return nil
+	}
+
+	i := v.Value
+	return &i
+}
+
+// optionalID converts a protobuf wrapper to an optional id, which is nil when the
+// wrapper is.
+func optionalID(v *wrappers.Int64Value) *int {
+	if v == nil {
+		return nil
+	}
+
+	id := int(v.Value)
+	return &id
+}
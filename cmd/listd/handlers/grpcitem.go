@@ -0,0 +1,198 @@
+package handlers
+
+import (
+	"context"
+
+	"github.com/george-e-shaw-iv/integration-tests-example/cmd/listd/item"
+	"github.com/george-e-shaw-iv/integration-tests-example/cmd/listd/listdpb"
+	"github.com/george-e-shaw-iv/integration-tests-example/internal/platform/db"
+	"github.com/george-e-shaw-iv/integration-tests-example/internal/platform/units"
+	"github.com/golang/protobuf/ptypes/empty"
+	"github.com/jmoiron/sqlx"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// itemsServer implements the listdpb.ItemsServer interface, mirroring the item
+// routes.
+type itemsServer struct {
+	a *Application
+}
+
+// ListItems returns all rows from the item table belonging to a list.
+func (s itemsServer) ListItems(ctx context.Context, req *listdpb.ListItemsRequest) (*listdpb.ListItemsResponse, error) {
+	items, err := item.SelectItems(s.a.DB, int(req.ListId))
+	if err != nil {
+		return nil, grpcError(err, "select all item rows")
+	}
+
+	resp := listdpb.ListItemsResponse{
+		Items: make([]*listdpb.Item, 0, len(items)),
+	}
+
+	for _, i := range items {
+		resp.Items = append(resp.Items, itemProto(i))
+	}
+
+	return &resp, nil
+}
+
+// GetItem returns a single row from the item table.
+func (s itemsServer) GetItem(ctx context.Context, req *listdpb.GetItemRequest) (*listdpb.Item, error) {
+	i, err := item.SelectItem(s.a.DB, int(req.Id), int(req.ListId))
+	if err != nil {
+		return nil, grpcError(err, "select item by id and list id")
+	}
+
+	return itemProto(i), nil
+}
+
+// CreateItem inserts a new row into the item table.
+func (s itemsServer) CreateItem(ctx context.Context, req *listdpb.Item) (*listdpb.Item, error) {
+	payload, err := itemFromProto(req)
+	if err != nil {
+		return nil, err
+	}
+
+	var i item.Item
+	err = db.Transact(s.a.DB, func(tx *sqlx.Tx) error {
+		var err error
+		i, err = item.CreateItem(tx, payload)
+		return err
+	})
+	if err != nil {
+		return nil, grpcError(err, "insert row into item table")
+	}
+
+	return itemProto(i), nil
+}
+
+// UpdateItem updates a row in the item table, merging the update with edits made
+// since its base version.
+func (s itemsServer) UpdateItem(ctx context.Context, req *listdpb.UpdateItemRequest) (*listdpb.Item, error) {
+	if req.Item == nil {
+		return nil, status.Error(codes.InvalidArgument, "item is a required field")
+	}
+
+	payload, err := itemFromProto(req.Item)
+	if err != nil {
+		return nil, err
+	}
+	payload.ID = int(req.Item.Id)
+
+	var updated item.Item
+	err = db.Transact(s.a.DB, func(tx *sqlx.Tx) error {
+		var err error
+		updated, err = item.UpdateItem(tx, payload, req.BaseVersion)
+		return err
+	})
+	if err != nil {
+		return nil, grpcError(err, "update row in item table")
+	}
+
+	return itemProto(updated), nil
+}
+
+// DeleteItem deletes a row from the item table.
+func (s itemsServer) DeleteItem(ctx context.Context, req *listdpb.DeleteItemRequest) (*empty.Empty, error) {
+	err := db.Transact(s.a.DB, func(tx *sqlx.Tx) error {
+		return item.DeleteItem(tx, int(req.Id), int(req.ListId))
+	})
+	if err != nil {
+		return nil, grpcError(err, "delete item row")
+	}
+
+	return &empty.Empty{}, nil
+}
+
+// CompleteItem marks an item as completed, creating its next occurrence if it
+// recurs.
+func (s itemsServer) CompleteItem(ctx context.Context, req *listdpb.CompleteItemRequest) (*listdpb.CompleteItemResponse, error) {
+	var completed item.Item
+	var next *item.Item
+
+	err := db.Transact(s.a.DB, func(tx *sqlx.Tx) error {
+		var err error
+		completed, next, err = item.CompleteItem(tx, int(req.Id), int(req.ListId))
+		return err
+	})
+	if err != nil {
+		return nil, grpcError(err, "complete item")
+	}
+
+	resp := listdpb.CompleteItemResponse{
+		Item: itemProto(completed),
+	}
+
+	if next != nil {
+		resp.Next = itemProto(*next)
+	}
+
+	return &resp, nil
+}
+
+// itemProto converts an item to its protobuf message.
+func itemProto(i item.Item) *listdpb.Item {
+	return &listdpb.Item{
+		Id:          int64(i.ID),
+		ListId:      int64(i.ListID),
+		Name:        i.Name,
+		Quantity:    i.Quantity.String(),
+		Unit:        i.Unit,
+		Price:       int64Value(i.Price),
+		Currency:    i.Currency,
+		Notes:       i.Notes,
+		DueAt:       optionalTimestampProto(i.DueAt),
+		RemindAt:    optionalTimestampProto(i.RemindAt),
+		ParentId:    idValue(i.ParentID),
+		Recurrence:  i.Recurrence,
+		CompletedAt: optionalTimestampProto(i.CompletedAt),
+		Created:     timestampProto(i.Created),
+		Modified:    timestampProto(i.Modified),
+		Version:     i.Version,
+		Tags:        []string(i.Tags),
+	}
+}
+
+// itemFromProto converts the fields of an item message that can be written to a
+// validated item payload. Tags are managed separately and are ignored, as they are
+// by the item routes.
+func itemFromProto(m *listdpb.Item) (item.Item, error) {
+	i := item.Item{
+		ListID:     int(m.ListId),
+		Name:       m.Name,
+		Unit:       m.Unit,
+		Price:      optionalInt64(m.Price),
+		Currency:   m.Currency,
+		Notes:      m.Notes,
+		ParentID:   optionalID(m.ParentId),
+		Recurrence: m.Recurrence,
+	}
+
+	if m.Quantity != "" {
+		q, err := units.ParseAmount(m.Quantity)
+		if err != nil {
+			return item.Item{}, status.Errorf(codes.InvalidArgument, "quantity: %v", err)
+		}
+		i.Quantity = q
+	}
+
+	var err error
+	if i.DueAt, err = optionalTime(m.DueAt, "due_at"); err != nil {
+		return item.Item{}, err
+	}
+
+	if i.RemindAt, err = optionalTime(m.RemindAt, "remind_at"); err != nil {
+		return item.Item{}, err
+	}
+
+	if i.CompletedAt, err = optionalTime(m.CompletedAt, "completed_at"); err != nil {
+		return item.Item{}, err
+	}
+
+	if err := validateItem(i); err != nil {
+		return item.Item{}, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	return i, nil
+}
@@ -0,0 +1,140 @@
+package handlers
+
+import (
+	"context"
+
+	"github.com/george-e-shaw-iv/integration-tests-example/cmd/listd/folder"
+	"github.com/george-e-shaw-iv/integration-tests-example/cmd/listd/list"
+	"github.com/george-e-shaw-iv/integration-tests-example/cmd/listd/listdpb"
+	"github.com/george-e-shaw-iv/integration-tests-example/internal/platform/db"
+	"github.com/golang/protobuf/ptypes/empty"
+	"github.com/jmoiron/sqlx"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// listsServer implements the listdpb.ListsServer interface, mirroring the list
+// routes.
+type listsServer struct {
+	a *Application
+}
+
+// ListLists returns all rows from the list table.
+func (s listsServer) ListLists(ctx context.Context, _ *empty.Empty) (*listdpb.ListListsResponse, error) {
+	lists, err := list.SelectLists(s.a.DB)
+	if err != nil {
+		return nil, grpcError(err, "select all lists")
+	}
+
+	resp := listdpb.ListListsResponse{
+		Lists: make([]*listdpb.List, 0, len(lists)),
+	}
+
+	for _, l := range lists {
+		resp.Lists = append(resp.Lists, listProto(l))
+	}
+
+	return &resp, nil
+}
+
+// GetList returns a single row from the list table.
+func (s listsServer) GetList(ctx context.Context, req *listdpb.GetListRequest) (*listdpb.List, error) {
+	l, err := list.SelectList(s.a.DB, int(req.Id))
+	if err != nil {
+		return nil, grpcError(err, "select list by id")
+	}
+
+	return listProto(l), nil
+}
+
+// CreateList inserts a new row into the list table.
+func (s listsServer) CreateList(ctx context.Context, req *listdpb.List) (*listdpb.List, error) {
+	payload := listFromProto(req)
+
+	if err := validateList(payload); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	normalizeBudget(&payload)
+
+	var l list.List
+	err := db.Transact(s.a.DB, func(tx *sqlx.Tx) error {
+		if err := folder.CheckFolder(tx, payload.FolderID); err != nil {
+			return err
+		}
+
+		var err error
+		l, err = list.CreateList(tx, payload)
+		return err
+	})
+	if err != nil {
+		return nil, grpcError(err, "insert row into list table")
+	}
+
+	return listProto(l), nil
+}
+
+// UpdateList updates a row in the list table, returning it as updated.
+func (s listsServer) UpdateList(ctx context.Context, req *listdpb.List) (*listdpb.List, error) {
+	payload := listFromProto(req)
+	payload.ID = int(req.Id)
+
+	if err := validateList(payload); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	normalizeBudget(&payload)
+
+	var l list.List
+	err := db.Transact(s.a.DB, func(tx *sqlx.Tx) error {
+		if err := list.UpdateList(tx, payload); err != nil {
+			return err
+		}
+
+		var err error
+		l, err = list.SelectList(tx, payload.ID)
+		return err
+	})
+	if err != nil {
+		return nil, grpcError(err, "update row in list table")
+	}
+
+	return listProto(l), nil
+}
+
+// DeleteList deletes a row from the list table.
+func (s listsServer) DeleteList(ctx context.Context, req *listdpb.DeleteListRequest) (*empty.Empty, error) {
+	err := db.Transact(s.a.DB, func(tx *sqlx.Tx) error {
+		return list.DeleteList(tx, int(req.Id))
+	})
+	if err != nil {
+		return nil, grpcError(err, "delete list by id")
+	}
+
+	return &empty.Empty{}, nil
+}
+
+// listProto converts a list to its protobuf message.
+func listProto(l list.List) *listdpb.List {
+	return &listdpb.List{
+		Id:             int64(l.ID),
+		Name:           l.Name,
+		FolderId:       idValue(l.FolderID),
+		Notes:          l.Notes,
+		Budget:         int64Value(l.Budget),
+		BudgetCurrency: l.BudgetCurrency,
+		Created:        timestampProto(l.Created),
+		Modified:       timestampProto(l.Modified),
+		Version:        l.Version,
+	}
+}
+
+// listFromProto converts the fields of a list message that can be written to a
+// list payload.
+func listFromProto(m *listdpb.List) list.List {
+	return list.List{
+		Name:           m.Name,
+		FolderID:       optionalID(m.FolderId),
+		Notes:          m.Notes,
+		Budget:         optionalInt64(m.Budget),
+		BudgetCurrency: m.BudgetCurrency,
+	}
+}
@@ -1,18 +1,32 @@
 package handlers
 
 import (
+	"context"
 	"net/http"
+	"strings"
 
+	"github.com/george-e-shaw-iv/integration-tests-example/cmd/listd/stats"
+	"github.com/george-e-shaw-iv/integration-tests-example/internal/platform/blob"
 	"github.com/george-e-shaw-iv/integration-tests-example/internal/platform/web"
 	"github.com/jmoiron/sqlx"
 	"github.com/julienschmidt/httprouter"
 )
 
 // Application is the struct that contains the server handler as well as
-// any references to services that the application needs.
+// any references to services that the application needs. Blobs is where the
+// contents of attachments are stored, attachments are unavailable when it is nil.
+// Stats caches aggregate statistics, not at all until its TTL is set.
+// UserHeader names the request header an authenticating proxy in front of the
+// application sets to the user a request is made on behalf of, such as the author
+// of a comment. The application does not authenticate users itself, so the proxy
+// must overwrite the header on every request. Requests made on behalf of a user
+// are refused while it is empty.
 type Application struct {
-	DB      *sqlx.DB
-	handler http.Handler
+	DB         *sqlx.DB
+	Blobs      blob.Store
+	Stats      *stats.Cache
+	UserHeader string
+	handler    http.Handler
 }
 
 // ServeHTTP implements the http.Handler interface for the Application type.
@@ -24,7 +38,8 @@ func (a *Application) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 // initiated.
 func NewApplication(db *sqlx.DB) *Application {
 	a := Application{
-		DB: db,
+		DB:    db,
+		Stats: &stats.Cache{DB: db},
 	}
 
 	router := httprouter.New()
@@ -54,13 +69,83 @@ func NewApplication(db *sqlx.DB) *Application {
 	router.HandlerFunc(http.MethodGet, "/list/:lid", a.getList)
 	router.HandlerFunc(http.MethodPut, "/list/:lid", a.updateList)
 	router.HandlerFunc(http.MethodDelete, "/list/:lid", a.deleteList)
+	router.HandlerFunc(http.MethodPost, "/list/:lid/move", a.moveList)
+	router.HandlerFunc(http.MethodGet, "/list/:lid/summary", a.getSummary)
+	router.HandlerFunc(http.MethodGet, "/list/:lid/export.csv", a.exportCSV)
+	router.HandlerFunc(http.MethodPost, "/list/:lid", dispatchParam("lid", map[string]http.HandlerFunc{
+		"import": a.importCSV,
+	}, methodNotAllowed(http.MethodGet, http.MethodPut, http.MethodDelete)))
+
+	// Folder Routes
+	router.HandlerFunc(http.MethodGet, "/folder", a.getFolder)
+	router.HandlerFunc(http.MethodPost, "/folder", a.createFolder)
+	router.HandlerFunc(http.MethodGet, "/folder/:fid", a.getFolder)
+	router.HandlerFunc(http.MethodPut, "/folder/:fid", a.updateFolder)
+	router.HandlerFunc(http.MethodDelete, "/folder/:fid", a.deleteFolder)
+	router.HandlerFunc(http.MethodPost, "/folder/:fid/move", a.moveFolder)
 
 	// Item Routes
 	router.HandlerFunc(http.MethodGet, "/list/:lid/item", a.getItems)
 	router.HandlerFunc(http.MethodPost, "/list/:lid/item", a.createItem)
-	router.HandlerFunc(http.MethodGet, "/list/:lid/item/:iid", a.getItem)
+	router.HandlerFunc(http.MethodGet, "/list/:lid/item/:iid", dispatchParam("iid", map[string]http.HandlerFunc{
+		"suggest": a.suggestItems,
+	}, a.getItem))
 	router.HandlerFunc(http.MethodPut, "/list/:lid/item/:iid", a.updateItem)
 	router.HandlerFunc(http.MethodDelete, "/list/:lid/item/:iid", a.deleteItem)
+	router.HandlerFunc(http.MethodPost, "/list/:lid/item/:iid/complete", a.completeItem)
+	router.HandlerFunc(http.MethodPost, "/list/:lid/item/:iid/move", a.moveItem)
+
+	// Custom Method Routes
+	router.NotFound = customMethods{
+		{method: http.MethodPost, path: "/list/:lid/item:merge", handler: a.mergeItems},
+		{method: http.MethodPost, path: "/list/:lid/item:import", handler: a.importItems},
+	}
+
+	// Attachment Routes
+	router.HandlerFunc(http.MethodGet, "/list/:lid/item/:iid/attachment", a.getAttachments)
+	router.HandlerFunc(http.MethodPost, "/list/:lid/item/:iid/attachment", a.createAttachment)
+	router.HandlerFunc(http.MethodGet, "/list/:lid/item/:iid/attachment/:aid", a.getAttachment)
+	router.HandlerFunc(http.MethodDelete, "/list/:lid/item/:iid/attachment/:aid", a.deleteAttachment)
+
+	// Comment Routes
+	router.HandlerFunc(http.MethodGet, "/list/:lid/item/:iid/comment", a.getComments)
+	router.HandlerFunc(http.MethodPost, "/list/:lid/item/:iid/comment", a.createComment)
+	router.HandlerFunc(http.MethodPut, "/list/:lid/item/:iid/comment/:cid", a.updateComment)
+	router.HandlerFunc(http.MethodDelete, "/list/:lid/item/:iid/comment/:cid", a.deleteComment)
+	router.HandlerFunc(http.MethodGet, "/mention", a.getMentions)
+
+	// Tag Routes
+	router.HandlerFunc(http.MethodGet, "/tag", a.getTags)
+	router.HandlerFunc(http.MethodPut, "/list/:lid/item/:iid/tag/:tag", a.addItemTag)
+	router.HandlerFunc(http.MethodDelete, "/list/:lid/item/:iid/tag/:tag", a.removeItemTag)
+
+	// Archive Routes
+	router.HandlerFunc(http.MethodGet, "/export", a.exportArchive)
+	router.HandlerFunc(http.MethodPost, "/import", a.importArchive)
+
+	// Batch Routes
+	router.HandlerFunc(http.MethodPost, "/batch", a.batch)
+
+	// Search Routes
+	router.HandlerFunc(http.MethodGet, "/search", a.searchAll)
+
+	// Stats Routes
+	router.HandlerFunc(http.MethodGet, "/stats", a.getStats)
+
+	// Sync Routes
+	router.HandlerFunc(http.MethodGet, "/sync", a.getChanges)
+
+	// Calendar Routes
+	router.HandlerFunc(http.MethodGet, "/list/:lid/calendar.ics", a.getCalendar)
+	router.HandlerFunc(http.MethodPost, "/list/:lid/calendar/token", a.createCalendarToken)
+	router.HandlerFunc(http.MethodDelete, "/list/:lid/calendar/token", a.deleteCalendarToken)
+
+	// Webhook Routes
+	router.HandlerFunc(http.MethodGet, "/list/:lid/webhook", a.getWebhooks)
+	router.HandlerFunc(http.MethodPost, "/list/:lid/webhook", a.createWebhook)
+	router.HandlerFunc(http.MethodGet, "/list/:lid/webhook/:wid", a.getWebhook)
+	router.HandlerFunc(http.MethodDelete, "/list/:lid/webhook/:wid", a.deleteWebhook)
+	router.HandlerFunc(http.MethodGet, "/list/:lid/webhook/:wid/delivery", a.getDeliveries)
 
 	// Wrap the router in middleware used for logging requests and set the application
 	// handler to utilize the returned http.Handler from RequestMW.
@@ -68,3 +153,90 @@ func NewApplication(db *sqlx.DB) *Application {
 
 	return &a
 }
+
+// dispatchParam returns a handler that serves a request with the handler in routes
+// keyed by the value of the named URL parameter, falling back to fallback. The
+// router does not allow a static path segment in the same position as a parameter,
+// so static routes such as /list/:lid/item/suggest are dispatched through the
+// parameter's route instead.
+func dispatchParam(param string, routes map[string]http.HandlerFunc, fallback http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if h, ok := routes[httprouter.ParamsFromContext(r.Context()).ByName(param)]; ok {
+			h(w, r)
+			return
+		}
+
+		fallback(w, r)
+	}
+}
+
+// methodNotAllowed returns a handler responding 405 with the given allowed methods,
+// as the router does for paths without a route for the request's method. It is
+// the fallback of routes dispatched by dispatchParam that only exist for some
+// values of the parameter.
+func methodNotAllowed(allow ...string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Allow", strings.Join(allow, ", "))
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+	}
+}
+
+// customMethod is a route to a custom method, an action on a resource named by
+// appending a colon and the name of the action to the path of the resource, such as
+// /list/:lid/item:merge.
+type customMethod struct {
+	method  string
+	path    string
+	handler http.HandlerFunc
+}
+
+// customMethods serves requests to custom methods, falling back to a 404. The
+// router treats every colon in a path as the start of a parameter, so custom
+// methods can't be registered with it and are served as its NotFound handler
+// instead. Segments of a path starting with a colon are parameters, which are
+// made available to the handler in the same way as the router's.
+type customMethods []customMethod
+
+// ServeHTTP implements the http.Handler interface for the customMethods type.
+func (cm customMethods) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	segments := strings.Split(r.URL.Path, "/")
+
+	for _, m := range cm {
+		if m.method != r.Method {
+			continue
+		}
+
+		if params, ok := matchPath(strings.Split(m.path, "/"), segments); ok {
+			m.handler(w, r.WithContext(context.WithValue(r.Context(), httprouter.ParamsKey, params)))
+			return
+		}
+	}
+
+	http.NotFound(w, r)
+}
+
+// matchPath matches the segments of a request path against the segments of a
+// route's path, returning the parameters of the route.
+func matchPath(route, segments []string) (httprouter.Params, bool) {
+	if len(route) != len(segments) {
+		return nil, false
+	}
+
+	var params httprouter.Params
+	for i, s := range route {
+		if strings.HasPrefix(s, ":") {
+			if segments[i] == "" {
+				return nil, false
+			}
+
+			params = append(params, httprouter.Param{Key: s[1:], Value: segments[i]})
+			continue
+		}
+
+		if s != segments[i] {
+			return nil, false
+		}
+	}
+
+	return params, true
+}
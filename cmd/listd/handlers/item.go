@@ -2,17 +2,33 @@ package handlers
 
 import (
 	"database/sql"
-	"encoding/json"
 	"net/http"
 	"strconv"
+	"strings"
 
 	"github.com/george-e-shaw-iv/integration-tests-example/cmd/listd/item"
+	"github.com/george-e-shaw-iv/integration-tests-example/internal/platform/db"
+	"github.com/george-e-shaw-iv/integration-tests-example/internal/platform/markdown"
+	"github.com/george-e-shaw-iv/integration-tests-example/internal/platform/rrule"
+	"github.com/george-e-shaw-iv/integration-tests-example/internal/platform/units"
 	"github.com/george-e-shaw-iv/integration-tests-example/internal/platform/web"
+	"github.com/jmoiron/sqlx"
 	"github.com/julienschmidt/httprouter"
 	"github.com/pkg/errors"
 )
 
-// getItems is a handler that returns all rows from the item table.
+// Bounds of the number of items returned by suggestItems.
+const (
+	defaultSuggestLimit = 10
+	maxSuggestLimit     = 50
+)
+
+// getItems is a handler that returns all rows from the item table. Repeating the tag
+// URL query parameter filters the items down to those carrying every given tag, or
+// any one of them when the match URL query parameter is any. When the tree URL query
+// parameter is true only top level items are returned, with their sub-items nested
+// in children. When the normalize URL query parameter is metric quantities are
+// converted to metric units.
 func (a *Application) getItems(w http.ResponseWriter, r *http.Request) {
 	listID, err := strconv.Atoi(httprouter.ParamsFromContext(r.Context()).ByName("lid"))
 	if err != nil {
@@ -20,13 +36,48 @@ func (a *Application) getItems(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	items, err := item.SelectItems(a.DB, listID)
+	tree := r.URL.Query().Get("tree") == "true"
+
+	metric, err := normalizeMetric(r)
+	if err != nil {
+		web.RespondError(w, r, http.StatusBadRequest, err)
+		return
+	}
+
+	var items []item.Item
+	if tags := r.URL.Query()["tag"]; len(tags) > 0 {
+		if tree {
+			web.RespondError(w, r, http.StatusBadRequest, errors.New("tree can't be combined with tag filtering"))
+			return
+		}
+
+		var all bool
+		switch r.URL.Query().Get("match") {
+		case "", "all":
+			all = true
+		case "any":
+		default:
+			web.RespondError(w, r, http.StatusBadRequest, errors.New("match must be either all or any"))
+			return
+		}
+
+		items, err = item.SelectItemsByTags(a.DB, listID, tags, all)
+	} else if tree {
+		items, err = item.SelectTree(a.DB, listID)
+	} else {
+		items, err = item.SelectItems(a.DB, listID)
+	}
 	if err != nil {
 		if errors.Cause(err) == sql.ErrNoRows {
 			web.RespondError(w, r, http.StatusNotFound, errors.New(http.StatusText(http.StatusNotFound)))
 			return
 		}
 
+		if errors.Cause(err) == item.ErrInvalidTag {
+			web.RespondError(w, r, http.StatusBadRequest, err)
+			return
+		}
+
 		web.RespondError(w, r, http.StatusInternalServerError, errors.Wrap(err, "select all item rows"))
 		return
 	}
@@ -35,6 +86,17 @@ func (a *Application) getItems(w http.ResponseWriter, r *http.Request) {
 		items = make([]item.Item, 0)
 	}
 
+	if renderHTML(r) {
+		renderItemNotes(items)
+	}
+
+	if metric {
+		if err := normalizeQuantities(items); err != nil {
+			web.RespondError(w, r, http.StatusInternalServerError, err)
+			return
+		}
+	}
+
 	web.Respond(w, r, http.StatusOK, items)
 }
 
@@ -47,37 +109,97 @@ func (a *Application) createItem(w http.ResponseWriter, r *http.Request) {
 	}
 
 	var payload item.Item
-	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+	if err := web.Decode(r, &payload); err != nil {
 		web.RespondError(w, r, http.StatusInternalServerError, errors.Wrap(err, "unmarshal request payload"))
 		return
 	}
 
 	payload.ListID = listID
 
-	if payload.Name == "" {
-		web.RespondError(w, r, http.StatusBadRequest, errors.New("name is a required field"))
+	if err := validateItem(payload); err != nil {
+		web.RespondError(w, r, http.StatusBadRequest, err)
 		return
 	}
 
-	if payload.Quantity <= 0 {
-		web.RespondError(w, r, http.StatusBadRequest, errors.New("quantity must be supplied and greater than 0"))
-		return
-	}
+	dedupe := r.URL.Query().Get("dedupe") == "true"
 
-	i, err := item.CreateItem(a.DB, payload)
+	var i item.Item
+	created := true
+	err = db.Transact(a.DB, func(tx *sqlx.Tx) error {
+		var err error
+		if dedupe {
+			i, created, err = item.AddItem(tx, payload)
+			return err
+		}
+
+		i, err = item.CreateItem(tx, payload)
+		return err
+	})
 	if err != nil {
 		if errors.Cause(err) == sql.ErrNoRows {
 			web.RespondError(w, r, http.StatusNotFound, errors.New(http.StatusText(http.StatusNotFound)))
 			return
 		}
 
+		if terr, ok := errors.Cause(err).(item.TreeError); ok {
+			web.RespondError(w, r, http.StatusBadRequest, terr)
+			return
+		}
+
 		web.RespondError(w, r, http.StatusInternalServerError, errors.Wrap(err, "insert row into item table"))
 		return
 	}
 
+	// A near match already on the list had its quantity increased instead.
+	if !created {
+		web.Respond(w, r, http.StatusOK, i)
+		return
+	}
+
 	web.Respond(w, r, http.StatusCreated, i)
 }
 
+// suggestItems is a handler that returns the items on a list whose name contains a
+// word similar to the q URL query parameter, for autocompleting item names as they
+// are typed. The optional limit URL query parameter bounds the number of items.
+func (a *Application) suggestItems(w http.ResponseWriter, r *http.Request) {
+	listID, err := strconv.Atoi(httprouter.ParamsFromContext(r.Context()).ByName("lid"))
+	if err != nil {
+		web.RespondError(w, r, http.StatusInternalServerError, errors.Wrap(err, "convert list id to integer"))
+		return
+	}
+
+	q := strings.TrimSpace(r.URL.Query().Get("q"))
+	if q == "" {
+		web.RespondError(w, r, http.StatusBadRequest, errors.New("q is a required query parameter"))
+		return
+	}
+
+	limit := defaultSuggestLimit
+	if v := r.URL.Query().Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > maxSuggestLimit {
+			web.RespondError(w, r, http.StatusBadRequest, errors.Errorf("limit must be between 1 and %d", maxSuggestLimit))
+			return
+		}
+
+		limit = n
+	}
+
+	matches, err := item.SuggestItems(a.DB, listID, q, limit)
+	if err != nil {
+		if errors.Cause(err) == sql.ErrNoRows {
+			web.RespondError(w, r, http.StatusNotFound, errors.New(http.StatusText(http.StatusNotFound)))
+			return
+		}
+
+		web.RespondError(w, r, http.StatusInternalServerError, errors.Wrap(err, "select suggested item rows"))
+		return
+	}
+
+	web.Respond(w, r, http.StatusOK, matches)
+}
+
 // getItem is a handler that returns a row from the item table based off of the lid and iid URL
 // parameters.
 func (a *Application) getItem(w http.ResponseWriter, r *http.Request) {
@@ -93,6 +215,12 @@ func (a *Application) getItem(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	metric, err := normalizeMetric(r)
+	if err != nil {
+		web.RespondError(w, r, http.StatusBadRequest, err)
+		return
+	}
+
 	i, err := item.SelectItem(a.DB, itemID, listID)
 	if err != nil {
 		if errors.Cause(err) == sql.ErrNoRows {
@@ -104,6 +232,19 @@ func (a *Application) getItem(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if renderHTML(r) {
+		i.NotesHTML = markdown.Render(i.Notes)
+	}
+
+	if metric {
+		normalized := []item.Item{i}
+		if err := normalizeQuantities(normalized); err != nil {
+			web.RespondError(w, r, http.StatusInternalServerError, errors.Wrap(err, "normalize item quantity"))
+			return
+		}
+		i = normalized[0]
+	}
+
 	web.Respond(w, r, http.StatusOK, i)
 }
 
@@ -122,8 +263,8 @@ func (a *Application) updateItem(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	var payload item.Item
-	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+	var payload itemUpdate
+	if err := web.Decode(r, &payload); err != nil {
 		web.RespondError(w, r, http.StatusInternalServerError, errors.Wrap(err, "unmarshal request payload"))
 		return
 	}
@@ -131,27 +272,38 @@ func (a *Application) updateItem(w http.ResponseWriter, r *http.Request) {
 	payload.ID = itemID
 	payload.ListID = listID
 
-	if payload.Name == "" {
-		web.RespondError(w, r, http.StatusBadRequest, errors.New("name is a required field"))
-		return
-	}
-
-	if payload.Quantity <= 0 {
-		web.RespondError(w, r, http.StatusBadRequest, errors.New("quantity must be supplied and greater than 0"))
+	if err := validateItem(payload.Item); err != nil {
+		web.RespondError(w, r, http.StatusBadRequest, err)
 		return
 	}
 
-	if err = item.UpdateItem(a.DB, payload); err != nil {
+	var updated item.Item
+	err = db.Transact(a.DB, func(tx *sqlx.Tx) error {
+		var err error
+		updated, err = item.UpdateItem(tx, payload.Item, payload.BaseVersion)
+		return err
+	})
+	if err != nil {
 		if errors.Cause(err) == sql.ErrNoRows {
 			web.RespondError(w, r, http.StatusNotFound, errors.New(http.StatusText(http.StatusNotFound)))
 			return
 		}
 
+		if conflict, ok := errors.Cause(err).(*item.ConflictError); ok {
+			web.Respond(w, r, http.StatusConflict, conflict, conflict)
+			return
+		}
+
+		if terr, ok := errors.Cause(err).(item.TreeError); ok {
+			web.RespondError(w, r, http.StatusBadRequest, terr)
+			return
+		}
+
 		web.RespondError(w, r, http.StatusInternalServerError, errors.Wrap(err, "update row in item table"))
 		return
 	}
 
-	web.Respond(w, r, http.StatusOK, payload)
+	web.Respond(w, r, http.StatusOK, updated)
 }
 
 // getItem is a handler that deletes a row from the item table based off of the lid and iid URL
@@ -169,7 +321,10 @@ func (a *Application) deleteItem(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if err = item.DeleteItem(a.DB, itemID, listID); err != nil {
+	err = db.Transact(a.DB, func(tx *sqlx.Tx) error {
+		return item.DeleteItem(tx, itemID, listID)
+	})
+	if err != nil {
 		if errors.Cause(err) == sql.ErrNoRows {
 			web.RespondError(w, r, http.StatusNotFound, errors.New(http.StatusText(http.StatusNotFound)))
 			return
@@ -181,3 +336,174 @@ func (a *Application) deleteItem(w http.ResponseWriter, r *http.Request) {
 
 	web.Respond(w, r, http.StatusNoContent, nil)
 }
+
+// completion is the response to completing an item, holding the next occurrence
+// of the item if it recurs.
+type completion struct {
+	Item item.Item  `json:"item"`
+	Next *item.Item `json:"next"`
+}
+
+// completeItem is a handler that marks the item given by the lid and iid URL
+// parameters as completed, creating its next occurrence if it recurs.
+func (a *Application) completeItem(w http.ResponseWriter, r *http.Request) {
+	listID, err := strconv.Atoi(httprouter.ParamsFromContext(r.Context()).ByName("lid"))
+	if err != nil {
+		web.RespondError(w, r, http.StatusInternalServerError, errors.Wrap(err, "convert list id to integer"))
+		return
+	}
+
+	itemID, err := strconv.Atoi(httprouter.ParamsFromContext(r.Context()).ByName("iid"))
+	if err != nil {
+		web.RespondError(w, r, http.StatusInternalServerError, errors.Wrap(err, "convert item id to integer"))
+		return
+	}
+
+	var c completion
+	err = db.Transact(a.DB, func(tx *sqlx.Tx) error {
+		var err error
+		c.Item, c.Next, err = item.CompleteItem(tx, itemID, listID)
+		return err
+	})
+	if err != nil {
+		if errors.Cause(err) == sql.ErrNoRows {
+			web.RespondError(w, r, http.StatusNotFound, errors.New(http.StatusText(http.StatusNotFound)))
+			return
+		}
+
+		web.RespondError(w, r, http.StatusInternalServerError, errors.Wrap(err, "complete item"))
+		return
+	}
+
+	web.Respond(w, r, http.StatusOK, c)
+}
+
+// itemMove is the payload of an item move. ListID is the list to move the item to,
+// leaving it out keeps the item on its current list. ParentID is the item to nest
+// it under, leaving it out makes it a top level item.
+type itemMove struct {
+	ListID   int  `json:"listID"`
+	ParentID *int `json:"parentID"`
+}
+
+// moveItem is a handler that moves the item given by the lid and iid URL parameters,
+// along with all of its sub-items, to another parent and optionally another list.
+func (a *Application) moveItem(w http.ResponseWriter, r *http.Request) {
+	listID, err := strconv.Atoi(httprouter.ParamsFromContext(r.Context()).ByName("lid"))
+	if err != nil {
+		web.RespondError(w, r, http.StatusInternalServerError, errors.Wrap(err, "convert list id to integer"))
+		return
+	}
+
+	itemID, err := strconv.Atoi(httprouter.ParamsFromContext(r.Context()).ByName("iid"))
+	if err != nil {
+		web.RespondError(w, r, http.StatusInternalServerError, errors.Wrap(err, "convert item id to integer"))
+		return
+	}
+
+	var payload itemMove
+	if err := web.Decode(r, &payload); err != nil {
+		web.RespondError(w, r, http.StatusInternalServerError, errors.Wrap(err, "unmarshal request payload"))
+		return
+	}
+
+	if payload.ListID == 0 {
+		payload.ListID = listID
+	}
+
+	var moved item.Item
+	err = db.Transact(a.DB, func(tx *sqlx.Tx) error {
+		var err error
+		moved, err = item.MoveItem(tx, itemID, listID, payload.ListID, payload.ParentID)
+		return err
+	})
+	if err != nil {
+		if errors.Cause(err) == sql.ErrNoRows {
+			web.RespondError(w, r, http.StatusNotFound, errors.New(http.StatusText(http.StatusNotFound)))
+			return
+		}
+
+		if terr, ok := errors.Cause(err).(item.TreeError); ok {
+			web.RespondError(w, r, http.StatusBadRequest, terr)
+			return
+		}
+
+		web.RespondError(w, r, http.StatusInternalServerError, errors.Wrap(err, "move item"))
+		return
+	}
+
+	web.Respond(w, r, http.StatusOK, moved)
+}
+
+// mergeReport is the response to merging the duplicate items on a list.
+type mergeReport struct {
+	Groups []item.MergedGroup `json:"groups"`
+}
+
+// mergeItems is a handler that merges the items on the list given by the lid URL
+// parameter whose names match once normalized, reporting the groups merged.
+func (a *Application) mergeItems(w http.ResponseWriter, r *http.Request) {
+	listID, err := strconv.Atoi(httprouter.ParamsFromContext(r.Context()).ByName("lid"))
+	if err != nil {
+		web.RespondError(w, r, http.StatusInternalServerError, errors.Wrap(err, "convert list id to integer"))
+		return
+	}
+
+	var report mergeReport
+	err = db.Transact(a.DB, func(tx *sqlx.Tx) error {
+		var err error
+		report.Groups, err = item.MergeDuplicates(tx, listID)
+		return err
+	})
+	if err != nil {
+		if errors.Cause(err) == sql.ErrNoRows {
+			web.RespondError(w, r, http.StatusNotFound, errors.New(http.StatusText(http.StatusNotFound)))
+			return
+		}
+
+		web.RespondError(w, r, http.StatusInternalServerError, errors.Wrap(err, "merge duplicate items"))
+		return
+	}
+
+	web.Respond(w, r, http.StatusOK, report)
+}
+
+// itemUpdate is the payload of an item update. BaseVersion is the version of the
+// item the update was made against, which allows edits made since to be merged
+// rather than overwritten. Leaving it out overwrites the item.
+type itemUpdate struct {
+	item.Item
+	BaseVersion int64 `json:"baseVersion"`
+}
+
+// validateItem returns an error describing the first invalid field of an item
+// payload, or nil if it is valid.
+func validateItem(i item.Item) error {
+	if i.Name == "" {
+		return errors.New("name is a required field")
+	}
+
+	if i.Quantity.Sign() <= 0 {
+		return errors.New("quantity must be supplied and greater than 0")
+	}
+
+	if _, err := units.Canonical(i.Unit); err != nil {
+		return err
+	}
+
+	if err := validatePrice(i.Price, i.Currency, "price"); err != nil {
+		return err
+	}
+
+	if err := validateNotes(i.Notes); err != nil {
+		return err
+	}
+
+	if i.Recurrence != "" {
+		if _, err := rrule.Parse(i.Recurrence); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -2,13 +2,16 @@ package handlers
 
 import (
 	"database/sql"
-	"encoding/json"
 	"net/http"
 	"strconv"
 
+	"github.com/george-e-shaw-iv/integration-tests-example/cmd/listd/folder"
 	"github.com/george-e-shaw-iv/integration-tests-example/cmd/listd/list"
+	"github.com/george-e-shaw-iv/integration-tests-example/cmd/listd/textlist"
 	"github.com/george-e-shaw-iv/integration-tests-example/internal/platform/db"
+	"github.com/george-e-shaw-iv/integration-tests-example/internal/platform/markdown"
 	"github.com/george-e-shaw-iv/integration-tests-example/internal/platform/web"
+	"github.com/jmoiron/sqlx"
 	"github.com/julienschmidt/httprouter"
 	"github.com/lib/pq"
 	"github.com/pkg/errors"
@@ -22,6 +25,10 @@ func (a *Application) getLists(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if renderHTML(r) {
+		renderListNotes(lists)
+	}
+
 	if len(lists) == 0 {
 		lists = make([]list.List, 0)
 	}
@@ -33,18 +40,33 @@ func (a *Application) getLists(w http.ResponseWriter, r *http.Request) {
 func (a *Application) createList(w http.ResponseWriter, r *http.Request) {
 	var payload list.List
 
-	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+	if err := web.Decode(r, &payload); err != nil {
 		web.RespondError(w, r, http.StatusInternalServerError, errors.Wrap(err, "unmarshal request payload"))
 		return
 	}
 
-	if payload.Name == "" {
-		web.RespondError(w, r, http.StatusBadRequest, errors.New("name key is required"))
+	if err := validateList(payload); err != nil {
+		web.RespondError(w, r, http.StatusBadRequest, err)
 		return
 	}
+	normalizeBudget(&payload)
+
+	var l list.List
+	err := db.Transact(a.DB, func(tx *sqlx.Tx) error {
+		if err := folder.CheckFolder(tx, payload.FolderID); err != nil {
+			return err
+		}
 
-	l, err := list.CreateList(a.DB, payload)
+		var err error
+		l, err = list.CreateList(tx, payload)
+		return err
+	})
 	if err != nil {
+		if terr, ok := errors.Cause(err).(folder.TreeError); ok {
+			web.RespondError(w, r, http.StatusBadRequest, terr)
+			return
+		}
+
 		if pgerr, ok := errors.Cause(err).(*pq.Error); ok {
 			if string(pgerr.Code) == db.PSQLErrUniqueConstraint {
 				web.RespondError(w, r, http.StatusBadRequest, errors.Wrap(err, "attempting to break unique name constraint"))
@@ -79,6 +101,19 @@ func (a *Application) getList(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Lists are served along with their items as text to clients preferring it over
+	// the wire formats every response can be encoded in.
+	w.Header().Add("Vary", "Accept")
+	switch mt := web.Negotiate(r, append(web.MediaTypes(), textlist.MarkdownType, textlist.TodoTxtType)...); mt {
+	case textlist.MarkdownType, textlist.TodoTxtType:
+		a.writeListText(w, r, l, mt)
+		return
+	}
+
+	if renderHTML(r) {
+		l.NotesHTML = markdown.Render(l.Notes)
+	}
+
 	web.Respond(w, r, http.StatusOK, l)
 }
 
@@ -92,19 +127,24 @@ func (a *Application) updateList(w http.ResponseWriter, r *http.Request) {
 	}
 
 	var payload list.List
-	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+	if err := web.Decode(r, &payload); err != nil {
 		web.RespondError(w, r, http.StatusInternalServerError, errors.Wrap(err, "unmarshal request payload"))
 		return
 	}
 
 	payload.ID = listID
+	payload.NotesHTML = ""
 
-	if payload.Name == "" {
-		web.RespondError(w, r, http.StatusBadRequest, errors.New("name key is required"))
+	if err := validateList(payload); err != nil {
+		web.RespondError(w, r, http.StatusBadRequest, err)
 		return
 	}
+	normalizeBudget(&payload)
 
-	if err := list.UpdateList(a.DB, payload); err != nil {
+	err = db.Transact(a.DB, func(tx *sqlx.Tx) error {
+		return list.UpdateList(tx, payload)
+	})
+	if err != nil {
 		if errors.Cause(err) == sql.ErrNoRows {
 			web.RespondError(w, r, http.StatusNotFound, errors.New(http.StatusText(http.StatusNotFound)))
 			return
@@ -133,7 +173,10 @@ func (a *Application) deleteList(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if err := list.DeleteList(a.DB, listID); err != nil {
+	err = db.Transact(a.DB, func(tx *sqlx.Tx) error {
+		return list.DeleteList(tx, listID)
+	})
+	if err != nil {
 		if errors.Cause(err) == sql.ErrNoRows {
 			web.RespondError(w, r, http.StatusNotFound, errors.New(http.StatusText(http.StatusNotFound)))
 			return
@@ -145,3 +188,66 @@ func (a *Application) deleteList(w http.ResponseWriter, r *http.Request) {
 
 	web.Respond(w, r, http.StatusNoContent, nil)
 }
+
+// listMove is the payload of a list move. FolderID is the folder to move the list
+// into, leaving it out moves the list to the top level.
+type listMove struct {
+	FolderID *int `json:"folderID"`
+}
+
+// moveList is a handler that moves the list given by the lid URL parameter into
+// another folder.
+func (a *Application) moveList(w http.ResponseWriter, r *http.Request) {
+	listID, err := strconv.Atoi(httprouter.ParamsFromContext(r.Context()).ByName("lid"))
+	if err != nil {
+		web.RespondError(w, r, http.StatusInternalServerError, errors.Wrap(err, "convert list id to integer"))
+		return
+	}
+
+	var payload listMove
+	if err := web.Decode(r, &payload); err != nil {
+		web.RespondError(w, r, http.StatusInternalServerError, errors.Wrap(err, "unmarshal request payload"))
+		return
+	}
+
+	var l list.List
+	err = db.Transact(a.DB, func(tx *sqlx.Tx) error {
+		var err error
+		l, err = folder.MoveList(tx, listID, payload.FolderID)
+		return err
+	})
+	if err != nil {
+		if errors.Cause(err) == sql.ErrNoRows {
+			web.RespondError(w, r, http.StatusNotFound, errors.New(http.StatusText(http.StatusNotFound)))
+			return
+		}
+
+		if terr, ok := errors.Cause(err).(folder.TreeError); ok {
+			web.RespondError(w, r, http.StatusBadRequest, terr)
+			return
+		}
+
+		web.RespondError(w, r, http.StatusInternalServerError, errors.Wrap(err, "move list"))
+		return
+	}
+
+	web.Respond(w, r, http.StatusOK, l)
+}
+
+// validateList returns an error describing the first invalid field of a list
+// payload, or nil if it is valid.
+func validateList(l list.List) error {
+	if l.Name == "" {
+		return errors.New("name key is required")
+	}
+
+	if err := validateNotes(l.Notes); err != nil {
+		return err
+	}
+
+	if err := validatePrice(l.Budget, l.BudgetCurrency, "budget"); err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,50 @@
+package handlers
+
+import (
+	"net/http"
+	"unicode/utf8"
+
+	"github.com/george-e-shaw-iv/integration-tests-example/cmd/listd/item"
+	"github.com/george-e-shaw-iv/integration-tests-example/cmd/listd/list"
+	"github.com/george-e-shaw-iv/integration-tests-example/internal/platform/markdown"
+	"github.com/pkg/errors"
+)
+
+// maxNotesLength is the maximum number of characters in the notes of a list or item.
+const maxNotesLength = 10000
+
+// validateNotes returns an error if notes are longer than maxNotesLength.
+func validateNotes(notes string) error {
+	if utf8.RuneCountInString(notes) > maxNotesLength {
+		return errors.Errorf("notes are limited to %d characters", maxNotesLength)
+	}
+
+	return nil
+}
+
+// renderHTML reports whether the render URL query parameter asks for notes to be
+// rendered as HTML.
+func renderHTML(r *http.Request) bool {
+	return r.URL.Query().Get("render") == "html"
+}
+
+// renderListNotes fills in the NotesHTML of every list with notes.
+func renderListNotes(lists []list.List) {
+	for i := range lists {
+		if lists[i].Notes != "" {
+			lists[i].NotesHTML = markdown.Render(lists[i].Notes)
+		}
+	}
+}
+
+// renderItemNotes fills in the NotesHTML of every item with notes, including
+// sub-items.
+func renderItemNotes(items []item.Item) {
+	for i := range items {
+		if items[i].Notes != "" {
+			items[i].NotesHTML = markdown.Render(items[i].Notes)
+		}
+
+		renderItemNotes(items[i].Children)
+	}
+}
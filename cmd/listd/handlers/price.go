@@ -0,0 +1,72 @@
+package handlers
+
+import (
+	"database/sql"
+	"net/http"
+	"strconv"
+
+	"github.com/george-e-shaw-iv/integration-tests-example/cmd/listd/item"
+	"github.com/george-e-shaw-iv/integration-tests-example/cmd/listd/list"
+	"github.com/george-e-shaw-iv/integration-tests-example/internal/platform/money"
+	"github.com/george-e-shaw-iv/integration-tests-example/internal/platform/web"
+	"github.com/julienschmidt/httprouter"
+	"github.com/pkg/errors"
+)
+
+// validatePrice returns an error when an optional amount of money, named field in
+// the payload, is negative or isn't given along with a known currency.
+func validatePrice(amount *int64, currency, field string) error {
+	if amount == nil {
+		if currency != "" {
+			return errors.Errorf("currency can't be given without a %s", field)
+		}
+
+		return nil
+	}
+
+	if *amount < 0 {
+		return errors.Errorf("%s must not be negative", field)
+	}
+
+	if currency == "" {
+		return errors.Errorf("%s must be given along with a currency", field)
+	}
+
+	if _, err := money.Currency(currency); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// normalizeBudget rewrites the currency of the budget of a validated list payload
+// as its ISO 4217 code.
+func normalizeBudget(l *list.List) {
+	if l.Budget != nil {
+		l.BudgetCurrency, _ = money.Currency(l.BudgetCurrency)
+	}
+}
+
+// getSummary is a handler that sums up the items on the list given by the lid URL
+// parameter: how many there are and are completed, their total quantities and their
+// total cost per currency, compared with the budget of the list.
+func (a *Application) getSummary(w http.ResponseWriter, r *http.Request) {
+	listID, err := strconv.Atoi(httprouter.ParamsFromContext(r.Context()).ByName("lid"))
+	if err != nil {
+		web.RespondError(w, r, http.StatusInternalServerError, errors.Wrap(err, "convert list id to integer"))
+		return
+	}
+
+	s, err := item.Summarize(a.DB, listID)
+	if err != nil {
+		if errors.Cause(err) == sql.ErrNoRows {
+			web.RespondError(w, r, http.StatusNotFound, errors.New(http.StatusText(http.StatusNotFound)))
+			return
+		}
+
+		web.RespondError(w, r, http.StatusInternalServerError, errors.Wrap(err, "summarize list"))
+		return
+	}
+
+	web.Respond(w, r, http.StatusOK, s)
+}
@@ -0,0 +1,47 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/george-e-shaw-iv/integration-tests-example/cmd/listd/search"
+	"github.com/george-e-shaw-iv/integration-tests-example/internal/platform/web"
+	"github.com/pkg/errors"
+)
+
+// Bounds of the number of hits returned by a single search.
+const (
+	defaultSearchLimit = 50
+	maxSearchLimit     = 200
+)
+
+// searchAll is a handler that returns the lists and items matching the q URL query
+// parameter, grouped by list and ranked by relevance. The optional limit URL
+// query parameter bounds the number of hits.
+func (a *Application) searchAll(w http.ResponseWriter, r *http.Request) {
+	q := strings.TrimSpace(r.URL.Query().Get("q"))
+	if q == "" {
+		web.RespondError(w, r, http.StatusBadRequest, errors.New("q is a required query parameter"))
+		return
+	}
+
+	limit := defaultSearchLimit
+	if v := r.URL.Query().Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > maxSearchLimit {
+			web.RespondError(w, r, http.StatusBadRequest, errors.Errorf("limit must be between 1 and %d", maxSearchLimit))
+			return
+		}
+
+		limit = n
+	}
+
+	results, err := search.Search(a.DB, q, limit)
+	if err != nil {
+		web.RespondError(w, r, http.StatusInternalServerError, errors.Wrap(err, "search lists and items"))
+		return
+	}
+
+	web.Respond(w, r, http.StatusOK, results)
+}
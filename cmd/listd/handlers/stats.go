@@ -0,0 +1,68 @@
+package handlers
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/george-e-shaw-iv/integration-tests-example/cmd/listd/stats"
+	"github.com/george-e-shaw-iv/integration-tests-example/internal/platform/web"
+	"github.com/pkg/errors"
+)
+
+// Bounds of the number of days and of item names covered by getStats.
+const (
+	defaultStatsDays = 30
+	maxStatsDays     = 365
+	defaultStatsTop  = 10
+	maxStatsTop      = 100
+)
+
+// getStats is a handler that returns aggregate statistics over every list and item.
+// The days URL query parameter is the number of days items created are counted
+// for and the top URL query parameter the number of most used item names returned.
+// The statistics are cached, which the Cache-Control header tells clients.
+func (a *Application) getStats(w http.ResponseWriter, r *http.Request) {
+	days, err := intParam(r, "days", defaultStatsDays, maxStatsDays)
+	if err != nil {
+		web.RespondError(w, r, http.StatusBadRequest, err)
+		return
+	}
+
+	top, err := intParam(r, "top", defaultStatsTop, maxStatsTop)
+	if err != nil {
+		web.RespondError(w, r, http.StatusBadRequest, err)
+		return
+	}
+
+	s, err := a.Stats.Get(stats.Options{Days: days, Top: top})
+	if err != nil {
+		web.RespondError(w, r, http.StatusInternalServerError, errors.Wrap(err, "compute stats"))
+		return
+	}
+
+	if maxAge := math.Floor(time.Until(s.Expires).Seconds()); maxAge > 0 {
+		w.Header().Set("Cache-Control", "max-age="+strconv.Itoa(int(maxAge)))
+	} else {
+		w.Header().Set("Cache-Control", "no-cache")
+	}
+
+	web.Respond(w, r, http.StatusOK, s)
+}
+
+// intParam returns the integer URL query parameter given by name, between 1 and max,
+// or def when it is left out.
+func intParam(r *http.Request, name string, def, max int) (int, error) {
+	v := r.URL.Query().Get(name)
+	if v == "" {
+		return def, nil
+	}
+
+	n, err := strconv.Atoi(v)
+	if err != nil || n < 1 || n > max {
+		return 0, errors.Errorf("%s must be an integer between 1 and %d", name, max)
+	}
+
+	return n, nil
+}
@@ -0,0 +1,80 @@
+package handlers
+
+import (
+	"database/sql"
+	"net/http"
+	"strconv"
+
+	"github.com/george-e-shaw-iv/integration-tests-example/cmd/listd/item"
+	"github.com/george-e-shaw-iv/integration-tests-example/cmd/listd/tag"
+	"github.com/george-e-shaw-iv/integration-tests-example/internal/platform/db"
+	"github.com/george-e-shaw-iv/integration-tests-example/internal/platform/web"
+	"github.com/jmoiron/sqlx"
+	"github.com/julienschmidt/httprouter"
+	"github.com/pkg/errors"
+)
+
+// getTags is a handler that returns every tag in use along with the number of items
+// carrying it.
+func (a *Application) getTags(w http.ResponseWriter, r *http.Request) {
+	tags, err := tag.SelectTags(a.DB)
+	if err != nil {
+		web.RespondError(w, r, http.StatusInternalServerError, errors.Wrap(err, "select all tag rows"))
+		return
+	}
+
+	web.Respond(w, r, http.StatusOK, tags)
+}
+
+// addItemTag is a handler that tags the item given by the lid and iid URL parameters
+// with the tag given by the tag URL parameter.
+func (a *Application) addItemTag(w http.ResponseWriter, r *http.Request) {
+	a.changeItemTag(w, r, item.AddTag)
+}
+
+// removeItemTag is a handler that removes the tag given by the tag URL parameter from
+// the item given by the lid and iid URL parameters.
+func (a *Application) removeItemTag(w http.ResponseWriter, r *http.Request) {
+	a.changeItemTag(w, r, item.RemoveTag)
+}
+
+// changeItemTag applies change to the item and tag given by the URL parameters and
+// responds with the item.
+func (a *Application) changeItemTag(w http.ResponseWriter, r *http.Request, change func(*sqlx.Tx, int, int, string) (item.Item, error)) {
+	params := httprouter.ParamsFromContext(r.Context())
+
+	listID, err := strconv.Atoi(params.ByName("lid"))
+	if err != nil {
+		web.RespondError(w, r, http.StatusInternalServerError, errors.Wrap(err, "convert list id to integer"))
+		return
+	}
+
+	itemID, err := strconv.Atoi(params.ByName("iid"))
+	if err != nil {
+		web.RespondError(w, r, http.StatusInternalServerError, errors.Wrap(err, "convert item id to integer"))
+		return
+	}
+
+	var i item.Item
+	err = db.Transact(a.DB, func(tx *sqlx.Tx) error {
+		var err error
+		i, err = change(tx, itemID, listID, params.ByName("tag"))
+		return err
+	})
+	if err != nil {
+		if errors.Cause(err) == sql.ErrNoRows {
+			web.RespondError(w, r, http.StatusNotFound, errors.New(http.StatusText(http.StatusNotFound)))
+			return
+		}
+
+		if errors.Cause(err) == item.ErrInvalidTag {
+			web.RespondError(w, r, http.StatusBadRequest, err)
+			return
+		}
+
+		web.RespondError(w, r, http.StatusInternalServerError, errors.Wrap(err, "change item tag"))
+		return
+	}
+
+	web.Respond(w, r, http.StatusOK, i)
+}
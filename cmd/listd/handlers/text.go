@@ -0,0 +1,130 @@
+package handlers
+
+import (
+	"database/sql"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+
+	"github.com/george-e-shaw-iv/integration-tests-example/cmd/listd/item"
+	"github.com/george-e-shaw-iv/integration-tests-example/cmd/listd/list"
+	"github.com/george-e-shaw-iv/integration-tests-example/cmd/listd/textlist"
+	"github.com/george-e-shaw-iv/integration-tests-example/internal/platform/db"
+	"github.com/george-e-shaw-iv/integration-tests-example/internal/platform/web"
+	"github.com/jmoiron/sqlx"
+	"github.com/julienschmidt/httprouter"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+// textReaders maps the media types items are imported from to their readers.
+var textReaders = map[string]func(io.Reader) ([]textlist.Entry, error){
+	textlist.MarkdownType: textlist.ReadMarkdown,
+	textlist.TodoTxtType:  textlist.ReadTodoTxt,
+}
+
+// writeListText writes a list along with its items as text of the given media type,
+// either a Markdown checklist or todo.txt.
+func (a *Application) writeListText(w http.ResponseWriter, r *http.Request, l list.List, mediaType string) {
+	tree, err := item.SelectTree(a.DB, l.ID)
+	if err != nil {
+		web.RespondError(w, r, http.StatusInternalServerError, errors.Wrap(err, "select items of list"))
+		return
+	}
+
+	w.Header().Set("Content-Type", mediaType+"; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+
+	if mediaType == textlist.MarkdownType {
+		err = textlist.WriteMarkdown(w, l, tree)
+	} else {
+		err = textlist.WriteTodoTxt(w, tree)
+	}
+
+	if err != nil {
+		log.WithError(errors.Wrap(err, "write list as text")).Info("get list")
+	}
+}
+
+// importItems is a handler that adds the items of a Markdown checklist or todo.txt
+// file, told apart by the Content-Type header, to the list given by the lid URL
+// parameter. Every item is validated and they are only added when all of them are
+// valid, otherwise the invalid items are reported by line.
+func (a *Application) importItems(w http.ResponseWriter, r *http.Request) {
+	listID, err := strconv.Atoi(httprouter.ParamsFromContext(r.Context()).ByName("lid"))
+	if err != nil {
+		web.RespondError(w, r, http.StatusInternalServerError, errors.Wrap(err, "convert list id to integer"))
+		return
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+
+	read, ok := textReaders[mediaType]
+	if !ok {
+		web.RespondError(w, r, http.StatusUnsupportedMediaType, errors.Errorf("Content-Type must be %s or %s", textlist.MarkdownType, textlist.TodoTxtType))
+		return
+	}
+
+	entries, err := read(&limitedReader{r: r.Body, n: maxImportSize})
+	if err != nil {
+		if lerrs, ok := err.(textlist.Errors); ok {
+			errs := make([]error, 0, len(lerrs))
+			for _, lerr := range lerrs {
+				errs = append(errs, lerr)
+			}
+
+			web.Respond(w, r, http.StatusBadRequest, nil, errs...)
+			return
+		}
+
+		if errors.Cause(err) == errImportTooLarge {
+			web.RespondError(w, r, http.StatusRequestEntityTooLarge, errors.Cause(err))
+			return
+		}
+
+		web.RespondError(w, r, http.StatusBadRequest, err)
+		return
+	}
+
+	var errs []error
+	for _, e := range entries {
+		if err := validateItem(e.Item); err != nil {
+			errs = append(errs, &textlist.LineError{Line: e.Line, Err: err})
+		}
+
+		for _, tag := range e.Tags {
+			if _, err := item.NormalizeTag(tag); err != nil {
+				errs = append(errs, &textlist.LineError{Line: e.Line, Err: errors.Wrapf(err, "tag %q", tag)})
+			}
+		}
+	}
+
+	if len(errs) > 0 {
+		web.Respond(w, r, http.StatusBadRequest, nil, errs...)
+		return
+	}
+
+	var items []item.Item
+	err = db.Transact(a.DB, func(tx *sqlx.Tx) error {
+		var err error
+		items, err = textlist.Import(tx, listID, entries)
+		return err
+	})
+	if err != nil {
+		if errors.Cause(err) == sql.ErrNoRows {
+			web.RespondError(w, r, http.StatusNotFound, errors.New(http.StatusText(http.StatusNotFound)))
+			return
+		}
+
+		if terr, ok := errors.Cause(err).(item.TreeError); ok {
+			web.RespondError(w, r, http.StatusBadRequest, terr)
+			return
+		}
+
+		web.RespondError(w, r, http.StatusInternalServerError, errors.Wrap(err, "import items"))
+		return
+	}
+
+	web.Respond(w, r, http.StatusCreated, items)
+}
@@ -0,0 +1,48 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/george-e-shaw-iv/integration-tests-example/cmd/listd/item"
+	"github.com/george-e-shaw-iv/integration-tests-example/internal/platform/units"
+	"github.com/pkg/errors"
+)
+
+// normalizeMetric reports whether the normalize URL query parameter asks for
+// quantities to be converted to metric units, returning an error when it asks
+// for anything else.
+func normalizeMetric(r *http.Request) (bool, error) {
+	switch r.URL.Query().Get("normalize") {
+	case "":
+		return false, nil
+	case "metric":
+		return true, nil
+	}
+
+	return false, errors.New("normalize must be metric")
+}
+
+// normalizeQuantities converts the quantity of every item, including sub-items, to
+// the metric unit best suited to it.
+func normalizeQuantities(items []item.Item) error {
+	for i := range items {
+		q, unit, err := units.Metric(items[i].Quantity, items[i].Unit)
+		if err != nil {
+			return errors.Wrapf(err, "normalize quantity of item %d", items[i].ID)
+		}
+
+		// The price stays the price of the unit it was given for, which can't
+		// always be restated exactly per the metric unit.
+		if items[i].Price != nil && unit != items[i].Unit {
+			items[i].PriceUnit = items[i].Unit
+		}
+
+		items[i].Quantity, items[i].Unit = q, unit
+
+		if err := normalizeQuantities(items[i].Children); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,178 @@
+package handlers
+
+import (
+	"database/sql"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/george-e-shaw-iv/integration-tests-example/cmd/listd/webhook"
+	"github.com/george-e-shaw-iv/integration-tests-example/internal/platform/web"
+	"github.com/julienschmidt/httprouter"
+	"github.com/pkg/errors"
+)
+
+// deliveryLogLimit is the maximum number of deliveries returned by getDeliveries.
+const deliveryLogLimit = 100
+
+// getWebhooks is a handler that returns all rows from the webhook table for a list.
+func (a *Application) getWebhooks(w http.ResponseWriter, r *http.Request) {
+	listID, err := strconv.Atoi(httprouter.ParamsFromContext(r.Context()).ByName("lid"))
+	if err != nil {
+		web.RespondError(w, r, http.StatusInternalServerError, errors.Wrap(err, "convert list id to integer"))
+		return
+	}
+
+	webhooks, err := webhook.SelectWebhooks(a.DB, listID)
+	if err != nil {
+		if errors.Cause(err) == sql.ErrNoRows {
+			web.RespondError(w, r, http.StatusNotFound, errors.New(http.StatusText(http.StatusNotFound)))
+			return
+		}
+
+		web.RespondError(w, r, http.StatusInternalServerError, errors.Wrap(err, "select all webhook rows"))
+		return
+	}
+
+	// The secret is only ever returned when the webhook is created.
+	for i := range webhooks {
+		webhooks[i].Secret = ""
+	}
+
+	web.Respond(w, r, http.StatusOK, webhooks)
+}
+
+// createWebhook is a handler that creates a new row in the webhook table.
+func (a *Application) createWebhook(w http.ResponseWriter, r *http.Request) {
+	listID, err := strconv.Atoi(httprouter.ParamsFromContext(r.Context()).ByName("lid"))
+	if err != nil {
+		web.RespondError(w, r, http.StatusInternalServerError, errors.Wrap(err, "convert list id to integer"))
+		return
+	}
+
+	var payload webhook.Webhook
+	if err := web.Decode(r, &payload); err != nil {
+		web.RespondError(w, r, http.StatusInternalServerError, errors.Wrap(err, "unmarshal request payload"))
+		return
+	}
+
+	payload.ListID = listID
+
+	if u, err := url.Parse(payload.URL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		web.RespondError(w, r, http.StatusBadRequest, errors.New("url must be an absolute http or https url"))
+		return
+	}
+
+	if len(payload.Events) == 0 {
+		web.RespondError(w, r, http.StatusBadRequest, errors.New("events must contain at least one event"))
+		return
+	}
+
+	for _, e := range payload.Events {
+		if !webhook.ValidEvent(e) {
+			web.RespondError(w, r, http.StatusBadRequest, errors.Errorf("unknown event: %s", e))
+			return
+		}
+	}
+
+	wh, err := webhook.CreateWebhook(a.DB, payload)
+	if err != nil {
+		if errors.Cause(err) == sql.ErrNoRows {
+			web.RespondError(w, r, http.StatusNotFound, errors.New(http.StatusText(http.StatusNotFound)))
+			return
+		}
+
+		web.RespondError(w, r, http.StatusInternalServerError, errors.Wrap(err, "insert row into webhook table"))
+		return
+	}
+
+	web.Respond(w, r, http.StatusCreated, wh)
+}
+
+// getWebhook is a handler that returns a row from the webhook table based off of the
+// lid and wid URL parameters.
+func (a *Application) getWebhook(w http.ResponseWriter, r *http.Request) {
+	listID, err := strconv.Atoi(httprouter.ParamsFromContext(r.Context()).ByName("lid"))
+	if err != nil {
+		web.RespondError(w, r, http.StatusInternalServerError, errors.Wrap(err, "convert list id to integer"))
+		return
+	}
+
+	webhookID, err := strconv.Atoi(httprouter.ParamsFromContext(r.Context()).ByName("wid"))
+	if err != nil {
+		web.RespondError(w, r, http.StatusInternalServerError, errors.Wrap(err, "convert webhook id to integer"))
+		return
+	}
+
+	wh, err := webhook.SelectWebhook(a.DB, webhookID, listID)
+	if err != nil {
+		if errors.Cause(err) == sql.ErrNoRows {
+			web.RespondError(w, r, http.StatusNotFound, errors.New(http.StatusText(http.StatusNotFound)))
+			return
+		}
+
+		web.RespondError(w, r, http.StatusInternalServerError, errors.Wrap(err, "select webhook by id and list id"))
+		return
+	}
+
+	wh.Secret = ""
+
+	web.Respond(w, r, http.StatusOK, wh)
+}
+
+// deleteWebhook is a handler that deletes a row from the webhook table based off of the
+// lid and wid URL parameters.
+func (a *Application) deleteWebhook(w http.ResponseWriter, r *http.Request) {
+	listID, err := strconv.Atoi(httprouter.ParamsFromContext(r.Context()).ByName("lid"))
+	if err != nil {
+		web.RespondError(w, r, http.StatusInternalServerError, errors.Wrap(err, "convert list id to integer"))
+		return
+	}
+
+	webhookID, err := strconv.Atoi(httprouter.ParamsFromContext(r.Context()).ByName("wid"))
+	if err != nil {
+		web.RespondError(w, r, http.StatusInternalServerError, errors.Wrap(err, "convert webhook id to integer"))
+		return
+	}
+
+	if err := webhook.DeleteWebhook(a.DB, webhookID, listID); err != nil {
+		if errors.Cause(err) == sql.ErrNoRows {
+			web.RespondError(w, r, http.StatusNotFound, errors.New(http.StatusText(http.StatusNotFound)))
+			return
+		}
+
+		web.RespondError(w, r, http.StatusInternalServerError, errors.Wrap(err, "delete webhook row"))
+		return
+	}
+
+	web.Respond(w, r, http.StatusNoContent, nil)
+}
+
+// getDeliveries is a handler that returns the most recent delivery attempts of a
+// webhook based off of the lid and wid URL parameters.
+func (a *Application) getDeliveries(w http.ResponseWriter, r *http.Request) {
+	listID, err := strconv.Atoi(httprouter.ParamsFromContext(r.Context()).ByName("lid"))
+	if err != nil {
+		web.RespondError(w, r, http.StatusInternalServerError, errors.Wrap(err, "convert list id to integer"))
+		return
+	}
+
+	webhookID, err := strconv.Atoi(httprouter.ParamsFromContext(r.Context()).ByName("wid"))
+	if err != nil {
+		web.RespondError(w, r, http.StatusInternalServerError, errors.Wrap(err, "convert webhook id to integer"))
+		return
+	}
+
+	deliveries, err := webhook.SelectDeliveries(a.DB, webhookID, listID, deliveryLogLimit)
+	if err != nil {
+		if errors.Cause(err) == sql.ErrNoRows {
+			web.RespondError(w, r, http.StatusNotFound, errors.New(http.StatusText(http.StatusNotFound)))
+			return
+		}
+
+		web.RespondError(w, r, http.StatusInternalServerError, errors.Wrap(err, "select webhook deliveries"))
+		return
+	}
+
+	web.Respond(w, r, http.StatusOK, deliveries)
+}
@@ -0,0 +1,205 @@
+package item
+
+import (
+	"database/sql"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/george-e-shaw-iv/integration-tests-example/cmd/listd/list"
+	"github.com/george-e-shaw-iv/integration-tests-example/internal/platform/outbox"
+	"github.com/george-e-shaw-iv/integration-tests-example/internal/platform/units"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/pkg/errors"
+)
+
+// MergedGroup is a group of duplicate items that were merged into one. Item is
+// the surviving item, Merged holds the ids of the items merged into it, which no
+// longer exist.
+type MergedGroup struct {
+	Item   Item  `json:"item"`
+	Merged []int `json:"merged"`
+}
+
+// duplicateKey is what duplicate items have in common. Only items on the same
+// level of the same parent are duplicates, so merging them never changes how deep
+// any item is nested, and open items are never merged with completed ones.
+type duplicateKey struct {
+	name      string
+	parentID  int
+	completed bool
+	dimension units.Dimension
+}
+
+// NormalizeName returns the form of an item name that duplicate names share,
+// lower cased with surrounding white space trimmed and inner white space
+// collapsed.
+func NormalizeName(name string) string {
+	return strings.Join(strings.Fields(strings.ToLower(name)), " ")
+}
+
+// MergeDuplicates merges the items on a list whose names are the same once
+// normalized, such as Milk, milk and "Milk ". The oldest item of each group of
+// duplicates survives with the quantities of the others added to it, converted
+// to its unit, along with their tags, sub-items, attachments and comments. Items
+// whose units measure different dimensions, such as a count and a weight, are not
+// merged. The other items are deleted. The merged groups are returned.
+func MergeDuplicates(tx *sqlx.Tx, listID int) ([]MergedGroup, error) {
+	if _, err := tx.Exec(lockList, listID); err != nil {
+		return nil, errors.Wrap(err, "lock list row")
+	}
+
+	if _, err := list.SelectList(tx, listID); errors.Cause(err) == sql.ErrNoRows {
+		return nil, sql.ErrNoRows
+	}
+
+	// Merging moves the sub-items of duplicates under the surviving item, where they
+	// may turn out to be duplicates of its own sub-items, so the list is searched
+	// again until no duplicates are left.
+	merged := make([]MergedGroup, 0)
+	for {
+		groups, err := duplicateGroups(tx, listID)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(groups) == 0 {
+			return merged, nil
+		}
+
+		for _, group := range groups {
+			g, err := mergeGroup(tx, listID, group)
+			if err != nil {
+				return nil, err
+			}
+
+			merged = addGroup(merged, g)
+		}
+	}
+}
+
+// duplicateGroups locks the items on a list and returns the groups of duplicates
+// among them, oldest first.
+func duplicateGroups(tx *sqlx.Tx, listID int) ([][]Item, error) {
+	var items []Item
+	if err := tx.Select(&items, selectAllForUpdate, listID); err != nil {
+		return nil, errors.Wrap(err, "lock item rows of list")
+	}
+
+	var keys []duplicateKey
+	groups := make(map[duplicateKey][]Item)
+	for _, i := range items {
+		u, err := units.Lookup(i.Unit)
+		if err != nil {
+			return nil, errors.Wrapf(err, "look up unit of item %d", i.ID)
+		}
+
+		k := duplicateKey{
+			name:      NormalizeName(i.Name),
+			completed: i.CompletedAt != nil,
+			dimension: u.Dimension,
+		}
+		if i.ParentID != nil {
+			k.parentID = *i.ParentID
+		}
+
+		if _, ok := groups[k]; !ok {
+			keys = append(keys, k)
+		}
+		groups[k] = append(groups[k], i)
+	}
+
+	var dups [][]Item
+	for _, k := range keys {
+		if len(groups[k]) > 1 {
+			dups = append(dups, groups[k])
+		}
+	}
+
+	return dups, nil
+}
+
+// addGroup adds g to the merged groups, folding it into the group of the same
+// surviving item if one was merged in an earlier pass.
+func addGroup(merged []MergedGroup, g MergedGroup) []MergedGroup {
+	for i := range merged {
+		if merged[i].Item.ID == g.Item.ID {
+			merged[i].Item = g.Item
+			merged[i].Merged = append(merged[i].Merged, g.Merged...)
+			return merged
+		}
+	}
+
+	return append(merged, g)
+}
+
+// mergeGroup merges a group of duplicate items, oldest first, into the oldest.
+func mergeGroup(tx *sqlx.Tx, listID int, group []Item) (MergedGroup, error) {
+	// The survivor is selected again as merging an earlier group may have moved it
+	// under another parent.
+	survivor, err := lockItem(tx, group[0].ID, listID)
+	if err != nil {
+		return MergedGroup{}, err
+	}
+
+	ids := make(pq.Int64Array, 0, len(group)-1)
+	for _, dup := range group[1:] {
+		sum, ok, err := addQuantity(survivor, dup)
+		if err != nil {
+			return MergedGroup{}, err
+		}
+		if !ok {
+			return MergedGroup{}, errors.Errorf("units of duplicate items %d and %d are incompatible", survivor.ID, dup.ID)
+		}
+
+		survivor.Quantity = sum
+		ids = append(ids, int64(dup.ID))
+	}
+
+	if _, err := tx.Exec(mergeTags, survivor.ID, ids); err != nil {
+		return MergedGroup{}, errors.Wrap(err, "merge tags of duplicate items")
+	}
+
+	if _, err := tx.Exec(reassignAttachments, survivor.ID, ids); err != nil {
+		return MergedGroup{}, errors.Wrap(err, "reassign attachments of duplicate items")
+	}
+
+	if _, err := tx.Exec(reassignComments, survivor.ID, ids); err != nil {
+		return MergedGroup{}, errors.Wrap(err, "reassign comments on duplicate items")
+	}
+
+	var children []Item
+	if err := tx.Select(&children, reparentChildren, survivor.ID, ids, time.Now()); err != nil {
+		return MergedGroup{}, errors.Wrap(err, "reparent sub-items of duplicate items")
+	}
+
+	for _, c := range children {
+		if err := writeRevision(tx, c); err != nil {
+			return MergedGroup{}, err
+		}
+
+		if err := outbox.Write(tx, EventUpdated, strconv.Itoa(listID), c); err != nil {
+			return MergedGroup{}, errors.Wrap(err, "write item updated event")
+		}
+	}
+
+	i, err := UpdateItem(tx, survivor, 0)
+	if err != nil {
+		return MergedGroup{}, err
+	}
+
+	g := MergedGroup{
+		Item:   i,
+		Merged: make([]int, 0, len(ids)),
+	}
+	for _, id := range ids {
+		if err := DeleteItem(tx, int(id), listID); err != nil {
+			return MergedGroup{}, err
+		}
+
+		g.Merged = append(g.Merged, int(id))
+	}
+
+	return g, nil
+}
@@ -2,27 +2,75 @@ package item
 
 import (
 	"database/sql"
+	"strconv"
 	"time"
 
 	"github.com/george-e-shaw-iv/integration-tests-example/cmd/listd/list"
+	"github.com/george-e-shaw-iv/integration-tests-example/internal/platform/db"
+	"github.com/george-e-shaw-iv/integration-tests-example/internal/platform/outbox"
+	"github.com/george-e-shaw-iv/integration-tests-example/internal/platform/units"
 	"github.com/jmoiron/sqlx"
 	"github.com/pkg/errors"
 	"github.com/sirupsen/logrus"
 )
 
+// Events written to the outbox by the mutations in this package. The key of each
+// event is the list_id of the item.
+const (
+	EventCreated = "item.created"
+	EventUpdated = "item.updated"
+	EventDeleted = "item.deleted"
+)
+
 // Item is a type that contains the proper struct tags for both
 // a JSON and Postgres representation of an item.
 type Item struct {
-	ID       int       `json:"id" db:"item_id"`
-	ListID   int       `json:"listID" db:"list_id"`
-	Name     string    `json:"name" db:"name"`
-	Quantity int       `json:"quantity" db:"quantity"`
+	ID     int    `json:"id" db:"item_id"`
+	ListID int    `json:"listID" db:"list_id"`
+	Name   string `json:"name" db:"name"`
+
+	// Quantity is an exact decimal amount of Unit, which is the symbol of a unit
+	// registered with the units package. Items without a unit are counted.
+	Quantity units.Amount `json:"quantity" db:"quantity"`
+	Unit     string       `json:"unit,omitempty" db:"unit"`
+
+	// Price is the price of one Unit of the item, or of one piece of an item that
+	// is counted, in the minor unit of Currency, such as cents. Items without a
+	// price leave out both. PriceUnit is only filled in by handlers converting the
+	// quantity to another unit, it is the unit the price is still given for.
+	Price     *int64 `json:"price,omitempty" db:"price"`
+	Currency  string `json:"currency,omitempty" db:"currency"`
+	PriceUnit string `json:"priceUnit,omitempty" db:"-"`
+
+	// Notes are free-form Markdown. NotesHTML is only filled in by handlers asked
+	// to render the notes.
+	Notes     string `json:"notes,omitempty" db:"notes"`
+	NotesHTML string `json:"notesHTML,omitempty" db:"-"`
+
+	DueAt    *time.Time `json:"dueAt,omitempty" db:"due_at"`
+	RemindAt *time.Time `json:"remindAt,omitempty" db:"remind_at"`
+
+	// ParentID is the item this item is a sub-item of, if any. Children is only
+	// filled in by SelectTree.
+	ParentID *int   `json:"parentID,omitempty" db:"parent_id"`
+	Children []Item `json:"children,omitempty" db:"-"`
+
+	// Recurrence is a recurrence rule, such as FREQ=WEEKLY;BYDAY=MO, after which
+	// the item is created again with the next due date once it is completed.
+	Recurrence  string     `json:"recurrence,omitempty" db:"recurrence"`
+	CompletedAt *time.Time `json:"completedAt,omitempty" db:"completed_at"`
+
 	Created  time.Time `json:"created" db:"created"`
 	Modified time.Time `json:"modified" db:"modified"`
+	Version  int64     `json:"version" db:"version"`
+
+	// Tags are managed through AddTag and RemoveTag, they are ignored by
+	// CreateItem and UpdateItem.
+	Tags Tags `json:"tags" db:"tags"`
 }
 
 // SelectItems selects all appropriate rows from the item table given a list_id.
-func SelectItems(dbc *sqlx.DB, listID int) ([]Item, error) {
+func SelectItems(dbc db.Executor, listID int) ([]Item, error) {
 	if _, err := list.SelectList(dbc, listID); errors.Cause(err) == sql.ErrNoRows {
 		return nil, sql.ErrNoRows
 	}
@@ -38,7 +86,7 @@ func SelectItems(dbc *sqlx.DB, listID int) ([]Item, error) {
 
 // SelectItem selects a single row from the item table based off given list_id and
 // item_id.
-func SelectItem(dbc *sqlx.DB, iid, lid int) (Item, error) {
+func SelectItem(dbc db.Executor, iid, lid int) (Item, error) {
 	var i Item
 	stmt := selectByIDAndListID
 
@@ -63,15 +111,41 @@ func SelectItem(dbc *sqlx.DB, iid, lid int) (Item, error) {
 }
 
 // CreateItem inserts a new row into the item table.
-func CreateItem(dbc *sqlx.DB, r Item) (Item, error) {
+func CreateItem(tx *sqlx.Tx, r Item) (Item, error) {
+	return createItem(tx, r, 0)
+}
+
+// createItem inserts a new row into the item table, tagged with the tags of the
+// item given by tagsFrom unless it is 0.
+func createItem(tx *sqlx.Tx, r Item, tagsFrom int) (Item, error) {
 	r.Created = time.Now()
 	r.Modified = time.Now()
+	r.Tags = nil
+	r.Children = nil
+	r.NotesHTML = ""
+	r.PriceUnit = ""
+
+	if err := normalizeRecurrence(&r); err != nil {
+		return Item{}, err
+	}
+
+	if err := normalizeUnit(&r); err != nil {
+		return Item{}, err
+	}
+
+	if err := normalizePrice(&r); err != nil {
+		return Item{}, err
+	}
 
-	if _, err := list.SelectList(dbc, r.ListID); errors.Cause(err) == sql.ErrNoRows {
+	if _, err := list.SelectList(tx, r.ListID); errors.Cause(err) == sql.ErrNoRows {
 		return Item{}, sql.ErrNoRows
 	}
 
-	stmt, err := dbc.Prepare(insert)
+	if err := checkParent(tx, r.ListID, 0, r.ParentID); err != nil {
+		return Item{}, err
+	}
+
+	stmt, err := tx.Prepare(insert)
 	if err != nil {
 		return Item{}, errors.Wrap(err, "insert new item row")
 	}
@@ -82,40 +156,161 @@ func CreateItem(dbc *sqlx.DB, r Item) (Item, error) {
 		}
 	}()
 
-	row := stmt.QueryRow(r.ListID, r.Name, r.Quantity, r.Created, r.Modified)
+	row := stmt.QueryRow(r.ListID, r.ParentID, r.Name, r.Quantity, r.DueAt, r.RemindAt, r.Recurrence, r.CompletedAt, r.Created, r.Modified, r.Notes, r.Unit, r.Price, r.Currency)
 
-	if err = row.Scan(&r.ID); err != nil {
+	if err = row.Scan(&r.ID, &r.Version); err != nil {
 		return Item{}, errors.Wrap(err, "get inserted row id")
 	}
 
+	if tagsFrom != 0 {
+		if _, err := tx.Exec(copyTags, r.ID, tagsFrom); err != nil {
+			return Item{}, errors.Wrap(err, "copy item tags")
+		}
+
+		if r, err = SelectItem(tx, r.ID, r.ListID); err != nil {
+			return Item{}, err
+		}
+	}
+
+	if err := writeRevision(tx, r); err != nil {
+		return Item{}, err
+	}
+
+	if err := outbox.Write(tx, EventCreated, strconv.Itoa(r.ListID), r); err != nil {
+		return Item{}, errors.Wrap(err, "write item created event")
+	}
+
 	return r, nil
 }
 
-// UpdateItem updates a row in the item table based off of item_id and list_id. The only fields
-// able to be updated are the name and quantity field.
-func UpdateItem(dbc *sqlx.DB, r Item) error {
-	if _, err := SelectItem(dbc, r.ID, r.ListID); errors.Cause(err) == sql.ErrNoRows {
-		return sql.ErrNoRows
+// UpdateItem updates a row in the item table based off of item_id and list_id. Every
+// field other than the ids, tags and timestamps is able to be updated. Completing a
+// recurring item creates its next occurrence, see CompleteItem.
+//
+// A baseVersion of 0 overwrites the row. Otherwise baseVersion is the version of the
+// row the update was made against. If the row has been modified since, the update is
+// merged field by field with those modifications, returning a *ConflictError if both
+// changed the same field. The updated row is returned.
+func UpdateItem(tx *sqlx.Tx, r Item, baseVersion int64) (Item, error) {
+	i, _, err := updateItem(tx, r, baseVersion)
+	return i, err
+}
+
+// updateItem implements UpdateItem, additionally returning the next occurrence of
+// the item if the update completed a recurring item that has none yet.
+func updateItem(tx *sqlx.Tx, r Item, baseVersion int64) (Item, *Item, error) {
+	current, err := lockItem(tx, r.ID, r.ListID)
+	if err != nil {
+		return Item{}, nil, err
+	}
+
+	if baseVersion != 0 && baseVersion != current.Version {
+		base, err := loadRevision(tx, r.ID, baseVersion)
+		if err != nil {
+			return Item{}, nil, err
+		}
+
+		merged, conflicts := Merge(base, current, r)
+		if len(conflicts) > 0 {
+			return Item{}, nil, &ConflictError{
+				Fields:   conflicts,
+				Current:  current,
+				Incoming: r,
+			}
+		}
+
+		r = merged
+	}
+
+	if err := normalizeRecurrence(&r); err != nil {
+		return Item{}, nil, err
+	}
+
+	if err := normalizeUnit(&r); err != nil {
+		return Item{}, nil, err
+	}
+
+	if err := normalizePrice(&r); err != nil {
+		return Item{}, nil, err
 	}
 
+	if !sameParent(r.ParentID, current.ParentID) {
+		if err := checkParent(tx, r.ListID, r.ID, r.ParentID); err != nil {
+			return Item{}, nil, err
+		}
+	}
+
+	r.Created = current.Created
+	r.Children = nil
+	r.NotesHTML = ""
+	r.PriceUnit = ""
 	r.Modified = time.Now()
+	r.Tags = current.Tags
 
-	if _, err := dbc.Exec(update, r.Name, r.Quantity, r.Modified, r.ID, r.ListID); err != nil {
-		return errors.Wrap(err, "update item row")
+	row := tx.QueryRow(update, r.ParentID, r.Name, r.Quantity, r.DueAt, r.RemindAt, r.Recurrence, r.CompletedAt, r.Modified, r.ID, r.ListID, r.Notes, r.Unit, r.Price, r.Currency)
+	if err := row.Scan(&r.Version); err != nil {
+		return Item{}, nil, errors.Wrap(err, "update item row")
 	}
 
-	return nil
+	if err := writeRevision(tx, r); err != nil {
+		return Item{}, nil, err
+	}
+
+	if err := outbox.Write(tx, EventUpdated, strconv.Itoa(r.ListID), r); err != nil {
+		return Item{}, nil, errors.Wrap(err, "write item updated event")
+	}
+
+	if current.CompletedAt != nil || r.CompletedAt == nil || r.Recurrence == "" {
+		return r, nil, nil
+	}
+
+	// An item completed again after being reopened already has its next
+	// occurrence, unless that has been deleted since.
+	var nextID *int
+	if err := tx.Get(&nextID, selectNextOccurrence, r.ID); err != nil {
+		return Item{}, nil, errors.Wrap(err, "select next occurrence of item")
+	}
+
+	if nextID != nil {
+		return r, nil, nil
+	}
+
+	next, err := createNextOccurrence(tx, r)
+	if err != nil {
+		return Item{}, nil, err
+	}
+
+	if next != nil {
+		if _, err := tx.Exec(setNextOccurrence, next.ID, r.ID); err != nil {
+			return Item{}, nil, errors.Wrap(err, "record next occurrence of item")
+		}
+	}
+
+	return r, next, nil
 }
 
-// DeleteItem deletes a row in the item table based off of item_id.
-func DeleteItem(dbc *sqlx.DB, itemID, listID int) error {
-	if _, err := SelectItem(dbc, itemID, listID); errors.Cause(err) == sql.ErrNoRows {
+// DeleteItem deletes a row in the item table based off of item_id, along with all of
+// its sub-items.
+func DeleteItem(tx *sqlx.Tx, itemID, listID int) error {
+	if _, err := SelectItem(tx, itemID, listID); errors.Cause(err) == sql.ErrNoRows {
 		return sql.ErrNoRows
 	}
 
-	if _, err := dbc.Exec(del, itemID); err != nil {
+	var deleted []int
+	if err := tx.Select(&deleted, tombstone, itemID, listID); err != nil {
+		return errors.Wrap(err, "record deletion of item rows")
+	}
+
+	// Sub-items are deleted along with the item through the foreign key.
+	if _, err := tx.Exec(del, itemID); err != nil {
 		return errors.Wrap(err, "delete list row")
 	}
 
+	for _, id := range deleted {
+		if err := outbox.Write(tx, EventDeleted, strconv.Itoa(listID), Item{ID: id, ListID: listID}); err != nil {
+			return errors.Wrap(err, "write item deleted event")
+		}
+	}
+
 	return nil
 }
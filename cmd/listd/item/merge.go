@@ -0,0 +1,157 @@
+package item
+
+import (
+	"database/sql"
+	"strings"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/pkg/errors"
+)
+
+// ConflictError is returned by UpdateItem when an update made against an older
+// version of an item changes the same field as a modification made since.
+type ConflictError struct {
+	Fields   []string `json:"fields"`
+	Current  Item     `json:"current"`
+	Incoming Item     `json:"incoming"`
+}
+
+// Error implements the error interface.
+func (c *ConflictError) Error() string {
+	return "conflicting changes to fields: " + strings.Join(c.Fields, ", ")
+}
+
+// mergeField is a field of an item that is merged on its own by Merge.
+type mergeField struct {
+	name  string
+	equal func(a, b Item) bool
+	take  func(dst *Item, src Item)
+}
+
+// mergeFields are the fields of an item able to be updated, merged in order.
+var mergeFields = []mergeField{
+	{
+		name:  "parentID",
+		equal: func(a, b Item) bool { return sameParent(a.ParentID, b.ParentID) },
+		take:  func(dst *Item, src Item) { dst.ParentID = src.ParentID },
+	},
+	{
+		name:  "name",
+		equal: func(a, b Item) bool { return a.Name == b.Name },
+		take:  func(dst *Item, src Item) { dst.Name = src.Name },
+	},
+	{
+		name:  "notes",
+		equal: func(a, b Item) bool { return a.Notes == b.Notes },
+		take:  func(dst *Item, src Item) { dst.Notes = src.Notes },
+	},
+	{
+		// A quantity only makes sense along with its unit, so the two are merged
+		// as one.
+		name:  "quantity",
+		equal: func(a, b Item) bool { return a.Quantity.Equal(b.Quantity) && a.Unit == b.Unit },
+		take:  func(dst *Item, src Item) { dst.Quantity, dst.Unit = src.Quantity, src.Unit },
+	},
+	{
+		// Likewise a price is only meaningful in its currency.
+		name:  "price",
+		equal: func(a, b Item) bool { return samePrice(a.Price, b.Price) && a.Currency == b.Currency },
+		take:  func(dst *Item, src Item) { dst.Price, dst.Currency = src.Price, src.Currency },
+	},
+	{
+		name:  "dueAt",
+		equal: func(a, b Item) bool { return sameTime(a.DueAt, b.DueAt) },
+		take:  func(dst *Item, src Item) { dst.DueAt = src.DueAt },
+	},
+	{
+		name:  "remindAt",
+		equal: func(a, b Item) bool { return sameTime(a.RemindAt, b.RemindAt) },
+		take:  func(dst *Item, src Item) { dst.RemindAt = src.RemindAt },
+	},
+	{
+		name:  "recurrence",
+		equal: func(a, b Item) bool { return a.Recurrence == b.Recurrence },
+		take:  func(dst *Item, src Item) { dst.Recurrence = src.Recurrence },
+	},
+	{
+		name:  "completedAt",
+		equal: func(a, b Item) bool { return sameTime(a.CompletedAt, b.CompletedAt) },
+		take:  func(dst *Item, src Item) { dst.CompletedAt = src.CompletedAt },
+	},
+}
+
+// Merge performs a three-way merge of the fields of an item able to be updated.
+// The incoming changes were made against base, while current holds the changes
+// made since. A field changed on only one side takes that side's value. The names
+// of the fields changed differently on both sides are returned as conflicts, in
+// which case the merged item must not be used.
+func Merge(base, current, incoming Item) (Item, []string) {
+	merged := incoming
+	var conflicts []string
+
+	for _, f := range mergeFields {
+		switch {
+		case f.equal(incoming, current), f.equal(current, base):
+		case f.equal(incoming, base):
+			f.take(&merged, current)
+		default:
+			conflicts = append(conflicts, f.name)
+		}
+	}
+
+	return merged, conflicts
+}
+
+// sameTime reports whether two optional times are both unset or the same instant.
+func sameTime(a, b *time.Time) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+
+	return a.Equal(*b)
+}
+
+// sameParent reports whether two optional parents are both unset or the same item.
+func sameParent(a, b *int) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+
+	return *a == *b
+}
+
+// samePrice reports whether two optional prices are both unset or the same amount.
+func samePrice(a, b *int64) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+
+	return *a == *b
+}
+
+// writeRevision records the fields of an item able to be updated at its current
+// version so that later updates made against that version can be merged.
+func writeRevision(tx *sqlx.Tx, i Item) error {
+	if _, err := tx.Exec(insertRevision, i.ID, i.Version, i.ParentID, i.Name, i.Quantity, i.DueAt, i.RemindAt, i.Recurrence, i.CompletedAt, i.Notes, i.Unit, i.Price, i.Currency); err != nil {
+		return errors.Wrap(err, "insert item revision row")
+	}
+
+	return nil
+}
+
+// loadRevision returns an item as it was at a version. An unknown version is
+// returned as an empty item, which makes every field that differs on both sides
+// of a merge a conflict.
+func loadRevision(tx *sqlx.Tx, itemID int, version int64) (Item, error) {
+	var i Item
+	if err := tx.QueryRowx(selectRevision, itemID, version).StructScan(&i); err != nil {
+		if errors.Cause(err) == sql.ErrNoRows {
+			return Item{}, nil
+		}
+
+		return Item{}, errors.Wrap(err, "select item revision row")
+	}
+
+	return i, nil
+}
@@ -0,0 +1,186 @@
+package item
+
+import (
+	"database/sql"
+	"math/big"
+
+	"github.com/george-e-shaw-iv/integration-tests-example/cmd/listd/list"
+	"github.com/george-e-shaw-iv/integration-tests-example/internal/platform/db"
+	"github.com/george-e-shaw-iv/integration-tests-example/internal/platform/money"
+	"github.com/george-e-shaw-iv/integration-tests-example/internal/platform/units"
+	"github.com/pkg/errors"
+)
+
+// Summary sums up the items on a list, sub-items included.
+type Summary struct {
+	ListID    int `json:"listID"`
+	Items     int `json:"items"`
+	Completed int `json:"completed"`
+
+	// CompletedRatio is the share of the items that are completed, from 0 to 1.
+	CompletedRatio float64 `json:"completedRatio"`
+
+	// Quantities are the total quantities of the items per dimension of their
+	// units, in the metric unit best suited to each total.
+	Quantities []Quantity `json:"quantities"`
+
+	// Totals are the total costs of the priced items per currency. Prices in
+	// different currencies are never converted into one another. Unpriced is the
+	// number of items without a price, which are left out of the totals.
+	Totals   []Total `json:"totals"`
+	Unpriced int     `json:"unpriced"`
+
+	// Budget compares the total cost in the currency of the list's budget with the
+	// budget, it is nil for lists without one.
+	Budget *Budget `json:"budget,omitempty"`
+}
+
+// Quantity is an amount of a unit.
+type Quantity struct {
+	Quantity units.Amount `json:"quantity"`
+	Unit     string       `json:"unit"`
+}
+
+// Total is the total cost of the items priced in a currency, in its minor unit.
+// Formatted holds the same amount in the major unit, such as 12.34 for 1234 cents.
+type Total struct {
+	Currency  string `json:"currency"`
+	Amount    int64  `json:"amount"`
+	Formatted string `json:"formatted"`
+}
+
+// Budget is the budget of a list along with what the items on it cost. Remaining
+// is negative and Exceeded true when the items cost more than the budget.
+type Budget struct {
+	Currency  string `json:"currency"`
+	Amount    int64  `json:"amount"`
+	Spent     int64  `json:"spent"`
+	Remaining int64  `json:"remaining"`
+	Exceeded  bool   `json:"exceeded"`
+}
+
+// normalizePrice rewrites the currency of a priced item as its ISO 4217 code.
+func normalizePrice(i *Item) error {
+	if i.Price == nil {
+		i.Currency = ""
+		return nil
+	}
+
+	c, err := money.Currency(i.Currency)
+	if err != nil {
+		return errors.Wrap(err, "normalize item currency")
+	}
+
+	i.Currency = c
+	return nil
+}
+
+// Summarize sums up the items on the list given by listID. The cost of an item is its
+// price times its quantity, rounded half away from zero to a whole minor unit.
+func Summarize(dbc db.Executor, listID int) (Summary, error) {
+	l, err := list.SelectList(dbc, listID)
+	if err != nil {
+		if errors.Cause(err) == sql.ErrNoRows {
+			return Summary{}, sql.ErrNoRows
+		}
+
+		return Summary{}, err
+	}
+
+	s := Summary{
+		ListID:     listID,
+		Quantities: make([]Quantity, 0),
+		Totals:     make([]Total, 0),
+	}
+
+	if err := dbc.QueryRowx(summarizeCounts, listID).Scan(&s.Items, &s.Completed, &s.Unpriced); err != nil {
+		return Summary{}, errors.Wrap(err, "count item rows of list")
+	}
+
+	if s.Items > 0 {
+		s.CompletedRatio = float64(s.Completed) / float64(s.Items)
+	}
+
+	var quantities []Quantity
+	if err := dbc.Select(&quantities, summarizeQuantities, listID); err != nil {
+		return Summary{}, errors.Wrap(err, "sum item quantities of list")
+	}
+
+	if s.Quantities, err = sumQuantities(quantities); err != nil {
+		return Summary{}, err
+	}
+
+	if err := dbc.Select(&s.Totals, summarizeTotals, listID); err != nil {
+		return Summary{}, errors.Wrap(err, "sum item costs of list")
+	}
+
+	for i := range s.Totals {
+		s.Totals[i].Formatted = money.Format(s.Totals[i].Amount, s.Totals[i].Currency)
+	}
+
+	if l.Budget != nil {
+		s.Budget = &Budget{
+			Currency: l.BudgetCurrency,
+			Amount:   *l.Budget,
+		}
+
+		for _, t := range s.Totals {
+			if t.Currency == l.BudgetCurrency {
+				s.Budget.Spent = t.Amount
+			}
+		}
+
+		s.Budget.Remaining = s.Budget.Amount - s.Budget.Spent
+		s.Budget.Exceeded = s.Budget.Remaining < 0
+	}
+
+	return s, nil
+}
+
+// sumQuantities adds up quantities per dimension of their units, converting each
+// total to the metric unit best suited to it. Dimensions are returned in the order
+// their first unit appears in.
+func sumQuantities(quantities []Quantity) ([]Quantity, error) {
+	var dims []units.Dimension
+	first := make(map[units.Dimension]string)
+	sums := make(map[units.Dimension]*big.Rat)
+
+	for _, q := range quantities {
+		u, err := units.Lookup(q.Unit)
+		if err != nil {
+			return nil, errors.Wrap(err, "look up unit of item quantity")
+		}
+
+		if _, ok := sums[u.Dimension]; !ok {
+			dims = append(dims, u.Dimension)
+			first[u.Dimension] = u.Symbol
+			sums[u.Dimension] = new(big.Rat)
+		}
+
+		// Totals are kept in the first unit of their dimension as rational numbers,
+		// so they are only rounded once.
+		r, err := units.Ratio(u.Symbol, first[u.Dimension])
+		if err != nil {
+			return nil, errors.Wrap(err, "convert item quantity")
+		}
+
+		sums[u.Dimension].Add(sums[u.Dimension], r.Mul(r, q.Quantity.Rat()))
+	}
+
+	totals := make([]Quantity, 0, len(dims))
+	for _, d := range dims {
+		sum, err := units.FromRat(sums[d])
+		if err != nil {
+			return nil, errors.Wrap(err, "total item quantities")
+		}
+
+		q, unit, err := units.Metric(sum, first[d])
+		if err != nil {
+			return nil, errors.Wrap(err, "normalize total quantity")
+		}
+
+		totals = append(totals, Quantity{Quantity: q, Unit: unit})
+	}
+
+	return totals, nil
+}
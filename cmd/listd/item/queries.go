@@ -2,23 +2,193 @@ package item
 
 // PostgreSQL queries for the item table.
 const (
+	// Columns are the columns of the item table that map onto the Item type, along
+	// with the names of the item's tags, for use in place of * in queries scanning
+	// rows into an Item. The item table must not be aliased.
+	Columns = `item_id, list_id, parent_id, name, notes, quantity, unit, price, currency, due_at, remind_at, recurrence, completed_at, created, modified, version,
+		ARRAY(SELECT t.name FROM item_tag it JOIN tag t ON t.tag_id = it.tag_id WHERE it.item_id = item.item_id ORDER BY t.name) AS tags`
+
 	// selectAll is a query that selects all rows in the item table filtered
 	// by list_id.
-	selectAll = "SELECT * FROM item WHERE list_id = $1;"
+	selectAll = "SELECT " + Columns + " FROM item WHERE list_id = $1;"
 
 	// selectByIDAndListID is a query that selects a row in the item table
 	// filtered by item_id and list_id.
-	selectByIDAndListID = "SELECT * FROM item WHERE item_id = $1 AND list_id = $2;"
+	selectByIDAndListID = "SELECT " + Columns + " FROM item WHERE item_id = $1 AND list_id = $2;"
+
+	// selectForUpdate is a query that selects and locks a row in the item table
+	// filtered by item_id and list_id.
+	selectForUpdate = "SELECT " + Columns + " FROM item WHERE item_id = $1 AND list_id = $2 FOR UPDATE;"
+
+	// selectByTags is a query that selects the rows in the item table on the list
+	// given by $1 carrying at least $3 of the tags named by $2.
+	selectByTags = `SELECT ` + Columns + ` FROM item WHERE list_id = $1 AND item_id IN (
+		SELECT it.item_id FROM item_tag it JOIN tag t ON t.tag_id = it.tag_id
+		WHERE t.name = ANY($2) GROUP BY it.item_id HAVING count(*) >= $3
+	) ORDER BY item_id;`
+
+	// selectSimilar is a query that selects the rows in the item table on the list
+	// given by $1 whose lower cased name has a trigram similarity of at least $3 to
+	// the lower cased name given by $2, most similar first, limited to $4 rows.
+	selectSimilar = `SELECT ` + Columns + `, similarity(lower(name), lower($2)) AS similarity FROM item
+		WHERE list_id = $1 AND lower(name) % lower($2) AND similarity(lower(name), lower($2)) >= $3
+		ORDER BY similarity DESC, item_id LIMIT $4;`
+
+	// selectDuplicates is a query that selects the open rows in the item table on
+	// the list given by $1 under the parent given by $5, or at the top of the list
+	// when it is null, whose name is similar to $2 as in selectSimilar.
+	selectDuplicates = `SELECT ` + Columns + `, similarity(lower(name), lower($2)) AS similarity FROM item
+		WHERE list_id = $1 AND parent_id IS NOT DISTINCT FROM $5 AND completed_at IS NULL
+		AND lower(name) % lower($2) AND similarity(lower(name), lower($2)) >= $3
+		ORDER BY similarity DESC, item_id LIMIT $4;`
+
+	// selectSuggestions is a query that selects the rows in the item table on the
+	// list given by $1 whose lower cased name contains a word similar to the lower
+	// cased text given by $2, most similar first, limited to $3 rows.
+	selectSuggestions = `SELECT ` + Columns + `, word_similarity(lower($2), lower(name)) AS similarity FROM item
+		WHERE list_id = $1 AND lower($2) <% lower(name)
+		ORDER BY similarity DESC, name, item_id LIMIT $3;`
+
+	// lockList is a query that locks the row in the list table given by list_id
+	// against concurrent additions of items made through AddItem.
+	lockList = "SELECT list_id FROM list WHERE list_id = $1 FOR NO KEY UPDATE;"
 
 	// insert is a query that inserts a row into the item table using the
-	// values given in order for list_id, name, quantity, created, and
-	// modified.
-	insert = "INSERT INTO item (list_id, name, quantity, created, modified) VALUES ($1, $2, $3, $4, $5) RETURNING item_id;"
+	// values given in order for list_id, parent_id, name, quantity, due_at,
+	// remind_at, recurrence, completed_at, created, modified, notes, unit, price and
+	// currency.
+	insert = `INSERT INTO item (list_id, parent_id, name, quantity, due_at, remind_at, recurrence, completed_at, created, modified, notes, unit, price, currency)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14) RETURNING item_id, version;`
 
 	// update is a query that updates a row in the item table based off of
-	// item_id and list_id. The values able to be updated are name,
-	// quantity, and modified.
-	update = "UPDATE item SET name = $1, quantity = $2, modified = $3 WHERE item_id = $4 AND list_id = $5;"
+	// item_id and list_id. The values able to be updated are parent_id, name,
+	// quantity, due_at, remind_at, recurrence, completed_at, modified, notes, unit,
+	// price and currency, the version is set to the id of the current transaction.
+	// Moving remind_at rearms the reminder, along with its attempts.
+	update = `UPDATE item SET parent_id = $1, name = $2, quantity = $3, due_at = $4,
+		reminded_at = CASE WHEN remind_at IS DISTINCT FROM $5 THEN NULL ELSE reminded_at END,
+		reminder_attempts = CASE WHEN remind_at IS DISTINCT FROM $5 THEN 0 ELSE reminder_attempts END,
+		reminder_next_attempt = CASE WHEN remind_at IS DISTINCT FROM $5 THEN NULL ELSE reminder_next_attempt END,
+		reminder_failed_at = CASE WHEN remind_at IS DISTINCT FROM $5 THEN NULL ELSE reminder_failed_at END,
+		remind_at = $5, recurrence = $6, completed_at = $7, modified = $8, notes = $11, unit = $12, price = $13, currency = $14, version = txid_current()
+		WHERE item_id = $9 AND list_id = $10 RETURNING version;`
+
+	// selectNextOccurrence is a query that selects the item_id of the next
+	// occurrence created when the row in the item table given by item_id was
+	// completed, which is null if there is none.
+	selectNextOccurrence = "SELECT next_occurrence_id FROM item WHERE item_id = $1;"
+
+	// setNextOccurrence is a query that records the item_id given by $1 as the
+	// next occurrence of the row in the item table given by $2.
+	setNextOccurrence = "UPDATE item SET next_occurrence_id = $1 WHERE item_id = $2;"
+
+	// tombstone is a query that records the deletion of a row in the item table
+	// and all of its sub-items given an item_id and list_id, returning the
+	// item_id of every row.
+	tombstone = `WITH RECURSIVE subtree AS (
+		SELECT item_id FROM item WHERE item_id = $1
+		UNION ALL SELECT i.item_id FROM item i JOIN subtree s ON i.parent_id = s.item_id
+	) INSERT INTO tombstone (kind, id, list_id) SELECT 'item', item_id, $2 FROM subtree RETURNING id;`
+
+	// selectAncestors is a query that selects the item_id and list_id of a row in
+	// the item table and each of its ancestors given an item_id, nearest first,
+	// stopping after one more than the number of levels given.
+	selectAncestors = `WITH RECURSIVE ancestors AS (
+		SELECT item_id, list_id, parent_id, 1 AS depth FROM item WHERE item_id = $1
+		UNION ALL SELECT i.item_id, i.list_id, i.parent_id, a.depth + 1 FROM item i
+		JOIN ancestors a ON i.item_id = a.parent_id WHERE a.depth <= $2
+	) SELECT item_id, list_id FROM ancestors ORDER BY depth;`
+
+	// selectHeight is a query that selects the number of levels of the subtree
+	// of the item table rooted at the given item_id.
+	selectHeight = `WITH RECURSIVE subtree AS (
+		SELECT item_id, 1 AS depth FROM item WHERE item_id = $1
+		UNION ALL SELECT i.item_id, s.depth + 1 FROM item i JOIN subtree s ON i.parent_id = s.item_id
+	) SELECT max(depth) FROM subtree;`
+
+	// moveSubtree is a query that moves the row in the item table given by $1,
+	// along with all of its sub-items, to the list given by $2, under the parent
+	// given by $3, setting the modified time to $4 and the version to the id of
+	// the current transaction. The moved rows are returned.
+	moveSubtree = `WITH RECURSIVE subtree AS (
+		SELECT item_id FROM item WHERE item_id = $1
+		UNION ALL SELECT i.item_id FROM item i JOIN subtree s ON i.parent_id = s.item_id
+	) UPDATE item SET list_id = $2, parent_id = CASE WHEN item_id = $1 THEN $3 ELSE parent_id END,
+		modified = $4, version = txid_current()
+	WHERE item_id IN (SELECT item_id FROM subtree) RETURNING ` + Columns + `;`
+
+	// insertRevision is a query that records the fields of an item able to be
+	// updated at a version, given in order for item_id, version, parent_id, name,
+	// quantity, due_at, remind_at, recurrence, completed_at, notes, unit, price and
+	// currency.
+	insertRevision = `INSERT INTO item_revision (item_id, version, parent_id, name, quantity, due_at, remind_at, recurrence, completed_at, notes, unit, price, currency)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
+		ON CONFLICT (item_id, version) DO UPDATE SET parent_id = EXCLUDED.parent_id, name = EXCLUDED.name,
+		quantity = EXCLUDED.quantity, due_at = EXCLUDED.due_at, remind_at = EXCLUDED.remind_at,
+		recurrence = EXCLUDED.recurrence, completed_at = EXCLUDED.completed_at, notes = EXCLUDED.notes,
+		unit = EXCLUDED.unit, price = EXCLUDED.price, currency = EXCLUDED.currency;`
+
+	// selectRevision is a query that selects the fields of an item able to be
+	// updated at a version given an item_id and version.
+	selectRevision = `SELECT item_id, version, parent_id, name, notes, quantity, unit, price, currency, due_at, remind_at, recurrence,
+		completed_at FROM item_revision WHERE item_id = $1 AND version = $2;`
+
+	// selectAllForUpdate is a query that selects and locks all rows in the item
+	// table filtered by list_id, oldest first.
+	selectAllForUpdate = "SELECT " + Columns + " FROM item WHERE list_id = $1 ORDER BY item_id FOR UPDATE;"
+
+	// mergeTags is a query that tags the item given by $1 with every tag of the
+	// items given by $2 it doesn't already carry.
+	mergeTags = `INSERT INTO item_tag (item_id, tag_id) SELECT DISTINCT $1::int, tag_id FROM item_tag
+		WHERE item_id = ANY($2) ON CONFLICT DO NOTHING;`
+
+	// reparentChildren is a query that moves the sub-items of the items given by
+	// $2 under the item given by $1, setting the modified time to $3 and the
+	// version to the id of the current transaction. The moved rows are returned.
+	reparentChildren = `UPDATE item SET parent_id = $1, modified = $3, version = txid_current()
+		WHERE parent_id = ANY($2) RETURNING ` + Columns + `;`
+
+	// reassignAttachments is a query that moves the attachments of the items given
+	// by $2 to the item given by $1.
+	reassignAttachments = "UPDATE attachment SET item_id = $1 WHERE item_id = ANY($2);"
+
+	// reassignComments is a query that moves the comments on the items given by $2
+	// to the item given by $1.
+	reassignComments = "UPDATE item_comment SET item_id = $1 WHERE item_id = ANY($2);"
+
+	// summarizeCounts is a query that counts the rows in the item table on the list
+	// given by $1, along with those completed and those without a price.
+	summarizeCounts = "SELECT count(*), count(completed_at), count(*) - count(price) FROM item WHERE list_id = $1;"
+
+	// summarizeQuantities is a query that sums the quantities of the rows in the
+	// item table on the list given by $1 per unit.
+	summarizeQuantities = "SELECT unit, sum(quantity) AS quantity FROM item WHERE list_id = $1 GROUP BY unit ORDER BY unit;"
+
+	// summarizeTotals is a query that sums the costs of the priced rows in the item
+	// table on the list given by $1 per currency. The cost of an item is its price
+	// times its quantity, rounded half away from zero to a whole minor unit.
+	summarizeTotals = `SELECT currency, sum(round(price * quantity))::bigint AS amount FROM item
+		WHERE list_id = $1 AND price IS NOT NULL GROUP BY currency ORDER BY currency;`
+
+	// copyTags is a query that tags the item given by $1 with every tag of the
+	// item given by $2.
+	copyTags = "INSERT INTO item_tag (item_id, tag_id) SELECT $1, tag_id FROM item_tag WHERE item_id = $2;"
+
+	// upsertTag is a query that inserts a row into the tag table given a name,
+	// returning the tag_id of the new or already existing tag.
+	upsertTag = "INSERT INTO tag (name) VALUES ($1) ON CONFLICT (name) DO UPDATE SET name = EXCLUDED.name RETURNING tag_id;"
+
+	// insertItemTag is a query that tags an item given an item_id and tag_id.
+	insertItemTag = "INSERT INTO item_tag (item_id, tag_id) VALUES ($1, $2) ON CONFLICT DO NOTHING;"
+
+	// delItemTag is a query that removes a tag from an item given an item_id and
+	// tag name.
+	delItemTag = "DELETE FROM item_tag WHERE item_id = $1 AND tag_id = (SELECT tag_id FROM tag WHERE name = $2);"
+
+	// touch is a query that sets the modified time of a row in the item table
+	// based off of item_id and list_id, setting the version to the id of the
+	// current transaction.
+	touch = "UPDATE item SET modified = $1, version = txid_current() WHERE item_id = $2 AND list_id = $3;"
 
 	// del is a query that deletes a row in the item table given an item_id.
 	del = "DELETE FROM item WHERE item_id = $1"
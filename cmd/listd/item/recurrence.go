@@ -0,0 +1,95 @@
+package item
+
+import (
+	"time"
+
+	"github.com/george-e-shaw-iv/integration-tests-example/internal/platform/rrule"
+	"github.com/jmoiron/sqlx"
+	"github.com/pkg/errors"
+)
+
+// CompleteItem marks the item given by itemID and listID as completed. If the item
+// recurs, its next occurrence is created and returned along with it. Completing an
+// item that is already completed changes nothing.
+func CompleteItem(tx *sqlx.Tx, itemID, listID int) (Item, *Item, error) {
+	i, err := lockItem(tx, itemID, listID)
+	if err != nil {
+		return Item{}, nil, err
+	}
+
+	if i.CompletedAt != nil {
+		return i, nil, nil
+	}
+
+	now := time.Now()
+	i.CompletedAt = &now
+
+	return updateItem(tx, i, 0)
+}
+
+// normalizeRecurrence rewrites the recurrence rule of an item in its canonical
+// form.
+func normalizeRecurrence(i *Item) error {
+	if i.Recurrence == "" {
+		return nil
+	}
+
+	rule, err := rrule.Parse(i.Recurrence)
+	if err != nil {
+		return errors.Wrap(err, "parse item recurrence")
+	}
+
+	i.Recurrence = rule.String()
+	return nil
+}
+
+// createNextOccurrence creates the occurrence of a completed recurring item that
+// follows it, carrying over its name, quantity, unit, recurrence and tags. The next due
+// date follows the due date of the completed item, or the time it was completed
+// if it had none, skipping any occurrences that had already passed by then. A
+// reminder is kept the same time ahead of the due date. Nil is returned once the
+// recurrence has ended.
+func createNextOccurrence(tx *sqlx.Tx, completed Item) (*Item, error) {
+	rule, err := rrule.Parse(completed.Recurrence)
+	if err != nil {
+		return nil, errors.Wrap(err, "parse item recurrence")
+	}
+
+	anchor := *completed.CompletedAt
+	if completed.DueAt != nil {
+		anchor = *completed.DueAt
+	}
+
+	due, ok := rule.NextAfter(anchor, *completed.CompletedAt)
+	if !ok {
+		return nil, nil
+	}
+
+	next := Item{
+		ListID:     completed.ListID,
+		Name:       completed.Name,
+		Notes:      completed.Notes,
+		Quantity:   completed.Quantity,
+		Unit:       completed.Unit,
+		Price:      completed.Price,
+		Currency:   completed.Currency,
+		DueAt:      &due,
+		Recurrence: completed.Recurrence,
+		ParentID:   completed.ParentID,
+	}
+
+	if completed.RemindAt != nil {
+		remind := due
+		if completed.DueAt != nil {
+			remind = due.Add(completed.RemindAt.Sub(*completed.DueAt))
+		}
+		next.RemindAt = &remind
+	}
+
+	i, err := createItem(tx, next, completed.ID)
+	if err != nil {
+		return nil, errors.Wrap(err, "create next occurrence of item")
+	}
+
+	return &i, nil
+}
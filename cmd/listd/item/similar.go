@@ -0,0 +1,180 @@
+package item
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/george-e-shaw-iv/integration-tests-example/cmd/listd/list"
+	"github.com/george-e-shaw-iv/integration-tests-example/internal/platform/db"
+	"github.com/jmoiron/sqlx"
+	"github.com/pkg/errors"
+)
+
+// DuplicateThreshold is the minimum trigram similarity between two item names
+// for them to be considered the same item, e.g. "choclate milk" and "Chocolate
+// Milk". Names that merely share a word, such as "Milk" and "Chocolate Milk",
+// fall below it.
+const DuplicateThreshold = 0.6
+
+// Match is an item along with how similar its name is to the name it was looked
+// up by, from 0 for no trigrams in common to 1 for the same name.
+type Match struct {
+	Item
+	Similarity float64 `json:"similarity" db:"similarity"`
+}
+
+// SimilarItems selects up to limit rows from the item table on a list whose name
+// is at least threshold similar to name as a whole, most similar first. Case is
+// ignored.
+func SimilarItems(dbc db.Executor, listID int, name string, threshold float64, limit int) ([]Match, error) {
+	matches := make([]Match, 0)
+
+	if err := dbc.Select(&matches, selectSimilar, listID, name, threshold, limit); err != nil {
+		return nil, errors.Wrap(err, "select similar rows from item table given a list_id")
+	}
+
+	return matches, nil
+}
+
+// SuggestItems selects up to limit rows from the item table on a list with a name
+// containing a word similar to the, possibly partial or misspelled, text typed so
+// far, most similar first. Case is ignored.
+func SuggestItems(dbc db.Executor, listID int, text string, limit int) ([]Match, error) {
+	if _, err := list.SelectList(dbc, listID); errors.Cause(err) == sql.ErrNoRows {
+		return nil, sql.ErrNoRows
+	}
+
+	matches := make([]Match, 0)
+
+	if err := dbc.Select(&matches, selectSuggestions, listID, text, limit); err != nil {
+		return nil, errors.Wrap(err, "select suggested rows from item table given a list_id")
+	}
+
+	return matches, nil
+}
+
+// AddItem inserts r into the item table unless the list already has an open item
+// under the same parent with a name at least DuplicateThreshold similar, in which
+// case r is merged into the most similar one instead: its quantity is added,
+// converted to the unit of that item, and its tags are added. Its due date,
+// reminder, recurrence, notes and price are only taken over where that item has
+// none, and r is inserted as an item of its own when they differ. Completed items
+// are always inserted. It reports whether a new row was inserted.
+func AddItem(tx *sqlx.Tx, r Item) (Item, bool, error) {
+	if r.CompletedAt != nil {
+		i, err := CreateItem(tx, r)
+		return i, err == nil, err
+	}
+
+	// Lock the list so concurrent additions of the same item can't both miss each
+	// other and insert a duplicate.
+	if _, err := tx.Exec(lockList, r.ListID); err != nil {
+		return Item{}, false, errors.Wrap(err, "lock list row")
+	}
+
+	matches := make([]Match, 0)
+	if err := tx.Select(&matches, selectDuplicates, r.ListID, r.Name, DuplicateThreshold, 1, r.ParentID); err != nil {
+		return Item{}, false, errors.Wrap(err, "select duplicate rows from item table given a list_id")
+	}
+
+	if len(matches) == 0 {
+		i, err := CreateItem(tx, r)
+		return i, err == nil, err
+	}
+
+	merged, ok, err := mergeDuplicate(matches[0].Item, r)
+	if err != nil {
+		return Item{}, false, err
+	}
+
+	if !ok {
+		i, err := CreateItem(tx, r)
+		return i, err == nil, err
+	}
+
+	i, err := UpdateItem(tx, merged, 0)
+	if err != nil {
+		return Item{}, false, err
+	}
+
+	for _, tag := range r.Tags {
+		if i, err = AddTag(tx, i.ID, i.ListID, tag); err != nil {
+			return Item{}, false, err
+		}
+	}
+
+	return i, false, nil
+}
+
+// mergeDuplicate returns existing with the quantity of r added to it, along with
+// the fields r sets that existing leaves unset. It reports false when they can't
+// be merged without dropping part of r: when the quantity of r can't be converted
+// to the unit of existing, such as a weight added to a count, or when both set a
+// due date, reminder, recurrence, notes or price and they differ.
+func mergeDuplicate(existing, r Item) (Item, bool, error) {
+	if err := normalizeRecurrence(&r); err != nil {
+		return Item{}, false, err
+	}
+
+	if err := normalizeUnit(&r); err != nil {
+		return Item{}, false, err
+	}
+
+	if err := normalizePrice(&r); err != nil {
+		return Item{}, false, err
+	}
+
+	sum, ok, err := addQuantity(existing, r)
+	if err != nil || !ok {
+		return Item{}, false, err
+	}
+
+	merged := existing
+	merged.Quantity = sum
+
+	if !mergeTime(&merged.DueAt, r.DueAt) || !mergeTime(&merged.RemindAt, r.RemindAt) {
+		return Item{}, false, nil
+	}
+
+	if !mergeString(&merged.Recurrence, r.Recurrence) || !mergeString(&merged.Notes, r.Notes) {
+		return Item{}, false, nil
+	}
+
+	// Prices are given per unit, so a price can only be taken over by an item of
+	// the same unit.
+	if r.Price != nil {
+		if r.Unit != existing.Unit {
+			return Item{}, false, nil
+		}
+
+		if merged.Price == nil {
+			merged.Price, merged.Currency = r.Price, r.Currency
+		} else if !samePrice(merged.Price, r.Price) || merged.Currency != r.Currency {
+			return Item{}, false, nil
+		}
+	}
+
+	return merged, true, nil
+}
+
+// mergeTime sets dst to src if dst is unset, reporting false if both are set to
+// different instants.
+func mergeTime(dst **time.Time, src *time.Time) bool {
+	if *dst == nil {
+		*dst = src
+		return true
+	}
+
+	return src == nil || sameTime(*dst, src)
+}
+
+// mergeString sets dst to src if dst is empty, reporting false if both are set to
+// different strings.
+func mergeString(dst *string, src string) bool {
+	if *dst == "" {
+		*dst = src
+		return true
+	}
+
+	return src == "" || *dst == src
+}
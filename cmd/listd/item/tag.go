@@ -0,0 +1,205 @@
+package item
+
+import (
+	"database/sql"
+	"encoding/json"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/george-e-shaw-iv/integration-tests-example/cmd/listd/list"
+	"github.com/george-e-shaw-iv/integration-tests-example/internal/platform/db"
+	"github.com/george-e-shaw-iv/integration-tests-example/internal/platform/outbox"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/pkg/errors"
+)
+
+// maxTagLength is the maximum length of a tag name, in characters.
+const maxTagLength = 64
+
+// ErrInvalidTag is returned when a tag name is empty or too long.
+var ErrInvalidTag = errors.Errorf("tag must be between 1 and %d characters", maxTagLength)
+
+// Tags are the names of the tags on an item, in alphabetical order. No tags are
+// represented by a nil slice, which is encoded as an empty JSON array.
+type Tags []string
+
+// Scan implements the sql.Scanner interface for the Tags type.
+func (t *Tags) Scan(src interface{}) error {
+	var a pq.StringArray
+	if err := a.Scan(src); err != nil {
+		return err
+	}
+
+	*t = nil
+	if len(a) > 0 {
+		*t = Tags(a)
+	}
+
+	return nil
+}
+
+// MarshalJSON implements the json.Marshaler interface for the Tags type.
+func (t Tags) MarshalJSON() ([]byte, error) {
+	if t == nil {
+		return []byte("[]"), nil
+	}
+
+	return json.Marshal([]string(t))
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface for the Tags type.
+func (t *Tags) UnmarshalJSON(data []byte) error {
+	var a []string
+	if err := json.Unmarshal(data, &a); err != nil {
+		return err
+	}
+
+	*t = nil
+	if len(a) > 0 {
+		*t = Tags(a)
+	}
+
+	return nil
+}
+
+// NormalizeTag returns the canonical form of a tag name, trimmed and lower cased,
+// so that "Dairy" and "dairy " are the same tag.
+func NormalizeTag(name string) (string, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if name == "" || len([]rune(name)) > maxTagLength {
+		return "", ErrInvalidTag
+	}
+
+	return name, nil
+}
+
+// SelectItemsByTags selects the rows from the item table on a list tagged with
+// the given tags. When all is true an item must carry every tag, otherwise any
+// one of them is enough.
+func SelectItemsByTags(dbc db.Executor, listID int, tags []string, all bool) ([]Item, error) {
+	if _, err := list.SelectList(dbc, listID); errors.Cause(err) == sql.ErrNoRows {
+		return nil, sql.ErrNoRows
+	}
+
+	names := make(map[string]bool)
+	for _, tag := range tags {
+		name, err := NormalizeTag(tag)
+		if err != nil {
+			return nil, err
+		}
+
+		names[name] = true
+	}
+
+	unique := make(pq.StringArray, 0, len(names))
+	for name := range names {
+		unique = append(unique, name)
+	}
+
+	matches := 1
+	if all {
+		matches = len(unique)
+	}
+
+	items := make([]Item, 0)
+
+	if err := dbc.Select(&items, selectByTags, listID, unique, matches); err != nil {
+		return nil, errors.Wrap(err, "select rows from item table given a list_id and tags")
+	}
+
+	return items, nil
+}
+
+// AddTag tags the item given by itemID and listID, creating the tag if it does not
+// exist yet. Adding a tag the item already carries changes nothing. The item is
+// returned with its tags.
+func AddTag(tx *sqlx.Tx, itemID, listID int, tag string) (Item, error) {
+	name, err := NormalizeTag(tag)
+	if err != nil {
+		return Item{}, err
+	}
+
+	if _, err := lockItem(tx, itemID, listID); err != nil {
+		return Item{}, err
+	}
+
+	var tagID int
+	if err := tx.QueryRow(upsertTag, name).Scan(&tagID); err != nil {
+		return Item{}, errors.Wrap(err, "upsert tag row")
+	}
+
+	res, err := tx.Exec(insertItemTag, itemID, tagID)
+	if err != nil {
+		return Item{}, errors.Wrap(err, "insert item_tag row")
+	}
+
+	return touchIfAffected(tx, res, itemID, listID)
+}
+
+// RemoveTag removes a tag from the item given by itemID and listID. Removing a tag
+// the item does not carry changes nothing. The item is returned with its tags.
+func RemoveTag(tx *sqlx.Tx, itemID, listID int, tag string) (Item, error) {
+	name, err := NormalizeTag(tag)
+	if err != nil {
+		return Item{}, err
+	}
+
+	if _, err := lockItem(tx, itemID, listID); err != nil {
+		return Item{}, err
+	}
+
+	res, err := tx.Exec(delItemTag, itemID, name)
+	if err != nil {
+		return Item{}, errors.Wrap(err, "delete item_tag row")
+	}
+
+	return touchIfAffected(tx, res, itemID, listID)
+}
+
+// lockItem selects and locks a row in the item table.
+func lockItem(tx *sqlx.Tx, itemID, listID int) (Item, error) {
+	var i Item
+	if err := tx.QueryRowx(selectForUpdate, itemID, listID).StructScan(&i); err != nil {
+		if errors.Cause(err) == sql.ErrNoRows {
+			return Item{}, sql.ErrNoRows
+		}
+
+		return Item{}, errors.Wrap(err, "lock item row")
+	}
+
+	return i, nil
+}
+
+// touchIfAffected stamps an item with a new version when res changed its tags, so
+// the change is picked up by syncing clients and webhooks, and returns the item.
+func touchIfAffected(tx *sqlx.Tx, res sql.Result, itemID, listID int) (Item, error) {
+	n, err := res.RowsAffected()
+	if err != nil {
+		return Item{}, errors.Wrap(err, "get affected item_tag rows")
+	}
+
+	if n == 0 {
+		return SelectItem(tx, itemID, listID)
+	}
+
+	if _, err := tx.Exec(touch, time.Now(), itemID, listID); err != nil {
+		return Item{}, errors.Wrap(err, "touch item row")
+	}
+
+	i, err := SelectItem(tx, itemID, listID)
+	if err != nil {
+		return Item{}, err
+	}
+
+	if err := writeRevision(tx, i); err != nil {
+		return Item{}, err
+	}
+
+	if err := outbox.Write(tx, EventUpdated, strconv.Itoa(listID), i); err != nil {
+		return Item{}, errors.Wrap(err, "write item updated event")
+	}
+
+	return i, nil
+}
@@ -0,0 +1,184 @@
+package item
+
+import (
+	"database/sql"
+	"strconv"
+	"time"
+
+	"github.com/george-e-shaw-iv/integration-tests-example/cmd/listd/list"
+	"github.com/george-e-shaw-iv/integration-tests-example/internal/platform/db"
+	"github.com/george-e-shaw-iv/integration-tests-example/internal/platform/outbox"
+	"github.com/jmoiron/sqlx"
+	"github.com/pkg/errors"
+)
+
+// MaxDepth is the maximum number of levels of an item tree, counting top level
+// items as the first level.
+const MaxDepth = 4
+
+// TreeError is returned when a change would leave the items of a list in a shape
+// that is not a tree of at most MaxDepth levels.
+type TreeError string
+
+// Error implements the error interface.
+func (t TreeError) Error() string {
+	return string(t)
+}
+
+// Errors describing why a parent can't be given to an item.
+const (
+	ErrParentNotFound TreeError = "parent must be an item on the same list"
+	ErrCycle          TreeError = "an item can't be moved under itself or one of its sub-items"
+	ErrTooDeep        TreeError = "items can't be nested more than 4 levels deep"
+)
+
+// SelectTree selects all rows from the item table given a list_id, nesting every
+// sub-item in the Children of its parent. The top level items are returned.
+func SelectTree(dbc db.Executor, listID int) ([]Item, error) {
+	items, err := SelectItems(dbc, listID)
+	if err != nil {
+		return nil, err
+	}
+
+	return buildTree(items), nil
+}
+
+// buildTree nests items in the Children of their parents, in the order given.
+func buildTree(items []Item) []Item {
+	children := make(map[int][]int)
+	var roots []int
+
+	for i, it := range items {
+		if it.ParentID == nil {
+			roots = append(roots, i)
+			continue
+		}
+
+		children[*it.ParentID] = append(children[*it.ParentID], i)
+	}
+
+	var nest func(i int) Item
+	nest = func(i int) Item {
+		it := items[i]
+		for _, c := range children[it.ID] {
+			it.Children = append(it.Children, nest(c))
+		}
+
+		return it
+	}
+
+	tree := make([]Item, 0, len(roots))
+	for _, i := range roots {
+		tree = append(tree, nest(i))
+	}
+
+	return tree
+}
+
+// checkParent verifies that the item given by itemID, which is 0 for an item not
+// created yet, can be placed under parentID on a list without forming a cycle or
+// exceeding MaxDepth. The list is locked so that concurrent changes can't do so
+// either.
+func checkParent(tx *sqlx.Tx, listID, itemID int, parentID *int) error {
+	if parentID == nil {
+		return nil
+	}
+
+	if _, err := tx.Exec(lockList, listID); err != nil {
+		return errors.Wrap(err, "lock list row")
+	}
+
+	var ancestors []struct {
+		ItemID int `db:"item_id"`
+		ListID int `db:"list_id"`
+	}
+	if err := tx.Select(&ancestors, selectAncestors, *parentID, MaxDepth); err != nil {
+		return errors.Wrap(err, "select ancestors of parent item")
+	}
+
+	if len(ancestors) == 0 || ancestors[0].ListID != listID {
+		return ErrParentNotFound
+	}
+
+	for _, a := range ancestors {
+		if a.ItemID == itemID {
+			return ErrCycle
+		}
+	}
+
+	height := 1
+	if itemID != 0 {
+		if err := tx.QueryRow(selectHeight, itemID).Scan(&height); err != nil {
+			return errors.Wrap(err, "select height of item subtree")
+		}
+	}
+
+	if len(ancestors)+height > MaxDepth {
+		return ErrTooDeep
+	}
+
+	return nil
+}
+
+// MoveItem moves the item given by itemID and listID, along with all of its
+// sub-items, under parentID on the list given by toListID. A nil parentID makes
+// it a top level item. Moving to another list is seen by webhooks as the items
+// being deleted from one list and created on the other. The moved item is
+// returned.
+func MoveItem(tx *sqlx.Tx, itemID, listID, toListID int, parentID *int) (Item, error) {
+	// Lock both lists in a consistent order so that opposing moves can't deadlock.
+	first, second := listID, toListID
+	if first > second {
+		first, second = second, first
+	}
+
+	for _, id := range []int{first, second} {
+		if _, err := tx.Exec(lockList, id); err != nil {
+			return Item{}, errors.Wrap(err, "lock list row")
+		}
+	}
+
+	if _, err := lockItem(tx, itemID, listID); err != nil {
+		return Item{}, err
+	}
+
+	if _, err := list.SelectList(tx, toListID); errors.Cause(err) == sql.ErrNoRows {
+		return Item{}, sql.ErrNoRows
+	}
+
+	if err := checkParent(tx, toListID, itemID, parentID); err != nil {
+		return Item{}, err
+	}
+
+	var moved []Item
+	if err := tx.Select(&moved, moveSubtree, itemID, toListID, parentID, time.Now()); err != nil {
+		return Item{}, errors.Wrap(err, "move item subtree")
+	}
+
+	var root Item
+	for _, i := range moved {
+		if err := writeRevision(tx, i); err != nil {
+			return Item{}, err
+		}
+
+		if toListID == listID {
+			if err := outbox.Write(tx, EventUpdated, strconv.Itoa(toListID), i); err != nil {
+				return Item{}, errors.Wrap(err, "write item updated event")
+			}
+		} else {
+			if err := outbox.Write(tx, EventDeleted, strconv.Itoa(listID), Item{ID: i.ID, ListID: listID}); err != nil {
+				return Item{}, errors.Wrap(err, "write item deleted event")
+			}
+
+			if err := outbox.Write(tx, EventCreated, strconv.Itoa(toListID), i); err != nil {
+				return Item{}, errors.Wrap(err, "write item created event")
+			}
+		}
+
+		if i.ID == itemID {
+			root = i
+		}
+	}
+
+	return root, nil
+}
@@ -0,0 +1,32 @@
+package item
+
+import (
+	"github.com/george-e-shaw-iv/integration-tests-example/internal/platform/units"
+	"github.com/pkg/errors"
+)
+
+// normalizeUnit rewrites the unit of an item as the symbol of the unit.
+func normalizeUnit(i *Item) error {
+	unit, err := units.Canonical(i.Unit)
+	if err != nil {
+		return errors.Wrap(err, "normalize item unit")
+	}
+
+	i.Unit = unit
+	return nil
+}
+
+// addQuantity returns the quantity of dst increased by the quantity of src,
+// converted to the unit of dst. ok is false when the units of the two measure
+// different dimensions, such as a count and a mass.
+func addQuantity(dst, src Item) (sum units.Amount, ok bool, err error) {
+	c, err := units.Convert(src.Quantity, src.Unit, dst.Unit)
+	if errors.Cause(err) == units.ErrIncompatible {
+		return units.Amount{}, false, nil
+	}
+	if err != nil {
+		return units.Amount{}, false, errors.Wrap(err, "convert item quantity")
+	}
+
+	return dst.Quantity.Add(c), true, nil
+}
@@ -2,24 +2,48 @@ package list
 
 import (
 	"database/sql"
+	"strconv"
 	"time"
 
+	"github.com/george-e-shaw-iv/integration-tests-example/internal/platform/db"
+	"github.com/george-e-shaw-iv/integration-tests-example/internal/platform/outbox"
 	"github.com/jmoiron/sqlx"
 	"github.com/pkg/errors"
 	"github.com/sirupsen/logrus"
 )
 
+// Events written to the outbox by the mutations in this package. The key of each
+// event is the list_id.
+const (
+	EventCreated = "list.created"
+	EventUpdated = "list.updated"
+	EventDeleted = "list.deleted"
+)
+
 // List is a type that contains the proper struct tags for both
 // a JSON and Postgres representation of a list.
 type List struct {
-	ID       int       `json:"id" db:"list_id"`
-	Name     string    `json:"name" db:"name"`
+	ID       int    `json:"id" db:"list_id"`
+	Name     string `json:"name" db:"name"`
+	FolderID *int   `json:"folderID,omitempty" db:"folder_id"`
+
+	// Notes are free-form Markdown. NotesHTML is only filled in by handlers asked
+	// to render the notes.
+	Notes     string `json:"notes,omitempty" db:"notes"`
+	NotesHTML string `json:"notesHTML,omitempty" db:"-"`
+
+	// Budget is what the items on the list are meant to cost in total, in the minor
+	// unit of BudgetCurrency. Lists without a budget leave out both.
+	Budget         *int64 `json:"budget,omitempty" db:"budget"`
+	BudgetCurrency string `json:"budgetCurrency,omitempty" db:"budget_currency"`
+
 	Created  time.Time `json:"created" db:"created"`
 	Modified time.Time `json:"modified" db:"modified"`
+	Version  int64     `json:"version" db:"version"`
 }
 
 // SelectLists selects all rows from the list table.
-func SelectLists(dbc *sqlx.DB) ([]List, error) {
+func SelectLists(dbc db.Executor) ([]List, error) {
 	lists := make([]List, 0)
 
 	if err := dbc.Select(&lists, selectAll); err != nil {
@@ -30,7 +54,7 @@ func SelectLists(dbc *sqlx.DB) ([]List, error) {
 }
 
 // SelectList selects a single row from the list table based off of a given list_id.
-func SelectList(dbc *sqlx.DB, id int) (List, error) {
+func SelectList(dbc db.Executor, id int) (List, error) {
 	var list List
 	stmt := selectByID
 
@@ -54,12 +78,14 @@ func SelectList(dbc *sqlx.DB, id int) (List, error) {
 	return list, nil
 }
 
-// CreateList inserts a new row into the list table.
-func CreateList(dbc *sqlx.DB, r List) (List, error) {
+// CreateList inserts a new row into the list table. The folder of the list, if any,
+// is expected to have been checked to exist by the caller.
+func CreateList(tx *sqlx.Tx, r List) (List, error) {
 	r.Created = time.Now()
 	r.Modified = time.Now()
+	r.NotesHTML = ""
 
-	stmt, err := dbc.Prepare(insert)
+	stmt, err := tx.Prepare(insert)
 	if err != nil {
 		return List{}, errors.Wrap(err, "insert new list row")
 	}
@@ -70,44 +96,65 @@ func CreateList(dbc *sqlx.DB, r List) (List, error) {
 		}
 	}()
 
-	row := stmt.QueryRow(r.Name, r.Created, r.Modified)
+	row := stmt.QueryRow(r.Name, r.FolderID, r.Created, r.Modified, r.Notes, r.Budget, r.BudgetCurrency)
 
-	if err = row.Scan(&r.ID); err != nil {
+	if err = row.Scan(&r.ID, &r.Version); err != nil {
 		return List{}, errors.Wrap(err, "get inserted row id")
 	}
 
+	if err := outbox.Write(tx, EventCreated, strconv.Itoa(r.ID), r); err != nil {
+		return List{}, errors.Wrap(err, "write list created event")
+	}
+
 	return r, nil
 }
 
-// UpdateList updates a row in the list table based off of a list_id. The only field
-// able to be updated is the name field.
-func UpdateList(dbc *sqlx.DB, r List) error {
-	if _, err := SelectList(dbc, r.ID); errors.Cause(err) == sql.ErrNoRows {
+// UpdateList updates a row in the list table based off of a list_id. The only fields
+// able to be updated are the name, notes and budget fields, lists are moved between folders with
+// folder.MoveList.
+func UpdateList(tx *sqlx.Tx, r List) error {
+	if _, err := SelectList(tx, r.ID); errors.Cause(err) == sql.ErrNoRows {
 		return sql.ErrNoRows
 	}
 
 	r.Modified = time.Now()
 
-	if _, err := dbc.Exec(update, r.Name, r.Modified, r.ID); err != nil {
+	if err := tx.QueryRow(update, r.Name, r.Modified, r.ID, r.Notes, r.Budget, r.BudgetCurrency).Scan(&r.Version); err != nil {
 		return errors.Wrap(err, "update list row")
 	}
 
+	if err := outbox.Write(tx, EventUpdated, strconv.Itoa(r.ID), r); err != nil {
+		return errors.Wrap(err, "write list updated event")
+	}
+
 	return nil
 }
 
 // DeleteList deletes a row in the list table based off of list_id.
-func DeleteList(dbc *sqlx.DB, id int) error {
-	if _, err := SelectList(dbc, id); errors.Cause(err) == sql.ErrNoRows {
+func DeleteList(tx *sqlx.Tx, id int) error {
+	if _, err := SelectList(tx, id); errors.Cause(err) == sql.ErrNoRows {
 		return sql.ErrNoRows
 	}
 
-	if _, err := dbc.Exec(delRelatedItems, id); err != nil && errors.Cause(err) != sql.ErrNoRows {
+	if _, err := tx.Exec(tombstoneRelatedItems, id); err != nil {
+		return errors.Wrap(err, "record deletion of related items to given list_id")
+	}
+
+	if _, err := tx.Exec(tombstone, id); err != nil {
+		return errors.Wrap(err, "record deletion of list row")
+	}
+
+	if _, err := tx.Exec(delRelatedItems, id); err != nil && errors.Cause(err) != sql.ErrNoRows {
 		return errors.Wrap(err, "deleted related items to given list_id")
 	}
 
-	if _, err := dbc.Exec(del, id); err != nil {
+	if _, err := tx.Exec(del, id); err != nil {
 		return errors.Wrap(err, "delete list row")
 	}
 
+	if err := outbox.Write(tx, EventDeleted, strconv.Itoa(id), List{ID: id}); err != nil {
+		return errors.Wrap(err, "write list deleted event")
+	}
+
 	return nil
 }
@@ -3,20 +3,36 @@ package list
 // PostgreSQL queries for the list table and tables related to the list table through
 // foreign keys, all used in the list package.
 const (
+	// Columns are the columns of the list table that map onto the List type, for
+	// use in place of * in queries scanning rows into a List.
+	Columns = "list_id, name, notes, budget, budget_currency, folder_id, created, modified, version"
+
 	// selectAll is a query that selects all rows from the list table.
-	selectAll = "SELECT * FROM list;"
+	selectAll = "SELECT " + Columns + " FROM list;"
 
 	// selectByID is a query that selects a row from the list table based off of
 	// the given list_id.
-	selectByID = "SELECT * FROM list WHERE list_id = $1;"
+	selectByID = "SELECT " + Columns + " FROM list WHERE list_id = $1;"
 
 	// insert is a query that inserts a new row in the list table using the values
-	// given in order for name, created, and modified.
-	insert = "INSERT INTO list (name, created, modified) VALUES ($1, $2, $3) RETURNING list_id;"
+	// given in order for name, folder_id, created, modified, notes, budget and
+	// budget_currency.
+	insert = `INSERT INTO list (name, folder_id, created, modified, notes, budget, budget_currency)
+		VALUES ($1, $2, $3, $4, $5, $6, $7) RETURNING list_id, version;`
 
 	// update is a query that updates a row in the list table based off of list_id.
-	// The values able to be updated are name and modified.
-	update = "UPDATE list SET name = $1, modified = $2 WHERE list_id = $3;"
+	// The values able to be updated are name, modified, notes, budget and
+	// budget_currency, the version is set to the id of the current transaction.
+	update = `UPDATE list SET name = $1, modified = $2, notes = $4, budget = $5, budget_currency = $6,
+		version = txid_current() WHERE list_id = $3 RETURNING version;`
+
+	// tombstoneRelatedItems is a query that records the deletion of the rows in the
+	// item table that are related to a list by a given list_id.
+	tombstoneRelatedItems = "INSERT INTO tombstone (kind, id, list_id) SELECT 'item', item_id, list_id FROM item WHERE list_id = $1;"
+
+	// tombstone is a query that records the deletion of a row in the list table
+	// given a list_id.
+	tombstone = "INSERT INTO tombstone (kind, id, list_id) VALUES ('list', $1, $1);"
 
 	// delRelatedItems deletes rows in the item table that are related to a list by
 	// a given list_id.
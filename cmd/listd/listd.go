@@ -3,14 +3,21 @@ package main
 import (
 	"context"
 	"fmt"
+	"net"
 	"net/http"
+	"net/smtp"
 	"os"
 	"os/signal"
 	"syscall"
 	"time"
 
+	"github.com/george-e-shaw-iv/integration-tests-example/cmd/listd/attachment"
 	"github.com/george-e-shaw-iv/integration-tests-example/cmd/listd/handlers"
+	"github.com/george-e-shaw-iv/integration-tests-example/cmd/listd/reminder"
+	"github.com/george-e-shaw-iv/integration-tests-example/cmd/listd/webhook"
+	"github.com/george-e-shaw-iv/integration-tests-example/internal/platform/blob"
 	"github.com/george-e-shaw-iv/integration-tests-example/internal/platform/db"
+	"github.com/george-e-shaw-iv/integration-tests-example/internal/platform/outbox"
 	"github.com/kelseyhightower/envconfig"
 	"github.com/pkg/errors"
 	log "github.com/sirupsen/logrus"
@@ -32,6 +39,7 @@ func main() {
 	// gathered from the environment.
 	var cfg struct {
 		DaemonPort int `envconfig:"DAEMON_PORT" default:"3000"`
+		GRPCPort   int `envconfig:"GRPC_PORT" default:"3001"`
 
 		DBUser string `envconfig:"DB_USER" default:"root"`
 		DBPass string `envconfig:"DB_PASS" default:"root"`
@@ -42,6 +50,37 @@ func main() {
 		ReadTimeout     time.Duration `envconfig:"READ_TIMEOUT" default:"5s"`
 		WriteTimeout    time.Duration `envconfig:"WRITE_TIMEOUT" default:"10s"`
 		ShutdownTimeout time.Duration `envconfig:"SHUTDOWN_TIMEOUT" default:"5s"`
+
+		OutboxInterval  time.Duration `envconfig:"OUTBOX_INTERVAL" default:"1s"`
+		OutboxRetention time.Duration `envconfig:"OUTBOX_RETENTION" default:"24h"`
+
+		WebhookInterval    time.Duration `envconfig:"WEBHOOK_INTERVAL" default:"5s"`
+		WebhookTimeout     time.Duration `envconfig:"WEBHOOK_TIMEOUT" default:"10s"`
+		WebhookBackoff     time.Duration `envconfig:"WEBHOOK_BACKOFF" default:"30s"`
+		WebhookMaxAttempts int           `envconfig:"WEBHOOK_MAX_ATTEMPTS" default:"8"`
+
+		ReminderInterval    time.Duration `envconfig:"REMINDER_INTERVAL" default:"30s"`
+		ReminderBackoff     time.Duration `envconfig:"REMINDER_BACKOFF" default:"1m"`
+		ReminderMaxAttempts int           `envconfig:"REMINDER_MAX_ATTEMPTS" default:"5"`
+
+		SMTPAddr string   `envconfig:"SMTP_ADDR"`
+		SMTPUser string   `envconfig:"SMTP_USER"`
+		SMTPPass string   `envconfig:"SMTP_PASS"`
+		SMTPFrom string   `envconfig:"SMTP_FROM"`
+		SMTPTo   []string `envconfig:"SMTP_TO"`
+
+		StatsTTL time.Duration `envconfig:"STATS_TTL" default:"1m"`
+
+		UserHeader string `envconfig:"USER_HEADER"`
+
+		BlobDir           string        `envconfig:"BLOB_DIR" default:"/var/lib/listd/blobs"`
+		BlobSweepInterval time.Duration `envconfig:"BLOB_SWEEP_INTERVAL" default:"1m"`
+
+		S3Endpoint  string `envconfig:"S3_ENDPOINT"`
+		S3Bucket    string `envconfig:"S3_BUCKET"`
+		S3Region    string `envconfig:"S3_REGION" default:"us-east-1"`
+		S3AccessKey string `envconfig:"S3_ACCESS_KEY"`
+		S3SecretKey string `envconfig:"S3_SECRET_KEY"`
 	}
 	if err := envconfig.Process("LIST", &cfg); err != nil {
 		err = errors.Wrap(err, "parse environment variables")
@@ -61,28 +100,124 @@ func main() {
 		return
 	}
 
-	defer func(){
+	defer func() {
 		if err := dbc.Close(); err != nil {
 			log.Printf("error closing database: %v", err)
 		}
 	}()
 
+	// Start the background workers, which are stopped once the daemon begins shutting
+	// down. The outbox relay publishes list and item events, queueing deliveries to
+	// webhooks which are then sent by the webhook dispatcher. The reminder scheduler
+	// fires the reminders set on items, and the sweeper removes the contents of deleted
+	// attachments from storage.
+	workerCtx, stopWorkers := context.WithCancel(context.Background())
+	defer stopWorkers()
+
+	relay := outbox.Relay{
+		DB:        dbc,
+		Publisher: outbox.Publishers{outbox.LogPublisher{}, webhook.Publisher{}},
+		BatchSize: 100,
+		Retention: cfg.OutboxRetention,
+	}
+	go relay.Run(workerCtx, cfg.OutboxInterval)
+
+	dispatcher := webhook.Dispatcher{
+		DB:          dbc,
+		Client:      &http.Client{Timeout: cfg.WebhookTimeout},
+		MaxAttempts: cfg.WebhookMaxAttempts,
+		Backoff:     cfg.WebhookBackoff,
+		BatchSize:   50,
+	}
+	go dispatcher.Run(workerCtx, cfg.WebhookInterval)
+
+	// Reminders are emailed when an SMTP server is configured and logged otherwise.
+	var notifier reminder.Notifier = reminder.LogNotifier{}
+	if cfg.SMTPAddr != "" {
+		n := reminder.SMTPNotifier{
+			Addr: cfg.SMTPAddr,
+			From: cfg.SMTPFrom,
+			To:   cfg.SMTPTo,
+		}
+
+		if cfg.SMTPUser != "" {
+			var host string
+			host, _, err = net.SplitHostPort(cfg.SMTPAddr)
+			if err != nil {
+				err = errors.Wrap(err, "parse smtp address")
+				return
+			}
+
+			n.Auth = smtp.PlainAuth("", cfg.SMTPUser, cfg.SMTPPass, host)
+		}
+
+		notifier = n
+	}
+
+	scheduler := reminder.Scheduler{
+		DB:          dbc,
+		Notifier:    notifier,
+		MaxAttempts: cfg.ReminderMaxAttempts,
+		Backoff:     cfg.ReminderBackoff,
+		BatchSize:   50,
+	}
+	go scheduler.Run(workerCtx, cfg.ReminderInterval)
+
+	// Attachments are kept in an object store when one is configured and on the
+	// local filesystem otherwise.
+	var blobs blob.Store = blob.FileStore{Dir: cfg.BlobDir}
+	if cfg.S3Endpoint != "" {
+		blobs = blob.S3Store{
+			Endpoint:  cfg.S3Endpoint,
+			Bucket:    cfg.S3Bucket,
+			Region:    cfg.S3Region,
+			AccessKey: cfg.S3AccessKey,
+			SecretKey: cfg.S3SecretKey,
+			Client:    &http.Client{Timeout: cfg.WriteTimeout},
+		}
+	}
+
+	sweeper := attachment.Sweeper{
+		DB:        dbc,
+		Store:     blobs,
+		BatchSize: 100,
+	}
+	go sweeper.Run(workerCtx, cfg.BlobSweepInterval)
+
+	app := handlers.NewApplication(dbc)
+	app.Blobs = blobs
+	app.Stats.TTL = cfg.StatsTTL
+	app.UserHeader = cfg.UserHeader
+
 	server := http.Server{
 		Addr:           fmt.Sprintf(":%d", cfg.DaemonPort),
-		Handler:        handlers.NewApplication(dbc),
+		Handler:        app,
 		ReadTimeout:    cfg.ReadTimeout,
 		WriteTimeout:   cfg.WriteTimeout,
 		MaxHeaderBytes: 1 << 20,
 	}
 
+	// The gRPC services are served on a port of their own, next to the REST API.
+	grpcListener, err := net.Listen("tcp", fmt.Sprintf(":%d", cfg.GRPCPort))
+	if err != nil {
+		err = errors.Wrap(err, "listen for grpc calls")
+		return
+	}
+	grpcServer := handlers.NewGRPCServer(app)
+
 	// Start listening for requests made to the daemon and create a channel
 	// to collect non-HTTP related server errors on.
-	serverErrors := make(chan error, 1)
+	serverErrors := make(chan error, 2)
 	go func() {
 		log.Printf("server started, listening on %s", server.Addr)
 		serverErrors <- server.ListenAndServe()
 	}()
 
+	go func() {
+		log.Printf("grpc server started, listening on %s", grpcListener.Addr())
+		serverErrors <- grpcServer.Serve(grpcListener)
+	}()
+
 	// Blocking main and waiting for shutdown of the daemon.
 	osSignals := make(chan os.Signal, 1)
 	signal.Notify(osSignals, os.Interrupt, syscall.SIGTERM)
@@ -96,10 +231,20 @@ func main() {
 	case <-osSignals:
 	}
 
+	stopWorkers()
+
 	// Gracefully shutdown server once an exit signal or error is received.
 	ctx, cancel := context.WithTimeout(context.Background(), cfg.ShutdownTimeout)
 	defer cancel()
 
+	// The gRPC server is stopped alongside the HTTP server, forcefully once the
+	// shutdown timeout runs out.
+	grpcStopped := make(chan struct{})
+	go func() {
+		grpcServer.GracefulStop()
+		close(grpcStopped)
+	}()
+
 	if err := server.Shutdown(ctx); err != nil {
 		log.Printf("shutdown : Graceful shutdown did not complete in %v : %v", cfg.ShutdownTimeout, err)
 
@@ -107,4 +252,11 @@ func main() {
 			log.Printf("shutdown : Error killing server : %v", err)
 		}
 	}
+
+	select {
+	case <-grpcStopped:
+	case <-ctx.Done():
+		log.Printf("shutdown : Graceful grpc shutdown did not complete in %v", cfg.ShutdownTimeout)
+		grpcServer.Stop()
+	}
 }
@@ -0,0 +1,1258 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// source: listd.proto
+
+package listdpb
+
+import (
+	context "context"
+	fmt "fmt"
+	proto "github.com/golang/protobuf/proto"
+	empty "github.com/golang/protobuf/ptypes/empty"
+	timestamp "github.com/golang/protobuf/ptypes/timestamp"
+	wrappers "github.com/golang/protobuf/ptypes/wrappers"
+	grpc "google.golang.org/grpc"
+	math "math"
+)
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ = proto.Marshal
+var _ = fmt.Errorf
+var _ = math.Inf
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the proto package it is being compiled against.
+// A compilation error at this line likely means your copy of the
+// proto package needs to be updated.
+const _ = proto.ProtoPackageIsVersion3 // please upgrade the proto package
+
+// List is a list.List.
+type List struct {
+	Id                   int64                `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	Name                 string               `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	FolderId             *wrappers.Int64Value `protobuf:"bytes,3,opt,name=folder_id,json=folderId,proto3" json:"folder_id,omitempty"`
+	Notes                string               `protobuf:"bytes,4,opt,name=notes,proto3" json:"notes,omitempty"`
+	Budget               *wrappers.Int64Value `protobuf:"bytes,5,opt,name=budget,proto3" json:"budget,omitempty"`
+	BudgetCurrency       string               `protobuf:"bytes,6,opt,name=budget_currency,json=budgetCurrency,proto3" json:"budget_currency,omitempty"`
+	Created              *timestamp.Timestamp `protobuf:"bytes,7,opt,name=created,proto3" json:"created,omitempty"`
+	Modified             *timestamp.Timestamp `protobuf:"bytes,8,opt,name=modified,proto3" json:"modified,omitempty"`
+	Version              int64                `protobuf:"varint,9,opt,name=version,proto3" json:"version,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}             `json:"-"`
+	XXX_unrecognized     []byte               `json:"-"`
+	XXX_sizecache        int32                `json:"-"`
+}
+
+func (m *List) Reset()         { *m = List{} }
+func (m *List) String() string { return proto.CompactTextString(m) }
+func (*List) ProtoMessage()    {}
+func (*List) Descriptor() ([]byte, []int) {
+	return fileDescriptor_cf8a97a40e001e4e, []int{0}
+}
+
+func (m *List) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_List.Unmarshal(m, b)
+}
+func (m *List) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_List.Marshal(b, m, deterministic)
+}
+func (m *List) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_List.Merge(m, src)
+}
+func (m *List) XXX_Size() int {
+	return xxx_messageInfo_List.Size(m)
+}
+func (m *List) XXX_DiscardUnknown() {
+	xxx_messageInfo_List.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_List proto.InternalMessageInfo
+
+func (m *List) GetId() int64 {
+	if m != nil {
+		return m.Id
+	}
+	return 0
+}
+
+func (m *List) GetName() string {
+	if m != nil {
+		return m.Name
+	}
+	return ""
+}
+
+func (m *List) GetFolderId() *wrappers.Int64Value {
+	if m != nil {
+		return m.FolderId
+	}
+	return nil
+}
+
+func (m *List) GetNotes() string {
+	if m != nil {
+		return m.Notes
+	}
+	return ""
+}
+
+func (m *List) GetBudget() *wrappers.Int64Value {
+	if m != nil {
+		return m.Budget
+	}
+	return nil
+}
+
+func (m *List) GetBudgetCurrency() string {
+	if m != nil {
+		return m.BudgetCurrency
+	}
+	return ""
+}
+
+func (m *List) GetCreated() *timestamp.Timestamp {
+	if m != nil {
+		return m.Created
+	}
+	return nil
+}
+
+func (m *List) GetModified() *timestamp.Timestamp {
+	if m != nil {
+		return m.Modified
+	}
+	return nil
+}
+
+func (m *List) GetVersion() int64 {
+	if m != nil {
+		return m.Version
+	}
+	return 0
+}
+
+// Item is an item.Item. The quantity is an exact decimal, such as "1.5".
+type Item struct {
+	Id                   int64                `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	ListId               int64                `protobuf:"varint,2,opt,name=list_id,json=listId,proto3" json:"list_id,omitempty"`
+	Name                 string               `protobuf:"bytes,3,opt,name=name,proto3" json:"name,omitempty"`
+	Quantity             string               `protobuf:"bytes,4,opt,name=quantity,proto3" json:"quantity,omitempty"`
+	Unit                 string               `protobuf:"bytes,5,opt,name=unit,proto3" json:"unit,omitempty"`
+	Price                *wrappers.Int64Value `protobuf:"bytes,6,opt,name=price,proto3" json:"price,omitempty"`
+	Currency             string               `protobuf:"bytes,7,opt,name=currency,proto3" json:"currency,omitempty"`
+	Notes                string               `protobuf:"bytes,8,opt,name=notes,proto3" json:"notes,omitempty"`
+	DueAt                *timestamp.Timestamp `protobuf:"bytes,9,opt,name=due_at,json=dueAt,proto3" json:"due_at,omitempty"`
+	RemindAt             *timestamp.Timestamp `protobuf:"bytes,10,opt,name=remind_at,json=remindAt,proto3" json:"remind_at,omitempty"`
+	ParentId             *wrappers.Int64Value `protobuf:"bytes,11,opt,name=parent_id,json=parentId,proto3" json:"parent_id,omitempty"`
+	Recurrence           string               `protobuf:"bytes,12,opt,name=recurrence,proto3" json:"recurrence,omitempty"`
+	CompletedAt          *timestamp.Timestamp `protobuf:"bytes,13,opt,name=completed_at,json=completedAt,proto3" json:"completed_at,omitempty"`
+	Created              *timestamp.Timestamp `protobuf:"bytes,14,opt,name=created,proto3" json:"created,omitempty"`
+	Modified             *timestamp.Timestamp `protobuf:"bytes,15,opt,name=modified,proto3" json:"modified,omitempty"`
+	Version              int64                `protobuf:"varint,16,opt,name=version,proto3" json:"version,omitempty"`
+	Tags                 []string             `protobuf:"bytes,17,rep,name=tags,proto3" json:"tags,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}             `json:"-"`
+	XXX_unrecognized     []byte               `json:"-"`
+	XXX_sizecache        int32                `json:"-"`
+}
+
+func (m *Item) Reset()         { *m = Item{} }
+func (m *Item) String() string { return proto.CompactTextString(m) }
+func (*Item) ProtoMessage()    {}
+func (*Item) Descriptor() ([]byte, []int) {
+	return fileDescriptor_cf8a97a40e001e4e, []int{1}
+}
+
+func (m *Item) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Item.Unmarshal(m, b)
+}
+func (m *Item) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Item.Marshal(b, m, deterministic)
+}
+func (m *Item) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Item.Merge(m, src)
+}
+func (m *Item) XXX_Size() int {
+	return xxx_messageInfo_Item.Size(m)
+}
+func (m *Item) XXX_DiscardUnknown() {
+	xxx_messageInfo_Item.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Item proto.InternalMessageInfo
+
+func (m *Item) GetId() int64 {
+	if m != nil {
+		return m.Id
+	}
+	return 0
+}
+
+func (m *Item) GetListId() int64 {
+	if m != nil {
+		return m.ListId
+	}
+	return 0
+}
+
+func (m *Item) GetName() string {
+	if m != nil {
+		return m.Name
+	}
+	return ""
+}
+
+func (m *Item) GetQuantity() string {
+	if m != nil {
+		return m.Quantity
+	}
+	return ""
+}
+
+func (m *Item) GetUnit() string {
+	if m != nil {
+		return m.Unit
+	}
+	return ""
+}
+
+func (m *Item) GetPrice() *wrappers.Int64Value {
+	if m != nil {
+		return m.Price
+	}
+	return nil
+}
+
+func (m *Item) GetCurrency() string {
+	if m != nil {
+		return m.Currency
+	}
+	return ""
+}
+
+func (m *Item) GetNotes() string {
+	if m != nil {
+		return m.Notes
+	}
+	return ""
+}
+
+func (m *Item) GetDueAt() *timestamp.Timestamp {
+	if m != nil {
+		return m.DueAt
+	}
+	return nil
+}
+
+func (m *Item) GetRemindAt() *timestamp.Timestamp {
+	if m != nil {
+		return m.RemindAt
+	}
+	return nil
+}
+
+func (m *Item) GetParentId() *wrappers.Int64Value {
+	if m != nil {
+		return m.ParentId
+	}
+	return nil
+}
+
+func (m *Item) GetRecurrence() string {
+	if m != nil {
+		return m.Recurrence
+	}
+	return ""
+}
+
+func (m *Item) GetCompletedAt() *timestamp.Timestamp {
+	if m != nil {
+		return m.CompletedAt
+	}
+	return nil
+}
+
+func (m *Item) GetCreated() *timestamp.Timestamp {
+	if m != nil {
+		return m.Created
+	}
+	return nil
+}
+
+func (m *Item) GetModified() *timestamp.Timestamp {
+	if m != nil {
+		return m.Modified
+	}
+	return nil
+}
+
+func (m *Item) GetVersion() int64 {
+	if m != nil {
+		return m.Version
+	}
+	return 0
+}
+
+func (m *Item) GetTags() []string {
+	if m != nil {
+		return m.Tags
+	}
+	return nil
+}
+
+type ListListsResponse struct {
+	Lists                []*List  `protobuf:"bytes,1,rep,name=lists,proto3" json:"lists,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ListListsResponse) Reset()         { *m = ListListsResponse{} }
+func (m *ListListsResponse) String() string { return proto.CompactTextString(m) }
+func (*ListListsResponse) ProtoMessage()    {}
+func (*ListListsResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_cf8a97a40e001e4e, []int{2}
+}
+
+func (m *ListListsResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ListListsResponse.Unmarshal(m, b)
+}
+func (m *ListListsResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ListListsResponse.Marshal(b, m, deterministic)
+}
+func (m *ListListsResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ListListsResponse.Merge(m, src)
+}
+func (m *ListListsResponse) XXX_Size() int {
+	return xxx_messageInfo_ListListsResponse.Size(m)
+}
+func (m *ListListsResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_ListListsResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ListListsResponse proto.InternalMessageInfo
+
+func (m *ListListsResponse) GetLists() []*List {
+	if m != nil {
+		return m.Lists
+	}
+	return nil
+}
+
+type GetListRequest struct {
+	Id                   int64    `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *GetListRequest) Reset()         { *m = GetListRequest{} }
+func (m *GetListRequest) String() string { return proto.CompactTextString(m) }
+func (*GetListRequest) ProtoMessage()    {}
+func (*GetListRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_cf8a97a40e001e4e, []int{3}
+}
+
+func (m *GetListRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_GetListRequest.Unmarshal(m, b)
+}
+func (m *GetListRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_GetListRequest.Marshal(b, m, deterministic)
+}
+func (m *GetListRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_GetListRequest.Merge(m, src)
+}
+func (m *GetListRequest) XXX_Size() int {
+	return xxx_messageInfo_GetListRequest.Size(m)
+}
+func (m *GetListRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_GetListRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_GetListRequest proto.InternalMessageInfo
+
+func (m *GetListRequest) GetId() int64 {
+	if m != nil {
+		return m.Id
+	}
+	return 0
+}
+
+type DeleteListRequest struct {
+	Id                   int64    `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *DeleteListRequest) Reset()         { *m = DeleteListRequest{} }
+func (m *DeleteListRequest) String() string { return proto.CompactTextString(m) }
+func (*DeleteListRequest) ProtoMessage()    {}
+func (*DeleteListRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_cf8a97a40e001e4e, []int{4}
+}
+
+func (m *DeleteListRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_DeleteListRequest.Unmarshal(m, b)
+}
+func (m *DeleteListRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_DeleteListRequest.Marshal(b, m, deterministic)
+}
+func (m *DeleteListRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_DeleteListRequest.Merge(m, src)
+}
+func (m *DeleteListRequest) XXX_Size() int {
+	return xxx_messageInfo_DeleteListRequest.Size(m)
+}
+func (m *DeleteListRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_DeleteListRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_DeleteListRequest proto.InternalMessageInfo
+
+func (m *DeleteListRequest) GetId() int64 {
+	if m != nil {
+		return m.Id
+	}
+	return 0
+}
+
+type ListItemsRequest struct {
+	ListId               int64    `protobuf:"varint,1,opt,name=list_id,json=listId,proto3" json:"list_id,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ListItemsRequest) Reset()         { *m = ListItemsRequest{} }
+func (m *ListItemsRequest) String() string { return proto.CompactTextString(m) }
+func (*ListItemsRequest) ProtoMessage()    {}
+func (*ListItemsRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_cf8a97a40e001e4e, []int{5}
+}
+
+func (m *ListItemsRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ListItemsRequest.Unmarshal(m, b)
+}
+func (m *ListItemsRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ListItemsRequest.Marshal(b, m, deterministic)
+}
+func (m *ListItemsRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ListItemsRequest.Merge(m, src)
+}
+func (m *ListItemsRequest) XXX_Size() int {
+	return xxx_messageInfo_ListItemsRequest.Size(m)
+}
+func (m *ListItemsRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_ListItemsRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ListItemsRequest proto.InternalMessageInfo
+
+func (m *ListItemsRequest) GetListId() int64 {
+	if m != nil {
+		return m.ListId
+	}
+	return 0
+}
+
+type ListItemsResponse struct {
+	Items                []*Item  `protobuf:"bytes,1,rep,name=items,proto3" json:"items,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ListItemsResponse) Reset()         { *m = ListItemsResponse{} }
+func (m *ListItemsResponse) String() string { return proto.CompactTextString(m) }
+func (*ListItemsResponse) ProtoMessage()    {}
+func (*ListItemsResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_cf8a97a40e001e4e, []int{6}
+}
+
+func (m *ListItemsResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ListItemsResponse.Unmarshal(m, b)
+}
+func (m *ListItemsResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ListItemsResponse.Marshal(b, m, deterministic)
+}
+func (m *ListItemsResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ListItemsResponse.Merge(m, src)
+}
+func (m *ListItemsResponse) XXX_Size() int {
+	return xxx_messageInfo_ListItemsResponse.Size(m)
+}
+func (m *ListItemsResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_ListItemsResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ListItemsResponse proto.InternalMessageInfo
+
+func (m *ListItemsResponse) GetItems() []*Item {
+	if m != nil {
+		return m.Items
+	}
+	return nil
+}
+
+type GetItemRequest struct {
+	ListId               int64    `protobuf:"varint,1,opt,name=list_id,json=listId,proto3" json:"list_id,omitempty"`
+	Id                   int64    `protobuf:"varint,2,opt,name=id,proto3" json:"id,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *GetItemRequest) Reset()         { *m = GetItemRequest{} }
+func (m *GetItemRequest) String() string { return proto.CompactTextString(m) }
+func (*GetItemRequest) ProtoMessage()    {}
+func (*GetItemRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_cf8a97a40e001e4e, []int{7}
+}
+
+func (m *GetItemRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_GetItemRequest.Unmarshal(m, b)
+}
+func (m *GetItemRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_GetItemRequest.Marshal(b, m, deterministic)
+}
+func (m *GetItemRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_GetItemRequest.Merge(m, src)
+}
+func (m *GetItemRequest) XXX_Size() int {
+	return xxx_messageInfo_GetItemRequest.Size(m)
+}
+func (m *GetItemRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_GetItemRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_GetItemRequest proto.InternalMessageInfo
+
+func (m *GetItemRequest) GetListId() int64 {
+	if m != nil {
+		return m.ListId
+	}
+	return 0
+}
+
+func (m *GetItemRequest) GetId() int64 {
+	if m != nil {
+		return m.Id
+	}
+	return 0
+}
+
+// UpdateItemRequest is an item update. The base version is the version of the item
+// the update was made against, leaving it out overwrites the item.
+type UpdateItemRequest struct {
+	Item                 *Item    `protobuf:"bytes,1,opt,name=item,proto3" json:"item,omitempty"`
+	BaseVersion          int64    `protobuf:"varint,2,opt,name=base_version,json=baseVersion,proto3" json:"base_version,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *UpdateItemRequest) Reset()         { *m = UpdateItemRequest{} }
+func (m *UpdateItemRequest) String() string { return proto.CompactTextString(m) }
+func (*UpdateItemRequest) ProtoMessage()    {}
+func (*UpdateItemRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_cf8a97a40e001e4e, []int{8}
+}
+
+func (m *UpdateItemRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_UpdateItemRequest.Unmarshal(m, b)
+}
+func (m *UpdateItemRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_UpdateItemRequest.Marshal(b, m, deterministic)
+}
+func (m *UpdateItemRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_UpdateItemRequest.Merge(m, src)
+}
+func (m *UpdateItemRequest) XXX_Size() int {
+	return xxx_messageInfo_UpdateItemRequest.Size(m)
+}
+func (m *UpdateItemRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_UpdateItemRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_UpdateItemRequest proto.InternalMessageInfo
+
+func (m *UpdateItemRequest) GetItem() *Item {
+	if m != nil {
+		return m.Item
+	}
+	return nil
+}
+
+func (m *UpdateItemRequest) GetBaseVersion() int64 {
+	if m != nil {
+		return m.BaseVersion
+	}
+	return 0
+}
+
+type DeleteItemRequest struct {
+	ListId               int64    `protobuf:"varint,1,opt,name=list_id,json=listId,proto3" json:"list_id,omitempty"`
+	Id                   int64    `protobuf:"varint,2,opt,name=id,proto3" json:"id,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *DeleteItemRequest) Reset()         { *m = DeleteItemRequest{} }
+func (m *DeleteItemRequest) String() string { return proto.CompactTextString(m) }
+func (*DeleteItemRequest) ProtoMessage()    {}
+func (*DeleteItemRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_cf8a97a40e001e4e, []int{9}
+}
+
+func (m *DeleteItemRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_DeleteItemRequest.Unmarshal(m, b)
+}
+func (m *DeleteItemRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_DeleteItemRequest.Marshal(b, m, deterministic)
+}
+func (m *DeleteItemRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_DeleteItemRequest.Merge(m, src)
+}
+func (m *DeleteItemRequest) XXX_Size() int {
+	return xxx_messageInfo_DeleteItemRequest.Size(m)
+}
+func (m *DeleteItemRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_DeleteItemRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_DeleteItemRequest proto.InternalMessageInfo
+
+func (m *DeleteItemRequest) GetListId() int64 {
+	if m != nil {
+		return m.ListId
+	}
+	return 0
+}
+
+func (m *DeleteItemRequest) GetId() int64 {
+	if m != nil {
+		return m.Id
+	}
+	return 0
+}
+
+type CompleteItemRequest struct {
+	ListId               int64    `protobuf:"varint,1,opt,name=list_id,json=listId,proto3" json:"list_id,omitempty"`
+	Id                   int64    `protobuf:"varint,2,opt,name=id,proto3" json:"id,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *CompleteItemRequest) Reset()         { *m = CompleteItemRequest{} }
+func (m *CompleteItemRequest) String() string { return proto.CompactTextString(m) }
+func (*CompleteItemRequest) ProtoMessage()    {}
+func (*CompleteItemRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_cf8a97a40e001e4e, []int{10}
+}
+
+func (m *CompleteItemRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_CompleteItemRequest.Unmarshal(m, b)
+}
+func (m *CompleteItemRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_CompleteItemRequest.Marshal(b, m, deterministic)
+}
+func (m *CompleteItemRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_CompleteItemRequest.Merge(m, src)
+}
+func (m *CompleteItemRequest) XXX_Size() int {
+	return xxx_messageInfo_CompleteItemRequest.Size(m)
+}
+func (m *CompleteItemRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_CompleteItemRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_CompleteItemRequest proto.InternalMessageInfo
+
+func (m *CompleteItemRequest) GetListId() int64 {
+	if m != nil {
+		return m.ListId
+	}
+	return 0
+}
+
+func (m *CompleteItemRequest) GetId() int64 {
+	if m != nil {
+		return m.Id
+	}
+	return 0
+}
+
+// CompleteItemResponse holds the completed item, and the next occurrence of it
+// when it recurs.
+type CompleteItemResponse struct {
+	Item                 *Item    `protobuf:"bytes,1,opt,name=item,proto3" json:"item,omitempty"`
+	Next                 *Item    `protobuf:"bytes,2,opt,name=next,proto3" json:"next,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *CompleteItemResponse) Reset()         { *m = CompleteItemResponse{} }
+func (m *CompleteItemResponse) String() string { return proto.CompactTextString(m) }
+func (*CompleteItemResponse) ProtoMessage()    {}
+func (*CompleteItemResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_cf8a97a40e001e4e, []int{11}
+}
+
+func (m *CompleteItemResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_CompleteItemResponse.Unmarshal(m, b)
+}
+func (m *CompleteItemResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_CompleteItemResponse.Marshal(b, m, deterministic)
+}
+func (m *CompleteItemResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_CompleteItemResponse.Merge(m, src)
+}
+func (m *CompleteItemResponse) XXX_Size() int {
+	return xxx_messageInfo_CompleteItemResponse.Size(m)
+}
+func (m *CompleteItemResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_CompleteItemResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_CompleteItemResponse proto.InternalMessageInfo
+
+func (m *CompleteItemResponse) GetItem() *Item {
+	if m != nil {
+		return m.Item
+	}
+	return nil
+}
+
+func (m *CompleteItemResponse) GetNext() *Item {
+	if m != nil {
+		return m.Next
+	}
+	return nil
+}
+
+func init() {
+	proto.RegisterType((*List)(nil), "listd.List")
+	proto.RegisterType((*Item)(nil), "listd.Item")
+	proto.RegisterType((*ListListsResponse)(nil), "listd.ListListsResponse")
+	proto.RegisterType((*GetListRequest)(nil), "listd.GetListRequest")
+	proto.RegisterType((*DeleteListRequest)(nil), "listd.DeleteListRequest")
+	proto.RegisterType((*ListItemsRequest)(nil), "listd.ListItemsRequest")
+	proto.RegisterType((*ListItemsResponse)(nil), "listd.ListItemsResponse")
+	proto.RegisterType((*GetItemRequest)(nil), "listd.GetItemRequest")
+	proto.RegisterType((*UpdateItemRequest)(nil), "listd.UpdateItemRequest")
+	proto.RegisterType((*DeleteItemRequest)(nil), "listd.DeleteItemRequest")
+	proto.RegisterType((*CompleteItemRequest)(nil), "listd.CompleteItemRequest")
+	proto.RegisterType((*CompleteItemResponse)(nil), "listd.CompleteItemResponse")
+}
+
+func init() { proto.RegisterFile("listd.proto", fileDescriptor_cf8a97a40e001e4e) }
+
+var fileDescriptor_cf8a97a40e001e4e = []byte{
+	// 788 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x9c, 0x55, 0xdd, 0x6a, 0x1b, 0x47,
+	0x14, 0x46, 0xab, 0xdf, 0x3d, 0x52, 0x65, 0x6b, 0xea, 0xd6, 0xc3, 0x1a, 0x6c, 0x59, 0x85, 0x56,
+	0x50, 0x2a, 0x63, 0xd9, 0xb8, 0x2d, 0xd4, 0x06, 0xd7, 0x2d, 0x46, 0x90, 0xab, 0x25, 0x71, 0x42,
+	0x6e, 0xc4, 0x4a, 0x73, 0x2c, 0x16, 0xb4, 0x3f, 0xde, 0x9d, 0x4d, 0xe2, 0x07, 0xc8, 0x5b, 0xe6,
+	0x22, 0xaf, 0x91, 0xbb, 0x30, 0x33, 0xbb, 0xd2, 0xac, 0xd6, 0xce, 0x06, 0x5d, 0x08, 0xe6, 0x9c,
+	0xf3, 0x9d, 0x9f, 0xf9, 0xce, 0xb7, 0x23, 0x68, 0x2f, 0xdd, 0x98, 0xb3, 0x51, 0x18, 0x05, 0x3c,
+	0x20, 0x75, 0x69, 0x58, 0x07, 0x8b, 0x20, 0x58, 0x2c, 0xf1, 0x44, 0x3a, 0x67, 0xc9, 0xfd, 0x09,
+	0x7a, 0x21, 0x7f, 0x54, 0x18, 0xeb, 0x68, 0x33, 0xc8, 0x5d, 0x0f, 0x63, 0xee, 0x78, 0x61, 0x0a,
+	0x38, 0xdc, 0x04, 0xbc, 0x8f, 0x9c, 0x30, 0xc4, 0x28, 0x56, 0xf1, 0xc1, 0x67, 0x03, 0x6a, 0x2f,
+	0xdc, 0x98, 0x93, 0x2e, 0x18, 0x2e, 0xa3, 0x95, 0x7e, 0x65, 0x58, 0xb5, 0x0d, 0x97, 0x11, 0x02,
+	0x35, 0xdf, 0xf1, 0x90, 0x1a, 0xfd, 0xca, 0xd0, 0xb4, 0xe5, 0x99, 0xfc, 0x05, 0xe6, 0x7d, 0xb0,
+	0x64, 0x18, 0x4d, 0x5d, 0x46, 0xab, 0xfd, 0xca, 0xb0, 0x3d, 0x3e, 0x18, 0xa9, 0x06, 0xa3, 0xac,
+	0xc1, 0x68, 0xe2, 0xf3, 0x8b, 0xf3, 0x3b, 0x67, 0x99, 0xa0, 0xdd, 0x52, 0xe8, 0x09, 0x23, 0x7b,
+	0x50, 0xf7, 0x03, 0x8e, 0x31, 0xad, 0xc9, 0x72, 0xca, 0x20, 0x67, 0xd0, 0x98, 0x25, 0x6c, 0x81,
+	0x9c, 0xd6, 0xcb, 0x8b, 0xa5, 0x50, 0xf2, 0x1b, 0xec, 0xa8, 0xd3, 0x74, 0x9e, 0x44, 0x11, 0xfa,
+	0xf3, 0x47, 0xda, 0x90, 0x45, 0xbb, 0xca, 0x7d, 0x93, 0x7a, 0xc9, 0x39, 0x34, 0xe7, 0x11, 0x3a,
+	0x1c, 0x19, 0x6d, 0xca, 0xf2, 0x56, 0xa1, 0xfc, 0xcb, 0x8c, 0x2d, 0x3b, 0x83, 0x92, 0x0b, 0x68,
+	0x79, 0x01, 0x73, 0xef, 0x5d, 0x64, 0xb4, 0x55, 0x9a, 0xb6, 0xc2, 0x12, 0x0a, 0xcd, 0x77, 0x18,
+	0xc5, 0x6e, 0xe0, 0x53, 0x53, 0x92, 0x98, 0x99, 0x83, 0x2f, 0x35, 0xa8, 0x4d, 0x38, 0x7a, 0x05,
+	0x8a, 0xf7, 0xa1, 0x29, 0x56, 0x2c, 0xc8, 0x34, 0xa4, 0xb3, 0x21, 0xcc, 0xc9, 0x9a, 0xfb, 0xaa,
+	0xc6, 0xbd, 0x05, 0xad, 0x87, 0xc4, 0xf1, 0xb9, 0xcb, 0x1f, 0x53, 0x12, 0x57, 0xb6, 0xc0, 0x27,
+	0xbe, 0xab, 0x58, 0x34, 0x6d, 0x79, 0x26, 0xa7, 0x50, 0x0f, 0x23, 0x77, 0x8e, 0xb4, 0x51, 0x4e,
+	0xad, 0x42, 0x8a, 0x16, 0x2b, 0x4a, 0x9b, 0xaa, 0x45, 0x66, 0xaf, 0x17, 0xd8, 0xd2, 0x17, 0x78,
+	0x0a, 0x0d, 0x96, 0xe0, 0xd4, 0xe1, 0xd4, 0x2c, 0xa5, 0xaa, 0xce, 0x12, 0xbc, 0xe6, 0xe4, 0x4f,
+	0x30, 0x23, 0xf4, 0x5c, 0x9f, 0x89, 0x2c, 0x28, 0x27, 0x58, 0x81, 0xaf, 0xb9, 0x10, 0x5f, 0xe8,
+	0x44, 0xe8, 0x4b, 0xbe, 0xda, 0xdf, 0x21, 0x3e, 0x85, 0x9e, 0x30, 0x72, 0x08, 0x10, 0x61, 0x7a,
+	0x13, 0xa4, 0x1d, 0x79, 0x01, 0xcd, 0x43, 0x2e, 0xa1, 0x33, 0x0f, 0xbc, 0x70, 0x89, 0x1c, 0xe5,
+	0x54, 0x3f, 0x94, 0x4e, 0xd5, 0x5e, 0xe1, 0xaf, 0xb9, 0xae, 0xb3, 0xee, 0x76, 0x3a, 0xdb, 0xd9,
+	0x4e, 0x67, 0xbb, 0x39, 0x9d, 0x09, 0x15, 0x70, 0x67, 0x11, 0xd3, 0x5e, 0xbf, 0x2a, 0x54, 0x20,
+	0xce, 0x83, 0x0b, 0xe8, 0x89, 0xaf, 0x5b, 0xfc, 0x62, 0x1b, 0xe3, 0x30, 0xf0, 0x63, 0x24, 0xc7,
+	0x20, 0x9f, 0x96, 0x98, 0x56, 0xfa, 0xd5, 0x61, 0x7b, 0xdc, 0x1e, 0xa9, 0x57, 0x47, 0x80, 0x6c,
+	0x15, 0x19, 0xf4, 0xa1, 0x7b, 0x8b, 0x32, 0xcd, 0xc6, 0x87, 0x04, 0x8b, 0xef, 0xc3, 0xe0, 0x17,
+	0xe8, 0xfd, 0x87, 0x82, 0x82, 0x6f, 0x81, 0x7e, 0x87, 0x5d, 0x11, 0x16, 0xea, 0x8f, 0x33, 0x8c,
+	0xa6, 0xfa, 0x8a, 0xae, 0xfa, 0x6c, 0xd6, 0x14, 0xbc, 0x9e, 0xd5, 0x15, 0x8e, 0x8d, 0x59, 0x05,
+	0xc8, 0x56, 0x91, 0xc1, 0xdf, 0x72, 0x56, 0xe9, 0x29, 0x69, 0x91, 0xce, 0x67, 0xac, 0xe6, 0x7b,
+	0x0d, 0xbd, 0x57, 0x21, 0x73, 0x38, 0xea, 0xd9, 0x47, 0x50, 0x13, 0x85, 0x65, 0xea, 0x46, 0x47,
+	0x19, 0x20, 0xc7, 0xd0, 0x99, 0x39, 0x31, 0x4e, 0xb3, 0x3d, 0xa8, 0x7a, 0x6d, 0xe1, 0xbb, 0x4b,
+	0xbf, 0xf9, 0x7f, 0x32, 0x76, 0xb6, 0x1a, 0xeb, 0x0a, 0x7e, 0xbc, 0x49, 0x05, 0xb6, 0x55, 0xfe,
+	0x1b, 0xd8, 0xcb, 0xe7, 0xa7, 0x64, 0x96, 0xde, 0xec, 0x08, 0x6a, 0x3e, 0x7e, 0xe0, 0xd4, 0x78,
+	0x02, 0x20, 0x02, 0xe3, 0x8f, 0x06, 0xd4, 0xa5, 0x98, 0xc8, 0x25, 0x98, 0x2b, 0x65, 0x91, 0x9f,
+	0x0b, 0xd2, 0xfd, 0x5f, 0xfc, 0x49, 0x59, 0x54, 0x93, 0x56, 0x5e, 0x83, 0x7f, 0x40, 0x33, 0x15,
+	0x18, 0xf9, 0x29, 0x05, 0xe5, 0x05, 0x67, 0xe9, 0xb2, 0x24, 0xbf, 0x02, 0xdc, 0xc8, 0x0f, 0x47,
+	0x5a, 0x7a, 0xa8, 0x80, 0x53, 0x0b, 0x2d, 0xc1, 0x5d, 0x01, 0xac, 0xd5, 0x4b, 0xb2, 0x31, 0x0b,
+	0x82, 0xb6, 0x9e, 0xb9, 0xd8, 0xf8, 0x93, 0x01, 0x75, 0x29, 0x54, 0x72, 0xa5, 0x78, 0x50, 0xc6,
+	0xbe, 0xd6, 0x43, 0x17, 0xbd, 0x45, 0x8b, 0x81, 0x1c, 0x11, 0xc2, 0xa7, 0x13, 0xa1, 0xad, 0xdd,
+	0xd2, 0xd7, 0xb0, 0x26, 0x42, 0x5a, 0x7a, 0x28, 0x8f, 0x3b, 0xcb, 0x88, 0x90, 0x56, 0xd6, 0xbe,
+	0x20, 0xf6, 0x7c, 0xd2, 0x8a, 0x95, 0x5c, 0x52, 0x41, 0xc8, 0xcf, 0xb1, 0x42, 0x6e, 0xa1, 0xa3,
+	0xeb, 0x8e, 0x58, 0x69, 0x85, 0x27, 0xc4, 0x6c, 0x1d, 0x3c, 0x19, 0x53, 0xa4, 0xfc, 0x6b, 0xbe,
+	0x95, 0x4a, 0x67, 0xe1, 0x6c, 0xd6, 0x90, 0x3d, 0xce, 0xbe, 0x0e, 0x00, 0xce, 0x7c, 0xaa, 0x7a,
+	0x1b, 0x09, 0x00, 0x00,
+}
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ context.Context
+var _ grpc.ClientConn
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+const _ = grpc.SupportPackageIsVersion4
+
+// ListsClient is the client API for Lists service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://godoc.org/google.golang.org/grpc#ClientConn.NewStream.
+type ListsClient interface {
+	// GET /list
+	ListLists(ctx context.Context, in *empty.Empty, opts ...grpc.CallOption) (*ListListsResponse, error)
+	// GET /list/:lid
+	GetList(ctx context.Context, in *GetListRequest, opts ...grpc.CallOption) (*List, error)
+	// POST /list
+	CreateList(ctx context.Context, in *List, opts ...grpc.CallOption) (*List, error)
+	// PUT /list/:lid
+	UpdateList(ctx context.Context, in *List, opts ...grpc.CallOption) (*List, error)
+	// DELETE /list/:lid
+	DeleteList(ctx context.Context, in *DeleteListRequest, opts ...grpc.CallOption) (*empty.Empty, error)
+}
+
+type listsClient struct {
+	cc *grpc.ClientConn
+}
+
+func NewListsClient(cc *grpc.ClientConn) ListsClient {
+	return &listsClient{cc}
+}
+
+func (c *listsClient) ListLists(ctx context.Context, in *empty.Empty, opts ...grpc.CallOption) (*ListListsResponse, error) {
+	out := new(ListListsResponse)
+	err := c.cc.Invoke(ctx, "/listd.Lists/ListLists", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *listsClient) GetList(ctx context.Context, in *GetListRequest, opts ...grpc.CallOption) (*List, error) {
+	out := new(List)
+	err := c.cc.Invoke(ctx, "/listd.Lists/GetList", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *listsClient) CreateList(ctx context.Context, in *List, opts ...grpc.CallOption) (*List, error) {
+	out := new(List)
+	err := c.cc.Invoke(ctx, "/listd.Lists/CreateList", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *listsClient) UpdateList(ctx context.Context, in *List, opts ...grpc.CallOption) (*List, error) {
+	out := new(List)
+	err := c.cc.Invoke(ctx, "/listd.Lists/UpdateList", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *listsClient) DeleteList(ctx context.Context, in *DeleteListRequest, opts ...grpc.CallOption) (*empty.Empty, error) {
+	out := new(empty.Empty)
+	err := c.cc.Invoke(ctx, "/listd.Lists/DeleteList", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// ListsServer is the server API for Lists service.
+type ListsServer interface {
+	// GET /list
+	ListLists(context.Context, *empty.Empty) (*ListListsResponse, error)
+	// GET /list/:lid
+	GetList(context.Context, *GetListRequest) (*List, error)
+	// POST /list
+	CreateList(context.Context, *List) (*List, error)
+	// PUT /list/:lid
+	UpdateList(context.Context, *List) (*List, error)
+	// DELETE /list/:lid
+	DeleteList(context.Context, *DeleteListRequest) (*empty.Empty, error)
+}
+
+func RegisterListsServer(s *grpc.Server, srv ListsServer) {
+	s.RegisterService(&_Lists_serviceDesc, srv)
+}
+
+func _Lists_ListLists_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(empty.Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ListsServer).ListLists(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/listd.Lists/ListLists",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ListsServer).ListLists(ctx, req.(*empty.Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Lists_GetList_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetListRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ListsServer).GetList(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/listd.Lists/GetList",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ListsServer).GetList(ctx, req.(*GetListRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Lists_CreateList_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(List)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ListsServer).CreateList(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/listd.Lists/CreateList",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ListsServer).CreateList(ctx, req.(*List))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Lists_UpdateList_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(List)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ListsServer).UpdateList(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/listd.Lists/UpdateList",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ListsServer).UpdateList(ctx, req.(*List))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Lists_DeleteList_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteListRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ListsServer).DeleteList(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/listd.Lists/DeleteList",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ListsServer).DeleteList(ctx, req.(*DeleteListRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+var _Lists_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "listd.Lists",
+	HandlerType: (*ListsServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "ListLists",
+			Handler:    _Lists_ListLists_Handler,
+		},
+		{
+			MethodName: "GetList",
+			Handler:    _Lists_GetList_Handler,
+		},
+		{
+			MethodName: "CreateList",
+			Handler:    _Lists_CreateList_Handler,
+		},
+		{
+			MethodName: "UpdateList",
+			Handler:    _Lists_UpdateList_Handler,
+		},
+		{
+			MethodName: "DeleteList",
+			Handler:    _Lists_DeleteList_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "listd.proto",
+}
+
+// ItemsClient is the client API for Items service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://godoc.org/google.golang.org/grpc#ClientConn.NewStream.
+type ItemsClient interface {
+	// GET /list/:lid/item
+	ListItems(ctx context.Context, in *ListItemsRequest, opts ...grpc.CallOption) (*ListItemsResponse, error)
+	// GET /list/:lid/item/:iid
+	GetItem(ctx context.Context, in *GetItemRequest, opts ...grpc.CallOption) (*Item, error)
+	// POST /list/:lid/item
+	CreateItem(ctx context.Context, in *Item, opts ...grpc.CallOption) (*Item, error)
+	// PUT /list/:lid/item/:iid
+	UpdateItem(ctx context.Context, in *UpdateItemRequest, opts ...grpc.CallOption) (*Item, error)
+	// DELETE /list/:lid/item/:iid
+	DeleteItem(ctx context.Context, in *DeleteItemRequest, opts ...grpc.CallOption) (*empty.Empty, error)
+	// POST /list/:lid/item/:iid/complete
+	CompleteItem(ctx context.Context, in *CompleteItemRequest, opts ...grpc.CallOption) (*CompleteItemResponse, error)
+}
+
+type itemsClient struct {
+	cc *grpc.ClientConn
+}
+
+func NewItemsClient(cc *grpc.ClientConn) ItemsClient {
+	return &itemsClient{cc}
+}
+
+func (c *itemsClient) ListItems(ctx context.Context, in *ListItemsRequest, opts ...grpc.CallOption) (*ListItemsResponse, error) {
+	out := new(ListItemsResponse)
+	err := c.cc.Invoke(ctx, "/listd.Items/ListItems", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *itemsClient) GetItem(ctx context.Context, in *GetItemRequest, opts ...grpc.CallOption) (*Item, error) {
+	out := new(Item)
+	err := c.cc.Invoke(ctx, "/listd.Items/GetItem", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *itemsClient) CreateItem(ctx context.Context, in *Item, opts ...grpc.CallOption) (*Item, error) {
+	out := new(Item)
+	err := c.cc.Invoke(ctx, "/listd.Items/CreateItem", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *itemsClient) UpdateItem(ctx context.Context, in *UpdateItemRequest, opts ...grpc.CallOption) (*Item, error) {
+	out := new(Item)
+	err := c.cc.Invoke(ctx, "/listd.Items/UpdateItem", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *itemsClient) DeleteItem(ctx context.Context, in *DeleteItemRequest, opts ...grpc.CallOption) (*empty.Empty, error) {
+	out := new(empty.Empty)
+	err := c.cc.Invoke(ctx, "/listd.Items/DeleteItem", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *itemsClient) CompleteItem(ctx context.Context, in *CompleteItemRequest, opts ...grpc.CallOption) (*CompleteItemResponse, error) {
+	out := new(CompleteItemResponse)
+	err := c.cc.Invoke(ctx, "/listd.Items/CompleteItem", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// ItemsServer is the server API for Items service.
+type ItemsServer interface {
+	// GET /list/:lid/item
+	ListItems(context.Context, *ListItemsRequest) (*ListItemsResponse, error)
+	// GET /list/:lid/item/:iid
+	GetItem(context.Context, *GetItemRequest) (*Item, error)
+	// POST /list/:lid/item
+	CreateItem(context.Context, *Item) (*Item, error)
+	// PUT /list/:lid/item/:iid
+	UpdateItem(context.Context, *UpdateItemRequest) (*Item, error)
+	// DELETE /list/:lid/item/:iid
+	DeleteItem(context.Context, *DeleteItemRequest) (*empty.Empty, error)
+	// POST /list/:lid/item/:iid/complete
+	CompleteItem(context.Context, *CompleteItemRequest) (*CompleteItemResponse, error)
+}
+
+func RegisterItemsServer(s *grpc.Server, srv ItemsServer) {
+	s.RegisterService(&_Items_serviceDesc, srv)
+}
+
+func _Items_ListItems_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListItemsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ItemsServer).ListItems(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/listd.Items/ListItems",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ItemsServer).ListItems(ctx, req.(*ListItemsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Items_GetItem_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetItemRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ItemsServer).GetItem(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/listd.Items/GetItem",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ItemsServer).GetItem(ctx, req.(*GetItemRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Items_CreateItem_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Item)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ItemsServer).CreateItem(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/listd.Items/CreateItem",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ItemsServer).CreateItem(ctx, req.(*Item))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Items_UpdateItem_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdateItemRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ItemsServer).UpdateItem(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/listd.Items/UpdateItem",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ItemsServer).UpdateItem(ctx, req.(*UpdateItemRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Items_DeleteItem_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteItemRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ItemsServer).DeleteItem(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/listd.Items/DeleteItem",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ItemsServer).DeleteItem(ctx, req.(*DeleteItemRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Items_CompleteItem_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CompleteItemRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ItemsServer).CompleteItem(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/listd.Items/CompleteItem",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ItemsServer).CompleteItem(ctx, req.(*CompleteItemRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+var _Items_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "listd.Items",
+	HandlerType: (*ItemsServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "ListItems",
+			Handler:    _Items_ListItems_Handler,
+		},
+		{
+			MethodName: "GetItem",
+			Handler:    _Items_GetItem_Handler,
+		},
+		{
+			MethodName: "CreateItem",
+			Handler:    _Items_CreateItem_Handler,
+		},
+		{
+			MethodName: "UpdateItem",
+			Handler:    _Items_UpdateItem_Handler,
+		},
+		{
+			MethodName: "DeleteItem",
+			Handler:    _Items_DeleteItem_Handler,
+		},
+		{
+			MethodName: "CompleteItem",
+			Handler:    _Items_CompleteItem_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "listd.proto",
+}
@@ -0,0 +1,45 @@
+package reminder
+
+import "github.com/george-e-shaw-iv/integration-tests-example/cmd/listd/item"
+
+// PostgreSQL queries for the reminders kept on the item table.
+const (
+	// claimDue is a query that claims up to $3 rows in the item table whose
+	// reminder is due at $1 and has neither fired nor failed yet, skipping rows
+	// locked by another scheduler and completed items. A reminder is due at its
+	// next attempt once it has been claimed or has failed before, and at
+	// remind_at otherwise. The next attempt of every claimed reminder is pushed to
+	// $2 so that it is left alone while being sent, and is returned along with
+	// the item and the attempts made so far.
+	claimDue = `UPDATE item SET reminder_next_attempt = $2
+		WHERE item_id IN (
+			SELECT item_id FROM item
+			WHERE COALESCE(reminder_next_attempt, remind_at) <= $1
+				AND reminded_at IS NULL AND reminder_failed_at IS NULL AND completed_at IS NULL
+			ORDER BY COALESCE(reminder_next_attempt, remind_at), item_id LIMIT $3
+			FOR UPDATE SKIP LOCKED)
+		RETURNING ` + item.Columns + `, reminder_attempts, reminder_next_attempt;`
+
+	// renewLease is a query that pushes the next attempt of a claimed reminder to
+	// $1, unless it has since been claimed again or rearmed, and returns the new
+	// next attempt. The values are given in order for reminder_next_attempt,
+	// item_id and the reminder_next_attempt it was claimed with.
+	renewLease = `UPDATE item SET reminder_next_attempt = $1
+		WHERE item_id = $2 AND reminder_next_attempt = $3
+		RETURNING reminder_next_attempt;`
+
+	// markFired is a query that records the time the reminder of a row in the
+	// item table given by item_id fired, unless it has since been claimed again or
+	// rearmed. The values are given in order for reminded_at, reminder_attempts,
+	// item_id and the reminder_next_attempt it was claimed with.
+	markFired = `UPDATE item SET reminded_at = $1, reminder_attempts = $2, reminder_next_attempt = NULL
+		WHERE item_id = $3 AND reminder_next_attempt = $4;`
+
+	// markFailed is a query that records a failed attempt to send the reminder of
+	// a row in the item table given by item_id, unless it has since been claimed
+	// again or rearmed. The values are given in order for reminder_attempts,
+	// reminder_next_attempt, reminder_failed_at, item_id and the
+	// reminder_next_attempt it was claimed with.
+	markFailed = `UPDATE item SET reminder_attempts = $1, reminder_next_attempt = $2, reminder_failed_at = $3
+		WHERE item_id = $4 AND reminder_next_attempt = $5;`
+)
@@ -0,0 +1,185 @@
+// Package reminder fires the reminders set on items once their time comes.
+//
+// Any number of schedulers may run against the same database, one per replica of
+// the list daemon. Due reminders are claimed with a lease, in a statement that
+// commits before any of them is sent, so no row is kept locked while a notifier
+// talks to the outside world. Other schedulers leave a claimed reminder alone
+// until its lease runs out, and the outcome of an attempt is only recorded by the
+// scheduler holding the lease. Only a scheduler that outlives the lease of a
+// reminder, or crashes in between sending it and recording it, sends it again.
+// Failed reminders are retried with exponential backoff until they run out of
+// attempts and are marked as failed.
+package reminder
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/george-e-shaw-iv/integration-tests-example/cmd/listd/item"
+	"github.com/george-e-shaw-iv/integration-tests-example/cmd/listd/list"
+	"github.com/jmoiron/sqlx"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+// Reminder is a reminder that has come due, along with the list of its item.
+type Reminder struct {
+	Item item.Item
+	List list.List
+}
+
+// Notifier notifies someone of a reminder that has come due.
+type Notifier interface {
+	Notify(ctx context.Context, r Reminder) error
+}
+
+// LogNotifier is a Notifier that logs every reminder.
+type LogNotifier struct{}
+
+// Notify implements the Notifier interface for the LogNotifier type.
+func (LogNotifier) Notify(ctx context.Context, r Reminder) error {
+	log.WithFields(log.Fields{
+		"listID": r.Item.ListID,
+		"list":   r.List.Name,
+		"itemID": r.Item.ID,
+		"item":   r.Item.Name,
+		"dueAt":  r.Item.DueAt,
+	}).Info("reminder")
+
+	return nil
+}
+
+// lease is how long a claimed reminder is left alone by other schedulers before
+// it is attempted again. It is renewed right before each reminder is sent.
+const lease = 5 * time.Minute
+
+// maxBackoff is the upper bound of the delay in between two attempts to send a
+// reminder.
+const maxBackoff = 6 * time.Hour
+
+// Scheduler fires due reminders through a Notifier. A reminder that fails to be
+// sent is retried after Backoff, doubled on every subsequent failure, until
+// MaxAttempts is reached and it is marked as failed.
+type Scheduler struct {
+	DB          *sqlx.DB
+	Notifier    Notifier
+	MaxAttempts int
+	Backoff     time.Duration
+	BatchSize   int
+}
+
+// claimedReminder is the item of a reminder claimed by a scheduler, along with the
+// attempts made to send it so far. Lease is the time at which it may be claimed
+// again.
+type claimedReminder struct {
+	item.Item
+	Attempts int       `db:"reminder_attempts"`
+	Lease    time.Time `db:"reminder_next_attempt"`
+}
+
+// Run fires due reminders every interval until ctx is cancelled.
+func (s *Scheduler) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := s.FireDue(ctx); err != nil {
+				log.WithError(err).Error("fire due reminders")
+			}
+		}
+	}
+}
+
+// FireDue fires one batch of due reminders and returns how many were sent. Each
+// reminder has its lease renewed before it is sent and its outcome recorded right
+// after, and one claimed again by another scheduler in the meantime is left to it.
+func (s *Scheduler) FireDue(ctx context.Context) (int, error) {
+	now := time.Now()
+
+	var due []claimedReminder
+	if err := s.DB.Select(&due, claimDue, now, now.Add(lease), s.BatchSize); err != nil {
+		return 0, errors.Wrap(err, "claim due reminders")
+	}
+
+	var sent int
+	for _, cr := range due {
+		l, err := list.SelectList(s.DB, cr.ListID)
+		if err != nil {
+			return sent, errors.Wrap(err, "select list of reminder")
+		}
+
+		if err := s.DB.Get(&cr.Lease, renewLease, time.Now().Add(lease), cr.ID, cr.Lease); err != nil {
+			if errors.Cause(err) == sql.ErrNoRows {
+				continue
+			}
+
+			return sent, errors.Wrap(err, "renew reminder lease")
+		}
+
+		notifyErr := s.Notifier.Notify(ctx, Reminder{Item: cr.Item, List: l})
+		if err := s.record(cr, notifyErr); err != nil {
+			return sent, err
+		}
+
+		if notifyErr == nil {
+			sent++
+		}
+	}
+
+	return sent, nil
+}
+
+// record stores the outcome of an attempt to send a reminder, rescheduling it if
+// it failed until it runs out of attempts. Nothing is recorded if the reminder has
+// been claimed again or rearmed since its lease was renewed.
+func (s *Scheduler) record(cr claimedReminder, notifyErr error) error {
+	n := cr.Attempts + 1
+
+	if notifyErr == nil {
+		if _, err := s.DB.Exec(markFired, time.Now(), n, cr.ID, cr.Lease); err != nil {
+			return errors.Wrap(err, "mark reminder as fired")
+		}
+
+		return nil
+	}
+
+	next := time.Now().Add(backoff(s.Backoff, n))
+	var failed *time.Time
+
+	if n >= s.MaxAttempts {
+		now := time.Now()
+		failed = &now
+	}
+
+	log.WithFields(log.Fields{
+		"error":    notifyErr,
+		"itemID":   cr.ID,
+		"attempts": n,
+		"failed":   failed != nil,
+	}).Error("send reminder")
+
+	if _, err := s.DB.Exec(markFailed, n, next, failed, cr.ID, cr.Lease); err != nil {
+		return errors.Wrap(err, "mark reminder attempt as failed")
+	}
+
+	return nil
+}
+
+// backoff returns the delay before the next attempt to send a reminder that has
+// failed attempts times, doubling base with every failure.
+func backoff(base time.Duration, attempts int) time.Duration {
+	d := base
+	for i := 1; i < attempts; i++ {
+		d *= 2
+		if d >= maxBackoff {
+			return maxBackoff
+		}
+	}
+
+	return d
+}
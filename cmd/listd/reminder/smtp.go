@@ -0,0 +1,69 @@
+package reminder
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"mime"
+	"net/smtp"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// SMTPNotifier is a Notifier that emails every reminder through an SMTP server.
+type SMTPNotifier struct {
+	// Addr is the host:port of the SMTP server.
+	Addr string
+
+	// Auth authenticates with the server, it is left nil for servers that do not
+	// require authentication.
+	Auth smtp.Auth
+
+	From string
+	To   []string
+}
+
+// Notify implements the Notifier interface for the SMTPNotifier type. The context
+// is not honoured as net/smtp offers no way to cancel a send.
+func (n SMTPNotifier) Notify(ctx context.Context, r Reminder) error {
+	if err := smtp.SendMail(n.Addr, n.Auth, n.From, n.To, n.message(r)); err != nil {
+		return errors.Wrap(err, "send reminder email")
+	}
+
+	return nil
+}
+
+// message returns the email sent for a reminder.
+func (n SMTPNotifier) message(r Reminder) []byte {
+	var b bytes.Buffer
+
+	subject := "Reminder: " + oneLine(r.Item.Name)
+
+	fmt.Fprintf(&b, "From: %s\r\n", n.From)
+	fmt.Fprintf(&b, "To: %s\r\n", strings.Join(n.To, ", "))
+	fmt.Fprintf(&b, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", subject))
+	fmt.Fprintf(&b, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	fmt.Fprint(&b, "MIME-Version: 1.0\r\n")
+	fmt.Fprint(&b, "Content-Type: text/plain; charset=utf-8\r\n")
+	fmt.Fprint(&b, "\r\n")
+
+	quantity := "x" + r.Item.Quantity.String()
+	if r.Item.Unit != "" {
+		quantity = r.Item.Quantity.String() + " " + r.Item.Unit
+	}
+
+	fmt.Fprintf(&b, "%s (%s) on your %s list.\r\n", oneLine(r.Item.Name), quantity, oneLine(r.List.Name))
+	if r.Item.DueAt != nil {
+		fmt.Fprintf(&b, "Due %s.\r\n", r.Item.DueAt.Format(time.RFC1123))
+	}
+
+	return b.Bytes()
+}
+
+// oneLine replaces line breaks in s with spaces so that user supplied names can't
+// inject headers or break the message body.
+func oneLine(s string) string {
+	return strings.NewReplacer("\r\n", " ", "\r", " ", "\n", " ").Replace(s)
+}
@@ -0,0 +1,36 @@
+package search
+
+// PostgreSQL queries for searching the list and item tables.
+const (
+	// search is a query that selects the rows in the list and item tables whose
+	// search vector matches the web search syntax query given by $1, best match
+	// first, limited to $2 rows. The snippet is taken from the name, or from the
+	// notes when only they match. Both are HTML escaped before highlighting so the
+	// only markup in a snippet is the <mark> element around matching words.
+	search = `WITH q AS (SELECT websearch_to_tsquery('english', $1) AS query)
+SELECT 'list' AS kind, l.list_id AS id, l.list_id, l.name AS list_name, l.name,
+	CASE WHEN l.notes = '' OR to_tsvector('english', l.name) @@ q.query THEN
+		ts_headline('english', replace(replace(replace(l.name, '&', '&amp;'), '<', '&lt;'), '>', '&gt;'), q.query,
+			'StartSel=<mark>, StopSel=</mark>, HighlightAll=true')
+	ELSE
+		ts_headline('english', replace(replace(replace(l.notes, '&', '&amp;'), '<', '&lt;'), '>', '&gt;'), q.query,
+			'StartSel=<mark>, StopSel=</mark>, MaxWords=20, MinWords=5')
+	END AS snippet,
+	ts_rank(l.search, q.query) AS rank
+FROM list l, q
+WHERE l.search @@ q.query
+UNION ALL
+SELECT 'item' AS kind, i.item_id AS id, i.list_id, l.name AS list_name, i.name,
+	CASE WHEN i.notes = '' OR to_tsvector('english', i.name) @@ q.query THEN
+		ts_headline('english', replace(replace(replace(i.name, '&', '&amp;'), '<', '&lt;'), '>', '&gt;'), q.query,
+			'StartSel=<mark>, StopSel=</mark>, HighlightAll=true')
+	ELSE
+		ts_headline('english', replace(replace(replace(i.notes, '&', '&amp;'), '<', '&lt;'), '>', '&gt;'), q.query,
+			'StartSel=<mark>, StopSel=</mark>, MaxWords=20, MinWords=5')
+	END AS snippet,
+	ts_rank(i.search, q.query) AS rank
+FROM item i JOIN list l ON l.list_id = i.list_id, q
+WHERE i.search @@ q.query
+ORDER BY rank DESC, kind, id
+LIMIT $2;`
+)
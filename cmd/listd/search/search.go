@@ -0,0 +1,77 @@
+// Package search finds lists and items by the words in their names and notes using
+// the full-text search vectors kept on the list and item tables.
+//
+// Every list is currently visible to every caller, so a search covers all lists.
+// Once lists can be restricted, the restriction belongs in the search query so
+// that ranking and limits only ever consider visible rows.
+package search
+
+import (
+	"github.com/george-e-shaw-iv/integration-tests-example/internal/platform/db"
+	"github.com/pkg/errors"
+)
+
+// Kinds of rows a hit can refer to.
+const (
+	KindList = "list"
+	KindItem = "item"
+)
+
+// Hit is a single list or item matching a search. Snippet is the HTML escaped
+// name, or an excerpt of the notes when only they match, with every matching word
+// wrapped in a <mark> element.
+type Hit struct {
+	Type    string  `json:"type" db:"kind"`
+	ID      int     `json:"id" db:"id"`
+	Name    string  `json:"name" db:"name"`
+	Snippet string  `json:"snippet" db:"snippet"`
+	Rank    float64 `json:"rank" db:"rank"`
+}
+
+// Result is the hits of a search within a single list, ranked by the best hit.
+type Result struct {
+	ListID   int     `json:"listID"`
+	ListName string  `json:"listName"`
+	Rank     float64 `json:"rank"`
+	Hits     []Hit   `json:"hits"`
+}
+
+// row is a hit along with the list it belongs to, as returned by the search query.
+type row struct {
+	Hit
+	ListID   int    `db:"list_id"`
+	ListName string `db:"list_name"`
+}
+
+// Search returns up to limit hits matching query grouped by list. The lists are
+// ordered by their best hit and the hits within a list by rank. The query uses
+// web search syntax, supporting quoted phrases, "or" and a leading - to exclude
+// a word.
+func Search(dbc db.Executor, query string, limit int) ([]Result, error) {
+	var rows []row
+	if err := dbc.Select(&rows, search, query, limit); err != nil {
+		return nil, errors.Wrap(err, "select rows matching search query")
+	}
+
+	results := make([]Result, 0)
+	index := make(map[int]int)
+
+	for _, r := range rows {
+		i, ok := index[r.ListID]
+		if !ok {
+			i = len(results)
+			index[r.ListID] = i
+
+			// Rows arrive best first, so the first hit of a list has its best rank.
+			results = append(results, Result{
+				ListID:   r.ListID,
+				ListName: r.ListName,
+				Rank:     r.Rank,
+			})
+		}
+
+		results[i].Hits = append(results[i].Hits, r.Hit)
+	}
+
+	return results, nil
+}
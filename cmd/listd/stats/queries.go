@@ -0,0 +1,22 @@
+package stats
+
+// PostgreSQL queries aggregating the list and item tables, all used in the stats
+// package.
+const (
+	// selectCounts is a query that counts the rows in the list and item tables.
+	selectCounts = "SELECT (SELECT count(*) FROM list) AS lists, (SELECT count(*) FROM item) AS items;"
+
+	// selectCreatedPerDay is a query that counts the rows in the item table created
+	// on each of the last $1 days, today included, oldest first. Days without any
+	// items created are counted as 0.
+	selectCreatedPerDay = `SELECT (current_date - d.n)::text AS day, count(i.item_id) AS items
+		FROM generate_series(0, $1::int - 1) AS d(n)
+		LEFT JOIN item i ON i.created >= current_date - d.n AND i.created < current_date - d.n + 1
+		GROUP BY d.n ORDER BY d.n DESC;`
+
+	// selectTopNames is a query that selects the $1 most used names of rows in the
+	// item table, lower cased with white space collapsed, along with how many rows
+	// carry each, most used first.
+	selectTopNames = `SELECT btrim(regexp_replace(lower(name), '\s+', ' ', 'g')) AS name, count(*) AS count
+		FROM item GROUP BY 1 ORDER BY count DESC, name LIMIT $1;`
+)
@@ -0,0 +1,162 @@
+// Package stats computes aggregate statistics over every list and item. The
+// statistics are computed with aggregate queries rather than by loading the rows,
+// and are cached for a configurable interval as they are costly to compute over
+// large tables.
+package stats
+
+import (
+	"sync"
+	"time"
+
+	"github.com/george-e-shaw-iv/integration-tests-example/internal/platform/db"
+	"github.com/pkg/errors"
+)
+
+// Options are the parameters of the statistics. Days is the number of days, today
+// included, items created are counted for. Top is the number of most used item
+// names returned.
+type Options struct {
+	Days int
+	Top  int
+}
+
+// Stats are aggregate statistics over every list and item.
+type Stats struct {
+	Lists int `json:"lists"`
+	Items int `json:"items"`
+
+	// AverageListSize is the average number of items on a list, sub-items
+	// included, 0 when there are no lists.
+	AverageListSize float64 `json:"averageListSize"`
+
+	CreatedPerDay []DayCount  `json:"createdPerDay"`
+	TopNames      []NameCount `json:"topNames"`
+
+	// Generated is when the statistics were computed, they may be served from the
+	// cache until Expires.
+	Generated time.Time `json:"generated"`
+	Expires   time.Time `json:"expires"`
+}
+
+// DayCount is the number of items created on a day, given as YYYY-MM-DD.
+type DayCount struct {
+	Day   string `json:"day" db:"day"`
+	Items int    `json:"items" db:"items"`
+}
+
+// NameCount is the number of items carrying the same name once lower cased.
+type NameCount struct {
+	Name  string `json:"name" db:"name"`
+	Count int    `json:"count" db:"count"`
+}
+
+// Compute computes the statistics given by o.
+func Compute(dbc db.Executor, o Options) (Stats, error) {
+	var s Stats
+
+	if err := dbc.QueryRowx(selectCounts).Scan(&s.Lists, &s.Items); err != nil {
+		return Stats{}, errors.Wrap(err, "count list and item rows")
+	}
+
+	if s.Lists > 0 {
+		s.AverageListSize = float64(s.Items) / float64(s.Lists)
+	}
+
+	s.CreatedPerDay = make([]DayCount, 0, o.Days)
+	if err := dbc.Select(&s.CreatedPerDay, selectCreatedPerDay, o.Days); err != nil {
+		return Stats{}, errors.Wrap(err, "count item rows created per day")
+	}
+
+	s.TopNames = make([]NameCount, 0, o.Top)
+	if err := dbc.Select(&s.TopNames, selectTopNames, o.Top); err != nil {
+		return Stats{}, errors.Wrap(err, "select most used item names")
+	}
+
+	s.Generated = time.Now()
+	s.Expires = s.Generated
+
+	return s, nil
+}
+
+// Cache caches statistics for TTL after they are computed. Statistics are computed
+// on every call when TTL is 0. Callers asking for the same statistics while they
+// are being computed wait for that computation rather than starting their own, so
+// that expiring statistics requested by many callers at once are only computed
+// once, without holding up callers asking for other statistics.
+type Cache struct {
+	DB  db.Executor
+	TTL time.Duration
+
+	mu      sync.Mutex
+	entries map[Options]Stats
+	calls   map[Options]*call
+}
+
+// call is a computation of statistics in progress.
+type call struct {
+	done  chan struct{}
+	stats Stats
+	err   error
+}
+
+// Get returns the statistics given by o, computing them if they aren't cached or
+// have expired.
+func (c *Cache) Get(o Options) (Stats, error) {
+	c.mu.Lock()
+
+	if s, ok := c.entries[o]; ok && time.Now().Before(s.Expires) {
+		c.mu.Unlock()
+		return s, nil
+	}
+
+	if cl, ok := c.calls[o]; ok {
+		c.mu.Unlock()
+		<-cl.done
+		return cl.stats, cl.err
+	}
+
+	cl := &call{done: make(chan struct{})}
+	if c.calls == nil {
+		c.calls = make(map[Options]*call)
+	}
+	c.calls[o] = cl
+
+	c.mu.Unlock()
+
+	c.compute(o, cl)
+	return cl.stats, cl.err
+}
+
+// compute computes the statistics given by o for cl, caching them unless TTL is 0.
+func (c *Cache) compute(o Options, cl *call) {
+	defer func() {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+
+		delete(c.calls, o)
+		close(cl.done)
+
+		if cl.err != nil || c.TTL <= 0 {
+			return
+		}
+
+		if c.entries == nil {
+			c.entries = make(map[Options]Stats)
+		}
+
+		// Expired entries are dropped as new ones are added, so the cache never
+		// holds more than the options requested within the last TTL.
+		now := time.Now()
+		for k, e := range c.entries {
+			if !now.Before(e.Expires) {
+				delete(c.entries, k)
+			}
+		}
+		c.entries[o] = cl.stats
+	}()
+
+	cl.stats, cl.err = Compute(c.DB, o)
+	if cl.err == nil && c.TTL > 0 {
+		cl.stats.Expires = cl.stats.Generated.Add(c.TTL)
+	}
+}
@@ -0,0 +1,9 @@
+package tag
+
+// PostgreSQL queries for the tag table.
+const (
+	// selectAll is a query that selects the name of every tag carried by at least
+	// one item along with the number of items carrying it.
+	selectAll = `SELECT t.name, count(*) AS count FROM tag t JOIN item_tag it ON it.tag_id = t.tag_id
+		GROUP BY t.name ORDER BY t.name;`
+)
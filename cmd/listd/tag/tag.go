@@ -0,0 +1,24 @@
+package tag
+
+import (
+	"github.com/george-e-shaw-iv/integration-tests-example/internal/platform/db"
+	"github.com/pkg/errors"
+)
+
+// Tag is a type that contains the proper struct tags for both a JSON and Postgres
+// representation of a tag along with the number of items carrying it.
+type Tag struct {
+	Name  string `json:"name" db:"name"`
+	Count int    `json:"count" db:"count"`
+}
+
+// SelectTags selects every tag carried by at least one item, in alphabetical order.
+func SelectTags(dbc db.Executor) ([]Tag, error) {
+	tags := make([]Tag, 0)
+
+	if err := dbc.Select(&tags, selectAll); err != nil {
+		return nil, errors.Wrap(err, "select all rows from tag table with usage counts")
+	}
+
+	return tags, nil
+}
@@ -0,0 +1,329 @@
+package tests
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/george-e-shaw-iv/integration-tests-example/cmd/listd/archive"
+	"github.com/george-e-shaw-iv/integration-tests-example/cmd/listd/comment"
+	"github.com/george-e-shaw-iv/integration-tests-example/cmd/listd/folder"
+	"github.com/george-e-shaw-iv/integration-tests-example/cmd/listd/item"
+	"github.com/george-e-shaw-iv/integration-tests-example/cmd/listd/list"
+	"github.com/george-e-shaw-iv/integration-tests-example/internal/platform/testdb"
+	"github.com/george-e-shaw-iv/integration-tests-example/internal/platform/units"
+	"github.com/google/go-cmp/cmp"
+)
+
+// exportArchive exports the archive of everything kept by the list daemon.
+func exportArchive(t *testing.T) archive.Archive {
+	req, err := http.NewRequest(http.MethodGet, "/export", nil)
+	if err != nil {
+		t.Fatalf("error creating request: %v", err)
+	}
+
+	w := httptest.NewRecorder()
+	a.ServeHTTP(w, req)
+
+	if e, a := http.StatusOK, w.Code; e != a {
+		t.Fatalf("expected status code: %v, got status code: %v", e, a)
+	}
+
+	var ar archive.Archive
+	if err := json.NewDecoder(w.Body).Decode(&ar); err != nil {
+		t.Fatalf("error decoding archive: %v", err)
+	}
+
+	return ar
+}
+
+// importArchive imports an archive with the given strategy and decodes the report
+// into report, returning the status code.
+func importArchive(t *testing.T, ar archive.Archive, strategy string, report *archive.Report) int {
+	return sendJSON(t, http.MethodPost, "/import?onConflict="+strategy, ar, report)
+}
+
+// archivedList returns the list named name in an archive.
+func archivedList(t *testing.T, ar archive.Archive, name string) archive.List {
+	for _, l := range ar.Lists {
+		if l.Name == name {
+			return l
+		}
+	}
+
+	t.Fatalf("expected list %q to be archived", name)
+	return archive.List{}
+}
+
+func Test_archive(t *testing.T) {
+	defer func() {
+		if err := testdb.Truncate(a.DB); err != nil {
+			t.Errorf("error truncating test database tables: %v", err)
+		}
+	}()
+
+	expectedLists, err := testdb.SeedLists(a.DB)
+	if err != nil {
+		t.Fatalf("error seeding lists: %v", err)
+	}
+
+	var kitchen folder.Folder
+	if e, a := http.StatusCreated, sendJSON(t, http.MethodPost, "/folder", folder.Folder{Name: "Kitchen"}, &kitchen); e != a {
+		t.Fatalf("expected status code: %v, got status code: %v", e, a)
+	}
+
+	if e, a := http.StatusOK, sendJSON(t, http.MethodPost, fmt.Sprintf("/list/%d/move", expectedLists[0].ID), map[string]interface{}{"folderID": kitchen.ID}, nil); e != a {
+		t.Fatalf("expected status code: %v, got status code: %v", e, a)
+	}
+
+	url := fmt.Sprintf("/list/%d/item", expectedLists[0].ID)
+
+	code, milk := sendItem(t, http.MethodPost, url, item.Item{Name: "Milk", Quantity: units.Int(2), Price: price(129), Currency: "USD"})
+	if e, a := http.StatusCreated, code; e != a {
+		t.Fatalf("expected status code: %v, got status code: %v", e, a)
+	}
+
+	code, _ = sendItem(t, http.MethodPost, url, item.Item{Name: "Oat", Quantity: units.Int(1), ParentID: &milk.ID})
+	if e, a := http.StatusCreated, code; e != a {
+		t.Fatalf("expected status code: %v, got status code: %v", e, a)
+	}
+
+	if e, a := http.StatusOK, sendJSON(t, http.MethodPut, fmt.Sprintf("%s/%d/tag/dairy", url, milk.ID), nil, nil); e != a {
+		t.Fatalf("expected status code: %v, got status code: %v", e, a)
+	}
+
+	if e, a := http.StatusCreated, sendAs(t, "alice", http.MethodPost, fmt.Sprintf("%s/%d/comment", url, milk.ID), comment.Comment{Body: "Whole, @bob"}, nil); e != a {
+		t.Fatalf("expected status code: %v, got status code: %v", e, a)
+	}
+
+	ar := exportArchive(t)
+
+	if e, a := archive.Version, ar.Version; e != a {
+		t.Errorf("expected archive version: %v, got archive version: %v", e, a)
+	}
+
+	if e, a := len(expectedLists), len(ar.Lists); e != a {
+		t.Fatalf("expected %v archived lists, got %v", e, a)
+	}
+
+	if e, a := 1, len(ar.Folders); e != a {
+		t.Fatalf("expected %v archived folders, got %v", e, a)
+	}
+
+	grocery := archivedList(t, ar, expectedLists[0].Name)
+	if grocery.FolderID == nil || *grocery.FolderID != kitchen.ID {
+		t.Errorf("expected archived list to be in folder %v, got folder: %v", kitchen.ID, grocery.FolderID)
+	}
+
+	if e, a := 2, len(grocery.Items); e != a {
+		t.Fatalf("expected %v archived items, got %v", e, a)
+	}
+
+	// Skipping leaves every existing list as it is.
+	var report archive.Report
+	if e, a := http.StatusOK, importArchive(t, ar, "skip", &report); e != a {
+		t.Fatalf("expected status code: %v, got status code: %v", e, a)
+	}
+
+	if e, a := 0, report.Folders; e != a {
+		t.Errorf("expected %v created folders, got %v", e, a)
+	}
+
+	for _, res := range report.Lists {
+		if res.Status != archive.StatusSkipped {
+			t.Errorf("expected list %q to be skipped, got status: %v", res.Name, res.Status)
+		}
+	}
+
+	// Renaming imports copies of every list, with their items, tags and comments.
+	report = archive.Report{}
+	if e, a := http.StatusOK, importArchive(t, ar, "rename", &report); e != a {
+		t.Fatalf("expected status code: %v, got status code: %v", e, a)
+	}
+
+	var copied archive.Result
+	for _, res := range report.Lists {
+		if res.Status != archive.StatusRenamed {
+			t.Errorf("expected list %q to be renamed, got status: %v", res.Name, res.Status)
+		}
+
+		if res.ArchiveID == grocery.ID {
+			copied = res
+		}
+	}
+
+	if e, a := expectedLists[0].Name+" (2)", copied.Name; e != a {
+		t.Errorf("expected list name: %v, got list name: %v", e, a)
+	}
+
+	if e, a := 2, copied.Items; e != a {
+		t.Errorf("expected %v imported items, got %v", e, a)
+	}
+
+	var l list.List
+	if e, a := http.StatusOK, sendJSON(t, http.MethodGet, fmt.Sprintf("/list/%d", copied.ID), nil, &l); e != a {
+		t.Fatalf("expected status code: %v, got status code: %v", e, a)
+	}
+
+	if l.FolderID == nil || *l.FolderID != kitchen.ID {
+		t.Errorf("expected imported list to reuse folder %v, got folder: %v", kitchen.ID, l.FolderID)
+	}
+
+	tree := getTree(t, copied.ID)
+	if e, a := 1, len(tree); e != a {
+		t.Fatalf("expected %v top level items, got %v", e, a)
+	}
+
+	copiedMilk := tree[0]
+	if copiedMilk.ID == milk.ID || copiedMilk.Name != "Milk" || copiedMilk.Price == nil || *copiedMilk.Price != 129 {
+		t.Errorf("unexpected imported item: %+v", copiedMilk)
+	}
+
+	if e, a := 1, len(copiedMilk.Children); e != a || copiedMilk.Children[0].Name != "Oat" {
+		t.Errorf("expected sub-item Oat to be imported under Milk, got: %+v", copiedMilk.Children)
+	}
+
+	if d := cmp.Diff(item.Tags{"dairy"}, copiedMilk.Tags); d != "" {
+		t.Errorf("tags differ from expected:\n%s", d)
+	}
+
+	var comments []comment.Comment
+	if e, a := http.StatusOK, sendJSON(t, http.MethodGet, fmt.Sprintf("/list/%d/item/%d/comment", copied.ID, copiedMilk.ID), nil, &comments); e != a {
+		t.Fatalf("expected status code: %v, got status code: %v", e, a)
+	}
+
+	if e, a := 1, len(comments); e != a || comments[0].Author != "alice" || comments[0].Body != "Whole, @bob" {
+		t.Errorf("unexpected imported comments: %+v", comments)
+	}
+
+	// Overwriting replaces the items of the existing list, which keeps its id.
+	for i := range ar.Lists {
+		if ar.Lists[i].ID == grocery.ID {
+			ar.Lists[i].Items = ar.Lists[i].Items[:0]
+			ar.Lists[i].Notes = "Restored"
+		}
+	}
+
+	report = archive.Report{}
+	if e, a := http.StatusOK, importArchive(t, ar, "overwrite", &report); e != a {
+		t.Fatalf("expected status code: %v, got status code: %v", e, a)
+	}
+
+	for _, res := range report.Lists {
+		if res.ArchiveID == grocery.ID && (res.Status != archive.StatusOverwritten || res.ID != expectedLists[0].ID) {
+			t.Errorf("expected list %v to be overwritten in place, got: %+v", expectedLists[0].ID, res)
+		}
+	}
+
+	var items []item.Item
+	if e, a := http.StatusOK, sendJSON(t, http.MethodGet, url, nil, &items); e != a {
+		t.Fatalf("expected status code: %v, got status code: %v", e, a)
+	}
+
+	if e, a := 0, len(items); e != a {
+		t.Errorf("expected %v items after overwriting, got %v", e, a)
+	}
+
+	l = list.List{}
+	if e, a := http.StatusOK, sendJSON(t, http.MethodGet, fmt.Sprintf("/list/%d", expectedLists[0].ID), nil, &l); e != a {
+		t.Fatalf("expected status code: %v, got status code: %v", e, a)
+	}
+
+	if e, a := "Restored", l.Notes; e != a {
+		t.Errorf("expected notes: %v, got notes: %v", e, a)
+	}
+}
+
+func Test_archiveInvalid(t *testing.T) {
+	defer func() {
+		if err := testdb.Truncate(a.DB); err != nil {
+			t.Errorf("error truncating test database tables: %v", err)
+		}
+	}()
+
+	one, two, missing := 1, 2, 99
+
+	valid := func() archive.Archive {
+		return archive.Archive{
+			Version: archive.Version,
+			Folders: []folder.Folder{{ID: 1, Name: "Home"}},
+			Lists: []archive.List{
+				{
+					List: list.List{ID: 1, Name: "Grocery", FolderID: &one},
+					Items: []archive.Item{
+						{Item: item.Item{ID: 2, Name: "Oat", Quantity: units.Int(1), ParentID: &one}},
+						{Item: item.Item{ID: 1, Name: "Milk", Quantity: units.Int(1)}},
+					},
+				},
+			},
+		}
+	}
+
+	tests := []struct {
+		Name     string
+		Strategy string
+		Change   func(ar *archive.Archive)
+	}{
+		{Name: "Version", Strategy: "skip", Change: func(ar *archive.Archive) { ar.Version = archive.Version + 1 }},
+		{Name: "Strategy", Strategy: "merge", Change: func(ar *archive.Archive) {}},
+		{Name: "MissingFolder", Strategy: "skip", Change: func(ar *archive.Archive) { ar.Lists[0].FolderID = &missing }},
+		{Name: "MissingParent", Strategy: "skip", Change: func(ar *archive.Archive) { ar.Lists[0].Items[0].ParentID = &missing }},
+		{Name: "Cycle", Strategy: "skip", Change: func(ar *archive.Archive) { ar.Lists[0].Items[1].ParentID = &two }},
+		{Name: "DuplicateName", Strategy: "skip", Change: func(ar *archive.Archive) { ar.Lists = append(ar.Lists, ar.Lists[0]) }},
+		{Name: "InvalidItem", Strategy: "skip", Change: func(ar *archive.Archive) { ar.Lists[0].Items[1].Quantity = units.Int(0) }},
+		{Name: "InvalidAuthor", Strategy: "skip", Change: func(ar *archive.Archive) {
+			ar.Lists[0].Items[1].Comments = []comment.Comment{{Author: "not a user", Body: "Hi"}}
+		}},
+	}
+
+	for _, test := range tests {
+		fn := func(t *testing.T) {
+			ar := valid()
+			test.Change(&ar)
+
+			if e, a := http.StatusBadRequest, importArchive(t, ar, test.Strategy, nil); e != a {
+				t.Errorf("expected status code: %v, got status code: %v", e, a)
+			}
+		}
+
+		t.Run(test.Name, fn)
+	}
+
+	var lists []list.List
+	if e, a := http.StatusOK, sendJSON(t, http.MethodGet, "/list", nil, &lists); e != a {
+		t.Fatalf("expected status code: %v, got status code: %v", e, a)
+	}
+
+	if e, a := 0, len(lists); e != a {
+		t.Errorf("expected nothing to be imported from invalid archives, got %v lists", a)
+	}
+
+	// Items are imported parents first whatever their order in the archive.
+	var report archive.Report
+	if e, a := http.StatusOK, importArchive(t, valid(), "skip", &report); e != a {
+		t.Fatalf("expected status code: %v, got status code: %v", e, a)
+	}
+
+	if e, a := 1, report.Folders; e != a {
+		t.Errorf("expected %v created folders, got %v", e, a)
+	}
+
+	if len(report.Lists) != 1 || report.Lists[0].Items != 2 {
+		t.Errorf("unexpected import report: %+v", report)
+	}
+
+	req, err := http.NewRequest(http.MethodPost, "/import", bytes.NewBufferString("{"))
+	if err != nil {
+		t.Fatalf("error creating request: %v", err)
+	}
+
+	w := httptest.NewRecorder()
+	a.ServeHTTP(w, req)
+
+	if e, a := http.StatusBadRequest, w.Code; e != a {
+		t.Errorf("expected status code: %v, got status code: %v", e, a)
+	}
+}
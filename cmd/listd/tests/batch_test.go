@@ -0,0 +1,159 @@
+package tests
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/george-e-shaw-iv/integration-tests-example/cmd/listd/item"
+	"github.com/george-e-shaw-iv/integration-tests-example/cmd/listd/list"
+	"github.com/george-e-shaw-iv/integration-tests-example/internal/platform/testdb"
+	"github.com/george-e-shaw-iv/integration-tests-example/internal/platform/units"
+	"github.com/george-e-shaw-iv/integration-tests-example/internal/platform/web"
+)
+
+// batchResult mirrors the result of a single batch operation.
+type batchResult struct {
+	Status int    `json:"status"`
+	ID     int    `json:"id"`
+	Error  string `json:"error"`
+}
+
+func Test_batch(t *testing.T) {
+	defer func() {
+		if err := testdb.Truncate(a.DB); err != nil {
+			t.Errorf("error truncating test database tables: %v", err)
+		}
+	}()
+
+	expectedLists, err := testdb.SeedLists(a.DB)
+	if err != nil {
+		t.Fatalf("error seeding lists: %v", err)
+	}
+
+	expectedItems, err := testdb.SeedItems(a.DB, expectedLists)
+	if err != nil {
+		t.Fatalf("error seeding items: %v", err)
+	}
+
+	tests := []struct {
+		Name             string
+		RequestBody      string
+		ExpectedCode     int
+		ExpectedStatuses []int
+		ExpectedLists    int
+	}{
+		{
+			Name: "OK",
+			RequestBody: fmt.Sprintf(`{"operations": [
+				{"op": "create", "type": "list", "ref": "hw", "body": {"name": "Hardware"}},
+				{"op": "create", "type": "item", "ref": "nails", "listID": "hw", "body": {"name": "Nails", "quantity": 100}},
+				{"op": "update", "type": "item", "id": "nails", "listID": "hw", "body": {"name": "Screws", "quantity": 50}},
+				{"op": "delete", "type": "item", "id": %d, "listID": %d}
+			]}`, expectedItems[0].ID, expectedLists[0].ID),
+			ExpectedCode:     http.StatusOK,
+			ExpectedStatuses: []int{http.StatusCreated, http.StatusCreated, http.StatusOK, http.StatusNoContent},
+			ExpectedLists:    4,
+		},
+		{
+			Name: "AtomicRollback",
+			RequestBody: `{"operations": [
+				{"op": "create", "type": "list", "ref": "garden", "body": {"name": "Garden"}},
+				{"op": "create", "type": "item", "listID": "garden", "body": {"name": "Seeds", "quantity": 0}}
+			]}`,
+			ExpectedCode:     http.StatusBadRequest,
+			ExpectedStatuses: []int{http.StatusFailedDependency, http.StatusBadRequest},
+			ExpectedLists:    4,
+		},
+		{
+			Name: "UnknownRef",
+			RequestBody: `{"operations": [
+				{"op": "create", "type": "item", "listID": "nope", "body": {"name": "Seeds", "quantity": 1}}
+			]}`,
+			ExpectedCode:     http.StatusBadRequest,
+			ExpectedStatuses: []int{http.StatusBadRequest},
+			ExpectedLists:    4,
+		},
+		{
+			Name: "NonAtomic",
+			RequestBody: `{"atomic": false, "operations": [
+				{"op": "create", "type": "list", "ref": "garden", "body": {"name": "Garden"}},
+				{"op": "delete", "type": "list", "id": 0},
+				{"op": "create", "type": "item", "listID": "garden", "body": {"name": "Seeds", "quantity": 1}}
+			]}`,
+			ExpectedCode:     http.StatusOK,
+			ExpectedStatuses: []int{http.StatusCreated, http.StatusBadRequest, http.StatusCreated},
+			ExpectedLists:    5,
+		},
+	}
+
+	for _, test := range tests {
+		fn := func(t *testing.T) {
+			req, err := http.NewRequest(http.MethodPost, "/batch", strings.NewReader(test.RequestBody))
+			if err != nil {
+				t.Errorf("error creating request: %v", err)
+			}
+
+			w := httptest.NewRecorder()
+			a.ServeHTTP(w, req)
+
+			if e, a := test.ExpectedCode, w.Code; e != a {
+				t.Errorf("expected status code: %v, got status code: %v", e, a)
+			}
+
+			var results []batchResult
+			resp := web.Response{
+				Results: &results,
+			}
+
+			if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+				t.Errorf("error decoding response body: %v", err)
+			}
+
+			if e, a := len(test.ExpectedStatuses), len(results); e != a {
+				t.Fatalf("expected %v results, got %v", e, a)
+			}
+
+			for i := range results {
+				if e, a := test.ExpectedStatuses[i], results[i].Status; e != a {
+					t.Errorf("expected status of operation %d: %v, got: %v (%s)", i, e, a, results[i].Error)
+				}
+			}
+
+			lists, err := list.SelectLists(a.DB)
+			if err != nil {
+				t.Fatalf("error selecting lists: %v", err)
+			}
+
+			if e, a := test.ExpectedLists, len(lists); e != a {
+				t.Errorf("expected %v lists, got %v", e, a)
+			}
+		}
+
+		t.Run(test.Name, fn)
+	}
+
+	// The item created in the first batch must have been updated by the same batch.
+	lists, err := list.SelectLists(a.DB)
+	if err != nil {
+		t.Fatalf("error selecting lists: %v", err)
+	}
+
+	for _, l := range lists {
+		if l.Name != "Hardware" {
+			continue
+		}
+
+		items, err := item.SelectItems(a.DB, l.ID)
+		if err != nil {
+			t.Fatalf("error selecting items: %v", err)
+		}
+
+		if len(items) != 1 || items[0].Name != "Screws" || !items[0].Quantity.Equal(units.Int(50)) {
+			t.Errorf("expected a single updated item, got %+v", items)
+		}
+	}
+}
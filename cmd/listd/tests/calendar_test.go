@@ -0,0 +1,240 @@
+package tests
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/george-e-shaw-iv/integration-tests-example/cmd/listd/calendar"
+	"github.com/george-e-shaw-iv/integration-tests-example/cmd/listd/item"
+	"github.com/george-e-shaw-iv/integration-tests-example/cmd/listd/list"
+	"github.com/george-e-shaw-iv/integration-tests-example/internal/platform/testdb"
+	"github.com/george-e-shaw-iv/integration-tests-example/internal/platform/units"
+	"github.com/google/go-cmp/cmp"
+)
+
+// getCalendar gets the calendar feed of a list with the given token and URL query,
+// and returns the status code along with the unfolded content lines.
+func getCalendar(t *testing.T, listID int, token, query string) (int, []string) {
+	req, err := http.NewRequest(http.MethodGet, fmt.Sprintf("/list/%d/calendar.ics?token=%s&%s", listID, token, query), nil)
+	if err != nil {
+		t.Fatalf("error creating request: %v", err)
+	}
+
+	w := httptest.NewRecorder()
+	a.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		return w.Code, nil
+	}
+
+	if e, a := "text/calendar; charset=utf-8", w.Header().Get("Content-Type"); e != a {
+		t.Errorf("expected content type: %v, got content type: %v", e, a)
+	}
+
+	return w.Code, unfold(w.Body.String())
+}
+
+// unfold returns the content lines of an iCalendar feed, joining folded lines.
+func unfold(ics string) []string {
+	return strings.Split(strings.TrimSuffix(strings.Replace(ics, "\r\n ", "", -1), "\r\n"), "\r\n")
+}
+
+// component returns the content lines of the component with the given UID.
+func component(lines []string, uid string) []string {
+	var found []string
+	for i, l := range lines {
+		if l == "UID:"+uid {
+			for _, l := range lines[i:] {
+				if strings.HasPrefix(l, "END:") {
+					break
+				}
+				found = append(found, l)
+			}
+		}
+	}
+
+	return found
+}
+
+// hasLine reports whether lines holds line.
+func hasLine(lines []string, line string) bool {
+	for _, l := range lines {
+		if l == line {
+			return true
+		}
+	}
+
+	return false
+}
+
+func Test_calendarWrite(t *testing.T) {
+	due := time.Date(2009, 11, 12, 0, 0, 0, 0, time.UTC)
+
+	items := []item.Item{
+		{ID: 1, Name: strings.Repeat("Chocolate Milk, Semi-Skimmed; ", 5) + "Ünïcödé", Quantity: units.Int(2), Notes: "Line one\nline two", DueAt: &due, Tags: item.Tags{"dairy", "a,b"}},
+		{ID: 2, Name: "Without due date", Quantity: units.Int(1)},
+	}
+
+	var b bytes.Buffer
+	if err := calendar.Write(&b, list.List{Name: "Grocery"}, items, calendar.ComponentEvent); err != nil {
+		t.Fatalf("error writing calendar: %v", err)
+	}
+
+	for _, l := range strings.Split(strings.TrimSuffix(b.String(), "\r\n"), "\r\n") {
+		if len(l) > 75 {
+			t.Errorf("expected lines of at most 75 bytes, got %d bytes: %q", len(l), l)
+		}
+
+		if !strings.HasPrefix(l, " ") && strings.Contains(l, "\n") {
+			t.Errorf("expected lines ending with CRLF, got: %q", l)
+		}
+	}
+
+	lines := unfold(b.String())
+
+	expected := []string{
+		"UID:" + calendar.UID(1),
+		"DTSTAMP:00010101T000000Z",
+		"CREATED:00010101T000000Z",
+		"LAST-MODIFIED:00010101T000000Z",
+		`SUMMARY:` + strings.Repeat(`Chocolate Milk\, Semi-Skimmed\; `, 5) + "Ünïcödé",
+		`DESCRIPTION:Quantity: 2\n\nLine one\nline two`,
+		`CATEGORIES:dairy,a\,b`,
+		"DTSTART;VALUE=DATE:20091112",
+		"DTEND;VALUE=DATE:20091113",
+		"TRANSP:TRANSPARENT",
+	}
+
+	if d := cmp.Diff(expected, component(lines, calendar.UID(1))); d != "" {
+		t.Errorf("event differs from expected:\n%s", d)
+	}
+
+	if c := component(lines, calendar.UID(2)); c != nil {
+		t.Errorf("expected item without due date to be left out, got: %q", c)
+	}
+
+	if e, a := "BEGIN:VCALENDAR", lines[0]; e != a {
+		t.Errorf("expected first line: %q, got first line: %q", e, a)
+	}
+
+	if !hasLine(lines, "X-WR-CALNAME:Grocery") {
+		t.Errorf("expected calendar to be named after the list, got: %q", lines)
+	}
+}
+
+func Test_calendar(t *testing.T) {
+	defer func() {
+		if err := testdb.Truncate(a.DB); err != nil {
+			t.Errorf("error truncating test database tables: %v", err)
+		}
+	}()
+
+	expectedLists, err := testdb.SeedLists(a.DB)
+	if err != nil {
+		t.Fatalf("error seeding lists: %v", err)
+	}
+
+	listID := expectedLists[0].ID
+	url := fmt.Sprintf("/list/%d/item", listID)
+
+	dueDate := time.Date(2009, 11, 12, 0, 0, 0, 0, time.UTC)
+	dueTime := time.Date(2009, 11, 12, 17, 30, 0, 0, time.UTC)
+	completed := time.Date(2009, 11, 11, 9, 0, 0, 0, time.UTC)
+
+	code, milk := sendItem(t, http.MethodPost, url, item.Item{Name: "Milk", Quantity: units.Int(1), DueAt: &dueDate})
+	if e, a := http.StatusCreated, code; e != a {
+		t.Fatalf("expected status code: %v, got status code: %v", e, a)
+	}
+
+	code, flour := sendItem(t, http.MethodPost, url, item.Item{Name: "Flour", Quantity: units.Int(1), DueAt: &dueTime, CompletedAt: &completed, ParentID: &milk.ID})
+	if e, a := http.StatusCreated, code; e != a {
+		t.Fatalf("expected status code: %v, got status code: %v", e, a)
+	}
+
+	code, eggs := sendItem(t, http.MethodPost, url, item.Item{Name: "Eggs", Quantity: units.Int(12)})
+	if e, a := http.StatusCreated, code; e != a {
+		t.Fatalf("expected status code: %v, got status code: %v", e, a)
+	}
+
+	if code, _ := getCalendar(t, listID, "", ""); code != http.StatusUnauthorized {
+		t.Errorf("expected status code: %v, got status code: %v", http.StatusUnauthorized, code)
+	}
+
+	var token calendar.Token
+	if e, a := http.StatusCreated, sendJSON(t, http.MethodPost, fmt.Sprintf("/list/%d/calendar/token", listID), nil, &token); e != a {
+		t.Fatalf("expected status code: %v, got status code: %v", e, a)
+	}
+
+	code, lines := getCalendar(t, listID, token.Token, "")
+	if e, a := http.StatusOK, code; e != a {
+		t.Fatalf("expected status code: %v, got status code: %v", e, a)
+	}
+
+	if c := component(lines, calendar.UID(eggs.ID)); c != nil {
+		t.Errorf("expected item without due date to be left out, got: %q", c)
+	}
+
+	if c := component(lines, calendar.UID(milk.ID)); !hasLine(c, "DTSTART;VALUE=DATE:20091112") || !hasLine(c, "SUMMARY:Milk") {
+		t.Errorf("unexpected event for milk: %q", c)
+	}
+
+	if c := component(lines, calendar.UID(flour.ID)); !hasLine(c, "DTSTART:20091112T173000Z") || !hasLine(c, "SUMMARY:✓ Flour") {
+		t.Errorf("unexpected event for flour: %q", c)
+	}
+
+	code, lines = getCalendar(t, listID, token.Token, "component=vtodo")
+	if e, a := http.StatusOK, code; e != a {
+		t.Fatalf("expected status code: %v, got status code: %v", e, a)
+	}
+
+	if c := component(lines, calendar.UID(milk.ID)); !hasLine(c, "DUE;VALUE=DATE:20091112") || !hasLine(c, "STATUS:NEEDS-ACTION") {
+		t.Errorf("unexpected to-do for milk: %q", c)
+	}
+
+	expected := []string{"DUE:20091112T173000Z", "RELATED-TO:" + calendar.UID(milk.ID), "STATUS:COMPLETED", "COMPLETED:20091111T090000Z", "PERCENT-COMPLETE:100"}
+	if c := component(lines, calendar.UID(flour.ID)); len(c) < len(expected) || cmp.Diff(expected, c[len(c)-len(expected):]) != "" {
+		t.Errorf("unexpected to-do for flour: %q", c)
+	}
+
+	if code, _ := getCalendar(t, listID, token.Token, "component=VJOURNAL"); code != http.StatusBadRequest {
+		t.Errorf("expected status code: %v, got status code: %v", http.StatusBadRequest, code)
+	}
+
+	if code, _ := getCalendar(t, expectedLists[1].ID, token.Token, ""); code != http.StatusUnauthorized {
+		t.Errorf("expected token of another list to be rejected, got status code: %v", code)
+	}
+
+	var rotated calendar.Token
+	if e, a := http.StatusCreated, sendJSON(t, http.MethodPost, fmt.Sprintf("/list/%d/calendar/token", listID), nil, &rotated); e != a {
+		t.Fatalf("expected status code: %v, got status code: %v", e, a)
+	}
+
+	if code, _ := getCalendar(t, listID, token.Token, ""); code != http.StatusUnauthorized {
+		t.Errorf("expected replaced token to be rejected, got status code: %v", code)
+	}
+
+	if code, _ := getCalendar(t, listID, rotated.Token, ""); code != http.StatusOK {
+		t.Errorf("expected status code: %v, got status code: %v", http.StatusOK, code)
+	}
+
+	if e, a := http.StatusNoContent, sendJSON(t, http.MethodDelete, fmt.Sprintf("/list/%d/calendar/token", listID), nil, nil); e != a {
+		t.Fatalf("expected status code: %v, got status code: %v", e, a)
+	}
+
+	if code, _ := getCalendar(t, listID, rotated.Token, ""); code != http.StatusUnauthorized {
+		t.Errorf("expected deleted token to be rejected, got status code: %v", code)
+	}
+
+	if e, a := http.StatusNotFound, sendJSON(t, http.MethodDelete, fmt.Sprintf("/list/%d/calendar/token", listID), nil, nil); e != a {
+		t.Errorf("expected status code: %v, got status code: %v", e, a)
+	}
+
+	if e, a := http.StatusNotFound, sendJSON(t, http.MethodPost, fmt.Sprintf("/list/%d/calendar/token", listID+100), nil, nil); e != a {
+		t.Errorf("expected status code: %v, got status code: %v", e, a)
+	}
+}
@@ -0,0 +1,189 @@
+package tests
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/george-e-shaw-iv/integration-tests-example/cmd/listd/item"
+	"github.com/george-e-shaw-iv/integration-tests-example/cmd/listd/list"
+	"github.com/george-e-shaw-iv/integration-tests-example/internal/platform/testdb"
+	"github.com/george-e-shaw-iv/integration-tests-example/internal/platform/units"
+	"github.com/george-e-shaw-iv/integration-tests-example/internal/platform/web"
+	"github.com/google/go-cmp/cmp"
+)
+
+// sendCodec makes a request with a body of the given content type, accepting the
+// given media type, and returns the response.
+func sendCodec(t *testing.T, method, url, contentType, accept string, body []byte) *httptest.ResponseRecorder {
+	req, err := http.NewRequest(method, url, bytes.NewReader(body))
+	if err != nil {
+		t.Fatalf("error creating request: %v", err)
+	}
+
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+
+	if accept != "" {
+		req.Header.Set("Accept", accept)
+	}
+
+	w := httptest.NewRecorder()
+	a.ServeHTTP(w, req)
+
+	return w
+}
+
+// lookupCodec returns the codec registered for a media type.
+func lookupCodec(t *testing.T, mediaType string) web.Codec {
+	c, ok := web.Lookup(mediaType)
+	if !ok {
+		t.Fatalf("expected a codec registered for %q", mediaType)
+	}
+
+	return c
+}
+
+func Test_codecRoundTrip(t *testing.T) {
+	due := time.Date(2009, 11, 10, 23, 0, 0, 0, time.UTC)
+	price, parent := int64(-1250), 1
+
+	items := []item.Item{
+		{
+			ID:       2,
+			ListID:   1,
+			Name:     "Flour: \"all purpose\" # 00",
+			Quantity: units.MustParseAmount("1.5"),
+			Unit:     "kg",
+			Price:    &price,
+			Currency: "EUR",
+			Notes:    "- sifted\n- unbleached\n",
+			DueAt:    &due,
+			ParentID: &parent,
+		},
+		{ID: 3, ListID: 1, Name: "yes", Quantity: units.Int(12)},
+		{ID: 4, ListID: 1, Name: "Crème fraîche ✓", Quantity: units.Int(1)},
+	}
+
+	for _, mediaType := range web.MediaTypes() {
+		fn := func(t *testing.T) {
+			c := lookupCodec(t, mediaType)
+
+			b, err := c.Marshal(web.Response{Results: items})
+			if err != nil {
+				t.Fatalf("error marshaling response: %v", err)
+			}
+
+			var decoded []item.Item
+			if err := c.Unmarshal(b, &web.Response{Results: &decoded}); err != nil {
+				t.Fatalf("error unmarshaling response: %v", err)
+			}
+
+			if d := cmp.Diff(items, decoded); d != "" {
+				t.Errorf("unexpected difference in decoded items:\n%v", d)
+			}
+		}
+
+		t.Run(mediaType, fn)
+	}
+}
+
+func Test_codecs(t *testing.T) {
+	defer func() {
+		if err := testdb.Truncate(a.DB); err != nil {
+			t.Errorf("error truncating test database tables: %v", err)
+		}
+	}()
+
+	expectedLists, err := testdb.SeedLists(a.DB)
+	if err != nil {
+		t.Fatalf("error seeding lists: %v", err)
+	}
+	url := fmt.Sprintf("/list/%d", expectedLists[0].ID)
+
+	// Responses are encoded in the negotiated wire format.
+	for _, mediaType := range []string{"application/json", "application/msgpack", "application/yaml", "application/cbor"} {
+		w := sendCodec(t, http.MethodGet, url, "", mediaType, nil)
+
+		if e, a := http.StatusOK, w.Code; e != a {
+			t.Fatalf("expected status code: %v, got status code: %v", e, a)
+		}
+
+		if e, a := mediaType, w.Header().Get("Content-Type"); e != a {
+			t.Errorf("expected content type: %q, got content type: %q", e, a)
+		}
+
+		var l list.List
+		if err := lookupCodec(t, mediaType).Unmarshal(w.Body.Bytes(), &web.Response{Results: &l}); err != nil {
+			t.Fatalf("error decoding %s response body: %v", mediaType, err)
+		}
+
+		if d := cmp.Diff(expectedLists[0], l); d != "" {
+			t.Errorf("unexpected difference in %s response body:\n%v", mediaType, d)
+		}
+	}
+
+	// Requests are decoded using their Content-Type.
+	for _, mediaType := range []string{"application/msgpack", "application/x-yaml", "application/cbor"} {
+		body, err := lookupCodec(t, mediaType).Marshal(list.List{Name: "Sent as " + mediaType})
+		if err != nil {
+			t.Fatalf("error encoding request body: %v", err)
+		}
+
+		w := sendCodec(t, http.MethodPost, "/list", mediaType+"; charset=utf-8", "", body)
+		if e, a := http.StatusCreated, w.Code; e != a {
+			t.Fatalf("expected status code: %v, got status code: %v", e, a)
+		}
+
+		var l list.List
+		if err := json.NewDecoder(w.Body).Decode(&web.Response{Results: &l}); err != nil {
+			t.Fatalf("error decoding response body: %v", err)
+		}
+
+		if e, a := "Sent as "+mediaType, l.Name; e != a {
+			t.Errorf("expected list name: %q, got list name: %q", e, a)
+		}
+	}
+
+	// Unsupported Content-Type
+	{
+		w := sendCodec(t, http.MethodPost, "/list", "text/xml", "", []byte("<list><name>XML</name></list>"))
+		if e, a := http.StatusUnsupportedMediaType, w.Code; e != a {
+			t.Errorf("expected status code: %v, got status code: %v", e, a)
+		}
+	}
+
+	// Not Acceptable
+	{
+		w := sendCodec(t, http.MethodGet, url, "", "image/png", nil)
+		if e, a := http.StatusNotAcceptable, w.Code; e != a {
+			t.Errorf("expected status code: %v, got status code: %v", e, a)
+		}
+
+		if e, a := "Accept", w.Header().Get("Vary"); e != a {
+			t.Errorf("expected vary header: %q, got vary header: %q", e, a)
+		}
+	}
+
+	// Errors fall back to JSON when nothing is acceptable.
+	{
+		w := sendCodec(t, http.MethodGet, "/list/0", "", "image/png", nil)
+		if e, a := http.StatusNotFound, w.Code; e != a {
+			t.Errorf("expected status code: %v, got status code: %v", e, a)
+		}
+
+		var resp web.Response
+		if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+			t.Fatalf("error decoding response body: %v", err)
+		}
+
+		if len(resp.Errors) == 0 {
+			t.Errorf("expected errors in response body")
+		}
+	}
+}
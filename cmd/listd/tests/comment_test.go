@@ -0,0 +1,287 @@
+package tests
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/george-e-shaw-iv/integration-tests-example/cmd/listd/comment"
+	"github.com/george-e-shaw-iv/integration-tests-example/internal/platform/testdb"
+	"github.com/george-e-shaw-iv/integration-tests-example/internal/platform/web"
+	"github.com/google/go-cmp/cmp"
+)
+
+// sendAs makes a request with body on behalf of user and decodes the response into
+// results, returning the status code. No X-User header is sent when user is empty.
+func sendAs(t *testing.T, user, method, url string, body, results interface{}) int {
+	var b bytes.Buffer
+	if err := json.NewEncoder(&b).Encode(body); err != nil {
+		t.Fatalf("error encoding request body: %v", err)
+	}
+
+	req, err := http.NewRequest(method, url, &b)
+	if err != nil {
+		t.Fatalf("error creating request: %v", err)
+	}
+
+	if user != "" {
+		req.Header.Set("X-User", user)
+	}
+
+	w := httptest.NewRecorder()
+	a.ServeHTTP(w, req)
+
+	if results != nil && w.Code < 300 {
+		resp := web.Response{
+			Results: results,
+		}
+
+		if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+			t.Fatalf("error decoding response body: %v", err)
+		}
+	}
+
+	return w.Code
+}
+
+func Test_parseMentions(t *testing.T) {
+	tests := []struct {
+		Name     string
+		Body     string
+		Expected []string
+	}{
+		{
+			Name:     "None",
+			Body:     "Picked these up already",
+			Expected: []string{},
+		},
+		{
+			Name:     "Several",
+			Body:     "@Bob can you grab this? cc @alice, @bob.",
+			Expected: []string{"alice", "bob"},
+		},
+		{
+			Name:     "EmailAddress",
+			Body:     "Send the receipt to bob@example.com",
+			Expected: []string{},
+		},
+		{
+			Name:     "Punctuation",
+			Body:     "(@carol_s) thanks @dave-k!",
+			Expected: []string{"carol_s", "dave-k"},
+		},
+	}
+
+	for _, test := range tests {
+		fn := func(t *testing.T) {
+			if d := cmp.Diff(test.Expected, comment.ParseMentions(test.Body)); d != "" {
+				t.Errorf("mentions differ from expected:\n%s", d)
+			}
+		}
+
+		t.Run(test.Name, fn)
+	}
+}
+
+func Test_comments(t *testing.T) {
+	defer func() {
+		if err := testdb.Truncate(a.DB); err != nil {
+			t.Errorf("error truncating test database tables: %v", err)
+		}
+	}()
+
+	expectedLists, err := testdb.SeedLists(a.DB)
+	if err != nil {
+		t.Fatalf("error seeding lists: %v", err)
+	}
+
+	expectedItems, err := testdb.SeedItems(a.DB, expectedLists)
+	if err != nil {
+		t.Fatalf("error seeding items: %v", err)
+	}
+
+	url := fmt.Sprintf("/list/%d/item/%d/comment", expectedItems[0].ListID, expectedItems[0].ID)
+
+	var c comment.Comment
+	if e, a := http.StatusCreated, sendAs(t, "Alice", http.MethodPost, url, comment.Comment{Body: "Whole or skim, @bob?"}, &c); e != a {
+		t.Fatalf("expected status code: %v, got status code: %v", e, a)
+	}
+
+	if e, a := "alice", c.Author; e != a {
+		t.Errorf("expected author: %v, got author: %v", e, a)
+	}
+
+	if d := cmp.Diff([]string{"bob"}, []string(c.Mentions)); d != "" {
+		t.Errorf("mentions differ from expected:\n%s", d)
+	}
+
+	commentURL := fmt.Sprintf("%s/%d", url, c.ID)
+
+	tests := []struct {
+		Name         string
+		User         string
+		Method       string
+		URL          string
+		RequestBody  interface{}
+		ExpectedCode int
+	}{
+		{
+			Name:         "NoUser",
+			Method:       http.MethodPost,
+			URL:          url,
+			RequestBody:  comment.Comment{Body: "Hello"},
+			ExpectedCode: http.StatusUnauthorized,
+		},
+		{
+			Name:         "InvalidUser",
+			User:         "bob smith",
+			Method:       http.MethodPost,
+			URL:          url,
+			RequestBody:  comment.Comment{Body: "Hello"},
+			ExpectedCode: http.StatusUnauthorized,
+		},
+		{
+			Name:         "EmptyBody",
+			User:         "bob",
+			Method:       http.MethodPost,
+			URL:          url,
+			RequestBody:  comment.Comment{Body: "  "},
+			ExpectedCode: http.StatusBadRequest,
+		},
+		{
+			Name:         "BodyTooLong",
+			User:         "bob",
+			Method:       http.MethodPost,
+			URL:          url,
+			RequestBody:  comment.Comment{Body: strings.Repeat("a", 5001)},
+			ExpectedCode: http.StatusBadRequest,
+		},
+		{
+			Name:         "ItemNotFound",
+			User:         "bob",
+			Method:       http.MethodPost,
+			URL:          fmt.Sprintf("/list/%d/item/%d/comment", expectedItems[0].ListID, expectedItems[len(expectedItems)-1].ID+100),
+			RequestBody:  comment.Comment{Body: "Hello"},
+			ExpectedCode: http.StatusNotFound,
+		},
+		{
+			Name:         "UpdateByOtherUser",
+			User:         "bob",
+			Method:       http.MethodPut,
+			URL:          commentURL,
+			RequestBody:  comment.Comment{Body: "Skim"},
+			ExpectedCode: http.StatusForbidden,
+		},
+		{
+			Name:         "DeleteByOtherUser",
+			User:         "bob",
+			Method:       http.MethodDelete,
+			URL:          commentURL,
+			ExpectedCode: http.StatusForbidden,
+		},
+		{
+			Name:         "ThroughOtherItem",
+			User:         "alice",
+			Method:       http.MethodDelete,
+			URL:          fmt.Sprintf("/list/%d/item/%d/comment/%d", expectedItems[1].ListID, expectedItems[1].ID, c.ID),
+			ExpectedCode: http.StatusNotFound,
+		},
+	}
+
+	for _, test := range tests {
+		fn := func(t *testing.T) {
+			if e, a := test.ExpectedCode, sendAs(t, test.User, test.Method, test.URL, test.RequestBody, nil); e != a {
+				t.Errorf("expected status code: %v, got status code: %v", e, a)
+			}
+		}
+
+		t.Run(test.Name, fn)
+	}
+
+	var mentions []comment.Comment
+	if e, a := http.StatusOK, sendAs(t, "bob", http.MethodGet, "/mention", nil, &mentions); e != a {
+		t.Fatalf("expected status code: %v, got status code: %v", e, a)
+	}
+
+	if e, a := 1, len(mentions); e != a || mentions[0].ID != c.ID {
+		t.Fatalf("expected %v comment %v mentioning bob, got %v", e, c.ID, mentions)
+	}
+
+	// Editing a comment replaces the users it mentions.
+	if e, a := http.StatusOK, sendAs(t, "ALICE", http.MethodPut, commentURL, comment.Comment{Body: "Never mind, @carol got it"}, &c); e != a {
+		t.Fatalf("expected status code: %v, got status code: %v", e, a)
+	}
+
+	if d := cmp.Diff([]string{"carol"}, []string(c.Mentions)); d != "" {
+		t.Errorf("mentions differ from expected:\n%s", d)
+	}
+
+	mentions = nil
+	if e, a := http.StatusOK, sendAs(t, "bob", http.MethodGet, "/mention", nil, &mentions); e != a {
+		t.Fatalf("expected status code: %v, got status code: %v", e, a)
+	}
+
+	if e, a := 0, len(mentions); e != a {
+		t.Errorf("expected %v comments mentioning bob, got %v", e, a)
+	}
+
+	var comments []comment.Comment
+	if e, a := http.StatusOK, sendAs(t, "", http.MethodGet, url, nil, &comments); e != a {
+		t.Fatalf("expected status code: %v, got status code: %v", e, a)
+	}
+
+	if e, a := 1, len(comments); e != a || comments[0].ID != c.ID || comments[0].Body != c.Body {
+		t.Errorf("expected %v comment %+v, got %+v", e, c, comments)
+	}
+
+	if e, a := http.StatusNoContent, sendAs(t, "alice", http.MethodDelete, commentURL, nil, nil); e != a {
+		t.Fatalf("expected status code: %v, got status code: %v", e, a)
+	}
+
+	comments = nil
+	if e, a := http.StatusOK, sendAs(t, "", http.MethodGet, url, nil, &comments); e != a {
+		t.Fatalf("expected status code: %v, got status code: %v", e, a)
+	}
+
+	if e, a := 0, len(comments); e != a {
+		t.Errorf("expected %v comments, got %v", e, a)
+	}
+}
+
+func Test_commentsWithoutUserHeader(t *testing.T) {
+	defer func() {
+		if err := testdb.Truncate(a.DB); err != nil {
+			t.Errorf("error truncating test database tables: %v", err)
+		}
+	}()
+
+	expectedLists, err := testdb.SeedLists(a.DB)
+	if err != nil {
+		t.Fatalf("error seeding lists: %v", err)
+	}
+
+	expectedItems, err := testdb.SeedItems(a.DB, expectedLists)
+	if err != nil {
+		t.Fatalf("error seeding items: %v", err)
+	}
+
+	// Without a header configured to identify users by, one sent by the client
+	// is not trusted.
+	userHeader := a.UserHeader
+	a.UserHeader = ""
+	defer func() { a.UserHeader = userHeader }()
+
+	url := fmt.Sprintf("/list/%d/item/%d/comment", expectedItems[0].ListID, expectedItems[0].ID)
+
+	if e, a := http.StatusUnauthorized, sendAs(t, "alice", http.MethodPost, url, comment.Comment{Body: "Hello"}, nil); e != a {
+		t.Errorf("expected status code: %v, got status code: %v", e, a)
+	}
+
+	if e, a := http.StatusUnauthorized, sendAs(t, "alice", http.MethodGet, "/mention", nil, nil); e != a {
+		t.Errorf("expected status code: %v, got status code: %v", e, a)
+	}
+}
@@ -0,0 +1,285 @@
+package tests
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/george-e-shaw-iv/integration-tests-example/cmd/listd/csvlist"
+	"github.com/george-e-shaw-iv/integration-tests-example/cmd/listd/item"
+	"github.com/george-e-shaw-iv/integration-tests-example/cmd/listd/list"
+	"github.com/george-e-shaw-iv/integration-tests-example/internal/platform/testdb"
+	"github.com/george-e-shaw-iv/integration-tests-example/internal/platform/units"
+	"github.com/george-e-shaw-iv/integration-tests-example/internal/platform/web"
+	"github.com/google/go-cmp/cmp"
+)
+
+// csvImport is the response to importing a CSV file.
+type csvImport struct {
+	List  list.List `json:"list"`
+	Items int       `json:"items"`
+}
+
+// importCSV imports data as a list with the given name, mapping headers to fields
+// as given, and returns the status code along with the decoded response.
+func importCSV(t *testing.T, name, data string, mapping ...string) (int, web.Response, csvImport) {
+	q := url.Values{"name": {name}, "map": mapping}
+
+	req, err := http.NewRequest(http.MethodPost, "/list/import?"+q.Encode(), strings.NewReader(data))
+	if err != nil {
+		t.Fatalf("error creating request: %v", err)
+	}
+	req.Header.Set("Content-Type", "text/csv")
+
+	w := httptest.NewRecorder()
+	a.ServeHTTP(w, req)
+
+	var imported csvImport
+	resp := web.Response{
+		Results: &imported,
+	}
+
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatalf("error decoding response body: %v", err)
+	}
+
+	return w.Code, resp, imported
+}
+
+func Test_csvField(t *testing.T) {
+	tests := []struct {
+		Name     string
+		Header   string
+		Expected string
+	}{
+		{Name: "Exact", Header: "name", Expected: csvlist.FieldName},
+		{Name: "Case", Header: "Quantity", Expected: csvlist.FieldQuantity},
+		{Name: "Spaced", Header: "Due At", Expected: csvlist.FieldDueAt},
+		{Name: "Dashed", Header: "remind-at", Expected: csvlist.FieldRemindAt},
+		{Name: "Synonym", Header: "Qty", Expected: csvlist.FieldQuantity},
+		{Name: "SpacedSynonym", Header: "Due date", Expected: csvlist.FieldDueAt},
+		{Name: "Unknown", Header: "Aisle", Expected: ""},
+	}
+
+	for _, test := range tests {
+		fn := func(t *testing.T) {
+			f, _ := csvlist.Field(test.Header)
+			if e, a := test.Expected, f; e != a {
+				t.Errorf("expected field: %q, got field: %q", e, a)
+			}
+		}
+
+		t.Run(test.Name, fn)
+	}
+}
+
+func Test_csvRoundTrip(t *testing.T) {
+	defer func() {
+		if err := testdb.Truncate(a.DB); err != nil {
+			t.Errorf("error truncating test database tables: %v", err)
+		}
+	}()
+
+	expectedLists, err := testdb.SeedLists(a.DB)
+	if err != nil {
+		t.Fatalf("error seeding lists: %v", err)
+	}
+
+	url := fmt.Sprintf("/list/%d/item", expectedLists[0].ID)
+
+	for _, i := range []item.Item{
+		{Name: "Flour", Quantity: units.MustParseAmount("1.5"), Unit: "kg", Price: price(199), Currency: "USD"},
+		{Name: "=1+1", Quantity: units.Int(2), Notes: "Line one\nline two"},
+	} {
+		code, created := sendItem(t, http.MethodPost, url, i)
+		if e, a := http.StatusCreated, code; e != a {
+			t.Fatalf("expected status code: %v, got status code: %v", e, a)
+		}
+
+		if i.Name == "Flour" {
+			if e, a := http.StatusOK, sendJSON(t, http.MethodPut, fmt.Sprintf("%s/%d/tag/baking", url, created.ID), nil, nil); e != a {
+				t.Fatalf("expected status code: %v, got status code: %v", e, a)
+			}
+		}
+	}
+
+	req, err := http.NewRequest(http.MethodGet, fmt.Sprintf("/list/%d/export.csv", expectedLists[0].ID), nil)
+	if err != nil {
+		t.Fatalf("error creating request: %v", err)
+	}
+
+	w := httptest.NewRecorder()
+	a.ServeHTTP(w, req)
+
+	if e, a := http.StatusOK, w.Code; e != a {
+		t.Fatalf("expected status code: %v, got status code: %v", e, a)
+	}
+
+	if e, a := "text/csv; charset=utf-8", w.Header().Get("Content-Type"); e != a {
+		t.Errorf("expected content type: %v, got content type: %v", e, a)
+	}
+
+	exported := w.Body.String()
+
+	records, err := csv.NewReader(strings.NewReader(exported)).ReadAll()
+	if err != nil {
+		t.Fatalf("error reading export: %v", err)
+	}
+
+	if d := cmp.Diff(csvlist.Header, records[0]); d != "" {
+		t.Errorf("header differs from expected:\n%s", d)
+	}
+
+	rows := make(map[string][]string)
+	for _, r := range records[1:] {
+		rows[r[0]] = r
+	}
+
+	if r := rows["Flour"]; r == nil || r[1] != "1.5" || r[2] != "kg" || r[3] != "1.99" || r[4] != "USD" || r[10] != "baking" {
+		t.Errorf("unexpected flour row: %q", r)
+	}
+
+	if _, ok := rows["'=1+1"]; !ok {
+		t.Errorf("expected formula to be escaped, got rows: %q", records[1:])
+	}
+
+	code, resp, imported := importCSV(t, "Imported", exported)
+	if e, a := http.StatusCreated, code; e != a {
+		t.Fatalf("expected status code: %v, got status code: %v, errors: %v", e, a, resp.Errors)
+	}
+
+	if e, a := 2, imported.Items; e != a {
+		t.Errorf("expected %v imported items, got %v", e, a)
+	}
+
+	var items []item.Item
+	if e, a := http.StatusOK, sendJSON(t, http.MethodGet, fmt.Sprintf("/list/%d/item", imported.List.ID), nil, &items); e != a {
+		t.Fatalf("expected status code: %v, got status code: %v", e, a)
+	}
+
+	names := make(map[string]item.Item)
+	for _, i := range items {
+		names[i.Name] = i
+	}
+
+	flour, ok := names["Flour"]
+	if !ok {
+		t.Fatalf("expected flour to be imported, got items: %+v", items)
+	}
+
+	if flour.Price == nil || *flour.Price != 199 || flour.Currency != "USD" || flour.Quantity.String() != "1.5" || flour.Unit != "kg" {
+		t.Errorf("unexpected imported flour: %+v", flour)
+	}
+
+	if d := cmp.Diff(item.Tags{"baking"}, flour.Tags); d != "" {
+		t.Errorf("tags differ from expected:\n%s", d)
+	}
+
+	if e, a := "Line one\nline two", names["=1+1"].Notes; e != a {
+		t.Errorf("expected notes: %q, got notes: %q", e, a)
+	}
+
+	if e, a := http.StatusNotFound, sendJSON(t, http.MethodGet, fmt.Sprintf("/list/%d/export.csv", imported.List.ID+1), nil, nil); e != a {
+		t.Errorf("expected status code: %v, got status code: %v", e, a)
+	}
+}
+
+func Test_csvImport(t *testing.T) {
+	defer func() {
+		if err := testdb.Truncate(a.DB); err != nil {
+			t.Errorf("error truncating test database tables: %v", err)
+		}
+	}()
+
+	data := "\ufeffItem,Qty,Aisle,Cost,Currency\n" +
+		"Milk,2,Dairy,1.25,usd\n" +
+		"Eggs,,Dairy,,\n"
+
+	code, resp, imported := importCSV(t, "Groceries", data, "Cost:price", "Aisle:")
+	if e, a := http.StatusCreated, code; e != a {
+		t.Fatalf("expected status code: %v, got status code: %v, errors: %v", e, a, resp.Errors)
+	}
+
+	if e, a := "Groceries", imported.List.Name; e != a {
+		t.Errorf("expected list name: %v, got list name: %v", e, a)
+	}
+
+	var items []item.Item
+	if e, a := http.StatusOK, sendJSON(t, http.MethodGet, fmt.Sprintf("/list/%d/item", imported.List.ID), nil, &items); e != a {
+		t.Fatalf("expected status code: %v, got status code: %v", e, a)
+	}
+
+	got := make(map[string]string)
+	for _, i := range items {
+		got[i.Name] = i.Quantity.String()
+		if i.Price != nil {
+			got[i.Name] += " at " + fmt.Sprint(*i.Price) + " " + i.Currency
+		}
+	}
+
+	if d := cmp.Diff(map[string]string{"Milk": "2 at 125 USD", "Eggs": "1"}, got); d != "" {
+		t.Errorf("items differ from expected:\n%s", d)
+	}
+
+	invalid := "name,quantity,price,currency,due at\n" +
+		"Bread,1,,,\n" +
+		",1,,,\n" +
+		"\"Cheese\nwedge\",abc,,,\n" +
+		"Butter,1,1.999,USD,\n" +
+		"Jam,1,,,tomorrow\n"
+
+	code, resp, _ = importCSV(t, "Invalid", invalid)
+	if e, a := http.StatusBadRequest, code; e != a {
+		t.Fatalf("expected status code: %v, got status code: %v", e, a)
+	}
+
+	var lines []string
+	for _, e := range resp.Errors {
+		lines = append(lines, strings.SplitN(e.Message, ":", 2)[0])
+	}
+
+	if d := cmp.Diff([]string{"line 3", "line 4", "line 6", "line 7"}, lines); d != "" {
+		t.Errorf("reported lines differ from expected:\n%s\nerrors: %v", d, resp.Errors)
+	}
+
+	var lists []list.List
+	if e, a := http.StatusOK, sendJSON(t, http.MethodGet, "/list", nil, &lists); e != a {
+		t.Fatalf("expected status code: %v, got status code: %v", e, a)
+	}
+
+	for _, l := range lists {
+		if l.Name == "Invalid" {
+			t.Errorf("expected nothing to be imported from an invalid file")
+		}
+	}
+
+	failures := []struct {
+		Name    string
+		List    string
+		Data    string
+		Mapping []string
+	}{
+		{Name: "NoName", Data: "name\nMilk\n"},
+		{Name: "Empty", List: "Empty", Data: ""},
+		{Name: "NoNameColumn", List: "NoNameColumn", Data: "quantity\n1\n"},
+		{Name: "UnknownField", List: "UnknownField", Data: "name,aisle\nMilk,Dairy\n", Mapping: []string{"aisle:shelf"}},
+		{Name: "DuplicateField", List: "DuplicateField", Data: "name,title\nMilk,Milk\n"},
+		{Name: "DuplicateList", List: "Groceries", Data: "name\nMilk\n"},
+	}
+
+	for _, test := range failures {
+		fn := func(t *testing.T) {
+			code, _, _ := importCSV(t, test.List, test.Data, test.Mapping...)
+			if e, a := http.StatusBadRequest, code; e != a {
+				t.Errorf("expected status code: %v, got status code: %v", e, a)
+			}
+		}
+
+		t.Run(test.Name, fn)
+	}
+}
@@ -0,0 +1,145 @@
+package tests
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/george-e-shaw-iv/integration-tests-example/cmd/listd/item"
+	"github.com/george-e-shaw-iv/integration-tests-example/internal/platform/testdb"
+	"github.com/george-e-shaw-iv/integration-tests-example/internal/platform/units"
+	"github.com/google/go-cmp/cmp"
+)
+
+// mergeReport is the response to merging the duplicate items on a list.
+type mergeReport struct {
+	Groups []item.MergedGroup `json:"groups"`
+}
+
+func Test_normalizeName(t *testing.T) {
+	tests := []struct {
+		Name     string
+		Input    string
+		Expected string
+	}{
+		{Name: "Lower", Input: "milk", Expected: "milk"},
+		{Name: "Upper", Input: "MILK", Expected: "milk"},
+		{Name: "Surrounding", Input: "  Milk ", Expected: "milk"},
+		{Name: "Inner", Input: "Oat \t Milk", Expected: "oat milk"},
+	}
+
+	for _, test := range tests {
+		fn := func(t *testing.T) {
+			if e, a := test.Expected, item.NormalizeName(test.Input); e != a {
+				t.Errorf("expected name: %q, got name: %q", e, a)
+			}
+		}
+
+		t.Run(test.Name, fn)
+	}
+}
+
+func Test_mergeItems(t *testing.T) {
+	defer func() {
+		if err := testdb.Truncate(a.DB); err != nil {
+			t.Errorf("error truncating test database tables: %v", err)
+		}
+	}()
+
+	expectedLists, err := testdb.SeedLists(a.DB)
+	if err != nil {
+		t.Fatalf("error seeding lists: %v", err)
+	}
+
+	url := fmt.Sprintf("/list/%d/item", expectedLists[0].ID)
+
+	create := func(i item.Item) item.Item {
+		code, created := sendItem(t, http.MethodPost, url, i)
+		if e, a := http.StatusCreated, code; e != a {
+			t.Fatalf("expected status code: %v, got status code: %v", e, a)
+		}
+
+		return created
+	}
+
+	milk := create(item.Item{Name: "Milk", Quantity: units.Int(1), Unit: "l"})
+	skim := create(item.Item{Name: "milk", Quantity: units.Int(500), Unit: "ml"})
+	spaced := create(item.Item{Name: " Milk  ", Quantity: units.Int(250), Unit: "ml"})
+	bottles := create(item.Item{Name: "MILK", Quantity: units.Int(2)})
+	eggs := create(item.Item{Name: "Eggs", Quantity: units.Int(12)})
+	child := create(item.Item{Name: "Lactose free", Quantity: units.Int(1), ParentID: &skim.ID})
+
+	for _, tag := range []string{"dairy", "organic"} {
+		if e, a := http.StatusOK, sendJSON(t, http.MethodPut, fmt.Sprintf("%s/%d/tag/%s", url, skim.ID, tag), nil, nil); e != a {
+			t.Fatalf("expected status code: %v, got status code: %v", e, a)
+		}
+	}
+
+	if e, a := http.StatusOK, sendJSON(t, http.MethodPut, fmt.Sprintf("%s/%d/tag/dairy", url, milk.ID), nil, nil); e != a {
+		t.Fatalf("expected status code: %v, got status code: %v", e, a)
+	}
+
+	var report mergeReport
+	if e, a := http.StatusOK, sendJSON(t, http.MethodPost, url+":merge", nil, &report); e != a {
+		t.Fatalf("expected status code: %v, got status code: %v", e, a)
+	}
+
+	if e, a := 1, len(report.Groups); e != a {
+		t.Fatalf("expected %v merged group, got %v", e, a)
+	}
+
+	g := report.Groups[0]
+	if e, a := milk.ID, g.Item.ID; e != a {
+		t.Errorf("expected surviving item: %v, got surviving item: %v", e, a)
+	}
+
+	if d := cmp.Diff([]int{skim.ID, spaced.ID}, g.Merged); d != "" {
+		t.Errorf("merged items differ from expected:\n%s", d)
+	}
+
+	if e, a := "1.75 l", g.Item.Quantity.String()+" "+g.Item.Unit; e != a {
+		t.Errorf("expected quantity: %v, got quantity: %v", e, a)
+	}
+
+	if d := cmp.Diff(item.Tags{"dairy", "organic"}, g.Item.Tags); d != "" {
+		t.Errorf("tags differ from expected:\n%s", d)
+	}
+
+	var items []item.Item
+	if e, a := http.StatusOK, sendJSON(t, http.MethodGet, url, nil, &items); e != a {
+		t.Fatalf("expected status code: %v, got status code: %v", e, a)
+	}
+
+	remaining := make(map[int]item.Item)
+	for _, i := range items {
+		remaining[i.ID] = i
+	}
+
+	for _, id := range []int{milk.ID, bottles.ID, eggs.ID, child.ID} {
+		if _, ok := remaining[id]; !ok {
+			t.Errorf("expected item %v to remain on the list", id)
+		}
+	}
+
+	if e, a := 4, len(items); e != a {
+		t.Errorf("expected %v items, got %v", e, a)
+	}
+
+	if p := remaining[child.ID].ParentID; p == nil || *p != milk.ID {
+		t.Errorf("expected sub-item %v to be moved under item %v, got parent: %v", child.ID, milk.ID, p)
+	}
+
+	// Merging again finds nothing left to merge.
+	report = mergeReport{}
+	if e, a := http.StatusOK, sendJSON(t, http.MethodPost, url+":merge", nil, &report); e != a {
+		t.Fatalf("expected status code: %v, got status code: %v", e, a)
+	}
+
+	if e, a := 0, len(report.Groups); e != a {
+		t.Errorf("expected %v merged groups, got %v", e, a)
+	}
+
+	if e, a := http.StatusNotFound, sendJSON(t, http.MethodPost, fmt.Sprintf("/list/%d/item:merge", expectedLists[len(expectedLists)-1].ID+1), nil, nil); e != a {
+		t.Errorf("expected status code: %v, got status code: %v", e, a)
+	}
+}
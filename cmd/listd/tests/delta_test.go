@@ -0,0 +1,135 @@
+package tests
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/george-e-shaw-iv/integration-tests-example/cmd/listd/delta"
+	"github.com/george-e-shaw-iv/integration-tests-example/internal/platform/testdb"
+	"github.com/george-e-shaw-iv/integration-tests-example/internal/platform/web"
+)
+
+// getChanges requests the changes since token and decodes the response.
+func getChanges(t *testing.T, token string) (delta.Changes, int) {
+	req, err := http.NewRequest(http.MethodGet, "/sync?since="+url.QueryEscape(token), nil)
+	if err != nil {
+		t.Fatalf("error creating request: %v", err)
+	}
+
+	w := httptest.NewRecorder()
+	a.ServeHTTP(w, req)
+
+	var c delta.Changes
+	resp := web.Response{
+		Results: &c,
+	}
+
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatalf("error decoding response body: %v", err)
+	}
+
+	return c, w.Code
+}
+
+func Test_getChanges(t *testing.T) {
+	defer func() {
+		if err := testdb.Truncate(a.DB); err != nil {
+			t.Errorf("error truncating test database tables: %v", err)
+		}
+	}()
+
+	expectedLists, err := testdb.SeedLists(a.DB)
+	if err != nil {
+		t.Fatalf("error seeding lists: %v", err)
+	}
+
+	expectedItems, err := testdb.SeedItems(a.DB, expectedLists)
+	if err != nil {
+		t.Fatalf("error seeding items: %v", err)
+	}
+
+	// Initial sync, everything is returned.
+	initial, code := getChanges(t, "")
+	if e, a := http.StatusOK, code; e != a {
+		t.Fatalf("expected status code: %v, got status code: %v", e, a)
+	}
+
+	if e, a := len(expectedLists), len(initial.Lists); e != a {
+		t.Errorf("expected %v lists, got %v", e, a)
+	}
+
+	if e, a := len(expectedItems), len(initial.Items); e != a {
+		t.Errorf("expected %v items, got %v", e, a)
+	}
+
+	if initial.Next == "" {
+		t.Fatal("expected a next sync token, got none")
+	}
+
+	// Nothing has changed since the initial sync.
+	unchanged, _ := getChanges(t, initial.Next)
+	if n := len(unchanged.Lists) + len(unchanged.Items) + len(unchanged.Deleted); n != 0 {
+		t.Errorf("expected no changes, got %v", n)
+	}
+
+	for _, r := range []struct {
+		Method string
+		Path   string
+		Body   string
+	}{
+		{http.MethodPut, fmt.Sprintf("/list/%d/item/%d", expectedLists[0].ID, expectedItems[0].ID), `{"name":"Strawberry Milk","quantity":2}`},
+		{http.MethodDelete, fmt.Sprintf("/list/%d/item/%d", expectedLists[0].ID, expectedItems[1].ID), ""},
+		{http.MethodPost, "/list", `{"name":"Hardware"}`},
+	} {
+		req, err := http.NewRequest(r.Method, r.Path, strings.NewReader(r.Body))
+		if err != nil {
+			t.Fatalf("error creating request: %v", err)
+		}
+
+		w := httptest.NewRecorder()
+		a.ServeHTTP(w, req)
+
+		if w.Code >= http.StatusBadRequest {
+			t.Fatalf("unexpected status code for %s %s: %v", r.Method, r.Path, w.Code)
+		}
+	}
+
+	changes, _ := getChanges(t, initial.Next)
+
+	if len(changes.Items) != 1 || changes.Items[0].Name != "Strawberry Milk" {
+		t.Errorf("expected the updated item to be returned, got %+v", changes.Items)
+	}
+
+	if len(changes.Lists) != 1 || changes.Lists[0].Name != "Hardware" {
+		t.Errorf("expected the created list to be returned, got %+v", changes.Lists)
+	}
+
+	if len(changes.Deleted) != 1 || changes.Deleted[0].Type != "item" || changes.Deleted[0].ID != expectedItems[1].ID {
+		t.Errorf("expected a tombstone for the deleted item, got %+v", changes.Deleted)
+	}
+
+	// Deleting a list leaves tombstones for the list and all of its items.
+	req, err := http.NewRequest(http.MethodDelete, fmt.Sprintf("/list/%d", expectedLists[1].ID), nil)
+	if err != nil {
+		t.Fatalf("error creating request: %v", err)
+	}
+	a.ServeHTTP(httptest.NewRecorder(), req)
+
+	deleted, _ := getChanges(t, changes.Next)
+	if e, a := 2, len(deleted.Deleted); e != a {
+		t.Errorf("expected %v tombstones, got %v", e, a)
+	}
+}
+
+func Test_getChangesInvalidToken(t *testing.T) {
+	_, code := getChanges(t, "not-a-token")
+
+	if e, a := http.StatusBadRequest, code; e != a {
+		t.Errorf("expected status code: %v, got status code: %v", e, a)
+	}
+}
@@ -0,0 +1,192 @@
+package tests
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/george-e-shaw-iv/integration-tests-example/cmd/listd/folder"
+	"github.com/george-e-shaw-iv/integration-tests-example/cmd/listd/list"
+	"github.com/george-e-shaw-iv/integration-tests-example/internal/platform/testdb"
+	"github.com/george-e-shaw-iv/integration-tests-example/internal/platform/web"
+)
+
+// sendJSON makes a request with body and decodes the response into results,
+// returning the status code.
+func sendJSON(t *testing.T, method, url string, body, results interface{}) int {
+	var b bytes.Buffer
+	if err := json.NewEncoder(&b).Encode(body); err != nil {
+		t.Fatalf("error encoding request body: %v", err)
+	}
+
+	req, err := http.NewRequest(method, url, &b)
+	if err != nil {
+		t.Fatalf("error creating request: %v", err)
+	}
+
+	w := httptest.NewRecorder()
+	a.ServeHTTP(w, req)
+
+	if results != nil && w.Code < 300 {
+		resp := web.Response{
+			Results: results,
+		}
+
+		if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+			t.Fatalf("error decoding response body: %v", err)
+		}
+	}
+
+	return w.Code
+}
+
+func Test_folders(t *testing.T) {
+	defer func() {
+		if err := testdb.Truncate(a.DB); err != nil {
+			t.Errorf("error truncating test database tables: %v", err)
+		}
+	}()
+
+	expectedLists, err := testdb.SeedLists(a.DB)
+	if err != nil {
+		t.Fatalf("error seeding lists: %v", err)
+	}
+
+	var home, kitchen folder.Folder
+	if e, a := http.StatusCreated, sendJSON(t, http.MethodPost, "/folder", folder.Folder{Name: "Home"}, &home); e != a {
+		t.Fatalf("expected status code: %v, got status code: %v", e, a)
+	}
+
+	if e, a := http.StatusCreated, sendJSON(t, http.MethodPost, "/folder", folder.Folder{Name: "Kitchen", ParentID: &home.ID}, &kitchen); e != a {
+		t.Fatalf("expected status code: %v, got status code: %v", e, a)
+	}
+
+	var moved list.List
+	if e, a := http.StatusOK, sendJSON(t, http.MethodPost, fmt.Sprintf("/list/%d/move", expectedLists[0].ID), map[string]interface{}{"folderID": kitchen.ID}, &moved); e != a {
+		t.Fatalf("expected status code: %v, got status code: %v", e, a)
+	}
+
+	if moved.FolderID == nil || *moved.FolderID != kitchen.ID {
+		t.Errorf("expected list folder: %v, got list folder: %v", kitchen.ID, moved.FolderID)
+	}
+
+	missing := kitchen.ID + 100
+
+	tests := []struct {
+		Name         string
+		Method       string
+		URL          string
+		RequestBody  interface{}
+		ExpectedCode int
+	}{
+		{
+			Name:         "NoName",
+			Method:       http.MethodPost,
+			URL:          "/folder",
+			RequestBody:  folder.Folder{},
+			ExpectedCode: http.StatusBadRequest,
+		},
+		{
+			Name:         "MissingParent",
+			Method:       http.MethodPost,
+			URL:          "/folder",
+			RequestBody:  folder.Folder{Name: "Orphan", ParentID: &missing},
+			ExpectedCode: http.StatusBadRequest,
+		},
+		{
+			Name:         "MoveIntoSelf",
+			Method:       http.MethodPost,
+			URL:          fmt.Sprintf("/folder/%d/move", home.ID),
+			RequestBody:  map[string]interface{}{"parentID": home.ID},
+			ExpectedCode: http.StatusBadRequest,
+		},
+		{
+			Name:         "MoveIntoSubFolder",
+			Method:       http.MethodPost,
+			URL:          fmt.Sprintf("/folder/%d/move", home.ID),
+			RequestBody:  map[string]interface{}{"parentID": kitchen.ID},
+			ExpectedCode: http.StatusBadRequest,
+		},
+		{
+			Name:         "MoveListToMissingFolder",
+			Method:       http.MethodPost,
+			URL:          fmt.Sprintf("/list/%d/move", expectedLists[1].ID),
+			RequestBody:  map[string]interface{}{"folderID": missing},
+			ExpectedCode: http.StatusBadRequest,
+		},
+		{
+			Name:         "NotFound",
+			Method:       http.MethodGet,
+			URL:          fmt.Sprintf("/folder/%d", missing),
+			ExpectedCode: http.StatusNotFound,
+		},
+	}
+
+	for _, test := range tests {
+		fn := func(t *testing.T) {
+			if e, a := test.ExpectedCode, sendJSON(t, test.Method, test.URL, test.RequestBody, nil); e != a {
+				t.Errorf("expected status code: %v, got status code: %v", e, a)
+			}
+		}
+
+		t.Run(test.Name, fn)
+	}
+
+	var c folder.Contents
+	if e, a := http.StatusOK, sendJSON(t, http.MethodGet, fmt.Sprintf("/folder/%d", home.ID), nil, &c); e != a {
+		t.Fatalf("expected status code: %v, got status code: %v", e, a)
+	}
+
+	if e, a := 1, len(c.Folders); e != a || c.Folders[0].ID != kitchen.ID {
+		t.Errorf("expected %v child folder %v, got %v", e, kitchen.ID, c.Folders)
+	}
+
+	if e, a := 0, len(c.Lists); e != a {
+		t.Errorf("expected %v lists directly in the folder, got %v", e, a)
+	}
+
+	// The flat list of every list is unaffected by folders.
+	var all []list.List
+	if e, a := http.StatusOK, sendJSON(t, http.MethodGet, "/list", nil, &all); e != a {
+		t.Fatalf("expected status code: %v, got status code: %v", e, a)
+	}
+
+	if e, a := len(expectedLists), len(all); e != a {
+		t.Errorf("expected %v lists, got %v", e, a)
+	}
+
+	// Deleting the kitchen folder moves its list up into the home folder.
+	if e, a := http.StatusNoContent, sendJSON(t, http.MethodDelete, fmt.Sprintf("/folder/%d", kitchen.ID), nil, nil); e != a {
+		t.Fatalf("expected status code: %v, got status code: %v", e, a)
+	}
+
+	c = folder.Contents{}
+	if e, a := http.StatusOK, sendJSON(t, http.MethodGet, fmt.Sprintf("/folder/%d", home.ID), nil, &c); e != a {
+		t.Fatalf("expected status code: %v, got status code: %v", e, a)
+	}
+
+	if e, a := 0, len(c.Folders); e != a {
+		t.Errorf("expected %v child folders, got %v", e, a)
+	}
+
+	if e, a := 1, len(c.Lists); e != a || c.Lists[0].ID != expectedLists[0].ID {
+		t.Errorf("expected %v list %v in the folder, got %v", e, expectedLists[0].ID, c.Lists)
+	}
+
+	// The top level holds the home folder and the lists in no folder.
+	c = folder.Contents{}
+	if e, a := http.StatusOK, sendJSON(t, http.MethodGet, "/folder", nil, &c); e != a {
+		t.Fatalf("expected status code: %v, got status code: %v", e, a)
+	}
+
+	if e, a := 1, len(c.Folders); e != a {
+		t.Errorf("expected %v top level folders, got %v", e, a)
+	}
+
+	if e, a := len(expectedLists)-1, len(c.Lists); e != a {
+		t.Errorf("expected %v top level lists, got %v", e, a)
+	}
+}
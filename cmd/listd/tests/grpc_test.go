@@ -0,0 +1,396 @@
+package tests
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"github.com/george-e-shaw-iv/integration-tests-example/cmd/listd/handlers"
+	"github.com/george-e-shaw-iv/integration-tests-example/cmd/listd/listdpb"
+	"github.com/george-e-shaw-iv/integration-tests-example/internal/platform/testdb"
+	"github.com/golang/protobuf/ptypes/empty"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// dialGRPC serves the gRPC services of the application on a local port and
+// returns a connection to them, along with a function stopping both.
+func dialGRPC(t *testing.T) (*grpc.ClientConn, func()) {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("error listening for gRPC: %v", err)
+	}
+
+	s := handlers.NewGRPCServer(a)
+	go s.Serve(lis)
+
+	conn, err := grpc.Dial(lis.Addr().String(), grpc.WithInsecure())
+	if err != nil {
+		s.Stop()
+		t.Fatalf("error dialing gRPC server: %v", err)
+	}
+
+	return conn, func() {
+		conn.Close()
+		s.Stop()
+	}
+}
+
+func Test_grpcLists(t *testing.T) {
+	defer testdb.Truncate(a.DB)
+
+	lists, err := testdb.SeedLists(a.DB)
+	if err != nil {
+		t.Fatalf("error seeding lists: %v", err)
+	}
+
+	conn, stop := dialGRPC(t)
+	defer stop()
+
+	c := listdpb.NewListsClient(conn)
+	ctx := context.Background()
+
+	resp, err := c.ListLists(ctx, &empty.Empty{})
+	if err != nil {
+		t.Fatalf("error listing lists: %v", err)
+	}
+
+	if e, a := len(lists), len(resp.Lists); e != a {
+		t.Errorf("expected list count: %v, got list count: %v", e, a)
+	}
+
+	tests := []struct {
+		Name         string
+		Call         func() error
+		ExpectedCode codes.Code
+	}{
+		{
+			Name: "GetList",
+			Call: func() error {
+				_, err := c.GetList(ctx, &listdpb.GetListRequest{Id: int64(lists[0].ID)})
+				return err
+			},
+			ExpectedCode: codes.OK,
+		},
+		{
+			Name: "GetListNotFound",
+			Call: func() error {
+				_, err := c.GetList(ctx, &listdpb.GetListRequest{Id: 0})
+				return err
+			},
+			ExpectedCode: codes.NotFound,
+		},
+		{
+			Name: "CreateList",
+			Call: func() error {
+				_, err := c.CreateList(ctx, &listdpb.List{Name: "Hardware"})
+				return err
+			},
+			ExpectedCode: codes.OK,
+		},
+		{
+			Name: "CreateListUniqueName",
+			Call: func() error {
+				_, err := c.CreateList(ctx, &listdpb.List{Name: lists[0].Name})
+				return err
+			},
+			ExpectedCode: codes.AlreadyExists,
+		},
+		{
+			Name: "CreateListWithoutName",
+			Call: func() error {
+				_, err := c.CreateList(ctx, &listdpb.List{})
+				return err
+			},
+			ExpectedCode: codes.InvalidArgument,
+		},
+		{
+			Name: "UpdateList",
+			Call: func() error {
+				_, err := c.UpdateList(ctx, &listdpb.List{Id: int64(lists[0].ID), Name: "Groceries"})
+				return err
+			},
+			ExpectedCode: codes.OK,
+		},
+		{
+			Name: "UpdateListNotFound",
+			Call: func() error {
+				_, err := c.UpdateList(ctx, &listdpb.List{Id: 0, Name: "Missing"})
+				return err
+			},
+			ExpectedCode: codes.NotFound,
+		},
+		{
+			Name: "UpdateListUniqueName",
+			Call: func() error {
+				_, err := c.UpdateList(ctx, &listdpb.List{Id: int64(lists[1].ID), Name: "Groceries"})
+				return err
+			},
+			ExpectedCode: codes.AlreadyExists,
+		},
+		{
+			Name: "DeleteList",
+			Call: func() error {
+				_, err := c.DeleteList(ctx, &listdpb.DeleteListRequest{Id: int64(lists[1].ID)})
+				return err
+			},
+			ExpectedCode: codes.OK,
+		},
+		{
+			Name: "DeleteListNotFound",
+			Call: func() error {
+				_, err := c.DeleteList(ctx, &listdpb.DeleteListRequest{Id: int64(lists[1].ID)})
+				return err
+			},
+			ExpectedCode: codes.NotFound,
+		},
+	}
+
+	// The cases run in order, as later ones depend on the changes of earlier ones.
+	for _, test := range tests {
+		fn := func(t *testing.T) {
+			if e, a := test.ExpectedCode, status.Code(test.Call()); e != a {
+				t.Errorf("expected status code: %v, got status code: %v", e, a)
+			}
+		}
+
+		t.Run(test.Name, fn)
+	}
+}
+
+func Test_grpcItems(t *testing.T) {
+	defer testdb.Truncate(a.DB)
+
+	lists, err := testdb.SeedLists(a.DB)
+	if err != nil {
+		t.Fatalf("error seeding lists: %v", err)
+	}
+
+	items, err := testdb.SeedItems(a.DB, lists)
+	if err != nil {
+		t.Fatalf("error seeding items: %v", err)
+	}
+
+	conn, stop := dialGRPC(t)
+	defer stop()
+
+	c := listdpb.NewItemsClient(conn)
+	ctx := context.Background()
+
+	listID := int64(lists[0].ID)
+
+	created, err := c.CreateItem(ctx, &listdpb.Item{ListId: listID, Name: "Eggs", Quantity: "12"})
+	if err != nil {
+		t.Fatalf("error creating item: %v", err)
+	}
+
+	if e, a := "Eggs", created.Name; e != a {
+		t.Errorf("expected item name: %v, got item name: %v", e, a)
+	}
+
+	tests := []struct {
+		Name         string
+		Call         func() error
+		ExpectedCode codes.Code
+	}{
+		{
+			Name: "ListItems",
+			Call: func() error {
+				_, err := c.ListItems(ctx, &listdpb.ListItemsRequest{ListId: listID})
+				return err
+			},
+			ExpectedCode: codes.OK,
+		},
+		{
+			Name: "GetItem",
+			Call: func() error {
+				_, err := c.GetItem(ctx, &listdpb.GetItemRequest{Id: int64(items[0].ID), ListId: int64(items[0].ListID)})
+				return err
+			},
+			ExpectedCode: codes.OK,
+		},
+		{
+			Name: "GetItemNotFound",
+			Call: func() error {
+				_, err := c.GetItem(ctx, &listdpb.GetItemRequest{Id: 0, ListId: listID})
+				return err
+			},
+			ExpectedCode: codes.NotFound,
+		},
+		{
+			Name: "CreateItemListNotFound",
+			Call: func() error {
+				_, err := c.CreateItem(ctx, &listdpb.Item{ListId: 0, Name: "Eggs", Quantity: "12"})
+				return err
+			},
+			ExpectedCode: codes.NotFound,
+		},
+		{
+			Name: "CreateItemInvalidQuantity",
+			Call: func() error {
+				_, err := c.CreateItem(ctx, &listdpb.Item{ListId: listID, Name: "Eggs", Quantity: "a dozen"})
+				return err
+			},
+			ExpectedCode: codes.InvalidArgument,
+		},
+		{
+			Name: "CreateItemInvalidUnit",
+			Call: func() error {
+				_, err := c.CreateItem(ctx, &listdpb.Item{ListId: listID, Name: "Eggs", Quantity: "12", Unit: "furlong"})
+				return err
+			},
+			ExpectedCode: codes.InvalidArgument,
+		},
+		{
+			Name: "UpdateItemWithoutItem",
+			Call: func() error {
+				_, err := c.UpdateItem(ctx, &listdpb.UpdateItemRequest{})
+				return err
+			},
+			ExpectedCode: codes.InvalidArgument,
+		},
+		{
+			Name: "UpdateItem",
+			Call: func() error {
+				_, err := c.UpdateItem(ctx, &listdpb.UpdateItemRequest{
+					Item:        &listdpb.Item{Id: created.Id, ListId: listID, Name: "Bar", Quantity: "12"},
+					BaseVersion: created.Version,
+				})
+				return err
+			},
+			ExpectedCode: codes.OK,
+		},
+		{
+			// Renames the item again against the version the previous case changed.
+			Name: "UpdateItemConflict",
+			Call: func() error {
+				_, err := c.UpdateItem(ctx, &listdpb.UpdateItemRequest{
+					Item:        &listdpb.Item{Id: created.Id, ListId: listID, Name: "Baz", Quantity: "12"},
+					BaseVersion: created.Version,
+				})
+				return err
+			},
+			ExpectedCode: codes.Aborted,
+		},
+		{
+			Name: "UpdateItemNotFound",
+			Call: func() error {
+				_, err := c.UpdateItem(ctx, &listdpb.UpdateItemRequest{
+					Item: &listdpb.Item{Id: 0, ListId: listID, Name: "Eggs", Quantity: "12"},
+				})
+				return err
+			},
+			ExpectedCode: codes.NotFound,
+		},
+		{
+			Name: "CompleteItem",
+			Call: func() error {
+				_, err := c.CompleteItem(ctx, &listdpb.CompleteItemRequest{Id: created.Id, ListId: listID})
+				return err
+			},
+			ExpectedCode: codes.OK,
+		},
+		{
+			Name: "CompleteItemNotFound",
+			Call: func() error {
+				_, err := c.CompleteItem(ctx, &listdpb.CompleteItemRequest{Id: 0, ListId: listID})
+				return err
+			},
+			ExpectedCode: codes.NotFound,
+		},
+		{
+			Name: "DeleteItem",
+			Call: func() error {
+				_, err := c.DeleteItem(ctx, &listdpb.DeleteItemRequest{Id: created.Id, ListId: listID})
+				return err
+			},
+			ExpectedCode: codes.OK,
+		},
+		{
+			Name: "DeleteItemNotFound",
+			Call: func() error {
+				_, err := c.DeleteItem(ctx, &listdpb.DeleteItemRequest{Id: created.Id, ListId: listID})
+				return err
+			},
+			ExpectedCode: codes.NotFound,
+		},
+	}
+
+	// The cases run in order, as later ones depend on the changes of earlier ones.
+	for _, test := range tests {
+		fn := func(t *testing.T) {
+			if e, a := test.ExpectedCode, status.Code(test.Call()); e != a {
+				t.Errorf("expected status code: %v, got status code: %v", e, a)
+			}
+		}
+
+		t.Run(test.Name, fn)
+	}
+}
+
+func Test_grpcItemRoundTrip(t *testing.T) {
+	defer testdb.Truncate(a.DB)
+
+	lists, err := testdb.SeedLists(a.DB)
+	if err != nil {
+		t.Fatalf("error seeding lists: %v", err)
+	}
+
+	conn, stop := dialGRPC(t)
+	defer stop()
+
+	c := listdpb.NewItemsClient(conn)
+	ctx := context.Background()
+
+	listID := int64(lists[0].ID)
+
+	created, err := c.CreateItem(ctx, &listdpb.Item{ListId: listID, Name: "Eggs", Quantity: "1.5", Unit: "kg", Notes: "Free range"})
+	if err != nil {
+		t.Fatalf("error creating item: %v", err)
+	}
+
+	if _, err := c.CompleteItem(ctx, &listdpb.CompleteItemRequest{Id: created.Id, ListId: listID}); err != nil {
+		t.Fatalf("error completing item: %v", err)
+	}
+
+	completed, err := c.GetItem(ctx, &listdpb.GetItemRequest{Id: created.Id, ListId: listID})
+	if err != nil {
+		t.Fatalf("error getting item: %v", err)
+	}
+
+	if completed.CompletedAt == nil {
+		t.Fatal("expected item to be completed")
+	}
+
+	// Sending back the item as it was received, renamed, leaves every other field
+	// as it was.
+	completed.Name = "Duck eggs"
+
+	updated, err := c.UpdateItem(ctx, &listdpb.UpdateItemRequest{Item: completed, BaseVersion: completed.Version})
+	if err != nil {
+		t.Fatalf("error updating item: %v", err)
+	}
+
+	if e, a := "Duck eggs", updated.Name; e != a {
+		t.Errorf("expected item name: %v, got item name: %v", e, a)
+	}
+
+	if updated.CompletedAt == nil {
+		t.Fatal("expected updated item to still be completed")
+	}
+
+	if e, a := completed.CompletedAt.Seconds, updated.CompletedAt.Seconds; e != a {
+		t.Errorf("expected completed at: %v, got completed at: %v", e, a)
+	}
+
+	for _, f := range []struct{ Name, Expected, Actual string }{
+		{"quantity", completed.Quantity, updated.Quantity},
+		{"unit", completed.Unit, updated.Unit},
+		{"notes", completed.Notes, updated.Notes},
+	} {
+		if f.Expected != f.Actual {
+			t.Errorf("expected %s: %v, got %s: %v", f.Name, f.Expected, f.Name, f.Actual)
+		}
+	}
+}
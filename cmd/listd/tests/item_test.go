@@ -10,6 +10,7 @@ import (
 
 	"github.com/george-e-shaw-iv/integration-tests-example/cmd/listd/item"
 	"github.com/george-e-shaw-iv/integration-tests-example/internal/platform/testdb"
+	"github.com/george-e-shaw-iv/integration-tests-example/internal/platform/units"
 	"github.com/george-e-shaw-iv/integration-tests-example/internal/platform/web"
 	"github.com/google/go-cmp/cmp"
 )
@@ -118,7 +119,7 @@ func Test_createItem(t *testing.T) {
 			ListID: expectedLists[0].ID,
 			RequestBody: item.Item{
 				Name:     "Foo",
-				Quantity: 1,
+				Quantity: units.Int(1),
 			},
 			ExpectedCode: http.StatusCreated,
 		},
@@ -126,7 +127,7 @@ func Test_createItem(t *testing.T) {
 			Name:   "NoName",
 			ListID: expectedLists[0].ID,
 			RequestBody: item.Item{
-				Quantity: 1,
+				Quantity: units.Int(1),
 			},
 			ExpectedCode: http.StatusBadRequest,
 		},
@@ -135,7 +136,17 @@ func Test_createItem(t *testing.T) {
 			ListID: expectedLists[0].ID,
 			RequestBody: item.Item{
 				Name:     "Bar",
-				Quantity: 0,
+				Quantity: units.Int(0),
+			},
+			ExpectedCode: http.StatusBadRequest,
+		},
+		{
+			Name:   "InvalidRecurrence",
+			ListID: expectedLists[0].ID,
+			RequestBody: item.Item{
+				Name:       "Bar",
+				Quantity:   units.Int(1),
+				Recurrence: "FREQ=HOURLY",
 			},
 			ExpectedCode: http.StatusBadRequest,
 		},
@@ -145,7 +156,7 @@ func Test_createItem(t *testing.T) {
 			ListID: 0,
 			RequestBody: item.Item{
 				Name:     "Bar",
-				Quantity: 1,
+				Quantity: units.Int(1),
 			},
 			ExpectedCode: http.StatusNotFound,
 		},
@@ -309,7 +320,7 @@ func Test_updateItem(t *testing.T) {
 			ItemID: expectedItems[0].ID,
 			RequestBody: item.Item{
 				Name:     "Foo",
-				Quantity: 1,
+				Quantity: units.Int(1),
 			},
 			ExpectedCode: http.StatusOK,
 		},
@@ -318,7 +329,7 @@ func Test_updateItem(t *testing.T) {
 			ListID: expectedLists[0].ID,
 			ItemID: expectedItems[0].ID,
 			RequestBody: item.Item{
-				Quantity: 1,
+				Quantity: units.Int(1),
 			},
 			ExpectedCode: http.StatusBadRequest,
 		},
@@ -328,7 +339,7 @@ func Test_updateItem(t *testing.T) {
 			ItemID: expectedItems[0].ID,
 			RequestBody: item.Item{
 				Name:     "Bar",
-				Quantity: 0,
+				Quantity: units.Int(0),
 			},
 			ExpectedCode: http.StatusBadRequest,
 		},
@@ -339,7 +350,7 @@ func Test_updateItem(t *testing.T) {
 			ItemID: expectedItems[0].ID,
 			RequestBody: item.Item{
 				Name:     "Bar",
-				Quantity: 1,
+				Quantity: units.Int(1),
 			},
 			ExpectedCode: http.StatusNotFound,
 		},
@@ -350,7 +361,7 @@ func Test_updateItem(t *testing.T) {
 			ItemID: 0,
 			RequestBody: item.Item{
 				Name:     "Bar",
-				Quantity: 1,
+				Quantity: units.Int(1),
 			},
 			ExpectedCode: http.StatusNotFound,
 		},
@@ -469,3 +480,391 @@ func Test_deleteItem(t *testing.T) {
 		t.Run(test.Name, fn)
 	}
 }
+
+func Test_updateItemMerge(t *testing.T) {
+	defer func() {
+		if err := testdb.Truncate(a.DB); err != nil {
+			t.Errorf("error truncating test database tables: %v", err)
+		}
+	}()
+
+	expectedLists, err := testdb.SeedLists(a.DB)
+	if err != nil {
+		t.Fatalf("error seeding lists: %v", err)
+	}
+
+	// send makes a request with body and decodes the item it responds with.
+	send := func(method, url string, body interface{}) (int, item.Item) {
+		var b bytes.Buffer
+		if err := json.NewEncoder(&b).Encode(body); err != nil {
+			t.Fatalf("error encoding request body: %v", err)
+		}
+
+		req, err := http.NewRequest(method, url, &b)
+		if err != nil {
+			t.Fatalf("error creating request: %v", err)
+		}
+
+		w := httptest.NewRecorder()
+		a.ServeHTTP(w, req)
+
+		var i item.Item
+		resp := web.Response{
+			Results: &i,
+		}
+
+		if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+			t.Fatalf("error decoding response body: %v", err)
+		}
+
+		return w.Code, i
+	}
+
+	code, base := send(http.MethodPost, fmt.Sprintf("/list/%d/item", expectedLists[0].ID), item.Item{
+		Name:     "Foo",
+		Quantity: units.Int(1),
+	})
+	if e, a := http.StatusCreated, code; e != a {
+		t.Fatalf("expected status code: %v, got status code: %v", e, a)
+	}
+
+	url := fmt.Sprintf("/list/%d/item/%d", expectedLists[0].ID, base.ID)
+
+	// The first client renames the item.
+	code, _ = send(http.MethodPut, url, map[string]interface{}{
+		"name":        "Bar",
+		"quantity":    1,
+		"baseVersion": base.Version,
+	})
+	if e, a := http.StatusOK, code; e != a {
+		t.Fatalf("expected status code: %v, got status code: %v", e, a)
+	}
+
+	// The second client, unaware of the rename, changes the quantity which merges
+	// cleanly.
+	code, merged := send(http.MethodPut, url, map[string]interface{}{
+		"name":        "Foo",
+		"quantity":    3,
+		"baseVersion": base.Version,
+	})
+	if e, a := http.StatusOK, code; e != a {
+		t.Fatalf("expected status code: %v, got status code: %v", e, a)
+	}
+
+	if e, a := "Bar", merged.Name; e != a {
+		t.Errorf("expected item name: %v, got item name: %v", e, a)
+	}
+
+	if e, a := units.Int(3), merged.Quantity; e != a {
+		t.Errorf("expected item quantity: %v, got item quantity: %v", e, a)
+	}
+
+	// A third client, also unaware of either change, renames the item too.
+	var b bytes.Buffer
+	if err := json.NewEncoder(&b).Encode(map[string]interface{}{
+		"name":        "Baz",
+		"quantity":    1,
+		"baseVersion": base.Version,
+	}); err != nil {
+		t.Fatalf("error encoding request body: %v", err)
+	}
+
+	req, err := http.NewRequest(http.MethodPut, url, &b)
+	if err != nil {
+		t.Fatalf("error creating request: %v", err)
+	}
+
+	w := httptest.NewRecorder()
+	a.ServeHTTP(w, req)
+
+	if e, a := http.StatusConflict, w.Code; e != a {
+		t.Fatalf("expected status code: %v, got status code: %v", e, a)
+	}
+
+	var conflict item.ConflictError
+	resp := web.Response{
+		Results: &conflict,
+	}
+
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatalf("error decoding response body: %v", err)
+	}
+
+	if e, a := []string{"name"}, conflict.Fields; !cmp.Equal(e, a) {
+		t.Errorf("expected conflicting fields: %v, got conflicting fields: %v", e, a)
+	}
+
+	if e, a := "Bar", conflict.Current.Name; e != a {
+		t.Errorf("expected current item name: %v, got current item name: %v", e, a)
+	}
+}
+
+func Test_suggestItems(t *testing.T) {
+	defer func() {
+		if err := testdb.Truncate(a.DB); err != nil {
+			t.Errorf("error truncating test database tables: %v", err)
+		}
+	}()
+
+	expectedLists, err := testdb.SeedLists(a.DB)
+	if err != nil {
+		t.Fatalf("error seeding lists: %v", err)
+	}
+
+	expectedItems, err := testdb.SeedItems(a.DB, expectedLists)
+	if err != nil {
+		t.Fatalf("error seeding items: %v", err)
+	}
+
+	tests := []struct {
+		Name         string
+		ListID       int
+		Query        string
+		ExpectedCode int
+		ExpectedIDs  []int
+	}{
+		{
+			Name:         "Prefix",
+			ListID:       expectedLists[0].ID,
+			Query:        "choc",
+			ExpectedCode: http.StatusOK,
+			ExpectedIDs:  []int{expectedItems[0].ID},
+		},
+		{
+			Name:         "Typo",
+			ListID:       expectedLists[0].ID,
+			Query:        "chese",
+			ExpectedCode: http.StatusOK,
+			ExpectedIDs:  []int{expectedItems[1].ID},
+		},
+		{
+			Name:         "OtherList",
+			ListID:       expectedLists[1].ID,
+			Query:        "choc",
+			ExpectedCode: http.StatusOK,
+			ExpectedIDs:  []int{},
+		},
+		{
+			Name:         "NoQuery",
+			ListID:       expectedLists[0].ID,
+			ExpectedCode: http.StatusBadRequest,
+		},
+		{
+			Name: "NotFoundList",
+			// Using 0 for ListID because postgres serial type starts at 1 so 0 will never exist.
+			ListID:       0,
+			Query:        "choc",
+			ExpectedCode: http.StatusNotFound,
+		},
+	}
+
+	for _, test := range tests {
+		fn := func(t *testing.T) {
+			req, err := http.NewRequest(http.MethodGet, fmt.Sprintf("/list/%d/item/suggest?q=%s", test.ListID, test.Query), nil)
+			if err != nil {
+				t.Errorf("error creating request: %v", err)
+			}
+
+			w := httptest.NewRecorder()
+			a.ServeHTTP(w, req)
+
+			if e, a := test.ExpectedCode, w.Code; e != a {
+				t.Errorf("expected status code: %v, got status code: %v", e, a)
+			}
+
+			if test.ExpectedCode != http.StatusOK {
+				return
+			}
+
+			var matches []item.Match
+			resp := web.Response{
+				Results: &matches,
+			}
+
+			if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+				t.Fatalf("error decoding response body: %v", err)
+			}
+
+			ids := make([]int, 0)
+			for _, m := range matches {
+				ids = append(ids, m.ID)
+			}
+
+			if e, a := test.ExpectedIDs, ids; !cmp.Equal(e, a) {
+				t.Errorf("expected item ids: %v, got item ids: %v", e, a)
+			}
+		}
+
+		t.Run(test.Name, fn)
+	}
+}
+
+func Test_createItemDedupe(t *testing.T) {
+	defer func() {
+		if err := testdb.Truncate(a.DB); err != nil {
+			t.Errorf("error truncating test database tables: %v", err)
+		}
+	}()
+
+	expectedLists, err := testdb.SeedLists(a.DB)
+	if err != nil {
+		t.Fatalf("error seeding lists: %v", err)
+	}
+
+	expectedItems, err := testdb.SeedItems(a.DB, expectedLists)
+	if err != nil {
+		t.Fatalf("error seeding items: %v", err)
+	}
+
+	tests := []struct {
+		Name             string
+		Query            string
+		RequestBody      item.Item
+		ExpectedCode     int
+		ExpectedID       int
+		ExpectedQuantity units.Amount
+		ExpectedNotes    string
+		ExpectedTags     item.Tags
+	}{
+		{
+			Name:  "Misspelled",
+			Query: "?dedupe=true",
+			RequestBody: item.Item{
+				Name:     "choclate milk",
+				Quantity: units.Int(2),
+			},
+			ExpectedCode:     http.StatusOK,
+			ExpectedID:       expectedItems[0].ID,
+			ExpectedQuantity: expectedItems[0].Quantity.Add(units.Int(2)),
+		},
+		{
+			Name:  "NoNearMatch",
+			Query: "?dedupe=true",
+			RequestBody: item.Item{
+				Name:     "Milk",
+				Quantity: units.Int(1),
+			},
+			ExpectedCode:     http.StatusCreated,
+			ExpectedQuantity: units.Int(1),
+		},
+		{
+			Name: "WithoutDedupe",
+			RequestBody: item.Item{
+				Name:     "Mac and Cheese",
+				Quantity: units.Int(1),
+			},
+			ExpectedCode:     http.StatusCreated,
+			ExpectedQuantity: units.Int(1),
+		},
+		{
+			Name:  "NotesAndTags",
+			Query: "?dedupe=true",
+			RequestBody: item.Item{
+				Name:     "Chocolate milk",
+				Quantity: units.Int(1),
+				Notes:    "Oat",
+				Tags:     item.Tags{"dairy"},
+			},
+			ExpectedCode:     http.StatusOK,
+			ExpectedID:       expectedItems[0].ID,
+			ExpectedQuantity: expectedItems[0].Quantity.Add(units.Int(3)),
+			ExpectedNotes:    "Oat",
+			ExpectedTags:     item.Tags{"dairy"},
+		},
+		{
+			Name:  "DifferentNotes",
+			Query: "?dedupe=true",
+			RequestBody: item.Item{
+				Name:     "Chocolate Milk",
+				Quantity: units.Int(1),
+				Notes:    "Soy",
+			},
+			ExpectedCode:     http.StatusCreated,
+			ExpectedQuantity: units.Int(1),
+			ExpectedNotes:    "Soy",
+		},
+		{
+			Name:  "DifferentParent",
+			Query: "?dedupe=true",
+			RequestBody: item.Item{
+				Name:     "Chocolate Milk",
+				Quantity: units.Int(1),
+				ParentID: &expectedItems[1].ID,
+			},
+			ExpectedCode:     http.StatusCreated,
+			ExpectedQuantity: units.Int(1),
+		},
+	}
+
+	for _, test := range tests {
+		fn := func(t *testing.T) {
+			var b bytes.Buffer
+			if err := json.NewEncoder(&b).Encode(test.RequestBody); err != nil {
+				t.Errorf("error encoding request body: %v", err)
+			}
+
+			req, err := http.NewRequest(http.MethodPost, fmt.Sprintf("/list/%d/item%s", expectedLists[0].ID, test.Query), &b)
+			if err != nil {
+				t.Errorf("error creating request: %v", err)
+			}
+
+			w := httptest.NewRecorder()
+			a.ServeHTTP(w, req)
+
+			if e, a := test.ExpectedCode, w.Code; e != a {
+				t.Errorf("expected status code: %v, got status code: %v", e, a)
+			}
+
+			var i item.Item
+			resp := web.Response{
+				Results: &i,
+			}
+
+			if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+				t.Fatalf("error decoding response body: %v", err)
+			}
+
+			if test.ExpectedID != 0 {
+				if e, a := test.ExpectedID, i.ID; e != a {
+					t.Errorf("expected item id: %v, got item id: %v", e, a)
+				}
+			}
+
+			if e, a := test.ExpectedQuantity, i.Quantity; e != a {
+				t.Errorf("expected item quantity: %v, got item quantity: %v", e, a)
+			}
+
+			if e, a := test.ExpectedNotes, i.Notes; e != a {
+				t.Errorf("expected item notes: %q, got item notes: %q", e, a)
+			}
+
+			if d := cmp.Diff(test.ExpectedTags, i.Tags); d != "" {
+				t.Errorf("unexpected difference in item tags:\n%v", d)
+			}
+		}
+
+		t.Run(test.Name, fn)
+	}
+
+	// Completed items aren't added to.
+	{
+		var towels item.Item
+		url := fmt.Sprintf("/list/%d/item", expectedLists[0].ID)
+		if e, a := http.StatusCreated, sendJSON(t, http.MethodPost, url, item.Item{Name: "Paper Towels", Quantity: units.Int(1)}, &towels); e != a {
+			t.Fatalf("expected status code: %v, got status code: %v", e, a)
+		}
+
+		if e, a := http.StatusOK, sendJSON(t, http.MethodPost, fmt.Sprintf("%s/%d/complete", url, towels.ID), nil, nil); e != a {
+			t.Fatalf("expected status code: %v, got status code: %v", e, a)
+		}
+
+		var i item.Item
+		if e, a := http.StatusCreated, sendJSON(t, http.MethodPost, url+"?dedupe=true", item.Item{Name: "paper towels", Quantity: units.Int(1)}, &i); e != a {
+			t.Errorf("expected status code: %v, got status code: %v", e, a)
+		}
+
+		if i.ID == towels.ID {
+			t.Errorf("expected a new item, got the completed item %v", towels.ID)
+		}
+	}
+}
@@ -1,10 +1,12 @@
 package tests
 
 import (
+	"io/ioutil"
 	"os"
 	"testing"
 
 	"github.com/george-e-shaw-iv/integration-tests-example/cmd/listd/handlers"
+	"github.com/george-e-shaw-iv/integration-tests-example/internal/platform/blob"
 	"github.com/george-e-shaw-iv/integration-tests-example/internal/platform/testdb"
 	log "github.com/sirupsen/logrus"
 )
@@ -32,7 +34,16 @@ func testMain(m *testing.M) int {
 	}
 	defer dbc.Close()
 
+	blobDir, err := ioutil.TempDir("", "listd-blobs-")
+	if err != nil {
+		log.WithError(err).Info("create test blob directory")
+		return 1
+	}
+	defer os.RemoveAll(blobDir)
+
 	a = handlers.NewApplication(dbc)
+	a.Blobs = blob.FileStore{Dir: blobDir}
+	a.UserHeader = "X-User"
 
 	return m.Run()
 }
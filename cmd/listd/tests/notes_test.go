@@ -0,0 +1,209 @@
+package tests
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/george-e-shaw-iv/integration-tests-example/cmd/listd/item"
+	"github.com/george-e-shaw-iv/integration-tests-example/cmd/listd/list"
+	"github.com/george-e-shaw-iv/integration-tests-example/cmd/listd/search"
+	"github.com/george-e-shaw-iv/integration-tests-example/internal/platform/markdown"
+	"github.com/george-e-shaw-iv/integration-tests-example/internal/platform/testdb"
+	"github.com/george-e-shaw-iv/integration-tests-example/internal/platform/units"
+)
+
+func Test_markdownRender(t *testing.T) {
+	tests := []struct {
+		Name     string
+		Source   string
+		Expected string
+	}{
+		{
+			Name:     "Paragraph",
+			Source:   "Some *em*, **strong** and `code`.",
+			Expected: "<p>Some <em>em</em>, <strong>strong</strong> and <code>code</code>.</p>\n",
+		},
+		{
+			Name:     "Heading",
+			Source:   "## Steps",
+			Expected: "<h2>Steps</h2>\n",
+		},
+		{
+			Name:     "List",
+			Source:   "- eggs\n- flour",
+			Expected: "<ul>\n<li>eggs</li>\n<li>flour</li>\n</ul>\n",
+		},
+		{
+			Name:     "Link",
+			Source:   "[recipe](https://example.com/?a=1&b=2)",
+			Expected: "<p><a href=\"https://example.com/?a=1&amp;b=2\" rel=\"nofollow noopener noreferrer\">recipe</a></p>\n",
+		},
+		{
+			Name:     "UnsafeLink",
+			Source:   "[click](javascript:alert)",
+			Expected: "<p>click</p>\n",
+		},
+		{
+			Name:     "RawHTML",
+			Source:   "<script>alert(1)</script>",
+			Expected: "<p>&lt;script&gt;alert(1)&lt;/script&gt;</p>\n",
+		},
+		{
+			Name:     "CodeBlock",
+			Source:   "```\n<b>bold</b>\n```",
+			Expected: "<pre><code>&lt;b&gt;bold&lt;/b&gt;</code></pre>\n",
+		},
+		{
+			Name:     "IntrawordUnderscore",
+			Source:   "snake_case_name",
+			Expected: "<p>snake_case_name</p>\n",
+		},
+	}
+
+	for _, test := range tests {
+		fn := func(t *testing.T) {
+			if e, a := test.Expected, markdown.Render(test.Source); e != a {
+				t.Errorf("expected html: %q, got html: %q", e, a)
+			}
+		}
+
+		t.Run(test.Name, fn)
+	}
+}
+
+func Test_notes(t *testing.T) {
+	defer func() {
+		if err := testdb.Truncate(a.DB); err != nil {
+			t.Errorf("error truncating test database tables: %v", err)
+		}
+	}()
+
+	expectedLists, err := testdb.SeedLists(a.DB)
+	if err != nil {
+		t.Fatalf("error seeding lists: %v", err)
+	}
+
+	listURL := fmt.Sprintf("/list/%d", expectedLists[0].ID)
+
+	code := sendJSON(t, http.MethodPut, listURL, list.List{
+		Name:  expectedLists[0].Name,
+		Notes: "Shop on **Saturday**",
+	}, nil)
+	if e, a := http.StatusOK, code; e != a {
+		t.Fatalf("expected status code: %v, got status code: %v", e, a)
+	}
+
+	code, i := sendItem(t, http.MethodPost, listURL+"/item", item.Item{
+		Name:     "Flour",
+		Quantity: units.Int(1),
+		Notes:    "The <em>unbleached</em> kind",
+	})
+	if e, a := http.StatusCreated, code; e != a {
+		t.Fatalf("expected status code: %v, got status code: %v", e, a)
+	}
+
+	tests := []struct {
+		Name         string
+		URL          string
+		RequestBody  interface{}
+		ExpectedCode int
+	}{
+		{
+			Name: "ListNotesTooLong",
+			URL:  listURL,
+			RequestBody: list.List{
+				Name:  expectedLists[0].Name,
+				Notes: strings.Repeat("a", 10001),
+			},
+			ExpectedCode: http.StatusBadRequest,
+		},
+		{
+			Name: "ItemNotesTooLong",
+			URL:  fmt.Sprintf("%s/item/%d", listURL, i.ID),
+			RequestBody: item.Item{
+				Name:     "Flour",
+				Quantity: units.Int(1),
+				Notes:    strings.Repeat("é", 10001),
+			},
+			ExpectedCode: http.StatusBadRequest,
+		},
+		{
+			Name: "MultibyteNotesAtLimit",
+			URL:  fmt.Sprintf("%s/item/%d", listURL, i.ID),
+			RequestBody: item.Item{
+				Name:     "Flour",
+				Quantity: units.Int(1),
+				Notes:    strings.Repeat("é", 10000),
+			},
+			ExpectedCode: http.StatusOK,
+		},
+	}
+
+	for _, test := range tests {
+		fn := func(t *testing.T) {
+			if e, a := test.ExpectedCode, sendJSON(t, http.MethodPut, test.URL, test.RequestBody, nil); e != a {
+				t.Errorf("expected status code: %v, got status code: %v", e, a)
+			}
+		}
+
+		t.Run(test.Name, fn)
+	}
+
+	// Restore the original notes of the item.
+	code, _ = sendItem(t, http.MethodPut, fmt.Sprintf("%s/item/%d", listURL, i.ID), item.Item{
+		Name:     "Flour",
+		Quantity: units.Int(1),
+		Notes:    i.Notes,
+	})
+	if e, a := http.StatusOK, code; e != a {
+		t.Fatalf("expected status code: %v, got status code: %v", e, a)
+	}
+
+	var l list.List
+	if e, a := http.StatusOK, sendJSON(t, http.MethodGet, listURL, nil, &l); e != a {
+		t.Fatalf("expected status code: %v, got status code: %v", e, a)
+	}
+
+	if l.NotesHTML != "" {
+		t.Errorf("expected no rendered notes unless asked for, got: %q", l.NotesHTML)
+	}
+
+	l = list.List{}
+	if e, a := http.StatusOK, sendJSON(t, http.MethodGet, listURL+"?render=html", nil, &l); e != a {
+		t.Fatalf("expected status code: %v, got status code: %v", e, a)
+	}
+
+	if e, a := "<p>Shop on <strong>Saturday</strong></p>\n", l.NotesHTML; e != a {
+		t.Errorf("expected rendered notes: %q, got rendered notes: %q", e, a)
+	}
+
+	var items []item.Item
+	if e, a := http.StatusOK, sendJSON(t, http.MethodGet, listURL+"/item?render=html", nil, &items); e != a {
+		t.Fatalf("expected status code: %v, got status code: %v", e, a)
+	}
+
+	if e, a := 1, len(items); e != a {
+		t.Fatalf("expected %v items, got %v", e, a)
+	}
+
+	if e, a := "<p>The &lt;em&gt;unbleached&lt;/em&gt; kind</p>\n", items[0].NotesHTML; e != a {
+		t.Errorf("expected rendered notes: %q, got rendered notes: %q", e, a)
+	}
+
+	// Notes are searched along with names.
+	var results []search.Result
+	if e, a := http.StatusOK, sendJSON(t, http.MethodGet, "/search?q="+url.QueryEscape("unbleached"), nil, &results); e != a {
+		t.Fatalf("expected status code: %v, got status code: %v", e, a)
+	}
+
+	if len(results) != 1 || len(results[0].Hits) != 1 || results[0].Hits[0].ID != i.ID {
+		t.Fatalf("expected a single hit for item %v, got %+v", i.ID, results)
+	}
+
+	if e, a := "<mark>unbleached</mark>", results[0].Hits[0].Snippet; !strings.Contains(a, e) {
+		t.Errorf("expected snippet containing: %q, got snippet: %q", e, a)
+	}
+}
@@ -0,0 +1,310 @@
+package tests
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/george-e-shaw-iv/integration-tests-example/cmd/listd/item"
+	"github.com/george-e-shaw-iv/integration-tests-example/cmd/listd/list"
+	"github.com/george-e-shaw-iv/integration-tests-example/internal/platform/outbox"
+	"github.com/george-e-shaw-iv/integration-tests-example/internal/platform/testdb"
+	"github.com/jmoiron/sqlx"
+	"github.com/pkg/errors"
+)
+
+// recordingPublisher is an outbox.Publisher that records the topics, ids and keys
+// of the events it publishes, failing with err if it is set.
+type recordingPublisher struct {
+	mu     sync.Mutex
+	delay  time.Duration
+	err    error
+	topics []string
+	ids    []int64
+	keys   []string
+}
+
+// Publish implements the outbox.Publisher interface for the recordingPublisher type.
+func (p *recordingPublisher) Publish(ctx context.Context, tx *sqlx.Tx, e outbox.Event) error {
+	time.Sleep(p.delay)
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.err != nil {
+		return p.err
+	}
+
+	p.topics = append(p.topics, e.Topic)
+	p.ids = append(p.ids, e.ID)
+	p.keys = append(p.keys, e.Key)
+
+	return nil
+}
+
+// blockingPublisher is an outbox.Publisher that signals started when it is given
+// its first event and waits for release before publishing it.
+type blockingPublisher struct {
+	once    sync.Once
+	started chan struct{}
+	release chan struct{}
+}
+
+// Publish implements the outbox.Publisher interface for the blockingPublisher type.
+func (p *blockingPublisher) Publish(ctx context.Context, tx *sqlx.Tx, e outbox.Event) error {
+	p.once.Do(func() { close(p.started) })
+	<-p.release
+
+	return nil
+}
+
+func Test_outboxRelay(t *testing.T) {
+	defer func() {
+		if err := testdb.Truncate(a.DB); err != nil {
+			t.Errorf("error truncating test database tables: %v", err)
+		}
+	}()
+
+	req, err := http.NewRequest(http.MethodPost, "/list", strings.NewReader(`{"name":"Outbox"}`))
+	if err != nil {
+		t.Fatalf("error creating request: %v", err)
+	}
+
+	w := httptest.NewRecorder()
+	a.ServeHTTP(w, req)
+
+	if e, a := http.StatusCreated, w.Code; e != a {
+		t.Fatalf("expected status code: %v, got status code: %v", e, a)
+	}
+
+	lists, err := list.SelectLists(a.DB)
+	if err != nil || len(lists) != 1 {
+		t.Fatalf("error selecting created list: %v", err)
+	}
+
+	for _, r := range []struct {
+		Method string
+		Path   string
+		Body   string
+	}{
+		{http.MethodPost, fmt.Sprintf("/list/%d/item", lists[0].ID), `{"name":"Foo","quantity":1}`},
+		{http.MethodPut, fmt.Sprintf("/list/%d", lists[0].ID), `{"name":"Renamed"}`},
+		{http.MethodDelete, fmt.Sprintf("/list/%d", lists[0].ID), ""},
+	} {
+		req, err := http.NewRequest(r.Method, r.Path, strings.NewReader(r.Body))
+		if err != nil {
+			t.Fatalf("error creating request: %v", err)
+		}
+
+		w := httptest.NewRecorder()
+		a.ServeHTTP(w, req)
+
+		if w.Code >= http.StatusBadRequest {
+			t.Fatalf("unexpected status code for %s %s: %v", r.Method, r.Path, w.Code)
+		}
+	}
+
+	expectedTopics := []string{list.EventCreated, item.EventCreated, list.EventUpdated, list.EventDeleted}
+
+	// A failing publisher must leave every event unpublished.
+	failing := recordingPublisher{err: errors.New("broker unavailable")}
+	relay := outbox.Relay{DB: a.DB, Publisher: &failing, BatchSize: 10}
+
+	if n, err := relay.Drain(context.Background()); err == nil || n != 0 {
+		t.Errorf("expected drain to fail without publishing, published %v with error %v", n, err)
+	}
+
+	p := recordingPublisher{}
+	relay.Publisher = &p
+
+	n, err := relay.Drain(context.Background())
+	if err != nil {
+		t.Fatalf("error draining outbox: %v", err)
+	}
+
+	if e, a := len(expectedTopics), n; e != a {
+		t.Errorf("expected %v events published, got %v", e, a)
+	}
+
+	if e, a := strings.Join(expectedTopics, ","), strings.Join(p.topics, ","); e != a {
+		t.Errorf("expected topics in order: %v, got topics: %v", e, a)
+	}
+
+	if n, err := relay.Drain(context.Background()); err != nil || n != 0 {
+		t.Errorf("expected outbox to be empty, published %v with error %v", n, err)
+	}
+
+	// Published events are only pruned once they are older than the retention.
+	if n, err := relay.Prune(); err != nil || n != 0 {
+		t.Errorf("expected nothing pruned without a retention, pruned %v with error %v", n, err)
+	}
+
+	relay.Retention = time.Hour
+	if n, err := relay.Prune(); err != nil || n != 0 {
+		t.Errorf("expected nothing pruned within the retention, pruned %v with error %v", n, err)
+	}
+
+	relay.Retention = time.Nanosecond
+	if n, err := relay.Prune(); err != nil || n != int64(len(expectedTopics)) {
+		t.Errorf("expected %v events pruned, pruned %v with error %v", len(expectedTopics), n, err)
+	}
+}
+
+func Test_outboxRelayConcurrent(t *testing.T) {
+	defer func() {
+		if err := testdb.Truncate(a.DB); err != nil {
+			t.Errorf("error truncating test database tables: %v", err)
+		}
+	}()
+
+	const keys, renames = 5, 3
+
+	for i := 0; i < keys; i++ {
+		req, err := http.NewRequest(http.MethodPost, "/list", strings.NewReader(fmt.Sprintf(`{"name":"List %d"}`, i)))
+		if err != nil {
+			t.Fatalf("error creating request: %v", err)
+		}
+
+		w := httptest.NewRecorder()
+		a.ServeHTTP(w, req)
+	}
+
+	lists, err := list.SelectLists(a.DB)
+	if err != nil || len(lists) != keys {
+		t.Fatalf("error selecting created lists: %v", err)
+	}
+
+	for j := 0; j < renames; j++ {
+		for _, l := range lists {
+			req, err := http.NewRequest(http.MethodPut, fmt.Sprintf("/list/%d", l.ID), strings.NewReader(fmt.Sprintf(`{"name":"List %d %d"}`, l.ID, j)))
+			if err != nil {
+				t.Fatalf("error creating request: %v", err)
+			}
+
+			w := httptest.NewRecorder()
+			a.ServeHTTP(w, req)
+		}
+	}
+
+	// Relays sharing a publisher must never publish the same event twice, nor an
+	// event ahead of one of the same key written before it.
+	p := recordingPublisher{delay: 5 * time.Millisecond}
+
+	var wg sync.WaitGroup
+	for r := 0; r < 3; r++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			relay := outbox.Relay{DB: a.DB, Publisher: &p, BatchSize: 2}
+			for {
+				n, err := relay.Drain(context.Background())
+				if err != nil {
+					t.Errorf("error draining outbox: %v", err)
+					return
+				}
+
+				if n == 0 {
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	// A relay may stop while another still holds keys, so drain what is left.
+	relay := outbox.Relay{DB: a.DB, Publisher: &p, BatchSize: 100}
+	if _, err := relay.Drain(context.Background()); err != nil {
+		t.Errorf("error draining outbox: %v", err)
+	}
+
+	seen := make(map[int64]bool)
+	last := make(map[string]int64)
+	for i, id := range p.ids {
+		if seen[id] {
+			t.Errorf("event %v was published more than once", id)
+		}
+		seen[id] = true
+
+		key := p.keys[i]
+		if id < last[key] {
+			t.Errorf("event %v of key %v was published after event %v", id, key, last[key])
+		}
+		last[key] = id
+	}
+
+	if e, a := keys*(1+renames), len(seen); e != a {
+		t.Errorf("expected %v events published, got %v", e, a)
+	}
+}
+
+func Test_outboxRelayParallel(t *testing.T) {
+	defer func() {
+		if err := testdb.Truncate(a.DB); err != nil {
+			t.Errorf("error truncating test database tables: %v", err)
+		}
+	}()
+
+	for _, name := range []string{"First", "Second"} {
+		req, err := http.NewRequest(http.MethodPost, "/list", strings.NewReader(`{"name":"`+name+`"}`))
+		if err != nil {
+			t.Fatalf("error creating request: %v", err)
+		}
+
+		w := httptest.NewRecorder()
+		a.ServeHTTP(w, req)
+
+		if e, a := http.StatusCreated, w.Code; e != a {
+			t.Fatalf("expected status code: %v, got status code: %v", e, a)
+		}
+	}
+
+	// A relay stuck publishing the event of the first list holds its key only.
+	blocking := blockingPublisher{started: make(chan struct{}), release: make(chan struct{})}
+	stuck := outbox.Relay{DB: a.DB, Publisher: &blocking, BatchSize: 1}
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := stuck.Drain(context.Background())
+		done <- err
+	}()
+
+	<-blocking.started
+
+	p := recordingPublisher{}
+	relay := outbox.Relay{DB: a.DB, Publisher: &p, BatchSize: 10}
+
+	n, err := relay.Drain(context.Background())
+	close(blocking.release)
+
+	if err != nil {
+		t.Fatalf("error draining outbox: %v", err)
+	}
+
+	if err := <-done; err != nil {
+		t.Fatalf("error draining outbox: %v", err)
+	}
+
+	if e, a := 1, n; e != a {
+		t.Fatalf("expected %v events published alongside the stuck relay, got %v", e, a)
+	}
+
+	lists, err := list.SelectLists(a.DB)
+	if err != nil {
+		t.Fatalf("error selecting lists: %v", err)
+	}
+
+	for _, l := range lists {
+		if l.Name == "Second" {
+			if e, a := strconv.Itoa(l.ID), p.keys[0]; e != a {
+				t.Errorf("expected event of key: %v, got event of key: %v", e, a)
+			}
+		}
+	}
+}
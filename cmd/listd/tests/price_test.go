@@ -0,0 +1,165 @@
+package tests
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/george-e-shaw-iv/integration-tests-example/cmd/listd/item"
+	"github.com/george-e-shaw-iv/integration-tests-example/cmd/listd/list"
+	"github.com/george-e-shaw-iv/integration-tests-example/internal/platform/money"
+	"github.com/george-e-shaw-iv/integration-tests-example/internal/platform/testdb"
+	"github.com/george-e-shaw-iv/integration-tests-example/internal/platform/units"
+	"github.com/google/go-cmp/cmp"
+)
+
+// price returns a pointer to an amount of money, for use in payloads.
+func price(minor int64) *int64 {
+	return &minor
+}
+
+func Test_formatMoney(t *testing.T) {
+	tests := []struct {
+		Name     string
+		Amount   int64
+		Currency string
+		Expected string
+	}{
+		{Name: "Cents", Amount: 1234, Currency: "USD", Expected: "12.34"},
+		{Name: "LessThanOne", Amount: 5, Currency: "EUR", Expected: "0.05"},
+		{Name: "Negative", Amount: -150, Currency: "GBP", Expected: "-1.50"},
+		{Name: "NoMinorUnit", Amount: 1234, Currency: "JPY", Expected: "1234"},
+		{Name: "ThreeDigits", Amount: 1234, Currency: "KWD", Expected: "1.234"},
+	}
+
+	for _, test := range tests {
+		fn := func(t *testing.T) {
+			if e, a := test.Expected, money.Format(test.Amount, test.Currency); e != a {
+				t.Errorf("expected amount: %v, got amount: %v", e, a)
+			}
+		}
+
+		t.Run(test.Name, fn)
+	}
+}
+
+func Test_listSummary(t *testing.T) {
+	defer func() {
+		if err := testdb.Truncate(a.DB); err != nil {
+			t.Errorf("error truncating test database tables: %v", err)
+		}
+	}()
+
+	expectedLists, err := testdb.SeedLists(a.DB)
+	if err != nil {
+		t.Fatalf("error seeding lists: %v", err)
+	}
+
+	listURL := fmt.Sprintf("/list/%d", expectedLists[0].ID)
+	url := listURL + "/item"
+
+	invalid := []struct {
+		Name string
+		Item item.Item
+	}{
+		{Name: "NoCurrency", Item: item.Item{Name: "Bread", Quantity: units.Int(1), Price: price(250)}},
+		{Name: "NoPrice", Item: item.Item{Name: "Bread", Quantity: units.Int(1), Currency: "USD"}},
+		{Name: "NegativePrice", Item: item.Item{Name: "Bread", Quantity: units.Int(1), Price: price(-1), Currency: "USD"}},
+		{Name: "UnknownCurrency", Item: item.Item{Name: "Bread", Quantity: units.Int(1), Price: price(250), Currency: "DOUBLOONS"}},
+	}
+
+	for _, test := range invalid {
+		fn := func(t *testing.T) {
+			if e, a := http.StatusBadRequest, sendJSON(t, http.MethodPost, url, test.Item, nil); e != a {
+				t.Errorf("expected status code: %v, got status code: %v", e, a)
+			}
+		}
+
+		t.Run(test.Name, fn)
+	}
+
+	var l list.List
+	if e, a := http.StatusOK, sendJSON(t, http.MethodPut, listURL, list.List{Name: expectedLists[0].Name, Budget: price(500), BudgetCurrency: "usd"}, &l); e != a {
+		t.Fatalf("expected status code: %v, got status code: %v", e, a)
+	}
+
+	if e, a := "USD", l.BudgetCurrency; e != a {
+		t.Errorf("expected budget currency: %v, got budget currency: %v", e, a)
+	}
+
+	completed := time.Now().UTC()
+	for _, i := range []item.Item{
+		{Name: "Apples", Quantity: units.Int(3), Price: price(50), Currency: "usd"},
+		{Name: "Flour", Quantity: units.MustParseAmount("1.5"), Unit: "kg", Price: price(199), Currency: "USD"},
+		{Name: "Cheese", Quantity: units.Int(250), Unit: "g", Price: price(2), Currency: "EUR", CompletedAt: &completed},
+		{Name: "Sugar", Quantity: units.Int(500), Unit: "g"},
+	} {
+		code, created := sendItem(t, http.MethodPost, url, i)
+		if e, a := http.StatusCreated, code; e != a {
+			t.Fatalf("expected status code: %v, got status code: %v", e, a)
+		}
+
+		if i.Name == "Apples" && created.Currency != "USD" {
+			t.Errorf("expected currency: USD, got currency: %v", created.Currency)
+		}
+	}
+
+	var s item.Summary
+	if e, a := http.StatusOK, sendJSON(t, http.MethodGet, listURL+"/summary", nil, &s); e != a {
+		t.Fatalf("expected status code: %v, got status code: %v", e, a)
+	}
+
+	if e, a := 4, s.Items; e != a {
+		t.Errorf("expected %v items, got %v", e, a)
+	}
+
+	if e, a := 0.25, s.CompletedRatio; e != a {
+		t.Errorf("expected completed ratio: %v, got completed ratio: %v", e, a)
+	}
+
+	if e, a := 1, s.Unpriced; e != a {
+		t.Errorf("expected %v unpriced items, got %v", e, a)
+	}
+
+	// 3 apples at 0.50 and 1.5 kg of flour at 1.99 a kilogram, rounded to 2.99.
+	expectedTotals := []item.Total{
+		{Currency: "EUR", Amount: 500, Formatted: "5.00"},
+		{Currency: "USD", Amount: 449, Formatted: "4.49"},
+	}
+	if d := cmp.Diff(expectedTotals, s.Totals); d != "" {
+		t.Errorf("totals differ from expected:\n%s", d)
+	}
+
+	quantities := make(map[string]string)
+	for _, q := range s.Quantities {
+		quantities[q.Unit] = q.Quantity.String()
+	}
+
+	if d := cmp.Diff(map[string]string{"": "3", "kg": "2.25"}, quantities); d != "" {
+		t.Errorf("quantities differ from expected:\n%s", d)
+	}
+
+	expectedBudget := &item.Budget{Currency: "USD", Amount: 500, Spent: 449, Remaining: 51}
+	if d := cmp.Diff(expectedBudget, s.Budget); d != "" {
+		t.Errorf("budget differs from expected:\n%s", d)
+	}
+
+	code, _ := sendItem(t, http.MethodPost, url, item.Item{Name: "Butter", Quantity: units.Int(1), Price: price(100), Currency: "USD"})
+	if e, a := http.StatusCreated, code; e != a {
+		t.Fatalf("expected status code: %v, got status code: %v", e, a)
+	}
+
+	s = item.Summary{}
+	if e, a := http.StatusOK, sendJSON(t, http.MethodGet, listURL+"/summary", nil, &s); e != a {
+		t.Fatalf("expected status code: %v, got status code: %v", e, a)
+	}
+
+	if s.Budget == nil || !s.Budget.Exceeded || s.Budget.Remaining != -49 {
+		t.Errorf("expected budget to be exceeded by 49, got: %+v", s.Budget)
+	}
+
+	if e, a := http.StatusNotFound, sendJSON(t, http.MethodGet, fmt.Sprintf("/list/%d/summary", expectedLists[len(expectedLists)-1].ID+1), nil, nil); e != a {
+		t.Errorf("expected status code: %v, got status code: %v", e, a)
+	}
+}
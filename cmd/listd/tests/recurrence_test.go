@@ -0,0 +1,322 @@
+package tests
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/george-e-shaw-iv/integration-tests-example/cmd/listd/item"
+	"github.com/george-e-shaw-iv/integration-tests-example/internal/platform/rrule"
+	"github.com/george-e-shaw-iv/integration-tests-example/internal/platform/testdb"
+	"github.com/george-e-shaw-iv/integration-tests-example/internal/platform/units"
+	"github.com/george-e-shaw-iv/integration-tests-example/internal/platform/web"
+	"github.com/google/go-cmp/cmp"
+)
+
+func Test_rruleNext(t *testing.T) {
+	tests := []struct {
+		Name         string
+		Rule         string
+		From         string
+		After        string
+		ExpectedRule string
+		ExpectedNext string
+	}{
+		{
+			Name:         "Daily",
+			Rule:         "FREQ=DAILY;INTERVAL=3",
+			From:         "2019-03-01T09:00:00Z",
+			ExpectedRule: "FREQ=DAILY;INTERVAL=3",
+			ExpectedNext: "2019-03-04T09:00:00Z",
+		},
+		{
+			Name:         "WeeklySameWeek",
+			Rule:         "freq=weekly;byday=th,mo",
+			From:         "2019-03-04T09:00:00Z",
+			ExpectedRule: "FREQ=WEEKLY;BYDAY=MO,TH",
+			ExpectedNext: "2019-03-07T09:00:00Z",
+		},
+		{
+			Name:         "WeeklyNextWeek",
+			Rule:         "FREQ=WEEKLY;BYDAY=MO,TH",
+			From:         "2019-03-07T09:00:00Z",
+			ExpectedRule: "FREQ=WEEKLY;BYDAY=MO,TH",
+			ExpectedNext: "2019-03-11T09:00:00Z",
+		},
+		{
+			Name:         "FortnightlyNextWeek",
+			Rule:         "FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,TH",
+			From:         "2019-03-07T09:00:00Z",
+			ExpectedRule: "FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,TH",
+			ExpectedNext: "2019-03-18T09:00:00Z",
+		},
+		{
+			Name:         "MonthlySkipsShortMonths",
+			Rule:         "FREQ=MONTHLY",
+			From:         "2019-01-31T09:00:00Z",
+			ExpectedRule: "FREQ=MONTHLY",
+			ExpectedNext: "2019-03-31T09:00:00Z",
+		},
+		{
+			Name:         "MonthlyLastDay",
+			Rule:         "FREQ=MONTHLY;BYMONTHDAY=-1",
+			From:         "2019-01-31T09:00:00Z",
+			ExpectedRule: "FREQ=MONTHLY;BYMONTHDAY=-1",
+			ExpectedNext: "2019-02-28T09:00:00Z",
+		},
+		{
+			Name:         "UntilDate",
+			Rule:         "FREQ=DAILY;UNTIL=20190302",
+			From:         "2019-03-01T09:00:00Z",
+			ExpectedRule: "FREQ=DAILY;UNTIL=20190302T235959Z",
+			ExpectedNext: "2019-03-02T09:00:00Z",
+		},
+		{
+			Name:         "Ended",
+			Rule:         "FREQ=DAILY;UNTIL=20190302T000000Z",
+			From:         "2019-03-01T09:00:00Z",
+			ExpectedRule: "FREQ=DAILY;UNTIL=20190302T000000Z",
+		},
+		{
+			Name:         "DailyLongOverdue",
+			Rule:         "FREQ=DAILY",
+			From:         "0001-01-01T09:00:00Z",
+			After:        "2019-03-01T10:00:00Z",
+			ExpectedRule: "FREQ=DAILY",
+			ExpectedNext: "2019-03-02T09:00:00Z",
+		},
+		{
+			Name:         "FortnightlyOverdue",
+			Rule:         "FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,TH",
+			From:         "2019-03-07T09:00:00Z",
+			After:        "2021-06-15T00:00:00Z",
+			ExpectedRule: "FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,TH",
+			ExpectedNext: "2021-06-21T09:00:00Z",
+		},
+		{
+			Name:         "MonthlyOverdue",
+			Rule:         "FREQ=MONTHLY",
+			From:         "2019-01-31T09:00:00Z",
+			After:        "2023-02-15T00:00:00Z",
+			ExpectedRule: "FREQ=MONTHLY",
+			ExpectedNext: "2023-03-31T09:00:00Z",
+		},
+		{
+			Name:         "EndedWhileOverdue",
+			Rule:         "FREQ=DAILY;UNTIL=20190302",
+			From:         "2019-03-01T09:00:00Z",
+			After:        "2019-03-05T00:00:00Z",
+			ExpectedRule: "FREQ=DAILY;UNTIL=20190302T235959Z",
+		},
+	}
+
+	for _, test := range tests {
+		fn := func(t *testing.T) {
+			r, err := rrule.Parse(test.Rule)
+			if err != nil {
+				t.Fatalf("error parsing rule: %v", err)
+			}
+
+			if e, a := test.ExpectedRule, r.String(); e != a {
+				t.Errorf("expected rule: %v, got rule: %v", e, a)
+			}
+
+			from, err := time.Parse(time.RFC3339, test.From)
+			if err != nil {
+				t.Fatalf("error parsing time: %v", err)
+			}
+
+			next, ok := r.Next(from)
+			if test.After != "" {
+				after, err := time.Parse(time.RFC3339, test.After)
+				if err != nil {
+					t.Fatalf("error parsing time: %v", err)
+				}
+
+				next, ok = r.NextAfter(from, after)
+			}
+
+			if test.ExpectedNext == "" {
+				if ok {
+					t.Errorf("expected no next occurrence, got: %v", next)
+				}
+				return
+			}
+
+			if e, a := test.ExpectedNext, next.Format(time.RFC3339); !ok || e != a {
+				t.Errorf("expected next occurrence: %v, got next occurrence: %v", e, a)
+			}
+		}
+
+		t.Run(test.Name, fn)
+	}
+}
+
+func Test_completeItem(t *testing.T) {
+	defer func() {
+		if err := testdb.Truncate(a.DB); err != nil {
+			t.Errorf("error truncating test database tables: %v", err)
+		}
+	}()
+
+	expectedLists, err := testdb.SeedLists(a.DB)
+	if err != nil {
+		t.Fatalf("error seeding lists: %v", err)
+	}
+
+	due := time.Now().AddDate(0, 0, 1).Truncate(time.Second).UTC()
+	remind := due.Add(-time.Hour)
+
+	var b bytes.Buffer
+	if err := json.NewEncoder(&b).Encode(item.Item{
+		Name:       "Take out trash",
+		Quantity:   units.Int(1),
+		DueAt:      &due,
+		RemindAt:   &remind,
+		Recurrence: "freq=weekly",
+	}); err != nil {
+		t.Fatalf("error encoding request body: %v", err)
+	}
+
+	req, err := http.NewRequest(http.MethodPost, fmt.Sprintf("/list/%d/item", expectedLists[1].ID), &b)
+	if err != nil {
+		t.Fatalf("error creating request: %v", err)
+	}
+
+	w := httptest.NewRecorder()
+	a.ServeHTTP(w, req)
+
+	if e, a := http.StatusCreated, w.Code; e != a {
+		t.Fatalf("expected status code: %v, got status code: %v", e, a)
+	}
+
+	var chore item.Item
+	resp := web.Response{
+		Results: &chore,
+	}
+
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatalf("error decoding response body: %v", err)
+	}
+
+	if e, a := "FREQ=WEEKLY", chore.Recurrence; e != a {
+		t.Errorf("expected item recurrence: %v, got item recurrence: %v", e, a)
+	}
+
+	if e, a := http.StatusOK, tagItem(t, http.MethodPut, chore, "chores"); e != a {
+		t.Fatalf("expected status code: %v, got status code: %v", e, a)
+	}
+
+	// complete completes the chore and decodes the response.
+	complete := func() completionResult {
+		req, err := http.NewRequest(http.MethodPost, fmt.Sprintf("/list/%d/item/%d/complete", chore.ListID, chore.ID), nil)
+		if err != nil {
+			t.Fatalf("error creating request: %v", err)
+		}
+
+		w := httptest.NewRecorder()
+		a.ServeHTTP(w, req)
+
+		if e, a := http.StatusOK, w.Code; e != a {
+			t.Fatalf("expected status code: %v, got status code: %v", e, a)
+		}
+
+		var c completionResult
+		resp := web.Response{
+			Results: &c,
+		}
+
+		if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+			t.Fatalf("error decoding response body: %v", err)
+		}
+
+		return c
+	}
+
+	c := complete()
+
+	if c.Item.CompletedAt == nil {
+		t.Error("expected the item to be completed")
+	}
+
+	if c.Next == nil {
+		t.Fatal("expected a next occurrence, got none")
+	}
+
+	if e, a := due.AddDate(0, 0, 7), c.Next.DueAt; a == nil || !e.Equal(*a) {
+		t.Errorf("expected next occurrence due at: %v, got next occurrence due at: %v", e, a)
+	}
+
+	if e, a := remind.AddDate(0, 0, 7), c.Next.RemindAt; a == nil || !e.Equal(*a) {
+		t.Errorf("expected next occurrence reminder at: %v, got next occurrence reminder at: %v", e, a)
+	}
+
+	if c.Next.CompletedAt != nil {
+		t.Error("expected the next occurrence not to be completed")
+	}
+
+	if d := cmp.Diff(item.Tags{"chores"}, c.Next.Tags); d != "" {
+		t.Errorf("unexpected difference in next occurrence tags:\n%v", d)
+	}
+
+	// Completing the item again changes nothing.
+	if c := complete(); c.Next != nil {
+		t.Errorf("expected no next occurrence, got: %v", c.Next.ID)
+	}
+
+	// Reopening and completing the item again, as undoing the completion would,
+	// keeps the next occurrence created the first time.
+	for i := 0; i < 2; i++ {
+		var b bytes.Buffer
+		if err := json.NewEncoder(&b).Encode(item.Item{
+			Name:       chore.Name,
+			Quantity:   chore.Quantity,
+			DueAt:      chore.DueAt,
+			RemindAt:   chore.RemindAt,
+			Recurrence: chore.Recurrence,
+		}); err != nil {
+			t.Fatalf("error encoding request body: %v", err)
+		}
+
+		req, err := http.NewRequest(http.MethodPut, fmt.Sprintf("/list/%d/item/%d", chore.ListID, chore.ID), &b)
+		if err != nil {
+			t.Fatalf("error creating request: %v", err)
+		}
+
+		w := httptest.NewRecorder()
+		a.ServeHTTP(w, req)
+
+		if e, a := http.StatusOK, w.Code; e != a {
+			t.Fatalf("expected status code: %v, got status code: %v", e, a)
+		}
+
+		c := complete()
+
+		if c.Item.CompletedAt == nil {
+			t.Error("expected the reopened item to be completed")
+		}
+
+		if c.Next != nil {
+			t.Errorf("expected no next occurrence, got: %v", c.Next.ID)
+		}
+	}
+
+	items, err := item.SelectItems(a.DB, expectedLists[1].ID)
+	if err != nil {
+		t.Fatalf("error selecting items: %v", err)
+	}
+
+	if e, a := 2, len(items); e != a {
+		t.Errorf("expected %v items, got %v", e, a)
+	}
+}
+
+// completionResult is the response to completing an item.
+type completionResult struct {
+	Item item.Item  `json:"item"`
+	Next *item.Item `json:"next"`
+}
@@ -0,0 +1,374 @@
+package tests
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/george-e-shaw-iv/integration-tests-example/cmd/listd/item"
+	"github.com/george-e-shaw-iv/integration-tests-example/cmd/listd/list"
+	"github.com/george-e-shaw-iv/integration-tests-example/cmd/listd/reminder"
+	"github.com/george-e-shaw-iv/integration-tests-example/internal/platform/db"
+	"github.com/george-e-shaw-iv/integration-tests-example/internal/platform/testdb"
+	"github.com/george-e-shaw-iv/integration-tests-example/internal/platform/units"
+	"github.com/george-e-shaw-iv/integration-tests-example/internal/platform/web"
+	"github.com/jmoiron/sqlx"
+	"github.com/pkg/errors"
+)
+
+// recordingNotifier is a reminder.Notifier that records every reminder it is given.
+type recordingNotifier struct {
+	mu        sync.Mutex
+	reminders []reminder.Reminder
+}
+
+// Notify implements the reminder.Notifier interface for the recordingNotifier type.
+func (n *recordingNotifier) Notify(ctx context.Context, r reminder.Reminder) error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	n.reminders = append(n.reminders, r)
+	return nil
+}
+
+func Test_reminderScheduler(t *testing.T) {
+	defer func() {
+		if err := testdb.Truncate(a.DB); err != nil {
+			t.Errorf("error truncating test database tables: %v", err)
+		}
+	}()
+
+	expectedLists, err := testdb.SeedLists(a.DB)
+	if err != nil {
+		t.Fatalf("error seeding lists: %v", err)
+	}
+
+	past := time.Now().Add(-time.Minute).Truncate(time.Second)
+	future := time.Now().Add(time.Hour).Truncate(time.Second)
+
+	// send makes a request with body and decodes the item it responds with.
+	send := func(method, url string, body item.Item) item.Item {
+		var b bytes.Buffer
+		if err := json.NewEncoder(&b).Encode(body); err != nil {
+			t.Fatalf("error encoding request body: %v", err)
+		}
+
+		req, err := http.NewRequest(method, url, &b)
+		if err != nil {
+			t.Fatalf("error creating request: %v", err)
+		}
+
+		w := httptest.NewRecorder()
+		a.ServeHTTP(w, req)
+
+		if w.Code != http.StatusOK && w.Code != http.StatusCreated {
+			t.Fatalf("unexpected status code: %v", w.Code)
+		}
+
+		var i item.Item
+		resp := web.Response{
+			Results: &i,
+		}
+
+		if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+			t.Fatalf("error decoding response body: %v", err)
+		}
+
+		return i
+	}
+
+	due := send(http.MethodPost, fmt.Sprintf("/list/%d/item", expectedLists[1].ID), item.Item{
+		Name:     "Pay Rent",
+		Quantity: units.Int(1),
+		DueAt:    &future,
+		RemindAt: &past,
+	})
+
+	send(http.MethodPost, fmt.Sprintf("/list/%d/item", expectedLists[1].ID), item.Item{
+		Name:     "File Taxes",
+		Quantity: units.Int(1),
+		RemindAt: &future,
+	})
+
+	if due.DueAt == nil || !due.DueAt.Equal(future) {
+		t.Errorf("expected item due at: %v, got item due at: %v", future, due.DueAt)
+	}
+
+	// Several schedulers racing for the same reminders, as replicas of the daemon
+	// would, fire each reminder once between them.
+	var n recordingNotifier
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			s := reminder.Scheduler{
+				DB:        a.DB,
+				Notifier:  &n,
+				BatchSize: 10,
+			}
+
+			if _, err := s.FireDue(context.Background()); err != nil {
+				t.Errorf("error firing due reminders: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if e, a := 1, len(n.reminders); e != a {
+		t.Fatalf("expected %v reminders, got %v", e, a)
+	}
+
+	if e, a := due.ID, n.reminders[0].Item.ID; e != a {
+		t.Errorf("expected reminder for item: %v, got reminder for item: %v", e, a)
+	}
+
+	if e, a := expectedLists[1].Name, n.reminders[0].List.Name; e != a {
+		t.Errorf("expected reminder for list: %v, got reminder for list: %v", e, a)
+	}
+
+	s := reminder.Scheduler{
+		DB:        a.DB,
+		Notifier:  &n,
+		BatchSize: 10,
+	}
+
+	if sent, _ := s.FireDue(context.Background()); sent != 0 {
+		t.Errorf("expected no reminders left to fire, got %v", sent)
+	}
+
+	// Moving the reminder rearms it.
+	later := past.Add(time.Second)
+	due.RemindAt = &later
+	send(http.MethodPut, fmt.Sprintf("/list/%d/item/%d", due.ListID, due.ID), due)
+
+	if sent, _ := s.FireDue(context.Background()); sent != 1 {
+		t.Errorf("expected the moved reminder to fire, got %v reminders", sent)
+	}
+}
+
+// failingNotifier is a reminder.Notifier that fails to send the reminders of items
+// with a name it is given, recording every reminder it is given.
+type failingNotifier struct {
+	mu        sync.Mutex
+	fail      string
+	reminders []reminder.Reminder
+}
+
+// Notify implements the reminder.Notifier interface for the failingNotifier type.
+func (n *failingNotifier) Notify(ctx context.Context, r reminder.Reminder) error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	n.reminders = append(n.reminders, r)
+	if r.Item.Name == n.fail {
+		return errors.New("mailbox unavailable")
+	}
+
+	return nil
+}
+
+func Test_reminderSchedulerFailures(t *testing.T) {
+	defer func() {
+		if err := testdb.Truncate(a.DB); err != nil {
+			t.Errorf("error truncating test database tables: %v", err)
+		}
+	}()
+
+	expectedLists, err := testdb.SeedLists(a.DB)
+	if err != nil {
+		t.Fatalf("error seeding lists: %v", err)
+	}
+
+	older := time.Now().Add(-time.Hour)
+	newer := time.Now().Add(-time.Minute)
+
+	var failing, working item.Item
+	err = db.Transact(a.DB, func(tx *sqlx.Tx) error {
+		var err error
+		if failing, err = item.CreateItem(tx, item.Item{ListID: expectedLists[0].ID, Name: "Pay Rent", Quantity: units.Int(1), RemindAt: &older}); err != nil {
+			return err
+		}
+
+		working, err = item.CreateItem(tx, item.Item{ListID: expectedLists[0].ID, Name: "File Taxes", Quantity: units.Int(1), RemindAt: &newer})
+		return err
+	})
+	if err != nil {
+		t.Fatalf("error creating items: %v", err)
+	}
+
+	n := failingNotifier{fail: failing.Name}
+	s := reminder.Scheduler{
+		DB:          a.DB,
+		Notifier:    &n,
+		MaxAttempts: 2,
+		Backoff:     time.Hour,
+		BatchSize:   1,
+	}
+
+	// The older reminder fails and is backed off, which leaves the newer one to be
+	// claimed by the next run rather than starving it.
+	for i, expected := range []int{0, 1, 0} {
+		sent, err := s.FireDue(context.Background())
+		if err != nil {
+			t.Fatalf("error firing due reminders: %v", err)
+		}
+
+		if e, a := expected, sent; e != a {
+			t.Errorf("run %d: expected %v reminders sent, got %v", i, e, a)
+		}
+	}
+
+	if e, a := 2, len(n.reminders); e != a {
+		t.Fatalf("expected %v reminders, got %v", e, a)
+	}
+
+	if e, a := working.ID, n.reminders[1].Item.ID; e != a {
+		t.Errorf("expected reminder for item: %v, got reminder for item: %v", e, a)
+	}
+
+	// Once its backoff has passed, the failing reminder is attempted again and
+	// runs out of attempts, after which it is given up on.
+	if _, err := a.DB.Exec("UPDATE item SET reminder_next_attempt = NOW() WHERE item_id = $1;", failing.ID); err != nil {
+		t.Fatalf("error expiring backoff: %v", err)
+	}
+
+	for i := 0; i < 2; i++ {
+		if _, err := s.FireDue(context.Background()); err != nil {
+			t.Fatalf("error firing due reminders: %v", err)
+		}
+
+		if _, err := a.DB.Exec("UPDATE item SET reminder_next_attempt = NOW() WHERE item_id = $1;", failing.ID); err != nil {
+			t.Fatalf("error expiring backoff: %v", err)
+		}
+	}
+
+	if e, a := 3, len(n.reminders); e != a {
+		t.Errorf("expected %v reminders, got %v", e, a)
+	}
+
+	var attempts int
+	var failed bool
+	if err := a.DB.QueryRow("SELECT reminder_attempts, reminder_failed_at IS NOT NULL FROM item WHERE item_id = $1;", failing.ID).Scan(&attempts, &failed); err != nil {
+		t.Fatalf("error selecting reminder state: %v", err)
+	}
+
+	if e, a := 2, attempts; e != a {
+		t.Errorf("expected reminder attempts: %v, got reminder attempts: %v", e, a)
+	}
+
+	if !failed {
+		t.Error("expected reminder to be marked as failed")
+	}
+}
+
+func Test_smtpNotifier(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("error listening: %v", err)
+	}
+	defer l.Close()
+
+	// A fake SMTP server accepting a single message.
+	messages := make(chan string, 1)
+	go func() {
+		conn, err := l.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		r := bufio.NewReader(conn)
+		reply := func(s string) { fmt.Fprintf(conn, "%s\r\n", s) }
+
+		reply("220 localhost ESMTP")
+
+		var data bytes.Buffer
+		inData := false
+		for {
+			line, err := r.ReadString('\n')
+			if err != nil {
+				return
+			}
+
+			if inData {
+				if line == ".\r\n" {
+					inData = false
+					messages <- data.String()
+					reply("250 OK")
+					continue
+				}
+
+				data.WriteString(line)
+				continue
+			}
+
+			switch cmd := strings.ToUpper(strings.TrimSpace(line)); {
+			case strings.HasPrefix(cmd, "EHLO"), strings.HasPrefix(cmd, "HELO"):
+				reply("250 localhost")
+			case strings.HasPrefix(cmd, "DATA"):
+				inData = true
+				reply("354 Go ahead")
+			case strings.HasPrefix(cmd, "QUIT"):
+				reply("221 Bye")
+				return
+			default:
+				reply("250 OK")
+			}
+		}
+	}()
+
+	n := reminder.SMTPNotifier{
+		Addr: l.Addr().String(),
+		From: "listd@example.com",
+		To:   []string{"user@example.com"},
+	}
+
+	due := time.Date(2019, time.March, 1, 9, 0, 0, 0, time.UTC)
+	err = n.Notify(context.Background(), reminder.Reminder{
+		Item: item.Item{
+			Name:     "Pay Rent\r\nBcc: victim@example.com",
+			Quantity: units.Int(1),
+			DueAt:    &due,
+		},
+		List: list.List{
+			Name: "To-do",
+		},
+	})
+	if err != nil {
+		t.Fatalf("error sending reminder: %v", err)
+	}
+
+	var msg string
+	select {
+	case msg = <-messages:
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected a message to be received by the smtp server")
+	}
+
+	if !strings.Contains(msg, "Subject: Reminder: Pay Rent Bcc: victim@example.com\r\n") {
+		t.Errorf("expected a single line subject with the item name, got message:\n%s", msg)
+	}
+
+	if strings.Contains(msg, "\r\nBcc:") {
+		t.Errorf("expected no injected headers, got message:\n%s", msg)
+	}
+
+	if !strings.Contains(msg, "on your To-do list") {
+		t.Errorf("expected the list name in the body, got message:\n%s", msg)
+	}
+
+	if !strings.Contains(msg, "Due Fri, 01 Mar 2019 09:00:00 UTC") {
+		t.Errorf("expected the due date in the body, got message:\n%s", msg)
+	}
+}
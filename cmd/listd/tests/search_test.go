@@ -0,0 +1,146 @@
+package tests
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/george-e-shaw-iv/integration-tests-example/cmd/listd/search"
+	"github.com/george-e-shaw-iv/integration-tests-example/internal/platform/testdb"
+	"github.com/george-e-shaw-iv/integration-tests-example/internal/platform/web"
+)
+
+func Test_searchAll(t *testing.T) {
+	defer func() {
+		if err := testdb.Truncate(a.DB); err != nil {
+			t.Errorf("error truncating test database tables: %v", err)
+		}
+	}()
+
+	expectedLists, err := testdb.SeedLists(a.DB)
+	if err != nil {
+		t.Fatalf("error seeding lists: %v", err)
+	}
+
+	expectedItems, err := testdb.SeedItems(a.DB, expectedLists)
+	if err != nil {
+		t.Fatalf("error seeding items: %v", err)
+	}
+
+	tests := []struct {
+		Name            string
+		Query           string
+		ExpectedCode    int
+		ExpectedListIDs []int
+		ExpectedHit     search.Hit
+	}{
+		{
+			Name:            "Item",
+			Query:           "milk",
+			ExpectedCode:    http.StatusOK,
+			ExpectedListIDs: []int{expectedLists[0].ID},
+			ExpectedHit: search.Hit{
+				Type:    search.KindItem,
+				ID:      expectedItems[0].ID,
+				Snippet: "Chocolate <mark>Milk</mark>",
+			},
+		},
+		{
+			Name:            "List",
+			Query:           "groceries",
+			ExpectedCode:    http.StatusOK,
+			ExpectedListIDs: []int{expectedLists[0].ID},
+			ExpectedHit: search.Hit{
+				Type:    search.KindList,
+				ID:      expectedLists[0].ID,
+				Snippet: "<mark>Grocery</mark>",
+			},
+		},
+		{
+			Name:            "Phrase",
+			Query:           `"integration test"`,
+			ExpectedCode:    http.StatusOK,
+			ExpectedListIDs: []int{expectedLists[1].ID},
+			ExpectedHit: search.Hit{
+				Type:    search.KindItem,
+				ID:      expectedItems[2].ID,
+				Snippet: "Write <mark>Integration</mark> <mark>Tests</mark>",
+			},
+		},
+		{
+			Name:            "NoMatches",
+			Query:           "bananas",
+			ExpectedCode:    http.StatusOK,
+			ExpectedListIDs: []int{},
+		},
+		{
+			Name:         "NoQuery",
+			Query:        "",
+			ExpectedCode: http.StatusBadRequest,
+		},
+	}
+
+	for _, test := range tests {
+		fn := func(t *testing.T) {
+			req, err := http.NewRequest(http.MethodGet, "/search?q="+url.QueryEscape(test.Query), nil)
+			if err != nil {
+				t.Errorf("error creating request: %v", err)
+			}
+
+			w := httptest.NewRecorder()
+			a.ServeHTTP(w, req)
+
+			if e, a := test.ExpectedCode, w.Code; e != a {
+				t.Errorf("expected status code: %v, got status code: %v", e, a)
+			}
+
+			if test.ExpectedCode != http.StatusOK {
+				return
+			}
+
+			var results []search.Result
+			resp := web.Response{
+				Results: &results,
+			}
+
+			if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+				t.Fatalf("error decoding response body: %v", err)
+			}
+
+			if e, a := len(test.ExpectedListIDs), len(results); e != a {
+				t.Fatalf("expected %v lists, got %v", e, a)
+			}
+
+			for i, id := range test.ExpectedListIDs {
+				if e, a := id, results[i].ListID; e != a {
+					t.Errorf("expected list id: %v, got list id: %v", e, a)
+				}
+			}
+
+			if len(results) == 0 {
+				return
+			}
+
+			if e, a := 1, len(results[0].Hits); e != a {
+				t.Fatalf("expected %v hits, got %v", e, a)
+			}
+
+			hit := results[0].Hits[0]
+			if e, a := test.ExpectedHit.Type, hit.Type; e != a {
+				t.Errorf("expected hit type: %v, got hit type: %v", e, a)
+			}
+
+			if e, a := test.ExpectedHit.ID, hit.ID; e != a {
+				t.Errorf("expected hit id: %v, got hit id: %v", e, a)
+			}
+
+			if e, a := test.ExpectedHit.Snippet, hit.Snippet; e != a {
+				t.Errorf("expected hit snippet: %v, got hit snippet: %v", e, a)
+			}
+		}
+
+		t.Run(test.Name, fn)
+	}
+}
@@ -0,0 +1,160 @@
+package tests
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/george-e-shaw-iv/integration-tests-example/cmd/listd/item"
+	"github.com/george-e-shaw-iv/integration-tests-example/cmd/listd/stats"
+	"github.com/george-e-shaw-iv/integration-tests-example/internal/platform/testdb"
+	"github.com/george-e-shaw-iv/integration-tests-example/internal/platform/units"
+	"github.com/google/go-cmp/cmp"
+)
+
+func Test_stats(t *testing.T) {
+	defer func() {
+		if err := testdb.Truncate(a.DB); err != nil {
+			t.Errorf("error truncating test database tables: %v", err)
+		}
+	}()
+
+	expectedLists, err := testdb.SeedLists(a.DB)
+	if err != nil {
+		t.Fatalf("error seeding lists: %v", err)
+	}
+
+	expectedItems, err := testdb.SeedItems(a.DB, expectedLists)
+	if err != nil {
+		t.Fatalf("error seeding items: %v", err)
+	}
+
+	code, _ := sendItem(t, http.MethodPost, fmt.Sprintf("/list/%d/item", expectedLists[1].ID), item.Item{
+		Name:     " chocolate  MILK",
+		Quantity: units.Int(1),
+	})
+	if e, a := http.StatusCreated, code; e != a {
+		t.Fatalf("expected status code: %v, got status code: %v", e, a)
+	}
+
+	var s stats.Stats
+	if e, a := http.StatusOK, sendJSON(t, http.MethodGet, "/stats?days=2&top=1", nil, &s); e != a {
+		t.Fatalf("expected status code: %v, got status code: %v", e, a)
+	}
+
+	items := len(expectedItems) + 1
+
+	if e, a := len(expectedLists), s.Lists; e != a {
+		t.Errorf("expected %v lists, got %v", e, a)
+	}
+
+	if e, a := items, s.Items; e != a {
+		t.Errorf("expected %v items, got %v", e, a)
+	}
+
+	if e, a := float64(items)/float64(len(expectedLists)), s.AverageListSize; e != a {
+		t.Errorf("expected average list size: %v, got average list size: %v", e, a)
+	}
+
+	if e, a := 2, len(s.CreatedPerDay); e != a {
+		t.Fatalf("expected %v days, got %v", e, a)
+	}
+
+	if e, a := items, s.CreatedPerDay[0].Items+s.CreatedPerDay[1].Items; e != a {
+		t.Errorf("expected %v items created over the last 2 days, got %v", e, a)
+	}
+
+	if d := cmp.Diff([]stats.NameCount{{Name: "chocolate milk", Count: 2}}, s.TopNames); d != "" {
+		t.Errorf("top names differ from expected:\n%s", d)
+	}
+
+	tests := []struct {
+		Name string
+		URL  string
+	}{
+		{Name: "DaysNotANumber", URL: "/stats?days=week"},
+		{Name: "TooManyDays", URL: "/stats?days=366"},
+		{Name: "NoTopNames", URL: "/stats?top=0"},
+	}
+
+	for _, test := range tests {
+		fn := func(t *testing.T) {
+			if e, a := http.StatusBadRequest, sendJSON(t, http.MethodGet, test.URL, nil, nil); e != a {
+				t.Errorf("expected status code: %v, got status code: %v", e, a)
+			}
+		}
+
+		t.Run(test.Name, fn)
+	}
+}
+
+func Test_statsCache(t *testing.T) {
+	defer func() {
+		if err := testdb.Truncate(a.DB); err != nil {
+			t.Errorf("error truncating test database tables: %v", err)
+		}
+	}()
+
+	c := stats.Cache{
+		DB:  a.DB,
+		TTL: time.Hour,
+	}
+
+	o := stats.Options{Days: 1, Top: 1}
+
+	before, err := c.Get(o)
+	if err != nil {
+		t.Fatalf("error getting stats: %v", err)
+	}
+
+	if _, err := testdb.SeedLists(a.DB); err != nil {
+		t.Fatalf("error seeding lists: %v", err)
+	}
+
+	cached, err := c.Get(o)
+	if err != nil {
+		t.Fatalf("error getting stats: %v", err)
+	}
+
+	if e, a := before.Lists, cached.Lists; e != a {
+		t.Errorf("expected cached count of %v lists, got %v", e, a)
+	}
+
+	if !cached.Generated.Equal(before.Generated) {
+		t.Errorf("expected stats generated at %v to be cached, got stats generated at %v", before.Generated, cached.Generated)
+	}
+
+	fresh, err := stats.Compute(a.DB, o)
+	if err != nil {
+		t.Fatalf("error computing stats: %v", err)
+	}
+
+	if fresh.Lists == before.Lists {
+		t.Errorf("expected computed stats to count the seeded lists")
+	}
+
+	// Callers asking for the same statistics at once share a single computation.
+	c = stats.Cache{
+		DB:  a.DB,
+		TTL: time.Hour,
+	}
+
+	results := make(chan stats.Stats)
+	for i := 0; i < 8; i++ {
+		go func() {
+			s, err := c.Get(o)
+			if err != nil {
+				t.Errorf("error getting stats: %v", err)
+			}
+			results <- s
+		}()
+	}
+
+	first := <-results
+	for i := 1; i < 8; i++ {
+		if s := <-results; !s.Generated.Equal(first.Generated) {
+			t.Errorf("expected stats generated at %v, got stats generated at %v", first.Generated, s.Generated)
+		}
+	}
+}
@@ -0,0 +1,183 @@
+package tests
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/george-e-shaw-iv/integration-tests-example/cmd/listd/item"
+	"github.com/george-e-shaw-iv/integration-tests-example/cmd/listd/tag"
+	"github.com/george-e-shaw-iv/integration-tests-example/internal/platform/testdb"
+	"github.com/george-e-shaw-iv/integration-tests-example/internal/platform/web"
+	"github.com/google/go-cmp/cmp"
+)
+
+// tagItem makes a request changing a tag on an item and returns the status code.
+func tagItem(t *testing.T, method string, i item.Item, tag string) int {
+	req, err := http.NewRequest(method, fmt.Sprintf("/list/%d/item/%d/tag/%s", i.ListID, i.ID, tag), nil)
+	if err != nil {
+		t.Fatalf("error creating request: %v", err)
+	}
+
+	w := httptest.NewRecorder()
+	a.ServeHTTP(w, req)
+
+	return w.Code
+}
+
+func Test_itemTags(t *testing.T) {
+	defer func() {
+		if err := testdb.Truncate(a.DB); err != nil {
+			t.Errorf("error truncating test database tables: %v", err)
+		}
+	}()
+
+	expectedLists, err := testdb.SeedLists(a.DB)
+	if err != nil {
+		t.Fatalf("error seeding lists: %v", err)
+	}
+
+	expectedItems, err := testdb.SeedItems(a.DB, expectedLists)
+	if err != nil {
+		t.Fatalf("error seeding items: %v", err)
+	}
+
+	// Chocolate Milk is dairy and sweet, Mac and Cheese is dairy and frozen.
+	for _, c := range []struct {
+		Item item.Item
+		Tag  string
+	}{
+		{expectedItems[0], "Dairy"},
+		{expectedItems[0], "sweet"},
+		{expectedItems[1], "dairy"},
+		{expectedItems[1], "frozen"},
+		{expectedItems[1], "frozen"},
+	} {
+		if e, a := http.StatusOK, tagItem(t, http.MethodPut, c.Item, c.Tag); e != a {
+			t.Fatalf("expected status code: %v, got status code: %v", e, a)
+		}
+	}
+
+	if e, a := http.StatusNotFound, tagItem(t, http.MethodPut, item.Item{ListID: expectedLists[0].ID}, "dairy"); e != a {
+		t.Errorf("expected status code: %v, got status code: %v", e, a)
+	}
+
+	tests := []struct {
+		Name          string
+		Query         string
+		ExpectedCode  int
+		ExpectedNames []string
+	}{
+		{
+			Name:          "All",
+			Query:         "?tag=dairy&tag=frozen",
+			ExpectedCode:  http.StatusOK,
+			ExpectedNames: []string{"Mac and Cheese"},
+		},
+		{
+			Name:          "Any",
+			Query:         "?tag=sweet&tag=frozen&match=any",
+			ExpectedCode:  http.StatusOK,
+			ExpectedNames: []string{"Chocolate Milk", "Mac and Cheese"},
+		},
+		{
+			Name:          "CaseInsensitive",
+			Query:         "?tag=SWEET",
+			ExpectedCode:  http.StatusOK,
+			ExpectedNames: []string{"Chocolate Milk"},
+		},
+		{
+			Name:          "UnknownTag",
+			Query:         "?tag=meat",
+			ExpectedCode:  http.StatusOK,
+			ExpectedNames: []string{},
+		},
+		{
+			Name:         "InvalidMatch",
+			Query:        "?tag=dairy&match=some",
+			ExpectedCode: http.StatusBadRequest,
+		},
+	}
+
+	for _, test := range tests {
+		fn := func(t *testing.T) {
+			req, err := http.NewRequest(http.MethodGet, fmt.Sprintf("/list/%d/item%s", expectedLists[0].ID, test.Query), nil)
+			if err != nil {
+				t.Errorf("error creating request: %v", err)
+			}
+
+			w := httptest.NewRecorder()
+			a.ServeHTTP(w, req)
+
+			if e, a := test.ExpectedCode, w.Code; e != a {
+				t.Errorf("expected status code: %v, got status code: %v", e, a)
+			}
+
+			if test.ExpectedCode != http.StatusOK {
+				return
+			}
+
+			var items []item.Item
+			resp := web.Response{
+				Results: &items,
+			}
+
+			if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+				t.Fatalf("error decoding response body: %v", err)
+			}
+
+			names := make([]string, 0)
+			for _, i := range items {
+				names = append(names, i.Name)
+			}
+
+			if d := cmp.Diff(test.ExpectedNames, names); d != "" {
+				t.Errorf("unexpected difference in item names:\n%v", d)
+			}
+		}
+
+		t.Run(test.Name, fn)
+	}
+
+	// Tags are included with every item.
+	i, err := item.SelectItem(a.DB, expectedItems[1].ID, expectedLists[0].ID)
+	if err != nil {
+		t.Fatalf("error selecting item: %v", err)
+	}
+
+	if d := cmp.Diff(item.Tags{"dairy", "frozen"}, i.Tags); d != "" {
+		t.Errorf("unexpected difference in item tags:\n%v", d)
+	}
+
+	if e, a := http.StatusOK, tagItem(t, http.MethodDelete, expectedItems[0], "sweet"); e != a {
+		t.Fatalf("expected status code: %v, got status code: %v", e, a)
+	}
+
+	req, err := http.NewRequest(http.MethodGet, "/tag", nil)
+	if err != nil {
+		t.Fatalf("error creating request: %v", err)
+	}
+
+	w := httptest.NewRecorder()
+	a.ServeHTTP(w, req)
+
+	var tags []tag.Tag
+	resp := web.Response{
+		Results: &tags,
+	}
+
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatalf("error decoding response body: %v", err)
+	}
+
+	expectedTags := []tag.Tag{
+		{Name: "dairy", Count: 2},
+		{Name: "frozen", Count: 1},
+	}
+
+	if d := cmp.Diff(expectedTags, tags); d != "" {
+		t.Errorf("unexpected difference in tags:\n%v", d)
+	}
+}
@@ -0,0 +1,398 @@
+package tests
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/george-e-shaw-iv/integration-tests-example/cmd/listd/item"
+	"github.com/george-e-shaw-iv/integration-tests-example/cmd/listd/textlist"
+	"github.com/george-e-shaw-iv/integration-tests-example/internal/platform/testdb"
+	"github.com/george-e-shaw-iv/integration-tests-example/internal/platform/units"
+	"github.com/george-e-shaw-iv/integration-tests-example/internal/platform/web"
+	"github.com/google/go-cmp/cmp"
+)
+
+// getListText gets a list as text of the media type negotiated for accept and
+// returns the status code, content type and body of the response.
+func getListText(t *testing.T, listID int, accept string) (int, string, string) {
+	req, err := http.NewRequest(http.MethodGet, fmt.Sprintf("/list/%d", listID), nil)
+	if err != nil {
+		t.Fatalf("error creating request: %v", err)
+	}
+	req.Header.Set("Accept", accept)
+
+	w := httptest.NewRecorder()
+	a.ServeHTTP(w, req)
+
+	return w.Code, w.Header().Get("Content-Type"), w.Body.String()
+}
+
+// importText imports the items held by data, of the given content type, into a list
+// and returns the status code along with the decoded response.
+func importText(t *testing.T, listID int, contentType, data string) (int, web.Response, []item.Item) {
+	req, err := http.NewRequest(http.MethodPost, fmt.Sprintf("/list/%d/item:import", listID), strings.NewReader(data))
+	if err != nil {
+		t.Fatalf("error creating request: %v", err)
+	}
+	req.Header.Set("Content-Type", contentType)
+
+	w := httptest.NewRecorder()
+	a.ServeHTTP(w, req)
+
+	var items []item.Item
+	resp := web.Response{
+		Results: &items,
+	}
+
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatalf("error decoding response body: %v", err)
+	}
+
+	return w.Code, resp, items
+}
+
+func Test_negotiate(t *testing.T) {
+	offers := []string{"application/json", textlist.MarkdownType, textlist.TodoTxtType}
+
+	tests := []struct {
+		Name     string
+		Accept   string
+		Expected string
+	}{
+		{Name: "None", Accept: "", Expected: "application/json"},
+		{Name: "Exact", Accept: "text/markdown", Expected: textlist.MarkdownType},
+		{Name: "Parameters", Accept: "text/plain; charset=utf-8", Expected: textlist.TodoTxtType},
+		{Name: "Quality", Accept: "application/json;q=0.5, text/markdown", Expected: textlist.MarkdownType},
+		{Name: "SubtypeWildcard", Accept: "text/*", Expected: textlist.MarkdownType},
+		{Name: "Specificity", Accept: "text/*;q=0.9, text/plain", Expected: textlist.TodoTxtType},
+		{Name: "Excluded", Accept: "text/*, text/markdown;q=0", Expected: textlist.TodoTxtType},
+		{Name: "Wildcard", Accept: "*/*", Expected: "application/json"},
+		{Name: "Unacceptable", Accept: "image/png", Expected: ""},
+	}
+
+	for _, test := range tests {
+		fn := func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			if test.Accept != "" {
+				r.Header.Set("Accept", test.Accept)
+			}
+
+			if e, a := test.Expected, web.Negotiate(r, offers...); e != a {
+				t.Errorf("expected media type: %q, got media type: %q", e, a)
+			}
+		}
+
+		t.Run(test.Name, fn)
+	}
+}
+
+func Test_readText(t *testing.T) {
+	due := time.Date(2009, 11, 12, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		Name     string
+		Read     func(string) ([]textlist.Entry, error)
+		Text     string
+		Expected textlist.Entry
+	}{
+		{
+			Name:     "MarkdownPlain",
+			Read:     readMarkdown,
+			Text:     "- Chocolate Milk",
+			Expected: textlist.Entry{Item: item.Item{Name: "Chocolate Milk", Quantity: units.Int(1)}},
+		},
+		{
+			Name:     "MarkdownQuantity",
+			Read:     readMarkdown,
+			Text:     "- [ ] Chocolate Milk x2",
+			Expected: textlist.Entry{Item: item.Item{Name: "Chocolate Milk", Quantity: units.Int(2)}},
+		},
+		{
+			Name:     "MarkdownUnitDueTags",
+			Read:     readMarkdown,
+			Text:     "* [ ] Flour x1.5 kg due:2009-11-12 #baking #dry%20goods",
+			Expected: textlist.Entry{Item: item.Item{Name: "Flour", Quantity: units.MustParseAmount("1.5"), Unit: "kg", DueAt: &due}, Tags: []string{"baking", "dry goods"}},
+		},
+		{
+			Name:     "MarkdownNameWords",
+			Read:     readMarkdown,
+			Text:     "- [ ] Box of x2 #hashtags kg",
+			Expected: textlist.Entry{Item: item.Item{Name: "Box of x2 #hashtags kg", Quantity: units.Int(1)}},
+		},
+		{
+			Name:     "TodoTxtPlain",
+			Read:     readTodoTxt,
+			Text:     "(A) 2009-11-10 Chocolate Milk",
+			Expected: textlist.Entry{Item: item.Item{Name: "Chocolate Milk", Quantity: units.Int(1)}},
+		},
+		{
+			Name:     "TodoTxtKeys",
+			Read:     readTodoTxt,
+			Text:     "Flour qty:1.5 unit:kg due:2009-11-12 +baking @store",
+			Expected: textlist.Entry{Item: item.Item{Name: "Flour", Quantity: units.MustParseAmount("1.5"), Unit: "kg", DueAt: &due}, Tags: []string{"baking", "store"}},
+		},
+		{
+			Name:     "TodoTxtNameWords",
+			Read:     readTodoTxt,
+			Text:     "Buy qty and unit",
+			Expected: textlist.Entry{Item: item.Item{Name: "Buy qty and unit", Quantity: units.Int(1)}},
+		},
+	}
+
+	for _, test := range tests {
+		fn := func(t *testing.T) {
+			entries, err := test.Read(test.Text)
+			if err != nil {
+				t.Fatalf("error reading text: %v", err)
+			}
+
+			if e, a := 1, len(entries); e != a {
+				t.Fatalf("expected %v entries, got %v", e, a)
+			}
+
+			test.Expected.Line = 1
+			test.Expected.Parent = -1
+
+			if d := cmp.Diff(test.Expected, entries[0]); d != "" {
+				t.Errorf("entry differs from expected:\n%s", d)
+			}
+		}
+
+		t.Run(test.Name, fn)
+	}
+}
+
+// readMarkdown reads the entries of a Markdown checklist held by a string.
+func readMarkdown(s string) ([]textlist.Entry, error) {
+	return textlist.ReadMarkdown(strings.NewReader(s))
+}
+
+// readTodoTxt reads the entries of todo.txt tasks held by a string.
+func readTodoTxt(s string) ([]textlist.Entry, error) {
+	return textlist.ReadTodoTxt(strings.NewReader(s))
+}
+
+func Test_readTextErrors(t *testing.T) {
+	_, err := readTodoTxt("Milk\n\nFlour due:tomorrow\nEggs qty:-\n")
+
+	lerrs, ok := err.(textlist.Errors)
+	if !ok {
+		t.Fatalf("expected line errors, got: %v", err)
+	}
+
+	var lines []int
+	for _, lerr := range lerrs {
+		lines = append(lines, lerr.Line)
+	}
+
+	if d := cmp.Diff([]int{3, 4}, lines); d != "" {
+		t.Errorf("invalid lines differ from expected:\n%s", d)
+	}
+
+	entries, err := readMarkdown("# List\n\n- [ ] Bread\n  - [x] Crust\n    - Crumbs\n- Butter\n")
+	if err != nil {
+		t.Fatalf("error reading markdown: %v", err)
+	}
+
+	var parents []int
+	for _, e := range entries {
+		parents = append(parents, e.Parent)
+	}
+
+	if d := cmp.Diff([]int{-1, 0, 1, -1}, parents); d != "" {
+		t.Errorf("parents differ from expected:\n%s", d)
+	}
+}
+
+func Test_textRoundTrip(t *testing.T) {
+	defer func() {
+		if err := testdb.Truncate(a.DB); err != nil {
+			t.Errorf("error truncating test database tables: %v", err)
+		}
+	}()
+
+	expectedLists, err := testdb.SeedLists(a.DB)
+	if err != nil {
+		t.Fatalf("error seeding lists: %v", err)
+	}
+
+	if len(expectedLists) < 3 {
+		t.Fatalf("expected at least 3 seeded lists, got %v", len(expectedLists))
+	}
+
+	due := time.Date(2009, 11, 12, 0, 0, 0, 0, time.UTC)
+	dueAt := time.Date(2009, 11, 12, 17, 30, 0, 0, time.UTC)
+	completed := time.Now()
+
+	url := fmt.Sprintf("/list/%d/item", expectedLists[0].ID)
+
+	code, flour := sendItem(t, http.MethodPost, url, item.Item{Name: "Flour", Quantity: units.MustParseAmount("1.5"), Unit: "fl oz", DueAt: &due})
+	if e, a := http.StatusCreated, code; e != a {
+		t.Fatalf("expected status code: %v, got status code: %v", e, a)
+	}
+
+	if e, a := http.StatusOK, tagItem(t, http.MethodPut, flour, "dry%20goods"); e != a {
+		t.Fatalf("expected status code: %v, got status code: %v", e, a)
+	}
+
+	code, milk := sendItem(t, http.MethodPost, url, item.Item{Name: "Chocolate Milk", Quantity: units.Int(1), ParentID: &flour.ID, DueAt: &dueAt, CompletedAt: &completed})
+	if e, a := http.StatusCreated, code; e != a {
+		t.Fatalf("expected status code: %v, got status code: %v", e, a)
+	}
+
+	if code, _ := sendItem(t, http.MethodPost, url, item.Item{Name: "Eggs #12", Quantity: units.Int(12), ParentID: &milk.ID}); code != http.StatusCreated {
+		t.Fatalf("expected status code: %v, got status code: %v", http.StatusCreated, code)
+	}
+
+	original := getTree(t, expectedLists[0].ID)
+
+	tests := []struct {
+		Name   string
+		Type   string
+		ListID int
+		Nested bool
+	}{
+		{Name: "Markdown", Type: textlist.MarkdownType, ListID: expectedLists[1].ID, Nested: true},
+		{Name: "TodoTxt", Type: textlist.TodoTxtType, ListID: expectedLists[2].ID},
+	}
+
+	for _, test := range tests {
+		fn := func(t *testing.T) {
+			code, contentType, text := getListText(t, expectedLists[0].ID, test.Type)
+			if e, a := http.StatusOK, code; e != a {
+				t.Fatalf("expected status code: %v, got status code: %v", e, a)
+			}
+
+			if e, a := test.Type+"; charset=utf-8", contentType; e != a {
+				t.Errorf("expected content type: %v, got content type: %v", e, a)
+			}
+
+			code, resp, imported := importText(t, test.ListID, test.Type, text)
+			if e, a := http.StatusCreated, code; e != a {
+				t.Fatalf("expected status code: %v, got status code: %v, errors: %v", e, a, resp.Errors)
+			}
+
+			if e, a := 3, len(imported); e != a {
+				t.Fatalf("expected %v imported items, got %v", e, a)
+			}
+
+			expected, actual := flatten(original, test.Nested), flatten(getTree(t, test.ListID), test.Nested)
+			if d := cmp.Diff(expected, actual); d != "" {
+				t.Errorf("imported items differ from exported items:\n%s\nexported text:\n%s", d, text)
+			}
+		}
+
+		t.Run(test.Name, fn)
+	}
+
+	if code, _, body := getListText(t, expectedLists[0].ID, "application/json"); code != http.StatusOK || !strings.HasPrefix(body, "{") {
+		t.Errorf("expected json, got status code: %v, body: %s", code, body)
+	}
+}
+
+// textItem holds the fields of an item kept by the text formats.
+type textItem struct {
+	Depth     int
+	Name      string
+	Quantity  string
+	Unit      string
+	DueAt     string
+	Completed string
+	Tags      item.Tags
+}
+
+// flatten returns the fields of a tree of items kept by the text formats, depth
+// first, with their depth in the tree if nested is true. Only the date items were
+// completed on is kept.
+func flatten(tree []item.Item, nested bool) []textItem {
+	var items []textItem
+
+	var walk func(tree []item.Item, depth int)
+	walk = func(tree []item.Item, depth int) {
+		for _, i := range tree {
+			ti := textItem{
+				Name:     i.Name,
+				Quantity: i.Quantity.String(),
+				Unit:     i.Unit,
+				Tags:     i.Tags,
+			}
+
+			if nested {
+				ti.Depth = depth
+			}
+
+			if i.DueAt != nil {
+				ti.DueAt = i.DueAt.UTC().Format(time.RFC3339)
+			}
+
+			if i.CompletedAt != nil {
+				ti.Completed = i.CompletedAt.UTC().Format("2006-01-02")
+			}
+
+			items = append(items, ti)
+			walk(i.Children, depth+1)
+		}
+	}
+
+	walk(tree, 0)
+	return items
+}
+
+func Test_importItemsInvalid(t *testing.T) {
+	defer func() {
+		if err := testdb.Truncate(a.DB); err != nil {
+			t.Errorf("error truncating test database tables: %v", err)
+		}
+	}()
+
+	expectedLists, err := testdb.SeedLists(a.DB)
+	if err != nil {
+		t.Fatalf("error seeding lists: %v", err)
+	}
+
+	listID := expectedLists[0].ID
+
+	tests := []struct {
+		Name        string
+		ListID      int
+		ContentType string
+		Text        string
+		Code        int
+		Errors      int
+	}{
+		{Name: "UnsupportedType", ListID: listID, ContentType: "application/json", Text: "[]", Code: http.StatusUnsupportedMediaType, Errors: 1},
+		{Name: "UnreadableLines", ListID: listID, ContentType: textlist.TodoTxtType, Text: "Milk due:never\nBread\nEggs qty:some\n", Code: http.StatusBadRequest, Errors: 2},
+		{Name: "InvalidItems", ListID: listID, ContentType: textlist.TodoTxtType, Text: "Bread\nEggs qty:0\nFlour unit:furlong\n", Code: http.StatusBadRequest, Errors: 2},
+		{Name: "InvalidTag", ListID: listID, ContentType: textlist.MarkdownType, Text: "- Milk #" + strings.Repeat("a", 100), Code: http.StatusBadRequest, Errors: 1},
+		{Name: "MissingList", ListID: listID + 100, ContentType: textlist.MarkdownType, Text: "", Code: http.StatusNotFound, Errors: 1},
+	}
+
+	for _, test := range tests {
+		fn := func(t *testing.T) {
+			code, resp, _ := importText(t, test.ListID, test.ContentType, test.Text)
+			if e, a := test.Code, code; e != a {
+				t.Fatalf("expected status code: %v, got status code: %v", e, a)
+			}
+
+			if e, a := test.Errors, len(resp.Errors); e != a {
+				t.Errorf("expected %v errors, got %v: %v", e, a, resp.Errors)
+			}
+		}
+
+		t.Run(test.Name, fn)
+	}
+
+	var items []item.Item
+	if e, a := http.StatusOK, sendJSON(t, http.MethodGet, fmt.Sprintf("/list/%d/item", listID), nil, &items); e != a {
+		t.Fatalf("expected status code: %v, got status code: %v", e, a)
+	}
+
+	if e, a := 0, len(items); e != a {
+		t.Errorf("expected no items to be imported, got %v", a)
+	}
+}
@@ -0,0 +1,241 @@
+package tests
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/george-e-shaw-iv/integration-tests-example/cmd/listd/item"
+	"github.com/george-e-shaw-iv/integration-tests-example/internal/platform/testdb"
+	"github.com/george-e-shaw-iv/integration-tests-example/internal/platform/units"
+	"github.com/george-e-shaw-iv/integration-tests-example/internal/platform/web"
+)
+
+// sendItem makes a request with body and decodes the item it responds with.
+func sendItem(t *testing.T, method, url string, body interface{}) (int, item.Item) {
+	var b bytes.Buffer
+	if err := json.NewEncoder(&b).Encode(body); err != nil {
+		t.Fatalf("error encoding request body: %v", err)
+	}
+
+	req, err := http.NewRequest(method, url, &b)
+	if err != nil {
+		t.Fatalf("error creating request: %v", err)
+	}
+
+	w := httptest.NewRecorder()
+	a.ServeHTTP(w, req)
+
+	var i item.Item
+	if w.Code < 300 {
+		resp := web.Response{
+			Results: &i,
+		}
+
+		if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+			t.Fatalf("error decoding response body: %v", err)
+		}
+	}
+
+	return w.Code, i
+}
+
+// getTree returns the items of a list nested as a tree.
+func getTree(t *testing.T, listID int) []item.Item {
+	req, err := http.NewRequest(http.MethodGet, fmt.Sprintf("/list/%d/item?tree=true", listID), nil)
+	if err != nil {
+		t.Fatalf("error creating request: %v", err)
+	}
+
+	w := httptest.NewRecorder()
+	a.ServeHTTP(w, req)
+
+	if e, a := http.StatusOK, w.Code; e != a {
+		t.Fatalf("expected status code: %v, got status code: %v", e, a)
+	}
+
+	var items []item.Item
+	resp := web.Response{
+		Results: &items,
+	}
+
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatalf("error decoding response body: %v", err)
+	}
+
+	return items
+}
+
+func Test_itemTree(t *testing.T) {
+	defer func() {
+		if err := testdb.Truncate(a.DB); err != nil {
+			t.Errorf("error truncating test database tables: %v", err)
+		}
+	}()
+
+	expectedLists, err := testdb.SeedLists(a.DB)
+	if err != nil {
+		t.Fatalf("error seeding lists: %v", err)
+	}
+
+	listURL := fmt.Sprintf("/list/%d/item", expectedLists[0].ID)
+
+	// Build a chain of items as deep as allowed.
+	var chain []item.Item
+	for n := 0; n < item.MaxDepth; n++ {
+		body := item.Item{
+			Name:     fmt.Sprintf("Level %d", n+1),
+			Quantity: units.Int(1),
+		}
+		if n > 0 {
+			body.ParentID = &chain[n-1].ID
+		}
+
+		code, i := sendItem(t, http.MethodPost, listURL, body)
+		if e, a := http.StatusCreated, code; e != a {
+			t.Fatalf("expected status code: %v, got status code: %v", e, a)
+		}
+
+		chain = append(chain, i)
+	}
+
+	code, other := sendItem(t, http.MethodPost, listURL, item.Item{
+		Name:     "Other",
+		Quantity: units.Int(1),
+	})
+	if e, a := http.StatusCreated, code; e != a {
+		t.Fatalf("expected status code: %v, got status code: %v", e, a)
+	}
+
+	tests := []struct {
+		Name         string
+		Method       string
+		URL          string
+		RequestBody  interface{}
+		ExpectedCode int
+	}{
+		{
+			Name:   "TooDeep",
+			Method: http.MethodPost,
+			URL:    listURL,
+			RequestBody: item.Item{
+				Name:     "Level 5",
+				Quantity: units.Int(1),
+				ParentID: &chain[item.MaxDepth-1].ID,
+			},
+			ExpectedCode: http.StatusBadRequest,
+		},
+		{
+			Name:   "ParentOnOtherList",
+			Method: http.MethodPost,
+			URL:    fmt.Sprintf("/list/%d/item", expectedLists[1].ID),
+			RequestBody: item.Item{
+				Name:     "Elsewhere",
+				Quantity: units.Int(1),
+				ParentID: &chain[0].ID,
+			},
+			ExpectedCode: http.StatusBadRequest,
+		},
+		{
+			Name:   "UpdateCycle",
+			Method: http.MethodPut,
+			URL:    fmt.Sprintf("%s/%d", listURL, chain[0].ID),
+			RequestBody: item.Item{
+				Name:     chain[0].Name,
+				Quantity: units.Int(1),
+				ParentID: &chain[2].ID,
+			},
+			ExpectedCode: http.StatusBadRequest,
+		},
+		{
+			Name:         "MoveUnderSelf",
+			Method:       http.MethodPost,
+			URL:          fmt.Sprintf("%s/%d/move", listURL, chain[1].ID),
+			RequestBody:  map[string]interface{}{"parentID": chain[1].ID},
+			ExpectedCode: http.StatusBadRequest,
+		},
+		{
+			Name:         "MoveTooDeep",
+			Method:       http.MethodPost,
+			URL:          fmt.Sprintf("%s/%d/move", listURL, chain[0].ID),
+			RequestBody:  map[string]interface{}{"parentID": other.ID},
+			ExpectedCode: http.StatusBadRequest,
+		},
+	}
+
+	for _, test := range tests {
+		fn := func(t *testing.T) {
+			code, _ := sendItem(t, test.Method, test.URL, test.RequestBody)
+			if e, a := test.ExpectedCode, code; e != a {
+				t.Errorf("expected status code: %v, got status code: %v", e, a)
+			}
+		}
+
+		t.Run(test.Name, fn)
+	}
+
+	var tree []item.Item
+	for _, i := range getTree(t, expectedLists[0].ID) {
+		if i.ID == chain[0].ID {
+			tree = append(tree, i)
+		}
+	}
+
+	for n := 0; n < item.MaxDepth; n++ {
+		if e, a := 1, len(tree); e != a {
+			t.Fatalf("expected %v items at level %v, got %v", e, n+1, a)
+		}
+
+		if e, a := chain[n].ID, tree[0].ID; e != a {
+			t.Errorf("expected item id at level %v: %v, got item id: %v", n+1, e, a)
+		}
+
+		tree = tree[0].Children
+	}
+
+	// Moving the second level to the other list takes its sub-items along.
+	code, m := sendItem(t, http.MethodPost, fmt.Sprintf("%s/%d/move", listURL, chain[1].ID), map[string]interface{}{
+		"listID": expectedLists[1].ID,
+	})
+	if e, a := http.StatusOK, code; e != a {
+		t.Fatalf("expected status code: %v, got status code: %v", e, a)
+	}
+
+	if m.ParentID != nil {
+		t.Errorf("expected moved item to be top level, got parent: %v", *m.ParentID)
+	}
+
+	if e, a := 2, len(getTree(t, expectedLists[0].ID)); e != a {
+		t.Errorf("expected %v items left on the list, got %v", e, a)
+	}
+
+	moved := getTree(t, expectedLists[1].ID)
+	if e, a := 1, len(moved); e != a {
+		t.Fatalf("expected %v top level items on the other list, got %v", e, a)
+	}
+
+	if e, a := 1, len(moved[0].Children); e != a {
+		t.Fatalf("expected %v sub-items to be moved along, got %v", e, a)
+	}
+
+	// Deleting the moved item deletes its sub-items too.
+	req, err := http.NewRequest(http.MethodDelete, fmt.Sprintf("/list/%d/item/%d", expectedLists[1].ID, chain[1].ID), nil)
+	if err != nil {
+		t.Fatalf("error creating request: %v", err)
+	}
+
+	w := httptest.NewRecorder()
+	a.ServeHTTP(w, req)
+
+	if e, a := http.StatusNoContent, w.Code; e != a {
+		t.Fatalf("expected status code: %v, got status code: %v", e, a)
+	}
+
+	code, _ = sendItem(t, http.MethodGet, fmt.Sprintf("/list/%d/item/%d", expectedLists[1].ID, chain[3].ID), nil)
+	if e, a := http.StatusNotFound, code; e != a {
+		t.Errorf("expected status code: %v, got status code: %v", e, a)
+	}
+}
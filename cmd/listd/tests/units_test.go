@@ -0,0 +1,184 @@
+package tests
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/george-e-shaw-iv/integration-tests-example/cmd/listd/item"
+	"github.com/george-e-shaw-iv/integration-tests-example/internal/platform/testdb"
+	"github.com/george-e-shaw-iv/integration-tests-example/internal/platform/units"
+)
+
+func Test_parseAmount(t *testing.T) {
+	tests := []struct {
+		Name     string
+		Input    string
+		Expected string
+		Invalid  bool
+	}{
+		{Name: "Integer", Input: "2", Expected: "2"},
+		{Name: "Decimal", Input: "1.50", Expected: "1.5"},
+		{Name: "Negative", Input: "-0.25", Expected: "-0.25"},
+		{Name: "Exponent", Input: "1.5e3", Expected: "1500"},
+		{Name: "Rounded", Input: "0.3335", Expected: "0.334"},
+		{Name: "Fraction", Input: "1/3", Invalid: true},
+		{Name: "NotANumber", Input: "lots", Invalid: true},
+		{Name: "TooLarge", Input: "1e15", Invalid: true},
+	}
+
+	for _, test := range tests {
+		fn := func(t *testing.T) {
+			a, err := units.ParseAmount(test.Input)
+			if test.Invalid {
+				if err == nil {
+					t.Errorf("expected an error parsing %q, got amount: %v", test.Input, a)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("error parsing amount: %v", err)
+			}
+
+			if e, a := test.Expected, a.String(); e != a {
+				t.Errorf("expected amount: %v, got amount: %v", e, a)
+			}
+		}
+
+		t.Run(test.Name, fn)
+	}
+}
+
+func Test_convertUnits(t *testing.T) {
+	tests := []struct {
+		Name         string
+		Amount       string
+		From         string
+		To           string
+		Expected     string
+		Incompatible bool
+	}{
+		{Name: "KilogramsToGrams", Amount: "1.5", From: "kg", To: "g", Expected: "1500"},
+		{Name: "PoundsToKilograms", Amount: "1", From: "pounds", To: "kg", Expected: "0.454"},
+		{Name: "CupsToMilliliters", Amount: "2", From: "Cups", To: "ml", Expected: "473.176"},
+		{Name: "GallonsToQuarts", Amount: "1", From: "gal", To: "qt", Expected: "4"},
+		{Name: "DozenToPieces", Amount: "1.5", From: "dozen", To: "", Expected: "18"},
+		{Name: "MassToVolume", Amount: "1", From: "kg", To: "l", Incompatible: true},
+		{Name: "CountToMass", Amount: "1", From: "", To: "g", Incompatible: true},
+	}
+
+	for _, test := range tests {
+		fn := func(t *testing.T) {
+			c, err := units.Convert(units.MustParseAmount(test.Amount), test.From, test.To)
+			if test.Incompatible {
+				if err != units.ErrIncompatible {
+					t.Errorf("expected error: %v, got error: %v", units.ErrIncompatible, err)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("error converting amount: %v", err)
+			}
+
+			if e, a := test.Expected, c.String(); e != a {
+				t.Errorf("expected amount: %v, got amount: %v", e, a)
+			}
+		}
+
+		t.Run(test.Name, fn)
+	}
+}
+
+func Test_itemUnits(t *testing.T) {
+	defer func() {
+		if err := testdb.Truncate(a.DB); err != nil {
+			t.Errorf("error truncating test database tables: %v", err)
+		}
+	}()
+
+	expectedLists, err := testdb.SeedLists(a.DB)
+	if err != nil {
+		t.Fatalf("error seeding lists: %v", err)
+	}
+
+	url := fmt.Sprintf("/list/%d/item", expectedLists[0].ID)
+
+	code, flour := sendItem(t, http.MethodPost, url, item.Item{
+		Name:     "Flour",
+		Quantity: units.MustParseAmount("1.5"),
+		Unit:     "Kilograms",
+	})
+	if e, a := http.StatusCreated, code; e != a {
+		t.Fatalf("expected status code: %v, got status code: %v", e, a)
+	}
+
+	if e, a := "kg", flour.Unit; e != a {
+		t.Errorf("expected unit: %v, got unit: %v", e, a)
+	}
+
+	if e, a := units.MustParseAmount("1.5"), flour.Quantity; !e.Equal(a) {
+		t.Errorf("expected quantity: %v, got quantity: %v", e, a)
+	}
+
+	code, milk := sendItem(t, http.MethodPost, url, item.Item{
+		Name:     "Milk",
+		Quantity: units.Int(2),
+		Unit:     "qt",
+	})
+	if e, a := http.StatusCreated, code; e != a {
+		t.Fatalf("expected status code: %v, got status code: %v", e, a)
+	}
+
+	if e, a := http.StatusBadRequest, sendJSON(t, http.MethodPost, url, item.Item{Name: "Rice", Quantity: units.Int(1), Unit: "bushels"}, nil); e != a {
+		t.Errorf("expected status code: %v, got status code: %v", e, a)
+	}
+
+	// Adding a near duplicate in another unit of the same dimension converts it.
+	code, added := sendItem(t, http.MethodPost, url+"?dedupe=true", item.Item{
+		Name:     "flour",
+		Quantity: units.Int(500),
+		Unit:     "g",
+	})
+	if e, a := http.StatusOK, code; e != a {
+		t.Fatalf("expected status code: %v, got status code: %v", e, a)
+	}
+
+	if added.ID != flour.ID || added.Unit != "kg" || !added.Quantity.Equal(units.Int(2)) {
+		t.Errorf("expected item %v to have 2 kg, got item %v with %v %v", flour.ID, added.ID, added.Quantity, added.Unit)
+	}
+
+	// A near duplicate that can't be converted is added as a separate item.
+	code, bag := sendItem(t, http.MethodPost, url+"?dedupe=true", item.Item{
+		Name:     "Flour",
+		Quantity: units.Int(1),
+	})
+	if e, a := http.StatusCreated, code; e != a {
+		t.Fatalf("expected status code: %v, got status code: %v", e, a)
+	}
+
+	if bag.ID == flour.ID {
+		t.Errorf("expected a count of flour to be kept apart from its weight")
+	}
+
+	var items []item.Item
+	if e, a := http.StatusOK, sendJSON(t, http.MethodGet, url+"?normalize=metric", nil, &items); e != a {
+		t.Fatalf("expected status code: %v, got status code: %v", e, a)
+	}
+
+	expected := map[int]string{
+		flour.ID: "2 kg",
+		milk.ID:  "1.893 l",
+		bag.ID:   "1 ",
+	}
+	for _, i := range items {
+		if e, a := expected[i.ID], i.Quantity.String()+" "+i.Unit; e != a {
+			t.Errorf("expected item %v normalized to: %q, got: %q", i.ID, e, a)
+		}
+	}
+
+	if e, a := http.StatusBadRequest, sendJSON(t, http.MethodGet, url+"?normalize=imperial", nil, nil); e != a {
+		t.Errorf("expected status code: %v, got status code: %v", e, a)
+	}
+}
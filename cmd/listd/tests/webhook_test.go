@@ -0,0 +1,442 @@
+package tests
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/george-e-shaw-iv/integration-tests-example/cmd/listd/item"
+	"github.com/george-e-shaw-iv/integration-tests-example/cmd/listd/webhook"
+	"github.com/george-e-shaw-iv/integration-tests-example/internal/platform/outbox"
+	"github.com/george-e-shaw-iv/integration-tests-example/internal/platform/testdb"
+	"github.com/george-e-shaw-iv/integration-tests-example/internal/platform/web"
+	"github.com/google/go-cmp/cmp"
+)
+
+func Test_createWebhook(t *testing.T) {
+	defer func() {
+		if err := testdb.Truncate(a.DB); err != nil {
+			t.Errorf("error truncating test database tables: %v", err)
+		}
+	}()
+
+	expectedLists, err := testdb.SeedLists(a.DB)
+	if err != nil {
+		t.Fatalf("error seeding lists: %v", err)
+	}
+
+	tests := []struct {
+		Name         string
+		ListID       int
+		RequestBody  webhook.Webhook
+		ExpectedCode int
+	}{
+		{
+			Name:   "OK",
+			ListID: expectedLists[0].ID,
+			RequestBody: webhook.Webhook{
+				URL:    "http://example.com/hook",
+				Events: []string{webhook.EventItemCreated},
+			},
+			ExpectedCode: http.StatusCreated,
+		},
+		{
+			Name:   "InvalidURL",
+			ListID: expectedLists[0].ID,
+			RequestBody: webhook.Webhook{
+				URL:    "example.com/hook",
+				Events: []string{webhook.EventItemCreated},
+			},
+			ExpectedCode: http.StatusBadRequest,
+		},
+		{
+			Name:   "NoEvents",
+			ListID: expectedLists[0].ID,
+			RequestBody: webhook.Webhook{
+				URL: "http://example.com/hook",
+			},
+			ExpectedCode: http.StatusBadRequest,
+		},
+		{
+			Name:   "UnknownEvent",
+			ListID: expectedLists[0].ID,
+			RequestBody: webhook.Webhook{
+				URL:    "http://example.com/hook",
+				Events: []string{"item.exploded"},
+			},
+			ExpectedCode: http.StatusBadRequest,
+		},
+		{
+			Name: "NotFoundList",
+			// Using 0 for ListID because postgres serial type starts at 1 so 0 will never exist.
+			ListID: 0,
+			RequestBody: webhook.Webhook{
+				URL:    "http://example.com/hook",
+				Events: []string{webhook.EventItemCreated},
+			},
+			ExpectedCode: http.StatusNotFound,
+		},
+	}
+
+	for _, test := range tests {
+		fn := func(t *testing.T) {
+			var b bytes.Buffer
+			if err := json.NewEncoder(&b).Encode(test.RequestBody); err != nil {
+				t.Errorf("error encoding request body: %v", err)
+			}
+
+			req, err := http.NewRequest(http.MethodPost, fmt.Sprintf("/list/%d/webhook", test.ListID), &b)
+			if err != nil {
+				t.Errorf("error creating request: %v", err)
+			}
+
+			w := httptest.NewRecorder()
+			a.ServeHTTP(w, req)
+
+			if e, a := test.ExpectedCode, w.Code; e != a {
+				t.Errorf("expected status code: %v, got status code: %v", e, a)
+			}
+
+			if test.ExpectedCode == http.StatusCreated {
+				var wh webhook.Webhook
+				resp := web.Response{
+					Results: &wh,
+				}
+
+				if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+					t.Errorf("error decoding response body: %v", err)
+				}
+
+				if e, a := test.RequestBody.URL, wh.URL; e != a {
+					t.Errorf("expected webhook url: %v, got webhook url: %v", e, a)
+				}
+
+				if wh.Secret == "" {
+					t.Error("expected a generated webhook secret, got none")
+				}
+			}
+		}
+
+		t.Run(test.Name, fn)
+	}
+}
+
+func Test_deleteWebhook(t *testing.T) {
+	defer func() {
+		if err := testdb.Truncate(a.DB); err != nil {
+			t.Errorf("error truncating test database tables: %v", err)
+		}
+	}()
+
+	expectedLists, err := testdb.SeedLists(a.DB)
+	if err != nil {
+		t.Fatalf("error seeding lists: %v", err)
+	}
+
+	wh, err := webhook.CreateWebhook(a.DB, webhook.Webhook{
+		ListID: expectedLists[0].ID,
+		URL:    "http://example.com/hook",
+		Events: []string{webhook.EventItemCreated},
+	})
+	if err != nil {
+		t.Fatalf("error creating webhook: %v", err)
+	}
+
+	tests := []struct {
+		Name         string
+		ListID       int
+		WebhookID    int
+		ExpectedCode int
+	}{
+		{
+			Name:         "OK",
+			ListID:       expectedLists[0].ID,
+			WebhookID:    wh.ID,
+			ExpectedCode: http.StatusNoContent,
+		},
+		{
+			Name:         "NotFound",
+			ListID:       expectedLists[0].ID,
+			WebhookID:    wh.ID,
+			ExpectedCode: http.StatusNotFound,
+		},
+	}
+
+	for _, test := range tests {
+		fn := func(t *testing.T) {
+			req, err := http.NewRequest(http.MethodDelete, fmt.Sprintf("/list/%d/webhook/%d", test.ListID, test.WebhookID), nil)
+			if err != nil {
+				t.Errorf("error creating request: %v", err)
+			}
+
+			w := httptest.NewRecorder()
+			a.ServeHTTP(w, req)
+
+			if e, a := test.ExpectedCode, w.Code; e != a {
+				t.Errorf("expected status code: %v, got status code: %v", e, a)
+			}
+		}
+
+		t.Run(test.Name, fn)
+	}
+}
+
+func Test_webhookDelivery(t *testing.T) {
+	defer func() {
+		if err := testdb.Truncate(a.DB); err != nil {
+			t.Errorf("error truncating test database tables: %v", err)
+		}
+	}()
+
+	expectedLists, err := testdb.SeedLists(a.DB)
+	if err != nil {
+		t.Fatalf("error seeding lists: %v", err)
+	}
+
+	// The receiver fails its first request so that the retry path is exercised,
+	// and records the status of the delivery as seen from outside the dispatcher
+	// while it is being sent.
+	var mu sync.Mutex
+	var received []*http.Request
+	var bodies [][]byte
+	var statuses []string
+	var wh webhook.Webhook
+
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		b, _ := ioutil.ReadAll(r.Body)
+		received = append(received, r)
+		bodies = append(bodies, b)
+
+		if deliveries, err := webhook.SelectDeliveries(a.DB, wh.ID, wh.ListID, 1); err == nil && len(deliveries) == 1 {
+			statuses = append(statuses, deliveries[0].Status)
+		}
+
+		if len(received) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer receiver.Close()
+
+	wh, err = webhook.CreateWebhook(a.DB, webhook.Webhook{
+		ListID: expectedLists[0].ID,
+		URL:    receiver.URL,
+		Events: []string{webhook.EventItemCreated},
+	})
+	if err != nil {
+		t.Fatalf("error creating webhook: %v", err)
+	}
+
+	req, err := http.NewRequest(http.MethodPost, fmt.Sprintf("/list/%d/item", expectedLists[0].ID), strings.NewReader(`{"name":"Foo","quantity":1}`))
+	if err != nil {
+		t.Fatalf("error creating request: %v", err)
+	}
+
+	w := httptest.NewRecorder()
+	a.ServeHTTP(w, req)
+
+	if e, a := http.StatusCreated, w.Code; e != a {
+		t.Fatalf("expected status code: %v, got status code: %v", e, a)
+	}
+
+	// Relay the item created event from the outbox, queueing the webhook delivery.
+	relay := outbox.Relay{
+		DB:        a.DB,
+		Publisher: webhook.Publisher{},
+		BatchSize: 10,
+	}
+
+	if _, err := relay.Drain(context.Background()); err != nil {
+		t.Fatalf("error draining outbox: %v", err)
+	}
+
+	d := webhook.Dispatcher{
+		DB:          a.DB,
+		Client:      receiver.Client(),
+		MaxAttempts: 3,
+		Backoff:     -time.Second, // Make retries immediately due.
+		BatchSize:   10,
+	}
+
+	// First attempt fails and gets rescheduled, second attempt succeeds.
+	for i := 0; i < 2; i++ {
+		n, err := d.DispatchPending(context.Background())
+		if err != nil {
+			t.Fatalf("error dispatching deliveries: %v", err)
+		}
+
+		if e, a := 1, n; e != a {
+			t.Errorf("expected %v deliveries attempted, got %v", e, a)
+		}
+	}
+
+	if n, _ := d.DispatchPending(context.Background()); n != 0 {
+		t.Errorf("expected no deliveries left to attempt, got %v", n)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	// The claim must be committed before the delivery is sent.
+	if d := cmp.Diff([]string{webhook.StatusSending, webhook.StatusSending}, statuses); d != "" {
+		t.Errorf("unexpected difference in delivery statuses while sending:\n%v", d)
+	}
+
+	if e, a := 2, len(received); e != a {
+		t.Fatalf("expected %v requests to the receiver, got %v", e, a)
+	}
+
+	if e, a := "sha256="+webhook.Sign(wh.Secret, bodies[1]), received[1].Header.Get(webhook.SignatureHeader); e != a {
+		t.Errorf("expected signature: %v, got signature: %v", e, a)
+	}
+
+	var p struct {
+		Event string    `json:"event"`
+		Data  item.Item `json:"data"`
+	}
+	if err := json.Unmarshal(bodies[1], &p); err != nil {
+		t.Fatalf("error decoding delivery body: %v", err)
+	}
+
+	if e, a := webhook.EventItemCreated, p.Event; e != a {
+		t.Errorf("expected event: %v, got event: %v", e, a)
+	}
+
+	if e, a := "Foo", p.Data.Name; e != a {
+		t.Errorf("expected item name: %v, got item name: %v", e, a)
+	}
+
+	// The delivery log should show a single delivery that took two attempts.
+	req, err = http.NewRequest(http.MethodGet, fmt.Sprintf("/list/%d/webhook/%d/delivery", expectedLists[0].ID, wh.ID), nil)
+	if err != nil {
+		t.Fatalf("error creating request: %v", err)
+	}
+
+	w = httptest.NewRecorder()
+	a.ServeHTTP(w, req)
+
+	var deliveries []webhook.Delivery
+	resp := web.Response{
+		Results: &deliveries,
+	}
+
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatalf("error decoding response body: %v", err)
+	}
+
+	if e, a := 1, len(deliveries); e != a {
+		t.Fatalf("expected %v deliveries, got %v", e, a)
+	}
+
+	if e, a := webhook.StatusDelivered, deliveries[0].Status; e != a {
+		t.Errorf("expected delivery status: %v, got delivery status: %v", e, a)
+	}
+
+	if e, a := 2, deliveries[0].Attempts; e != a {
+		t.Errorf("expected delivery attempts: %v, got delivery attempts: %v", e, a)
+	}
+}
+
+func Test_webhookDeliveryReclaimed(t *testing.T) {
+	defer func() {
+		if err := testdb.Truncate(a.DB); err != nil {
+			t.Errorf("error truncating test database tables: %v", err)
+		}
+	}()
+
+	expectedLists, err := testdb.SeedLists(a.DB)
+	if err != nil {
+		t.Fatalf("error seeding lists: %v", err)
+	}
+
+	// While the first delivery of the batch is being sent, the other is claimed
+	// again as if its lease had run out and another dispatcher had picked it up.
+	var mu sync.Mutex
+	var received []string
+
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		id := r.Header.Get(webhook.DeliveryHeader)
+		received = append(received, id)
+
+		if len(received) == 1 {
+			if _, err := a.DB.Exec("UPDATE webhook_delivery SET next_attempt = next_attempt + interval '1 hour' WHERE delivery_id <> $1;", id); err != nil {
+				t.Errorf("error claiming delivery again: %v", err)
+			}
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer receiver.Close()
+
+	if _, err := webhook.CreateWebhook(a.DB, webhook.Webhook{
+		ListID: expectedLists[0].ID,
+		URL:    receiver.URL,
+		Events: []string{webhook.EventItemCreated},
+	}); err != nil {
+		t.Fatalf("error creating webhook: %v", err)
+	}
+
+	for _, name := range []string{"Foo", "Bar"} {
+		req, err := http.NewRequest(http.MethodPost, fmt.Sprintf("/list/%d/item", expectedLists[0].ID), strings.NewReader(`{"name":"`+name+`","quantity":1}`))
+		if err != nil {
+			t.Fatalf("error creating request: %v", err)
+		}
+
+		w := httptest.NewRecorder()
+		a.ServeHTTP(w, req)
+
+		if e, a := http.StatusCreated, w.Code; e != a {
+			t.Fatalf("expected status code: %v, got status code: %v", e, a)
+		}
+	}
+
+	relay := outbox.Relay{
+		DB:        a.DB,
+		Publisher: webhook.Publisher{},
+		BatchSize: 10,
+	}
+
+	if _, err := relay.Drain(context.Background()); err != nil {
+		t.Fatalf("error draining outbox: %v", err)
+	}
+
+	d := webhook.Dispatcher{
+		DB:          a.DB,
+		Client:      receiver.Client(),
+		MaxAttempts: 3,
+		Backoff:     time.Second,
+		BatchSize:   10,
+	}
+
+	n, err := d.DispatchPending(context.Background())
+	if err != nil {
+		t.Fatalf("error dispatching deliveries: %v", err)
+	}
+
+	if e, a := 1, n; e != a {
+		t.Errorf("expected %v deliveries attempted, got %v", e, a)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	if e, a := 1, len(received); e != a {
+		t.Errorf("expected %v requests to the receiver, got %v", e, a)
+	}
+}
@@ -0,0 +1,206 @@
+package textlist
+
+import (
+	"bufio"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/george-e-shaw-iv/integration-tests-example/cmd/listd/item"
+	"github.com/george-e-shaw-iv/integration-tests-example/cmd/listd/list"
+	"github.com/george-e-shaw-iv/integration-tests-example/internal/platform/units"
+	"github.com/pkg/errors"
+)
+
+// MarkdownType is the media type of Markdown.
+const MarkdownType = "text/markdown"
+
+// markdownIndent is the indentation of each level of sub-items.
+const markdownIndent = "  "
+
+// WriteMarkdown writes a list as a Markdown document, titled with the name of the
+// list and holding a checklist of its items. Each item is written on a line such as
+// "- [x] Flour x1.5 kg due:2009-11-10 #baking", the quantity of every item followed
+// by its unit, due time and tags. Sub-items are indented under their parents. tree
+// holds the items at the top level with their sub-items nested in their Children.
+func WriteMarkdown(w io.Writer, l list.List, tree []item.Item) error {
+	bw := bufio.NewWriter(w)
+
+	if _, err := bw.WriteString("# " + oneLine(l.Name) + "\n\n"); err != nil {
+		return errors.Wrap(err, "write title")
+	}
+
+	var write func(items []item.Item, depth int) error
+	write = func(items []item.Item, depth int) error {
+		for _, i := range items {
+			box := "[ ]"
+			if i.CompletedAt != nil {
+				box = "[x]"
+			}
+
+			line := strings.Repeat(markdownIndent, depth) + "- " + box + " " + oneLine(i.Name) + " x" + i.Quantity.String()
+			if i.Unit != "" {
+				line += " " + unitToken(i.Unit)
+			}
+
+			if _, err := bw.WriteString(line + tokens(i) + "\n"); err != nil {
+				return errors.Wrapf(err, "write item %d", i.ID)
+			}
+
+			if err := write(i.Children, depth+1); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}
+
+	if err := write(tree, 0); err != nil {
+		return err
+	}
+
+	return errors.Wrap(bw.Flush(), "flush markdown")
+}
+
+// tokens returns the due time and tags of an item as the tokens ending a line of a
+// Markdown checklist, each preceded by a space.
+func tokens(i item.Item) string {
+	var s string
+	if i.DueAt != nil {
+		s += " due:" + formatTime(*i.DueAt)
+	}
+
+	for _, tag := range i.Tags {
+		s += " #" + escapeTag.Replace(tag)
+	}
+
+	return s
+}
+
+// ReadMarkdown reads the items of the checklist in a Markdown document. Every list
+// item, starting with -, * or +, is an item, which is completed when checked with
+// [x]. Items indented under another are its sub-items. Every other line is ignored.
+// A quantity of 1 is assumed for items without one. Invalid lines are returned as
+// Errors.
+func ReadMarkdown(r io.Reader) ([]Entry, error) {
+	type level struct {
+		indent, entry int
+	}
+
+	var (
+		entries []Entry
+		invalid Errors
+		stack   []level
+		line    int
+	)
+
+	s := bufio.NewScanner(r)
+	s.Buffer(make([]byte, 4096), maxLineLength)
+
+	for s.Scan() && len(invalid) < MaxErrors {
+		line++
+
+		text := strings.Replace(strings.TrimRight(s.Text(), " \t\r"), "\t", "    ", -1)
+		if line == 1 {
+			text = strings.TrimPrefix(text, "\ufeff")
+		}
+
+		trimmed := strings.TrimLeft(text, " ")
+		indent := len(text) - len(trimmed)
+
+		if len(trimmed) < 2 || !strings.ContainsRune("-*+", rune(trimmed[0])) || trimmed[1] != ' ' {
+			continue
+		}
+		trimmed = strings.TrimLeft(trimmed[2:], " ")
+
+		e := Entry{
+			Line:   line,
+			Parent: -1,
+		}
+
+		switch {
+		case strings.HasPrefix(trimmed, "[ ] "), trimmed == "[ ]":
+			trimmed = strings.TrimPrefix(trimmed[3:], " ")
+		case strings.HasPrefix(strings.ToLower(trimmed), "[x] "), strings.ToLower(trimmed) == "[x]":
+			trimmed = strings.TrimPrefix(trimmed[3:], " ")
+			now := time.Now()
+			e.Item.CompletedAt = &now
+		}
+
+		if err := readMarkdownItem(&e, trimmed); err != nil {
+			invalid = append(invalid, &LineError{Line: line, Err: err})
+			continue
+		}
+
+		for len(stack) > 0 && stack[len(stack)-1].indent >= indent {
+			stack = stack[:len(stack)-1]
+		}
+
+		if len(stack) > 0 {
+			e.Parent = stack[len(stack)-1].entry
+		}
+
+		stack = append(stack, level{indent: indent, entry: len(entries)})
+		entries = append(entries, e)
+	}
+
+	if err := s.Err(); err != nil {
+		return nil, errors.Wrapf(err, "read line %d", line+1)
+	}
+
+	if len(invalid) > 0 {
+		return nil, invalid
+	}
+
+	return entries, nil
+}
+
+// readMarkdownItem reads the name, quantity, unit, due time and tags of an item from
+// the text of a list item following its checkbox into e.
+func readMarkdownItem(e *Entry, text string) error {
+	e.Item.Quantity = units.Int(1)
+
+	for {
+		rest, token := cutLast(text)
+		if strings.HasPrefix(token, "#") && len(token) > 1 {
+			e.Tags = append([]string{unescapeTag.Replace(token[1:])}, e.Tags...)
+		} else if strings.HasPrefix(token, "due:") {
+			due, err := parseTime(token[len("due:"):])
+			if err != nil {
+				return errors.Wrap(err, "due")
+			}
+			e.Item.DueAt = due
+		} else {
+			break
+		}
+
+		text = rest
+	}
+
+	// The quantity is either the last token or followed by the unit.
+	rest, token := cutLast(text)
+	if _, err := units.Lookup(token); err == nil && token != "" {
+		if before, q := cutLast(rest); isQuantity(q) {
+			e.Item.Unit = token
+			e.Item.Quantity, _ = units.ParseAmount(q[1:])
+			text = before
+		}
+	} else if isQuantity(token) {
+		e.Item.Quantity, _ = units.ParseAmount(token[1:])
+		text = rest
+	}
+
+	e.Item.Name = text
+	return nil
+}
+
+// isQuantity reports whether a token is a quantity, written as x followed by an
+// amount, such as x1.5.
+func isQuantity(token string) bool {
+	if len(token) < 2 || token[0] != 'x' {
+		return false
+	}
+
+	_, err := units.ParseAmount(token[1:])
+	return err == nil
+}
@@ -0,0 +1,145 @@
+// Package textlist reads and writes the items of a list as text, for users who keep
+// their lists in a terminal or text editor. Two formats are supported, Markdown
+// checklists and todo.txt.
+//
+// Both formats keep the name, quantity, unit, due time, completion and tags of an
+// item, written after its name as tokens separated by spaces. Tags containing
+// spaces have them written as %20. Only the tokens at the end of a line are read as
+// such, so words within a name are never taken for tags.
+package textlist
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/george-e-shaw-iv/integration-tests-example/cmd/listd/item"
+	"github.com/george-e-shaw-iv/integration-tests-example/cmd/listd/list"
+	"github.com/jmoiron/sqlx"
+	"github.com/pkg/errors"
+)
+
+// MaxErrors is the number of invalid lines reported, reading stops after as many.
+const MaxErrors = 100
+
+// maxLineLength is the maximum length of a line in bytes.
+const maxLineLength = 64 << 10
+
+// Entry is an item read from a line of text, along with the names of its tags.
+// Parent is the index of the entry the item is a sub-item of, or -1 for items at
+// the top level.
+type Entry struct {
+	Line   int
+	Item   item.Item
+	Tags   []string
+	Parent int
+}
+
+// LineError is an error in a line of text, the first line being line 1.
+type LineError struct {
+	Line int
+	Err  error
+}
+
+// Error implements the error interface.
+func (l *LineError) Error() string {
+	return fmt.Sprintf("line %d: %v", l.Line, l.Err)
+}
+
+// Errors are the errors in the lines of a text, in the order of the lines.
+type Errors []*LineError
+
+// Error implements the error interface.
+func (e Errors) Error() string {
+	if len(e) == 0 {
+		return "no invalid lines"
+	}
+
+	return fmt.Sprintf("%d invalid lines, first %v", len(e), e[0])
+}
+
+// Import creates the items read from entries on the list given by listID, all in tx,
+// and returns them. The parent of an entry must come before it.
+func Import(tx *sqlx.Tx, listID int, entries []Entry) ([]item.Item, error) {
+	if _, err := list.SelectList(tx, listID); err != nil {
+		return nil, err
+	}
+
+	items := make([]item.Item, 0, len(entries))
+
+	for _, e := range entries {
+		i := e.Item
+		i.ListID = listID
+
+		if e.Parent >= 0 {
+			i.ParentID = &items[e.Parent].ID
+		}
+
+		created, err := item.CreateItem(tx, i)
+		if err != nil {
+			return nil, errors.Wrapf(err, "import line %d", e.Line)
+		}
+
+		for _, tag := range e.Tags {
+			if created, err = item.AddTag(tx, created.ID, listID, tag); err != nil {
+				return nil, errors.Wrapf(err, "import line %d", e.Line)
+			}
+		}
+
+		items = append(items, created)
+	}
+
+	return items, nil
+}
+
+// escapeTag and unescapeTag escape and unescape the characters of tags that can't be
+// written in a token as is.
+var (
+	escapeTag   = strings.NewReplacer("%", "%25", " ", "%20")
+	unescapeTag = strings.NewReplacer("%20", " ", "%25", "%")
+)
+
+// oneLine replaces the line breaks in s with spaces, so that it can be written on a
+// single line.
+func oneLine(s string) string {
+	return strings.Join(strings.FieldsFunc(s, func(r rune) bool { return r == '\n' || r == '\r' }), " ")
+}
+
+// cutLast cuts the last token, separated by a space, off the end of s. The token is
+// empty when s holds a single token.
+func cutLast(s string) (rest, token string) {
+	i := strings.LastIndexByte(s, ' ')
+	if i < 0 {
+		return s, ""
+	}
+
+	return s[:i], s[i+1:]
+}
+
+// unitToken returns the symbol of a unit as a single token. Symbols with spaces,
+// such as fl oz, are registered without them too.
+func unitToken(unit string) string {
+	return strings.Replace(unit, " ", "", -1)
+}
+
+// formatTime formats a time as a date when it is at midnight UTC, or as an RFC 3339
+// timestamp otherwise.
+func formatTime(t time.Time) string {
+	t = t.UTC()
+	if t.Equal(t.Truncate(24 * time.Hour)) {
+		return t.Format("2006-01-02")
+	}
+
+	return t.Format(time.RFC3339)
+}
+
+// parseTime parses an RFC 3339 timestamp or a date, which is taken as midnight UTC.
+func parseTime(s string) (*time.Time, error) {
+	for _, layout := range []string{time.RFC3339, "2006-01-02"} {
+		if t, err := time.Parse(layout, s); err == nil {
+			return &t, nil
+		}
+	}
+
+	return nil, errors.Errorf("%q is neither an RFC 3339 timestamp nor a YYYY-MM-DD date", s)
+}
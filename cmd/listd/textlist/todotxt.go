@@ -0,0 +1,198 @@
+package textlist
+
+import (
+	"bufio"
+	"io"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/george-e-shaw-iv/integration-tests-example/cmd/listd/item"
+	"github.com/george-e-shaw-iv/integration-tests-example/internal/platform/units"
+	"github.com/pkg/errors"
+)
+
+// TodoTxtType is the media type of todo.txt, which is plain text.
+const TodoTxtType = "text/plain"
+
+// priorityRE matches the priority a todo.txt task may start with, such as (A).
+var priorityRE = regexp.MustCompile(`^\([A-Z]\) `)
+
+// WriteTodoTxt writes items as todo.txt tasks, one per line, such as
+// "x 2009-11-11 2009-11-10 Flour qty:1.5 unit:kg due:2009-11-12 +baking". Completed
+// items start with x and the date they were completed on, followed by the date every
+// item was created on. The quantity is left out when it is a single piece. Tags are
+// written as projects. tree holds the items at the top level with their sub-items
+// nested in their Children, sub-items are written as tasks of their own following
+// their parents, the tree they form is not kept.
+func WriteTodoTxt(w io.Writer, tree []item.Item) error {
+	bw := bufio.NewWriter(w)
+
+	var write func(items []item.Item) error
+	write = func(items []item.Item) error {
+		for _, i := range items {
+			if _, err := bw.WriteString(todoTxtLine(i) + "\n"); err != nil {
+				return errors.Wrapf(err, "write item %d", i.ID)
+			}
+
+			if err := write(i.Children); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}
+
+	if err := write(tree); err != nil {
+		return err
+	}
+
+	return errors.Wrap(bw.Flush(), "flush todo.txt")
+}
+
+// todoTxtLine returns an item as a todo.txt task.
+func todoTxtLine(i item.Item) string {
+	var line string
+	if i.CompletedAt != nil {
+		line = "x " + i.CompletedAt.UTC().Format("2006-01-02") + " "
+	}
+
+	line += i.Created.UTC().Format("2006-01-02") + " " + oneLine(i.Name)
+
+	if i.Unit != "" || !i.Quantity.Equal(units.Int(1)) {
+		line += " qty:" + i.Quantity.String()
+	}
+
+	if i.Unit != "" {
+		line += " unit:" + unitToken(i.Unit)
+	}
+
+	if i.DueAt != nil {
+		line += " due:" + formatTime(*i.DueAt)
+	}
+
+	for _, tag := range i.Tags {
+		line += " +" + escapeTag.Replace(tag)
+	}
+
+	return line
+}
+
+// ReadTodoTxt reads the items of todo.txt tasks, one per line. Tasks starting with x
+// are completed, on the date following it if any. Priorities and creation dates are
+// ignored. Both projects and contexts are read as tags, along with the qty, unit and
+// due keys. A quantity of 1 is assumed for items without one. Blank lines are
+// ignored. Invalid lines are returned as Errors.
+func ReadTodoTxt(r io.Reader) ([]Entry, error) {
+	var (
+		entries []Entry
+		invalid Errors
+		line    int
+	)
+
+	s := bufio.NewScanner(r)
+	s.Buffer(make([]byte, 4096), maxLineLength)
+
+	for s.Scan() && len(invalid) < MaxErrors {
+		line++
+
+		text := strings.TrimSpace(s.Text())
+		if line == 1 {
+			text = strings.TrimPrefix(text, "\ufeff")
+		}
+
+		if text == "" {
+			continue
+		}
+
+		e := Entry{
+			Line:   line,
+			Parent: -1,
+		}
+
+		if strings.HasPrefix(text, "x ") {
+			text = strings.TrimLeft(text[2:], " ")
+
+			completed := time.Now()
+			if date, rest := cutFirst(text); isDate(date) {
+				completed, _ = time.Parse("2006-01-02", date)
+				text = rest
+			}
+			e.Item.CompletedAt = &completed
+		}
+
+		text = priorityRE.ReplaceAllString(text, "")
+		if date, rest := cutFirst(text); isDate(date) {
+			text = rest
+		}
+
+		if err := readTodoTxtItem(&e, text); err != nil {
+			invalid = append(invalid, &LineError{Line: line, Err: err})
+			continue
+		}
+
+		entries = append(entries, e)
+	}
+
+	if err := s.Err(); err != nil {
+		return nil, errors.Wrapf(err, "read line %d", line+1)
+	}
+
+	if len(invalid) > 0 {
+		return nil, invalid
+	}
+
+	return entries, nil
+}
+
+// readTodoTxtItem reads the name, quantity, unit, due time and tags of an item from
+// the description of a task into e.
+func readTodoTxtItem(e *Entry, text string) error {
+	e.Item.Quantity = units.Int(1)
+
+	for {
+		rest, token := cutLast(text)
+
+		var key, value string
+		if i := strings.IndexByte(token, ':'); i > 0 {
+			key, value = token[:i], token[i+1:]
+		}
+
+		var err error
+		switch {
+		case len(token) > 1 && (token[0] == '+' || token[0] == '@'):
+			e.Tags = append([]string{unescapeTag.Replace(token[1:])}, e.Tags...)
+		case key == "qty":
+			if e.Item.Quantity, err = units.ParseAmount(value); err != nil {
+				return errors.Wrap(err, "qty")
+			}
+		case key == "unit":
+			e.Item.Unit = value
+		case key == "due":
+			if e.Item.DueAt, err = parseTime(value); err != nil {
+				return errors.Wrap(err, "due")
+			}
+		default:
+			e.Item.Name = text
+			return nil
+		}
+
+		text = rest
+	}
+}
+
+// cutFirst cuts the first token, separated by a space, off the start of s.
+func cutFirst(s string) (token, rest string) {
+	i := strings.IndexByte(s, ' ')
+	if i < 0 {
+		return s, ""
+	}
+
+	return s[:i], s[i+1:]
+}
+
+// isDate reports whether a token is a YYYY-MM-DD date.
+func isDate(token string) bool {
+	_, err := time.Parse("2006-01-02", token)
+	return err == nil
+}
@@ -0,0 +1,200 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+// Headers that are set on every delivery request.
+const (
+	EventHeader     = "X-Listd-Event"
+	DeliveryHeader  = "X-Listd-Delivery"
+	SignatureHeader = "X-Listd-Signature"
+)
+
+// maxBackoff is the upper bound of the delay in between two delivery attempts.
+const maxBackoff = 6 * time.Hour
+
+// Bounds of how long a claimed delivery is left alone before it is attempted
+// again. The lease of a delivery is renewed right before it is sent, so a
+// dispatcher is given the timeout of its client plus leaseMargin to send it and
+// record the outcome, or maxLease if its client has no timeout.
+const (
+	leaseMargin = time.Minute
+	maxLease    = 15 * time.Minute
+)
+
+// Dispatcher sends pending webhook deliveries, retrying failed attempts with
+// exponential backoff until MaxAttempts is reached.
+type Dispatcher struct {
+	DB          *sqlx.DB
+	Client      *http.Client
+	MaxAttempts int
+	Backoff     time.Duration
+	BatchSize   int
+}
+
+// claimedDelivery is a delivery marked as sending by a dispatcher along with the
+// webhook fields needed to send it. Lease is the time at which it may be claimed
+// again.
+type claimedDelivery struct {
+	ID       int       `db:"delivery_id"`
+	Event    string    `db:"event"`
+	Payload  string    `db:"payload"`
+	Attempts int       `db:"attempts"`
+	Lease    time.Time `db:"next_attempt"`
+	URL      string    `db:"url"`
+	Secret   string    `db:"secret"`
+}
+
+// Backoff returns the delay before the next attempt of a delivery that has failed
+// attempts times, doubling base with every failure.
+func Backoff(base time.Duration, attempts int) time.Duration {
+	d := base
+	for i := 1; i < attempts; i++ {
+		d *= 2
+		if d >= maxBackoff {
+			return maxBackoff
+		}
+	}
+
+	return d
+}
+
+// Run dispatches pending deliveries every interval until ctx is cancelled.
+func (d *Dispatcher) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := d.DispatchPending(ctx); err != nil {
+				log.WithError(err).Error("dispatch webhook deliveries")
+			}
+		}
+	}
+}
+
+// DispatchPending sends one batch of due deliveries and returns how many were
+// attempted. The deliveries are claimed in a statement of its own, which commits
+// before any of them is sent. Each delivery then has its lease renewed before it
+// is sent and its outcome recorded right after, so that a batch of slow endpoints
+// doesn't outlast the leases of the deliveries at its end. A delivery claimed
+// again by another dispatcher in the meantime is left to it.
+func (d *Dispatcher) DispatchPending(ctx context.Context) (int, error) {
+	now := time.Now()
+
+	var due []claimedDelivery
+	if err := d.DB.Select(&due, claimDue, now, now.Add(d.lease()), d.BatchSize); err != nil {
+		return 0, errors.Wrap(err, "claim due webhook deliveries")
+	}
+
+	var attempted int
+	for _, cd := range due {
+		if err := d.DB.Get(&cd.Lease, renewLease, time.Now().Add(d.lease()), cd.ID, cd.Lease); err != nil {
+			if errors.Cause(err) == sql.ErrNoRows {
+				continue
+			}
+
+			return attempted, errors.Wrap(err, "renew webhook delivery lease")
+		}
+
+		code, err := d.send(ctx, cd)
+		if err := d.record(cd, code, err); err != nil {
+			return attempted, err
+		}
+
+		attempted++
+	}
+
+	return attempted, nil
+}
+
+// lease returns how long a claimed delivery is left alone before it is attempted
+// again.
+func (d *Dispatcher) lease() time.Duration {
+	if d.Client.Timeout <= 0 || d.Client.Timeout+leaseMargin > maxLease {
+		return maxLease
+	}
+
+	return d.Client.Timeout + leaseMargin
+}
+
+// record stores the outcome of a delivery attempt, rescheduling the delivery if it
+// failed until it runs out of attempts. Nothing is recorded if the delivery has
+// been claimed again since its lease was renewed.
+func (d *Dispatcher) record(cd claimedDelivery, code int, sendErr error) error {
+	n := cd.Attempts + 1
+	status := StatusDelivered
+	next := time.Now()
+	lastErr := ""
+
+	if sendErr != nil {
+		lastErr = sendErr.Error()
+		status = StatusPending
+		next = next.Add(Backoff(d.Backoff, n))
+
+		if n >= d.MaxAttempts {
+			status = StatusFailed
+		}
+	}
+
+	if _, err := d.DB.Exec(updateDelivery, status, n, next, code, lastErr, time.Now(), cd.ID, cd.Lease); err != nil {
+		return errors.Wrap(err, "update webhook delivery row")
+	}
+
+	return nil
+}
+
+// send makes a single delivery attempt, returning the response status code. Any
+// non-2xx response is treated as a failure.
+func (d *Dispatcher) send(ctx context.Context, cd claimedDelivery) (int, error) {
+	body := []byte(cd.Payload)
+
+	req, err := http.NewRequest(http.MethodPost, cd.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, errors.Wrap(err, "create delivery request")
+	}
+	req = req.WithContext(ctx)
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(EventHeader, cd.Event)
+	req.Header.Set(DeliveryHeader, strconv.Itoa(cd.ID))
+	req.Header.Set(SignatureHeader, "sha256="+Sign(cd.Secret, body))
+
+	resp, err := d.Client.Do(req)
+	if err != nil {
+		return 0, errors.Wrap(err, "send delivery request")
+	}
+
+	defer func() {
+		if err := resp.Body.Close(); err != nil {
+			log.WithError(errors.Wrap(err, "close response body")).Info("send webhook delivery")
+		}
+	}()
+
+	// Drain the body so the connection can be reused.
+	if _, err := io.Copy(ioutil.Discard, resp.Body); err != nil {
+		log.WithError(errors.Wrap(err, "drain response body")).Info("send webhook delivery")
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, fmt.Errorf("unexpected response status: %d", resp.StatusCode)
+	}
+
+	return resp.StatusCode, nil
+}
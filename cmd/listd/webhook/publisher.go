@@ -0,0 +1,30 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+
+	"github.com/george-e-shaw-iv/integration-tests-example/internal/platform/outbox"
+	"github.com/jmoiron/sqlx"
+	"github.com/pkg/errors"
+)
+
+// Publisher is an outbox.Publisher that queues a delivery of every list and item
+// event to the webhooks subscribed to it. The deliveries are queued in the relay's
+// transaction, so each event is queued exactly once.
+type Publisher struct{}
+
+// Publish implements the outbox.Publisher interface for the Publisher type.
+func (Publisher) Publish(ctx context.Context, tx *sqlx.Tx, e outbox.Event) error {
+	if !ValidEvent(e.Topic) {
+		return nil
+	}
+
+	listID, err := strconv.Atoi(e.Key)
+	if err != nil {
+		return errors.Wrap(err, "convert event key to list id")
+	}
+
+	return Enqueue(tx, listID, e.Topic, json.RawMessage(e.Payload))
+}
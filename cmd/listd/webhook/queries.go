@@ -0,0 +1,62 @@
+package webhook
+
+// PostgreSQL queries for the webhook and webhook_delivery tables.
+const (
+	// selectAll is a query that selects all rows in the webhook table filtered
+	// by list_id.
+	selectAll = "SELECT * FROM webhook WHERE list_id = $1 ORDER BY webhook_id;"
+
+	// selectByIDAndListID is a query that selects a row in the webhook table
+	// filtered by webhook_id and list_id.
+	selectByIDAndListID = "SELECT * FROM webhook WHERE webhook_id = $1 AND list_id = $2;"
+
+	// insert is a query that inserts a row into the webhook table using the
+	// values given in order for list_id, url, secret, events, created, and
+	// modified.
+	insert = "INSERT INTO webhook (list_id, url, secret, events, created, modified) VALUES ($1, $2, $3, $4, $5, $6) RETURNING webhook_id;"
+
+	// del is a query that deletes a row in the webhook table given a webhook_id.
+	// Deliveries belonging to the webhook are removed by the foreign key cascade.
+	del = "DELETE FROM webhook WHERE webhook_id = $1;"
+
+	// enqueue is a query that inserts a pending row into the webhook_delivery
+	// table for every webhook on the given list_id that subscribes to the given
+	// event. The values are given in order for list_id, event, payload and
+	// created.
+	enqueue = `INSERT INTO webhook_delivery (webhook_id, event, payload, next_attempt, created, modified)
+		SELECT webhook_id, $2, $3, $4, $4, $4 FROM webhook WHERE list_id = $1 AND $2 = ANY(events);`
+
+	// selectDeliveries is a query that selects the most recent rows in the
+	// webhook_delivery table for a webhook_id, newest first.
+	selectDeliveries = "SELECT * FROM webhook_delivery WHERE webhook_id = $1 ORDER BY delivery_id DESC LIMIT $2;"
+
+	// claimDue is a query that marks up to $3 deliveries as sending whose next
+	// attempt is due at $1, either pending or left sending by a dispatcher that
+	// never recorded the outcome, and returns them along with the url and secret
+	// of their webhook. Their next attempt is pushed to $2 so that they are left
+	// alone while being sent. Rows locked by another dispatcher are skipped so
+	// multiple replicas can share the work.
+	claimDue = `UPDATE webhook_delivery d SET status = 'sending', next_attempt = $2, modified = $1
+		FROM webhook w
+		WHERE w.webhook_id = d.webhook_id AND d.delivery_id IN (
+			SELECT delivery_id FROM webhook_delivery
+			WHERE status IN ('pending', 'sending') AND next_attempt <= $1
+			ORDER BY delivery_id LIMIT $3 FOR UPDATE SKIP LOCKED)
+		RETURNING d.delivery_id, d.event, d.payload, d.attempts, d.next_attempt, w.url, w.secret;`
+
+	// renewLease is a query that pushes the next attempt of a delivery that is
+	// being sent to $1, unless the delivery has since been claimed again, and
+	// returns the new next attempt. The values are given in order for
+	// next_attempt, delivery_id and the next_attempt it was claimed with.
+	renewLease = `UPDATE webhook_delivery SET next_attempt = $1
+		WHERE delivery_id = $2 AND status = 'sending' AND next_attempt = $3
+		RETURNING next_attempt;`
+
+	// updateDelivery is a query that records the outcome of a delivery attempt,
+	// unless the delivery has since been claimed again. The values are given in
+	// order for status, attempts, next_attempt, response_code, last_error,
+	// modified, delivery_id and the next_attempt it was claimed with.
+	updateDelivery = `UPDATE webhook_delivery SET status = $1, attempts = $2, next_attempt = $3,
+		response_code = $4, last_error = $5, modified = $6
+		WHERE delivery_id = $7 AND status = 'sending' AND next_attempt = $8;`
+)
@@ -0,0 +1,203 @@
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"time"
+
+	"github.com/george-e-shaw-iv/integration-tests-example/cmd/listd/item"
+	"github.com/george-e-shaw-iv/integration-tests-example/cmd/listd/list"
+	"github.com/george-e-shaw-iv/integration-tests-example/internal/platform/db"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+// Events that a webhook is able to subscribe to.
+const (
+	EventListUpdated = list.EventUpdated
+	EventItemCreated = item.EventCreated
+	EventItemUpdated = item.EventUpdated
+	EventItemDeleted = item.EventDeleted
+)
+
+// Statuses that a delivery can be in. A delivery is sending while a dispatcher
+// attempts it, and is attempted again should the dispatcher not record the
+// outcome before its next attempt is due.
+const (
+	StatusPending   = "pending"
+	StatusSending   = "sending"
+	StatusDelivered = "delivered"
+	StatusFailed    = "failed"
+)
+
+// ValidEvent reports whether e is an event that a webhook is able to subscribe to.
+func ValidEvent(e string) bool {
+	switch e {
+	case EventListUpdated, EventItemCreated, EventItemUpdated, EventItemDeleted:
+		return true
+	}
+
+	return false
+}
+
+// Webhook is a type that contains the proper struct tags for both
+// a JSON and Postgres representation of a webhook subscription.
+type Webhook struct {
+	ID       int            `json:"id" db:"webhook_id"`
+	ListID   int            `json:"listID" db:"list_id"`
+	URL      string         `json:"url" db:"url"`
+	Secret   string         `json:"secret,omitempty" db:"secret"`
+	Events   pq.StringArray `json:"events" db:"events"`
+	Created  time.Time      `json:"created" db:"created"`
+	Modified time.Time      `json:"modified" db:"modified"`
+}
+
+// Delivery is a type that contains the proper struct tags for both a JSON
+// and Postgres representation of a single event delivery to a webhook.
+type Delivery struct {
+	ID           int       `json:"id" db:"delivery_id"`
+	WebhookID    int       `json:"webhookID" db:"webhook_id"`
+	Event        string    `json:"event" db:"event"`
+	Payload      string    `json:"payload" db:"payload"`
+	Status       string    `json:"status" db:"status"`
+	Attempts     int       `json:"attempts" db:"attempts"`
+	NextAttempt  time.Time `json:"nextAttempt" db:"next_attempt"`
+	ResponseCode int       `json:"responseCode" db:"response_code"`
+	LastError    string    `json:"lastError" db:"last_error"`
+	Created      time.Time `json:"created" db:"created"`
+	Modified     time.Time `json:"modified" db:"modified"`
+}
+
+// Payload is the body that gets sent to a webhook for every event.
+type Payload struct {
+	Event    string      `json:"event"`
+	ListID   int         `json:"listID"`
+	Occurred time.Time   `json:"occurred"`
+	Data     interface{} `json:"data"`
+}
+
+// SelectWebhooks selects all rows from the webhook table given a list_id.
+func SelectWebhooks(dbc *sqlx.DB, listID int) ([]Webhook, error) {
+	if _, err := list.SelectList(dbc, listID); errors.Cause(err) == sql.ErrNoRows {
+		return nil, sql.ErrNoRows
+	}
+
+	webhooks := make([]Webhook, 0)
+
+	if err := dbc.Select(&webhooks, selectAll, listID); err != nil {
+		return nil, errors.Wrap(err, "select all rows from webhook table given a list_id")
+	}
+
+	return webhooks, nil
+}
+
+// SelectWebhook selects a single row from the webhook table based off of a given
+// webhook_id and list_id.
+func SelectWebhook(dbc *sqlx.DB, wid, lid int) (Webhook, error) {
+	var w Webhook
+
+	if err := dbc.QueryRowx(selectByIDAndListID, wid, lid).StructScan(&w); err != nil {
+		return Webhook{}, errors.Wrap(err, "select singular row from webhook table")
+	}
+
+	return w, nil
+}
+
+// CreateWebhook inserts a new row into the webhook table. If no secret is given
+// a random one is generated and returned on the created webhook.
+func CreateWebhook(dbc *sqlx.DB, r Webhook) (Webhook, error) {
+	r.Created = time.Now()
+	r.Modified = time.Now()
+
+	if _, err := list.SelectList(dbc, r.ListID); errors.Cause(err) == sql.ErrNoRows {
+		return Webhook{}, sql.ErrNoRows
+	}
+
+	if r.Secret == "" {
+		b := make([]byte, 32)
+		if _, err := rand.Read(b); err != nil {
+			return Webhook{}, errors.Wrap(err, "generate webhook secret")
+		}
+		r.Secret = hex.EncodeToString(b)
+	}
+
+	row := dbc.QueryRow(insert, r.ListID, r.URL, r.Secret, r.Events, r.Created, r.Modified)
+
+	if err := row.Scan(&r.ID); err != nil {
+		return Webhook{}, errors.Wrap(err, "get inserted row id")
+	}
+
+	return r, nil
+}
+
+// DeleteWebhook deletes a row in the webhook table, along with its deliveries,
+// based off of webhook_id and list_id.
+func DeleteWebhook(dbc *sqlx.DB, webhookID, listID int) error {
+	if _, err := SelectWebhook(dbc, webhookID, listID); errors.Cause(err) == sql.ErrNoRows {
+		return sql.ErrNoRows
+	}
+
+	if _, err := dbc.Exec(del, webhookID); err != nil {
+		return errors.Wrap(err, "delete webhook row")
+	}
+
+	return nil
+}
+
+// SelectDeliveries selects the most recent rows from the webhook_delivery table for
+// a webhook, newest first.
+func SelectDeliveries(dbc *sqlx.DB, webhookID, listID, limit int) ([]Delivery, error) {
+	if _, err := SelectWebhook(dbc, webhookID, listID); errors.Cause(err) == sql.ErrNoRows {
+		return nil, sql.ErrNoRows
+	}
+
+	deliveries := make([]Delivery, 0)
+
+	if err := dbc.Select(&deliveries, selectDeliveries, webhookID, limit); err != nil {
+		return nil, errors.Wrap(err, "select rows from webhook_delivery table given a webhook_id")
+	}
+
+	return deliveries, nil
+}
+
+// Enqueue queues a delivery of event to every webhook on the list that subscribes
+// to it. The deliveries are sent later by a Dispatcher.
+func Enqueue(dbc db.Executor, listID int, event string, data interface{}) error {
+	now := time.Now()
+
+	b, err := json.Marshal(Payload{
+		Event:    event,
+		ListID:   listID,
+		Occurred: now,
+		Data:     data,
+	})
+	if err != nil {
+		return errors.Wrap(err, "marshal webhook payload")
+	}
+
+	if _, err := dbc.Exec(enqueue, listID, event, string(b), now); err != nil {
+		return errors.Wrap(err, "insert webhook delivery rows")
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"listID": listID,
+		"event":  event,
+	}).Debug("enqueued webhook deliveries")
+
+	return nil
+}
+
+// Sign returns the hex encoded HMAC-SHA256 of body using the webhook secret. The
+// receiver recomputes this to verify that a delivery came from the list daemon.
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -1,6 +1,8 @@
 module github.com/george-e-shaw-iv/integration-tests-example
 
 require (
+	github.com/fxamacker/cbor/v2 v2.2.0
+	github.com/golang/protobuf v1.3.1
 	github.com/google/go-cmp v0.2.0
 	github.com/jmoiron/sqlx v1.2.0
 	github.com/julienschmidt/httprouter v1.2.0
@@ -9,5 +11,10 @@ require (
 	github.com/pborman/uuid v1.2.0
 	github.com/pkg/errors v0.8.0
 	github.com/sirupsen/logrus v1.2.0
+	github.com/vmihailenco/msgpack/v5 v5.3.5
+	github.com/vmihailenco/tagparser/v2 v2.0.0 // indirect
+	github.com/x448/float16 v0.8.4 // indirect
 	google.golang.org/appengine v1.4.0 // indirect
+	google.golang.org/grpc v1.21.0
+	gopkg.in/yaml.v2 v2.2.8
 )
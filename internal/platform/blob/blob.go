@@ -0,0 +1,54 @@
+// Package blob stores the contents of files, such as item attachments, outside of
+// Postgres. Blobs are written once under a key and then read or deleted by it.
+package blob
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"io"
+
+	"github.com/pkg/errors"
+)
+
+// ErrNotFound is returned when no blob is stored under a key.
+var ErrNotFound = errors.New("blob not found")
+
+// Store is a place blobs are kept.
+type Store interface {
+	// Put stores data under key, replacing any blob already stored under it.
+	Put(ctx context.Context, key string, data []byte, contentType string) error
+
+	// Get opens the blob stored under key, which the caller must close.
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+
+	// Delete removes the blob stored under key. Deleting a key that has no blob
+	// is not an error.
+	Delete(ctx context.Context, key string) error
+}
+
+// NewKey returns a random key to store a new blob under.
+func NewKey() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", errors.Wrap(err, "generate blob key")
+	}
+
+	return hex.EncodeToString(b), nil
+}
+
+// validKey reports whether key is safe to use as a file name or URL path segment,
+// which keys made by NewKey always are.
+func validKey(key string) bool {
+	if key == "" || len(key) > 128 {
+		return false
+	}
+
+	for _, c := range key {
+		if !(c >= 'a' && c <= 'z' || c >= '0' && c <= '9' || c == '-') {
+			return false
+		}
+	}
+
+	return true
+}
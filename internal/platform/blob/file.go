@@ -0,0 +1,81 @@
+package blob
+
+import (
+	"context"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+)
+
+// FileStore keeps blobs as files in a directory on the local filesystem.
+type FileStore struct {
+	Dir string
+}
+
+// Put implements the Store interface. The blob is written to a temporary file
+// that is renamed into place so that a partially written blob is never read.
+func (f FileStore) Put(ctx context.Context, key string, data []byte, contentType string) error {
+	if !validKey(key) {
+		return errors.Errorf("invalid blob key: %q", key)
+	}
+
+	if err := os.MkdirAll(f.Dir, 0700); err != nil {
+		return errors.Wrap(err, "create blob directory")
+	}
+
+	tmp, err := ioutil.TempFile(f.Dir, ".upload-")
+	if err != nil {
+		return errors.Wrap(err, "create temporary blob file")
+	}
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return errors.Wrap(err, "write temporary blob file")
+	}
+
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return errors.Wrap(err, "close temporary blob file")
+	}
+
+	if err := os.Rename(tmp.Name(), filepath.Join(f.Dir, key)); err != nil {
+		os.Remove(tmp.Name())
+		return errors.Wrap(err, "move blob file into place")
+	}
+
+	return nil
+}
+
+// Get implements the Store interface.
+func (f FileStore) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	if !validKey(key) {
+		return nil, ErrNotFound
+	}
+
+	file, err := os.Open(filepath.Join(f.Dir, key))
+	if os.IsNotExist(err) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "open blob file")
+	}
+
+	return file, nil
+}
+
+// Delete implements the Store interface.
+func (f FileStore) Delete(ctx context.Context, key string) error {
+	if !validKey(key) {
+		return nil
+	}
+
+	if err := os.Remove(filepath.Join(f.Dir, key)); err != nil && !os.IsNotExist(err) {
+		return errors.Wrap(err, "remove blob file")
+	}
+
+	return nil
+}
@@ -1,6 +1,7 @@
 package db
 
 import (
+	"database/sql"
 	"fmt"
 	"time"
 
@@ -16,6 +17,16 @@ var (
 	PSQLErrUniqueConstraint = "23505"
 )
 
+// Executor is the set of methods shared by *sqlx.DB and *sqlx.Tx, allowing queries
+// to be ran either directly against the database or as part of a transaction.
+type Executor interface {
+	sqlx.Ext
+	Prepare(query string) (*sql.Stmt, error)
+	Preparex(query string) (*sqlx.Stmt, error)
+	Select(dest interface{}, query string, args ...interface{}) error
+	Get(dest interface{}, query string, args ...interface{}) error
+}
+
 type Config struct {
 	User string
 	Pass string
@@ -65,3 +76,27 @@ func NewConnection(cfg Config) (*sqlx.DB, error) {
 
 	return db, nil
 }
+
+// Transact runs fn inside of a transaction. The transaction is committed if fn
+// returns nil and rolled back otherwise, in which case the error from fn is
+// returned unwrapped so callers can still inspect its cause.
+func Transact(dbc *sqlx.DB, fn func(*sqlx.Tx) error) error {
+	tx, err := dbc.Beginx()
+	if err != nil {
+		return errors.Wrap(err, "begin transaction")
+	}
+
+	if err := fn(tx); err != nil {
+		if rerr := tx.Rollback(); rerr != nil {
+			log.WithError(errors.Wrap(rerr, "rollback transaction")).Info("transact")
+		}
+
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return errors.Wrap(err, "commit transaction")
+	}
+
+	return nil
+}
@@ -18,4 +18,260 @@ CREATE TABLE IF NOT EXISTS item (
 	created timestamp NOT NULL DEFAULT NOW(),
 	modified timestamp NOT NULL DEFAULT NOW(),
 	FOREIGN KEY(list_id) REFERENCES list(list_id)
+);
+
+CREATE TABLE IF NOT EXISTS webhook (
+	webhook_id SERIAL PRIMARY KEY,
+	list_id int NOT NULL,
+	url varchar(2048) NOT NULL,
+	secret varchar(255) NOT NULL,
+	events varchar(64)[] NOT NULL,
+	created timestamp NOT NULL DEFAULT NOW(),
+	modified timestamp NOT NULL DEFAULT NOW(),
+	FOREIGN KEY(list_id) REFERENCES list(list_id) ON DELETE CASCADE
+);
+
+CREATE TABLE IF NOT EXISTS webhook_delivery (
+	delivery_id SERIAL PRIMARY KEY,
+	webhook_id int NOT NULL,
+	event varchar(64) NOT NULL,
+	payload text NOT NULL,
+	status varchar(16) NOT NULL DEFAULT 'pending',
+	attempts int NOT NULL DEFAULT 0,
+	next_attempt timestamp NOT NULL DEFAULT NOW(),
+	response_code int NOT NULL DEFAULT 0,
+	last_error text NOT NULL DEFAULT '',
+	created timestamp NOT NULL DEFAULT NOW(),
+	modified timestamp NOT NULL DEFAULT NOW(),
+	FOREIGN KEY(webhook_id) REFERENCES webhook(webhook_id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS webhook_delivery_due_idx ON webhook_delivery (next_attempt) WHERE status IN ('pending', 'sending');
+
+CREATE TABLE IF NOT EXISTS outbox (
+	outbox_id BIGSERIAL PRIMARY KEY,
+	topic varchar(64) NOT NULL,
+	key varchar(255) NOT NULL,
+	payload text NOT NULL,
+	created timestamp NOT NULL DEFAULT NOW(),
+	published timestamp
+);
+
+CREATE INDEX IF NOT EXISTS outbox_unpublished_idx ON outbox (outbox_id) WHERE published IS NULL;
+CREATE INDEX IF NOT EXISTS outbox_unpublished_key_idx ON outbox (key, outbox_id) WHERE published IS NULL;
+
+ALTER TABLE list ADD COLUMN IF NOT EXISTS version bigint NOT NULL DEFAULT txid_current();
+ALTER TABLE item ADD COLUMN IF NOT EXISTS version bigint NOT NULL DEFAULT txid_current();
+
+CREATE INDEX IF NOT EXISTS list_version_idx ON list (version);
+CREATE INDEX IF NOT EXISTS item_version_idx ON item (version);
+
+CREATE TABLE IF NOT EXISTS tombstone (
+	kind varchar(16) NOT NULL,
+	id int NOT NULL,
+	list_id int NOT NULL,
+	version bigint NOT NULL DEFAULT txid_current(),
+	deleted timestamp NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS tombstone_version_idx ON tombstone (version);
+
+CREATE TABLE IF NOT EXISTS item_revision (
+	item_id int NOT NULL,
+	version bigint NOT NULL,
+	name varchar(255) NOT NULL,
+	quantity int NOT NULL,
+	created timestamp NOT NULL DEFAULT NOW(),
+	PRIMARY KEY(item_id, version),
+	FOREIGN KEY(item_id) REFERENCES item(item_id) ON DELETE CASCADE
+);
+
+ALTER TABLE list ADD COLUMN IF NOT EXISTS search tsvector;
+ALTER TABLE item ADD COLUMN IF NOT EXISTS search tsvector;
+
+CREATE OR REPLACE FUNCTION list_search_update() RETURNS trigger AS $$
+BEGIN
+	NEW.search := to_tsvector('english', NEW.name);
+	RETURN NEW;
+END
+$$ LANGUAGE plpgsql;
+
+CREATE OR REPLACE FUNCTION item_search_update() RETURNS trigger AS $$
+BEGIN
+	NEW.search := to_tsvector('english', NEW.name);
+	RETURN NEW;
+END
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS list_search_trigger ON list;
+CREATE TRIGGER list_search_trigger BEFORE INSERT OR UPDATE ON list
+	FOR EACH ROW EXECUTE PROCEDURE list_search_update();
+
+DROP TRIGGER IF EXISTS item_search_trigger ON item;
+CREATE TRIGGER item_search_trigger BEFORE INSERT OR UPDATE ON item
+	FOR EACH ROW EXECUTE PROCEDURE item_search_update();
+
+UPDATE list SET search = to_tsvector('english', name) WHERE search IS NULL;
+UPDATE item SET search = to_tsvector('english', name) WHERE search IS NULL;
+
+CREATE INDEX IF NOT EXISTS list_search_idx ON list USING GIN (search);
+CREATE INDEX IF NOT EXISTS item_search_idx ON item USING GIN (search);
+
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+CREATE INDEX IF NOT EXISTS item_name_trgm_idx ON item USING GIN (lower(name) gin_trgm_ops);
+
+CREATE TABLE IF NOT EXISTS tag (
+	tag_id SERIAL PRIMARY KEY,
+	name varchar(64) NOT NULL UNIQUE,
+	created timestamp NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS item_tag (
+	item_id int NOT NULL,
+	tag_id int NOT NULL,
+	PRIMARY KEY(item_id, tag_id),
+	FOREIGN KEY(item_id) REFERENCES item(item_id) ON DELETE CASCADE,
+	FOREIGN KEY(tag_id) REFERENCES tag(tag_id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS item_tag_tag_idx ON item_tag (tag_id);
+
+ALTER TABLE item ADD COLUMN IF NOT EXISTS due_at timestamptz;
+ALTER TABLE item ADD COLUMN IF NOT EXISTS remind_at timestamptz;
+ALTER TABLE item ADD COLUMN IF NOT EXISTS reminded_at timestamptz;
+ALTER TABLE item ADD COLUMN IF NOT EXISTS reminder_attempts int NOT NULL DEFAULT 0;
+ALTER TABLE item ADD COLUMN IF NOT EXISTS reminder_next_attempt timestamptz;
+ALTER TABLE item ADD COLUMN IF NOT EXISTS reminder_failed_at timestamptz;
+
+CREATE INDEX IF NOT EXISTS item_reminder_due_idx ON item (COALESCE(reminder_next_attempt, remind_at))
+	WHERE reminded_at IS NULL AND reminder_failed_at IS NULL;
+
+ALTER TABLE item_revision ADD COLUMN IF NOT EXISTS due_at timestamptz;
+ALTER TABLE item_revision ADD COLUMN IF NOT EXISTS remind_at timestamptz;
+
+ALTER TABLE item ADD COLUMN IF NOT EXISTS recurrence varchar(255) NOT NULL DEFAULT '';
+ALTER TABLE item ADD COLUMN IF NOT EXISTS completed_at timestamptz;
+ALTER TABLE item ADD COLUMN IF NOT EXISTS next_occurrence_id int REFERENCES item(item_id) ON DELETE SET NULL;
+
+ALTER TABLE item_revision ADD COLUMN IF NOT EXISTS recurrence varchar(255) NOT NULL DEFAULT '';
+ALTER TABLE item_revision ADD COLUMN IF NOT EXISTS completed_at timestamptz;
+
+ALTER TABLE item ADD COLUMN IF NOT EXISTS parent_id int REFERENCES item(item_id) ON DELETE CASCADE;
+
+CREATE INDEX IF NOT EXISTS item_parent_idx ON item (parent_id);
+
+ALTER TABLE item_revision ADD COLUMN IF NOT EXISTS parent_id int;
+
+CREATE TABLE IF NOT EXISTS folder (
+	folder_id SERIAL PRIMARY KEY,
+	parent_id int REFERENCES folder(folder_id) ON DELETE SET NULL,
+	name varchar(255) NOT NULL,
+	created timestamp NOT NULL DEFAULT NOW(),
+	modified timestamp NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS folder_parent_idx ON folder (parent_id);
+
+ALTER TABLE list ADD COLUMN IF NOT EXISTS folder_id int REFERENCES folder(folder_id) ON DELETE SET NULL;
+
+CREATE INDEX IF NOT EXISTS list_folder_idx ON list (folder_id);
+
+ALTER TABLE list ADD COLUMN IF NOT EXISTS notes text NOT NULL DEFAULT '';
+ALTER TABLE item ADD COLUMN IF NOT EXISTS notes text NOT NULL DEFAULT '';
+ALTER TABLE item_revision ADD COLUMN IF NOT EXISTS notes text NOT NULL DEFAULT '';
+
+CREATE OR REPLACE FUNCTION list_search_update() RETURNS trigger AS $$
+BEGIN
+	NEW.search := setweight(to_tsvector('english', NEW.name), 'A') ||
+		setweight(to_tsvector('english', NEW.notes), 'B');
+	RETURN NEW;
+END
+$$ LANGUAGE plpgsql;
+
+CREATE OR REPLACE FUNCTION item_search_update() RETURNS trigger AS $$
+BEGIN
+	NEW.search := setweight(to_tsvector('english', NEW.name), 'A') ||
+		setweight(to_tsvector('english', NEW.notes), 'B');
+	RETURN NEW;
+END
+$$ LANGUAGE plpgsql;
+
+CREATE TABLE IF NOT EXISTS attachment (
+	attachment_id SERIAL PRIMARY KEY,
+	item_id int NOT NULL REFERENCES item(item_id) ON DELETE CASCADE,
+	name varchar(255) NOT NULL,
+	content_type varchar(255) NOT NULL,
+	size bigint NOT NULL,
+	blob_key varchar(128) NOT NULL UNIQUE,
+	created timestamp NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS attachment_item_idx ON attachment (item_id);
+
+CREATE TABLE IF NOT EXISTS blob_orphan (
+	blob_key varchar(128) PRIMARY KEY,
+	created timestamp NOT NULL DEFAULT NOW()
+);
+
+CREATE OR REPLACE FUNCTION attachment_orphan_blob() RETURNS trigger AS $$
+BEGIN
+	INSERT INTO blob_orphan (blob_key) VALUES (OLD.blob_key) ON CONFLICT DO NOTHING;
+	RETURN OLD;
+END
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS attachment_orphan_trigger ON attachment;
+CREATE TRIGGER attachment_orphan_trigger AFTER DELETE ON attachment
+	FOR EACH ROW EXECUTE PROCEDURE attachment_orphan_blob();
+
+CREATE TABLE IF NOT EXISTS item_comment (
+	comment_id SERIAL PRIMARY KEY,
+	item_id int NOT NULL REFERENCES item(item_id) ON DELETE CASCADE,
+	author varchar(64) NOT NULL,
+	body text NOT NULL,
+	created timestamp NOT NULL DEFAULT NOW(),
+	modified timestamp NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS item_comment_item_idx ON item_comment (item_id, created);
+
+CREATE TABLE IF NOT EXISTS item_comment_mention (
+	comment_id int NOT NULL REFERENCES item_comment(comment_id) ON DELETE CASCADE,
+	username varchar(64) NOT NULL,
+	PRIMARY KEY (comment_id, username)
+);
+
+CREATE INDEX IF NOT EXISTS item_comment_mention_user_idx ON item_comment_mention (username);
+
+DO $$
+BEGIN
+	IF (SELECT data_type FROM information_schema.columns
+		WHERE table_schema = current_schema() AND table_name = 'item' AND column_name = 'quantity') <> 'numeric' THEN
+		ALTER TABLE item ALTER COLUMN quantity TYPE numeric(18, 3);
+	END IF;
+
+	IF (SELECT data_type FROM information_schema.columns
+		WHERE table_schema = current_schema() AND table_name = 'item_revision' AND column_name = 'quantity') <> 'numeric' THEN
+		ALTER TABLE item_revision ALTER COLUMN quantity TYPE numeric(18, 3);
+	END IF;
+END
+$$;
+ALTER TABLE item ADD COLUMN IF NOT EXISTS unit varchar(16) NOT NULL DEFAULT '';
+ALTER TABLE item_revision ADD COLUMN IF NOT EXISTS unit varchar(16) NOT NULL DEFAULT '';
+
+ALTER TABLE item ADD COLUMN IF NOT EXISTS price bigint CHECK (price >= 0);
+ALTER TABLE item ADD COLUMN IF NOT EXISTS currency varchar(3) NOT NULL DEFAULT '';
+ALTER TABLE item_revision ADD COLUMN IF NOT EXISTS price bigint;
+ALTER TABLE item_revision ADD COLUMN IF NOT EXISTS currency varchar(3) NOT NULL DEFAULT '';
+ALTER TABLE list ADD COLUMN IF NOT EXISTS budget bigint CHECK (budget >= 0);
+ALTER TABLE list ADD COLUMN IF NOT EXISTS budget_currency varchar(3) NOT NULL DEFAULT '';
+
+CREATE INDEX IF NOT EXISTS item_created_idx ON item (created);
+
+CREATE TABLE IF NOT EXISTS calendar_token (
+	list_id int PRIMARY KEY,
+	token_hash char(64) NOT NULL,
+	created timestamp NOT NULL DEFAULT NOW(),
+	FOREIGN KEY(list_id) REFERENCES list(list_id) ON DELETE CASCADE
 );`
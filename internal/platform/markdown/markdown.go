@@ -0,0 +1,240 @@
+// Package markdown renders the subset of Markdown used for notes into HTML that is
+// safe to embed in a page: paragraphs, headings, block quotes, lists, fenced code,
+// horizontal rules, emphasis, strong emphasis, inline code and links.
+//
+// Rather than rendering arbitrary Markdown and sanitizing the result, nothing in
+// the source is ever passed through as markup. Every character of text is HTML
+// escaped, raw HTML included, and links are only kept when they point to an http,
+// https or mailto URL.
+package markdown
+
+import (
+	"html"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// safeSchemes are the URL schemes a link is allowed to point to.
+var safeSchemes = map[string]bool{
+	"http":   true,
+	"https":  true,
+	"mailto": true,
+}
+
+// Block level syntax, matched against a single line.
+var (
+	headingRE = regexp.MustCompile(`^(#{1,6})\s+(.*?)\s*#*\s*$`)
+	ruleRE    = regexp.MustCompile(`^(?:-\s*){3,}$|^(?:\*\s*){3,}$|^(?:_\s*){3,}$`)
+	bulletRE  = regexp.MustCompile(`^[-*+]\s+(.*)$`)
+	orderedRE = regexp.MustCompile(`^\d{1,9}[.)]\s+(.*)$`)
+	quoteRE   = regexp.MustCompile(`^>\s?(.*)$`)
+	fenceRE   = regexp.MustCompile("^(```|~~~)")
+)
+
+// Render converts Markdown source to safe HTML.
+func Render(src string) string {
+	src = strings.Replace(src, "\r\n", "\n", -1)
+	src = strings.Replace(src, "\r", "\n", -1)
+
+	var b strings.Builder
+	renderBlocks(&b, strings.Split(src, "\n"))
+
+	return b.String()
+}
+
+// renderBlocks writes the block level elements of lines to b.
+func renderBlocks(b *strings.Builder, lines []string) {
+	var para []string
+
+	flush := func() {
+		if len(para) == 0 {
+			return
+		}
+
+		b.WriteString("<p>")
+		b.WriteString(renderInline(strings.Join(para, "\n")))
+		b.WriteString("</p>\n")
+		para = nil
+	}
+
+	for i := 0; i < len(lines); i++ {
+		line := strings.TrimRight(lines[i], " \t")
+		trimmed := strings.TrimLeft(line, " ")
+
+		switch {
+		case trimmed == "":
+			flush()
+
+		case fenceRE.MatchString(trimmed):
+			flush()
+
+			fence := fenceRE.FindString(trimmed)
+			var code []string
+			for i++; i < len(lines) && !strings.HasPrefix(strings.TrimSpace(lines[i]), fence); i++ {
+				code = append(code, lines[i])
+			}
+
+			b.WriteString("<pre><code>")
+			b.WriteString(html.EscapeString(strings.Join(code, "\n")))
+			b.WriteString("</code></pre>\n")
+
+		case headingRE.MatchString(trimmed):
+			flush()
+
+			m := headingRE.FindStringSubmatch(trimmed)
+			tag := "h" + strconv.Itoa(len(m[1]))
+			b.WriteString("<" + tag + ">")
+			b.WriteString(renderInline(m[2]))
+			b.WriteString("</" + tag + ">\n")
+
+		case ruleRE.MatchString(trimmed):
+			flush()
+			b.WriteString("<hr>\n")
+
+		case quoteRE.MatchString(trimmed):
+			flush()
+
+			var quoted []string
+			for ; i < len(lines) && quoteRE.MatchString(strings.TrimLeft(lines[i], " ")); i++ {
+				quoted = append(quoted, quoteRE.FindStringSubmatch(strings.TrimLeft(lines[i], " "))[1])
+			}
+			i--
+
+			b.WriteString("<blockquote>\n")
+			renderBlocks(b, quoted)
+			b.WriteString("</blockquote>\n")
+
+		case bulletRE.MatchString(trimmed), orderedRE.MatchString(trimmed):
+			flush()
+
+			re, tag := bulletRE, "ul"
+			if !bulletRE.MatchString(trimmed) {
+				re, tag = orderedRE, "ol"
+			}
+
+			b.WriteString("<" + tag + ">\n")
+			for ; i < len(lines) && re.MatchString(strings.TrimLeft(lines[i], " ")); i++ {
+				b.WriteString("<li>")
+				b.WriteString(renderInline(re.FindStringSubmatch(strings.TrimLeft(lines[i], " "))[1]))
+				b.WriteString("</li>\n")
+			}
+			i--
+			b.WriteString("</" + tag + ">\n")
+
+		default:
+			para = append(para, trimmed)
+		}
+	}
+
+	flush()
+}
+
+// renderInline converts the inline elements of s to HTML, escaping everything else.
+func renderInline(s string) string {
+	var b strings.Builder
+
+	for i := 0; i < len(s); {
+		c := s[i]
+
+		switch {
+		case c == '\\' && i+1 < len(s) && strings.IndexByte("\\`*_[]()#+-.!>~", s[i+1]) >= 0:
+			b.WriteString(html.EscapeString(s[i+1 : i+2]))
+			i += 2
+			continue
+
+		case c == '`':
+			if end := strings.IndexByte(s[i+1:], '`'); end >= 0 {
+				b.WriteString("<code>")
+				b.WriteString(html.EscapeString(s[i+1 : i+1+end]))
+				b.WriteString("</code>")
+				i += end + 2
+				continue
+			}
+
+		// An underscore inside of a word, as in snake_case, is not emphasis.
+		case c == '*' || (c == '_' && (i == 0 || !isWordByte(s[i-1]))):
+			delim := s[i : i+1]
+			tag := "em"
+			if strings.HasPrefix(s[i:], delim+delim) {
+				delim += delim
+				tag = "strong"
+			}
+
+			start := i + len(delim)
+			end := strings.Index(s[start:], delim)
+			if end > 0 && c == '_' && start+end+len(delim) < len(s) && isWordByte(s[start+end+len(delim)]) {
+				end = -1
+			}
+
+			if end > 0 && s[start] != ' ' {
+				b.WriteString("<" + tag + ">")
+				b.WriteString(renderInline(s[start : start+end]))
+				b.WriteString("</" + tag + ">")
+				i = start + end + len(delim)
+				continue
+			}
+
+		case c == '[':
+			if text, href, n, ok := parseLink(s[i:]); ok {
+				if u, safe := safeURL(href); safe {
+					b.WriteString(`<a href="`)
+					b.WriteString(html.EscapeString(u))
+					b.WriteString(`" rel="nofollow noopener noreferrer">`)
+					b.WriteString(renderInline(text))
+					b.WriteString("</a>")
+				} else {
+					b.WriteString(renderInline(text))
+				}
+
+				i += n
+				continue
+			}
+		}
+
+		b.WriteString(html.EscapeString(s[i : i+1]))
+		i++
+	}
+
+	return b.String()
+}
+
+// isWordByte reports whether c is an ASCII letter or digit.
+func isWordByte(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9'
+}
+
+// parseLink parses a [text](href) link at the start of s, returning its parts and
+// length.
+func parseLink(s string) (text, href string, n int, ok bool) {
+	closeText := strings.Index(s, "](")
+	if closeText < 0 || strings.IndexByte(s[1:closeText], '[') >= 0 {
+		return "", "", 0, false
+	}
+
+	closeHref := strings.IndexByte(s[closeText+2:], ')')
+	if closeHref < 0 {
+		return "", "", 0, false
+	}
+
+	text = s[1:closeText]
+	href = strings.TrimSpace(s[closeText+2 : closeText+2+closeHref])
+
+	return text, href, closeText + 3 + closeHref, true
+}
+
+// safeURL reports whether href is an absolute URL with a safe scheme, returning it
+// in its normalized form.
+func safeURL(href string) (string, bool) {
+	u, err := url.Parse(href)
+	if err != nil || !safeSchemes[strings.ToLower(u.Scheme)] {
+		return "", false
+	}
+
+	if u.Scheme != "mailto" && u.Host == "" {
+		return "", false
+	}
+
+	return u.String(), true
+}
@@ -0,0 +1,93 @@
+// Package money handles prices kept as exact integer amounts of the minor unit of a
+// currency, such as cents of a US dollar, rather than as floating point numbers.
+// Currencies are known by their ISO 4217 codes, along with the number of digits
+// after the decimal point their minor unit stands for.
+package money
+
+import (
+	"math/big"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// ErrUnknownCurrency is returned when a currency code is not a known ISO 4217 code.
+var ErrUnknownCurrency = errors.New("unknown currency")
+
+// exponents maps the code of every known currency to the number of digits after
+// the decimal point of its minor unit.
+var exponents = map[string]int{
+	"AED": 2, "ARS": 2, "AUD": 2, "BGN": 2, "BHD": 3, "BRL": 2, "CAD": 2, "CHF": 2,
+	"CLP": 0, "CNY": 2, "COP": 2, "CZK": 2, "DKK": 2, "EGP": 2, "EUR": 2, "GBP": 2,
+	"HKD": 2, "HUF": 2, "IDR": 2, "ILS": 2, "INR": 2, "ISK": 0, "JOD": 3, "JPY": 0,
+	"KRW": 0, "KWD": 3, "MXN": 2, "MYR": 2, "NGN": 2, "NOK": 2, "NZD": 2, "OMR": 3,
+	"PHP": 2, "PKR": 2, "PLN": 2, "RON": 2, "SAR": 2, "SEK": 2, "SGD": 2, "THB": 2,
+	"TND": 3, "TRY": 2, "TWD": 2, "UAH": 2, "USD": 2, "VND": 0, "ZAR": 2,
+}
+
+// Currency returns the code of the currency known by code in any case.
+func Currency(code string) (string, error) {
+	c := strings.ToUpper(strings.TrimSpace(code))
+	if _, ok := exponents[c]; !ok {
+		return "", errors.Wrapf(ErrUnknownCurrency, "%q", code)
+	}
+
+	return c, nil
+}
+
+// Format returns an amount of the minor unit of a currency as a decimal number of
+// its major unit, such as 1234 US cents as 12.34.
+func Format(minor int64, currency string) string {
+	exp := exponents[currency]
+	if exp == 0 {
+		return strconv.FormatInt(minor, 10)
+	}
+
+	s := strconv.FormatInt(minor, 10)
+
+	neg := strings.HasPrefix(s, "-")
+	s = strings.TrimPrefix(s, "-")
+
+	for len(s) <= exp {
+		s = "0" + s
+	}
+
+	s = s[:len(s)-exp] + "." + s[len(s)-exp:]
+	if neg {
+		return "-" + s
+	}
+
+	return s
+}
+
+// Parse parses a decimal number of the major unit of a currency, such as 12.34 US
+// dollars, into an amount of its minor unit. Amounts more precise than the minor
+// unit are rejected rather than rounded.
+func Parse(s, currency string) (int64, error) {
+	exp, ok := exponents[currency]
+	if !ok {
+		return 0, errors.Wrapf(ErrUnknownCurrency, "%q", currency)
+	}
+
+	s = strings.TrimSpace(s)
+	if s == "" || strings.ContainsAny(s, "/eE") {
+		return 0, errors.Errorf("%q is not a decimal amount", s)
+	}
+
+	r, ok := new(big.Rat).SetString(s)
+	if !ok {
+		return 0, errors.Errorf("%q is not a decimal amount", s)
+	}
+
+	r.Mul(r, new(big.Rat).SetInt(new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(exp)), nil)))
+	if !r.IsInt() {
+		return 0, errors.Errorf("%q has more decimal places than %s allows", s, currency)
+	}
+
+	if !r.Num().IsInt64() {
+		return 0, errors.Errorf("%q is out of range", s)
+	}
+
+	return r.Num().Int64(), nil
+}
@@ -0,0 +1,88 @@
+// Package outbox implements the transactional outbox pattern. Events are written
+// to the outbox table in the same transaction as the change that produced them,
+// and a Relay later hands them to a Publisher, so a committed change always
+// produces its event even if the process dies right after the commit.
+//
+// Events carrying the same key, which identifies the aggregate they belong to, are
+// published one at a time in the order their transactions committed. Events of
+// different keys are independent of each other: any number of relays share the
+// work by claiming keys, and a key whose events fail to publish holds up no other.
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+// Event is a type that contains the proper struct tags for the Postgres
+// representation of an outbox row.
+type Event struct {
+	ID      int64     `db:"outbox_id"`
+	Topic   string    `db:"topic"`
+	Key     string    `db:"key"`
+	Payload string    `db:"payload"`
+	Created time.Time `db:"created"`
+}
+
+// Publisher publishes events drained from the outbox. The transaction that holds
+// the lock on the event is passed along so that publishers writing to the same
+// database can do so atomically with the event being marked as published.
+type Publisher interface {
+	Publish(ctx context.Context, tx *sqlx.Tx, e Event) error
+}
+
+// Publishers is a Publisher that hands every event to each of its publishers in
+// order, stopping at the first error.
+type Publishers []Publisher
+
+// Publish implements the Publisher interface for the Publishers type.
+func (ps Publishers) Publish(ctx context.Context, tx *sqlx.Tx, e Event) error {
+	for _, p := range ps {
+		if err := p.Publish(ctx, tx, e); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// LogPublisher is a Publisher that logs every event.
+type LogPublisher struct{}
+
+// Publish implements the Publisher interface for the LogPublisher type.
+func (LogPublisher) Publish(ctx context.Context, tx *sqlx.Tx, e Event) error {
+	log.WithFields(log.Fields{
+		"id":    e.ID,
+		"topic": e.Topic,
+		"key":   e.Key,
+	}).Info("published event")
+
+	return nil
+}
+
+// Write inserts an event into the outbox as part of tx. The key identifies the
+// aggregate the event belongs to and data is marshaled to JSON as the payload.
+// Transactions writing events of the same key are serialized from then on until
+// they end, so that the events of a key are numbered in the order they commit.
+func Write(tx *sqlx.Tx, topic, key string, data interface{}) error {
+	b, err := json.Marshal(data)
+	if err != nil {
+		return errors.Wrap(err, "marshal outbox payload")
+	}
+
+	if _, err := tx.Exec(lockKey, key); err != nil {
+		return errors.Wrap(err, "lock outbox key")
+	}
+
+	var id int64
+	if err := tx.QueryRow(insert, topic, key, string(b), time.Now()).Scan(&id); err != nil {
+		return errors.Wrap(err, "insert outbox row")
+	}
+
+	return nil
+}
@@ -0,0 +1,35 @@
+package outbox
+
+// PostgreSQL queries for the outbox table.
+const (
+	// insert is a query that inserts a row into the outbox table using the values
+	// given in order for topic, key, payload and created.
+	insert = "INSERT INTO outbox (topic, key, payload, created) VALUES ($1, $2, $3, $4) RETURNING outbox_id;"
+
+	// lockKey is a query that takes the advisory lock of the key given by $1 for
+	// the rest of the transaction, waiting for transactions holding it to end.
+	lockKey = "SELECT pg_advisory_xact_lock(hashtext('outbox'), hashtext($1));"
+
+	// claimKeys is a query that locks the oldest unpublished row of up to $1 keys
+	// in the outbox table, oldest first, and returns their keys. Rows locked by
+	// another relay are skipped, and as the other unpublished rows of a key are not
+	// its oldest, so is every key another relay is publishing.
+	claimKeys = `SELECT key FROM outbox o
+		WHERE published IS NULL AND NOT EXISTS (
+			SELECT 1 FROM outbox p WHERE p.published IS NULL AND p.key = o.key AND p.outbox_id < o.outbox_id)
+		ORDER BY outbox_id LIMIT $1
+		FOR UPDATE SKIP LOCKED;`
+
+	// selectUnpublished is a query that selects the oldest unpublished rows in the
+	// outbox table with one of the keys given by $1, limited to $2 rows.
+	selectUnpublished = `SELECT outbox_id, topic, key, payload, created FROM outbox
+		WHERE published IS NULL AND key = ANY($1) ORDER BY outbox_id LIMIT $2;`
+
+	// markPublished is a query that marks a row in the outbox table as published
+	// given a published time and outbox_id.
+	markPublished = "UPDATE outbox SET published = $1 WHERE outbox_id = $2;"
+
+	// prune is a query that deletes rows from the outbox table that were published
+	// before the given time.
+	prune = "DELETE FROM outbox WHERE published < $1;"
+)
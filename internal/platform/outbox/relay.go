@@ -0,0 +1,156 @@
+package outbox
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+// pruneInterval is how often Run prunes published events.
+const pruneInterval = 10 * time.Minute
+
+// Relay drains the outbox, handing unpublished events to a Publisher. Any number
+// of relays may run against the same database in parallel. Each of them claims
+// the keys it drains by locking their oldest unpublished event, so the events of a
+// key are only ever published by one relay at a time, in order.
+type Relay struct {
+	DB        *sqlx.DB
+	Publisher Publisher
+	BatchSize int
+
+	// Retention is how long published events are kept before being pruned. A zero
+	// Retention keeps them forever.
+	Retention time.Duration
+}
+
+// Run drains the outbox every interval, and prunes published events every
+// pruneInterval, until ctx is cancelled.
+func (r *Relay) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	pruneTicker := time.NewTicker(pruneInterval)
+	defer pruneTicker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-pruneTicker.C:
+			if _, err := r.Prune(); err != nil {
+				log.WithError(err).Error("prune outbox")
+			}
+		case <-ticker.C:
+			for {
+				n, err := r.Drain(ctx)
+				if err != nil {
+					log.WithError(err).Error("drain outbox")
+				}
+
+				// Keep draining without waiting on the ticker while full batches
+				// are being returned.
+				if err != nil || n < r.BatchSize || ctx.Err() != nil {
+					break
+				}
+			}
+		}
+	}
+}
+
+// Drain publishes a single batch of unpublished events of the keys it claims and
+// returns how many were published. Publishing the events of a key stops at the
+// first one that fails, so that no event is published before one written ahead of
+// it; the failed event is retried by the next call. Keys claimed by another relay
+// are left to it.
+func (r *Relay) Drain(ctx context.Context) (int, error) {
+	tx, err := r.DB.Beginx()
+	if err != nil {
+		return 0, errors.Wrap(err, "begin transaction")
+	}
+
+	defer func() {
+		if err := tx.Rollback(); err != nil && err != sql.ErrTxDone {
+			log.WithError(errors.Wrap(err, "rollback transaction")).Info("drain outbox")
+		}
+	}()
+
+	var keys []string
+	if err := tx.Select(&keys, claimKeys, r.BatchSize); err != nil {
+		return 0, errors.Wrap(err, "claim outbox keys")
+	}
+
+	if len(keys) == 0 {
+		return 0, nil
+	}
+
+	var events []Event
+	if err := tx.Select(&events, selectUnpublished, pq.Array(keys), r.BatchSize); err != nil {
+		return 0, errors.Wrap(err, "select unpublished outbox rows")
+	}
+
+	var published int
+	var pubErr error
+	failed := make(map[string]bool)
+
+	for _, e := range events {
+		if failed[e.Key] {
+			continue
+		}
+
+		// Each event is published under a savepoint so a publisher failing part
+		// way through does not undo the events published before it.
+		if _, err := tx.Exec("SAVEPOINT publish;"); err != nil {
+			return 0, errors.Wrap(err, "create savepoint")
+		}
+
+		if err := r.Publisher.Publish(ctx, tx, e); err != nil {
+			if _, err := tx.Exec("ROLLBACK TO SAVEPOINT publish;"); err != nil {
+				return 0, errors.Wrap(err, "rollback to savepoint")
+			}
+
+			failed[e.Key] = true
+			if pubErr == nil {
+				pubErr = errors.Wrapf(err, "publish outbox event %d", e.ID)
+			}
+
+			continue
+		}
+
+		if _, err := tx.Exec(markPublished, time.Now(), e.ID); err != nil {
+			return 0, errors.Wrap(err, "mark outbox row as published")
+		}
+
+		published++
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, errors.Wrap(err, "commit transaction")
+	}
+
+	return published, pubErr
+}
+
+// Prune deletes the events published longer than Retention ago and returns how
+// many were deleted. It deletes nothing when Retention is zero.
+func (r *Relay) Prune() (int64, error) {
+	if r.Retention <= 0 {
+		return 0, nil
+	}
+
+	res, err := r.DB.Exec(prune, time.Now().Add(-r.Retention))
+	if err != nil {
+		return 0, errors.Wrap(err, "prune published outbox rows")
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return 0, errors.Wrap(err, "rows affected")
+	}
+
+	return n, nil
+}
@@ -0,0 +1,306 @@
+// Package rrule implements the subset of iCalendar recurrence rules (RFC 5545)
+// needed for repeating to-dos: daily, weekly on given weekdays and monthly on
+// given days of the month, every interval periods, optionally until a time.
+//
+// Rules are written the way they are in iCalendar, without the RRULE: prefix,
+// e.g. "FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,TH". Weeks start on Monday.
+package rrule
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Frequencies a rule is able to repeat at.
+const (
+	Daily   = "DAILY"
+	Weekly  = "WEEKLY"
+	Monthly = "MONTHLY"
+)
+
+// maxMonthSearch bounds the number of periods searched for a monthly occurrence,
+// as a rule like BYMONTHDAY=31 skips every month shorter than that.
+const maxMonthSearch = 48
+
+// untilLayouts are the accepted formats of the UNTIL part, a UTC time or a date.
+var untilLayouts = []string{"20060102T150405Z", "20060102"}
+
+// weekdays maps the two letter weekday codes of a BYDAY part to weekdays.
+var weekdays = map[string]time.Weekday{
+	"MO": time.Monday,
+	"TU": time.Tuesday,
+	"WE": time.Wednesday,
+	"TH": time.Thursday,
+	"FR": time.Friday,
+	"SA": time.Saturday,
+	"SU": time.Sunday,
+}
+
+// Rule is a parsed recurrence rule.
+type Rule struct {
+	Freq       string
+	Interval   int
+	ByDay      []time.Weekday
+	ByMonthDay []int
+	Until      *time.Time
+}
+
+// Parse parses a recurrence rule, returning an error naming the first part that
+// is invalid or not supported.
+func Parse(s string) (Rule, error) {
+	r := Rule{Interval: 1}
+
+	for _, part := range strings.Split(strings.TrimPrefix(strings.TrimSpace(s), "RRULE:"), ";") {
+		kv := strings.SplitN(part, "=", 2)
+		if len(kv) != 2 {
+			return Rule{}, errors.Errorf("invalid recurrence rule part: %q", part)
+		}
+
+		key, value := strings.ToUpper(kv[0]), strings.ToUpper(kv[1])
+
+		switch key {
+		case "FREQ":
+			switch value {
+			case Daily, Weekly, Monthly:
+				r.Freq = value
+			default:
+				return Rule{}, errors.Errorf("unsupported recurrence frequency: %s", value)
+			}
+
+		case "INTERVAL":
+			n, err := strconv.Atoi(value)
+			if err != nil || n < 1 {
+				return Rule{}, errors.New("recurrence interval must be a positive integer")
+			}
+			r.Interval = n
+
+		case "BYDAY":
+			for _, code := range strings.Split(value, ",") {
+				d, ok := weekdays[code]
+				if !ok {
+					return Rule{}, errors.Errorf("invalid recurrence weekday: %s", code)
+				}
+				r.ByDay = append(r.ByDay, d)
+			}
+
+		case "BYMONTHDAY":
+			for _, v := range strings.Split(value, ",") {
+				n, err := strconv.Atoi(v)
+				if err != nil || n == 0 || n < -31 || n > 31 {
+					return Rule{}, errors.Errorf("invalid recurrence day of month: %s", v)
+				}
+				r.ByMonthDay = append(r.ByMonthDay, n)
+			}
+
+		case "UNTIL":
+			until, err := time.Parse(untilLayouts[0], value)
+			if err != nil {
+				// A date includes every occurrence on that day.
+				if until, err = time.Parse(untilLayouts[1], value); err != nil {
+					return Rule{}, errors.Errorf("invalid recurrence until: %s", value)
+				}
+				until = until.Add(24*time.Hour - time.Second)
+			}
+			r.Until = &until
+
+		default:
+			return Rule{}, errors.Errorf("unsupported recurrence rule part: %s", key)
+		}
+	}
+
+	if r.Freq == "" {
+		return Rule{}, errors.New("recurrence rule requires a FREQ")
+	}
+
+	if len(r.ByDay) > 0 && r.Freq != Weekly {
+		return Rule{}, errors.New("BYDAY is only supported with FREQ=WEEKLY")
+	}
+
+	if len(r.ByMonthDay) > 0 && r.Freq != Monthly {
+		return Rule{}, errors.New("BYMONTHDAY is only supported with FREQ=MONTHLY")
+	}
+
+	sort.Slice(r.ByDay, func(i, j int) bool { return weekdayIndex(r.ByDay[i]) < weekdayIndex(r.ByDay[j]) })
+	sort.Ints(r.ByMonthDay)
+
+	return r, nil
+}
+
+// String returns the canonical form of the rule, as accepted by Parse.
+func (r Rule) String() string {
+	parts := []string{"FREQ=" + r.Freq}
+
+	if r.Interval > 1 {
+		parts = append(parts, "INTERVAL="+strconv.Itoa(r.Interval))
+	}
+
+	if len(r.ByDay) > 0 {
+		codes := make([]string, len(r.ByDay))
+		for i, d := range r.ByDay {
+			codes[i] = strings.ToUpper(d.String()[:2])
+		}
+		parts = append(parts, "BYDAY="+strings.Join(codes, ","))
+	}
+
+	if len(r.ByMonthDay) > 0 {
+		days := make([]string, len(r.ByMonthDay))
+		for i, d := range r.ByMonthDay {
+			days[i] = strconv.Itoa(d)
+		}
+		parts = append(parts, "BYMONTHDAY="+strings.Join(days, ","))
+	}
+
+	if r.Until != nil {
+		parts = append(parts, "UNTIL="+r.Until.UTC().Format(untilLayouts[0]))
+	}
+
+	return strings.Join(parts, ";")
+}
+
+// Next returns the first occurrence of the rule after the occurrence at t, keeping
+// the time of day of t. It reports false when the rule has no further occurrences.
+func (r Rule) Next(t time.Time) (time.Time, bool) {
+	return r.next(t, 0)
+}
+
+// NextAfter returns the first occurrence of the rule following the occurrence at t
+// that is after after, as Next would once called often enough. Periods ending
+// before after are skipped at once, so an occurrence long past is no slower to
+// catch up on than a recent one.
+func (r Rule) NextAfter(t, after time.Time) (time.Time, bool) {
+	interval := r.interval()
+
+	// The number of whole periods between t and after, less one so that no
+	// occurrence after after is skipped.
+	var skip int64
+	switch r.Freq {
+	case Daily:
+		skip = (after.Unix()-t.Unix())/(24*60*60)/int64(interval) - 1
+	case Weekly:
+		skip = (after.Unix()-t.Unix())/(7*24*60*60)/int64(interval) - 1
+	case Monthly:
+		skip = (int64(after.Year()-t.Year())*12+int64(after.Month()-t.Month()))/int64(interval) - 1
+	}
+
+	if skip < 0 {
+		skip = 0
+	}
+
+	next, ok := r.next(t, int(skip))
+	for ok && !next.After(after) {
+		next, ok = r.next(next, 0)
+	}
+
+	return next, ok
+}
+
+// interval returns the interval of the rule, which is at least 1.
+func (r Rule) interval() int {
+	if r.Interval < 1 {
+		return 1
+	}
+
+	return r.Interval
+}
+
+// next returns the first occurrence of the rule after the occurrence at t, once the
+// periods up to skip periods after the one of t have been skipped.
+func (r Rule) next(t time.Time, skip int) (time.Time, bool) {
+	interval := r.interval()
+
+	var next time.Time
+	switch r.Freq {
+	case Daily:
+		next = t.AddDate(0, 0, interval*(skip+1))
+
+	case Weekly:
+		next = r.nextWeekly(t.AddDate(0, 0, 7*interval*skip), interval)
+
+	case Monthly:
+		var ok bool
+		if next, ok = r.nextMonthly(t, interval, skip); !ok {
+			return time.Time{}, false
+		}
+
+	default:
+		return time.Time{}, false
+	}
+
+	if r.Until != nil && next.After(*r.Until) {
+		return time.Time{}, false
+	}
+
+	return next, true
+}
+
+// nextWeekly returns the next occurrence of a weekly rule after t.
+func (r Rule) nextWeekly(t time.Time, interval int) time.Time {
+	if len(r.ByDay) == 0 {
+		return t.AddDate(0, 0, 7*interval)
+	}
+
+	// A later weekday in the same week.
+	for _, d := range r.ByDay {
+		if weekdayIndex(d) > weekdayIndex(t.Weekday()) {
+			return t.AddDate(0, 0, weekdayIndex(d)-weekdayIndex(t.Weekday()))
+		}
+	}
+
+	// Otherwise the first weekday of the week interval weeks on.
+	monday := t.AddDate(0, 0, -weekdayIndex(t.Weekday()))
+	return monday.AddDate(0, 0, 7*interval+weekdayIndex(r.ByDay[0]))
+}
+
+// nextMonthly returns the next occurrence of a monthly rule after t, starting the
+// search skip periods after the month of t.
+func (r Rule) nextMonthly(t time.Time, interval, skip int) (time.Time, bool) {
+	days := r.ByMonthDay
+	if len(days) == 0 {
+		days = []int{t.Day()}
+	}
+
+	year, month, _ := t.Date()
+	for i := skip; i <= skip+maxMonthSearch; i++ {
+		m := time.Date(year, month+time.Month(i*interval), 1, t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), t.Location())
+
+		var candidates []int
+		for _, d := range days {
+			if day, ok := resolveMonthDay(m, d); ok {
+				candidates = append(candidates, day)
+			}
+		}
+		sort.Ints(candidates)
+
+		for _, day := range candidates {
+			if i == 0 && day <= t.Day() {
+				continue
+			}
+
+			return m.AddDate(0, 0, day-1), true
+		}
+	}
+
+	return time.Time{}, false
+}
+
+// resolveMonthDay returns the day of the month of m given by d, counting from the
+// end of the month when d is negative. It reports false when the month is too
+// short.
+func resolveMonthDay(m time.Time, d int) (int, bool) {
+	last := time.Date(m.Year(), m.Month()+1, 0, 0, 0, 0, 0, m.Location()).Day()
+
+	if d < 0 {
+		d = last + d + 1
+	}
+
+	return d, d >= 1 && d <= last
+}
+
+// weekdayIndex returns the position of d in a week starting on Monday.
+func weekdayIndex(d time.Weekday) int {
+	return (int(d) + 6) % 7
+}
@@ -6,6 +6,7 @@ import (
 	"github.com/george-e-shaw-iv/integration-tests-example/cmd/listd/item"
 	"github.com/george-e-shaw-iv/integration-tests-example/cmd/listd/list"
 	"github.com/george-e-shaw-iv/integration-tests-example/internal/platform/db"
+	"github.com/george-e-shaw-iv/integration-tests-example/internal/platform/units"
 	"github.com/jmoiron/sqlx"
 	"github.com/pkg/errors"
 )
@@ -43,7 +44,7 @@ func Open() (*sqlx.DB, error) {
 
 // Truncate removes all seed data from the test database.
 func Truncate(dbc *sqlx.DB) error {
-	stmt := "TRUNCATE TABLE list, item;"
+	stmt := "TRUNCATE TABLE list, item, webhook, webhook_delivery, outbox, tombstone, item_revision, tag, item_tag, folder, attachment, blob_orphan, item_comment, item_comment_mention, calendar_token;"
 
 	if _, err := dbc.Exec(stmt); err != nil {
 		return errors.Wrap(err, "truncate test database tables")
@@ -75,14 +76,14 @@ func SeedLists(dbc *sqlx.DB) ([]list.List, error) {
 	}
 
 	for i := range lists {
-		stmt, err := dbc.Prepare("INSERT INTO list (name, created, modified) VALUES ($1, $2, $3) RETURNING list_id;")
+		stmt, err := dbc.Prepare("INSERT INTO list (name, created, modified) VALUES ($1, $2, $3) RETURNING list_id, version;")
 		if err != nil {
 			return nil, errors.Wrap(err, "prepare list insertion")
 		}
 
 		row := stmt.QueryRow(lists[i].Name, lists[i].Created, lists[i].Modified)
 
-		if err = row.Scan(&lists[i].ID); err != nil {
+		if err = row.Scan(&lists[i].ID, &lists[i].Version); err != nil {
 			if err := stmt.Close(); err != nil {
 				return nil, errors.Wrap(err, "close psql statement")
 			}
@@ -106,35 +107,35 @@ func SeedItems(dbc *sqlx.DB, lists []list.List) ([]item.Item, error) {
 		{
 			ListID:   lists[0].ID, // Grocery
 			Name:     "Chocolate Milk",
-			Quantity: 1,
+			Quantity: units.Int(1),
 			Created:  now,
 			Modified: now,
 		},
 		{
 			ListID:   lists[0].ID, // Grocery
 			Name:     "Mac and Cheese",
-			Quantity: 2,
+			Quantity: units.Int(2),
 			Created:  now,
 			Modified: now,
 		},
 		{
 			ListID:   lists[1].ID, // To-do
 			Name:     "Write Integration Tests",
-			Quantity: 1,
+			Quantity: units.Int(1),
 			Created:  now,
 			Modified: now,
 		},
 	}
 
 	for i := range items {
-		stmt, err := dbc.Prepare("INSERT INTO item (list_id, name, quantity, created, modified) VALUES ($1, $2, $3, $4, $5) RETURNING item_id;")
+		stmt, err := dbc.Prepare("INSERT INTO item (list_id, name, quantity, created, modified) VALUES ($1, $2, $3, $4, $5) RETURNING item_id, version;")
 		if err != nil {
 			return nil, errors.Wrap(err, "prepare item insertion")
 		}
 
 		row := stmt.QueryRow(items[i].ListID, items[i].Name, items[i].Quantity, items[i].Created, items[i].Modified)
 
-		if err = row.Scan(&items[i].ID); err != nil {
+		if err = row.Scan(&items[i].ID, &items[i].Version); err != nil {
 			if err := stmt.Close(); err != nil {
 				return nil, errors.Wrap(err, "close psql statement")
 			}
@@ -0,0 +1,197 @@
+package units
+
+import (
+	"bytes"
+	"database/sql/driver"
+	"math/big"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Scale is the number of decimal places an Amount is kept to. Amounts given with
+// more decimal places are rounded half away from zero.
+const Scale = 3
+
+// scaleFactor is 10 to the power of Scale.
+const scaleFactor = 1000
+
+// maxMilli bounds the magnitude of an Amount in thousandths, matching the
+// numeric(18, 3) columns amounts are stored in.
+const maxMilli = 1e18 - 1
+
+// ErrInvalidAmount is returned when an amount is not a decimal number or is out
+// of range.
+var ErrInvalidAmount = errors.New("amounts must be decimal numbers with at most 15 digits before the decimal point")
+
+// Amount is an exact decimal number kept to Scale decimal places, such as the
+// quantity of an item. The zero value is 0.
+type Amount struct {
+	milli int64
+}
+
+// Int returns the Amount equal to n.
+func Int(n int64) Amount {
+	return Amount{milli: n * scaleFactor}
+}
+
+// ParseAmount parses a decimal number such as 1.5 or 1e3.
+func ParseAmount(s string) (Amount, error) {
+	s = strings.TrimSpace(s)
+	if s == "" || strings.ContainsAny(s, "/") {
+		return Amount{}, ErrInvalidAmount
+	}
+
+	r, ok := new(big.Rat).SetString(s)
+	if !ok {
+		return Amount{}, ErrInvalidAmount
+	}
+
+	return FromRat(r)
+}
+
+// MustParseAmount is like ParseAmount but panics when s is not a valid amount.
+func MustParseAmount(s string) Amount {
+	a, err := ParseAmount(s)
+	if err != nil {
+		panic(err)
+	}
+
+	return a
+}
+
+// FromRat returns r rounded half away from zero to Scale decimal places.
+func FromRat(r *big.Rat) (Amount, error) {
+	num := new(big.Int).Mul(r.Num(), big.NewInt(scaleFactor))
+	den := r.Denom()
+
+	q, m := new(big.Int).QuoRem(num, den, new(big.Int))
+	if new(big.Int).Mul(new(big.Int).Abs(m), big.NewInt(2)).Cmp(den) >= 0 {
+		q.Add(q, big.NewInt(int64(num.Sign())))
+	}
+
+	if !q.IsInt64() || q.Int64() > maxMilli || q.Int64() < -maxMilli {
+		return Amount{}, ErrInvalidAmount
+	}
+
+	return Amount{milli: q.Int64()}, nil
+}
+
+// Rat returns a as a rational number.
+func (a Amount) Rat() *big.Rat {
+	return big.NewRat(a.milli, scaleFactor)
+}
+
+// Add returns the sum of a and b.
+func (a Amount) Add(b Amount) Amount {
+	return Amount{milli: a.milli + b.milli}
+}
+
+// Sign returns -1, 0 or 1 depending on whether a is negative, zero or positive.
+func (a Amount) Sign() int {
+	switch {
+	case a.milli < 0:
+		return -1
+	case a.milli > 0:
+		return 1
+	}
+
+	return 0
+}
+
+// Cmp returns -1, 0 or 1 depending on whether a is less than, equal to or greater
+// than b.
+func (a Amount) Cmp(b Amount) int {
+	return Amount{milli: a.milli - b.milli}.Sign()
+}
+
+// Equal reports whether a and b are the same amount.
+func (a Amount) Equal(b Amount) bool {
+	return a.milli == b.milli
+}
+
+// String returns a in its shortest decimal form, such as 1.5 or 2.
+func (a Amount) String() string {
+	s := strconv.FormatInt(a.milli, 10)
+
+	neg := strings.HasPrefix(s, "-")
+	s = strings.TrimPrefix(s, "-")
+
+	for len(s) <= Scale {
+		s = "0" + s
+	}
+
+	whole, frac := s[:len(s)-Scale], strings.TrimRight(s[len(s)-Scale:], "0")
+	if frac != "" {
+		whole += "." + frac
+	}
+
+	if neg {
+		return "-" + whole
+	}
+
+	return whole
+}
+
+// MarshalJSON implements the json.Marshaler interface, encoding a as a number.
+func (a Amount) MarshalJSON() ([]byte, error) {
+	return []byte(a.String()), nil
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface, decoding a number or a
+// string holding one.
+func (a *Amount) UnmarshalJSON(data []byte) error {
+	if bytes.Equal(data, []byte("null")) {
+		return nil
+	}
+
+	s := string(data)
+	if strings.HasPrefix(s, `"`) {
+		var err error
+		if s, err = strconv.Unquote(s); err != nil {
+			return ErrInvalidAmount
+		}
+	}
+
+	v, err := ParseAmount(s)
+	if err != nil {
+		return err
+	}
+
+	*a = v
+	return nil
+}
+
+// Scan implements the sql.Scanner interface.
+func (a *Amount) Scan(src interface{}) error {
+	var (
+		v   Amount
+		err error
+	)
+
+	switch s := src.(type) {
+	case []byte:
+		v, err = ParseAmount(string(s))
+	case string:
+		v, err = ParseAmount(s)
+	case int64:
+		v = Int(s)
+	case float64:
+		v, err = ParseAmount(strconv.FormatFloat(s, 'f', -1, 64))
+	case nil:
+	default:
+		return errors.Errorf("unable to scan %T into an amount", src)
+	}
+	if err != nil {
+		return errors.Wrap(err, "scan amount")
+	}
+
+	*a = v
+	return nil
+}
+
+// Value implements the driver.Valuer interface.
+func (a Amount) Value() (driver.Value, error) {
+	return a.String(), nil
+}
@@ -0,0 +1,177 @@
+// Package units is a registry of units of measure for the quantities of items,
+// with conversions between units of the same dimension. Quantities are kept as
+// exact decimal Amounts rather than floating point numbers, and conversions are
+// carried out on rational numbers, so that converting never drifts.
+//
+// Every dimension has a base unit that the factors of its units are given in:
+// grams for mass, milliliters for volume and single pieces for counts. A count
+// of pieces has no unit symbol at all.
+package units
+
+import (
+	"math/big"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Dimension is a kind of quantity that units measure.
+type Dimension string
+
+// Dimensions of the registered units.
+const (
+	Count  Dimension = "count"
+	Mass   Dimension = "mass"
+	Volume Dimension = "volume"
+)
+
+// Errors returned when a unit can't be used.
+var (
+	ErrUnknownUnit  = errors.New("unknown unit")
+	ErrIncompatible = errors.New("units measure different dimensions and can't be converted")
+)
+
+// Unit is a unit of measure.
+type Unit struct {
+	Symbol    string
+	Dimension Dimension
+
+	// factor is the size of the unit in the base unit of its dimension.
+	factor *big.Rat
+}
+
+var (
+	// registry holds every unit keyed by its symbol.
+	registry = make(map[string]Unit)
+
+	// aliases maps the normalized symbols, names and plurals of units to their
+	// symbols.
+	aliases = make(map[string]string)
+)
+
+func init() {
+	register(Count, "", "1", "pc", "pcs", "piece", "pieces", "each", "ea", "x")
+	register(Count, "dozen", "12", "dz", "doz", "dozens")
+
+	register(Mass, "mg", "0.001", "milligram", "milligrams")
+	register(Mass, "g", "1", "gr", "gram", "grams", "gramme", "grammes")
+	register(Mass, "kg", "1000", "kgs", "kilo", "kilos", "kilogram", "kilograms")
+	register(Mass, "oz", "28.349523125", "ounce", "ounces")
+	register(Mass, "lb", "453.59237", "lbs", "pound", "pounds")
+
+	register(Volume, "ml", "1", "milliliter", "milliliters", "millilitre", "millilitres")
+	register(Volume, "cl", "10", "centiliter", "centiliters", "centilitre", "centilitres")
+	register(Volume, "dl", "100", "deciliter", "deciliters", "decilitre", "decilitres")
+	register(Volume, "l", "1000", "ltr", "liter", "liters", "litre", "litres")
+	register(Volume, "tsp", "4.92892159375", "teaspoon", "teaspoons")
+	register(Volume, "tbsp", "14.78676478125", "tbs", "tablespoon", "tablespoons")
+	register(Volume, "fl oz", "29.5735295625", "floz", "fluid ounce", "fluid ounces")
+	register(Volume, "cup", "236.5882365", "cups")
+	register(Volume, "pt", "473.176473", "pint", "pints")
+	register(Volume, "qt", "946.352946", "quart", "quarts")
+	register(Volume, "gal", "3785.411784", "gallon", "gallons")
+}
+
+// register adds a unit of dimension d, factor times the size of the base unit of
+// d, to the registry.
+func register(d Dimension, symbol, factor string, names ...string) {
+	f, ok := new(big.Rat).SetString(factor)
+	if !ok {
+		panic("invalid factor of unit " + symbol)
+	}
+
+	registry[symbol] = Unit{
+		Symbol:    symbol,
+		Dimension: d,
+		factor:    f,
+	}
+
+	aliases[symbol] = symbol
+	for _, n := range names {
+		aliases[n] = symbol
+	}
+}
+
+// Lookup returns the unit known by name, which is either its symbol, its name
+// or its plural, in any case.
+func Lookup(name string) (Unit, error) {
+	symbol, ok := aliases[strings.Join(strings.Fields(strings.ToLower(name)), " ")]
+	if !ok {
+		return Unit{}, errors.Wrapf(ErrUnknownUnit, "%q", name)
+	}
+
+	return registry[symbol], nil
+}
+
+// Canonical returns the symbol of the unit known by name.
+func Canonical(name string) (string, error) {
+	u, err := Lookup(name)
+	if err != nil {
+		return "", err
+	}
+
+	return u.Symbol, nil
+}
+
+// Convert converts a from the unit known by from to the unit known by to.
+func Convert(a Amount, from, to string) (Amount, error) {
+	r, err := Ratio(from, to)
+	if err != nil {
+		return Amount{}, err
+	}
+
+	return FromRat(r.Mul(r, a.Rat()))
+}
+
+// Ratio returns the size of the unit known by from in the unit known by to, such as
+// 1000 for kilograms in grams.
+func Ratio(from, to string) (*big.Rat, error) {
+	f, err := Lookup(from)
+	if err != nil {
+		return nil, err
+	}
+
+	t, err := Lookup(to)
+	if err != nil {
+		return nil, err
+	}
+
+	if f.Dimension != t.Dimension {
+		return nil, ErrIncompatible
+	}
+
+	return new(big.Rat).Quo(f.factor, t.factor), nil
+}
+
+// Metric converts a in the unit known by unit to the metric unit best suited to
+// it: grams or, from 1000 grams, kilograms for mass, milliliters or, from 1000
+// milliliters, liters for volume, and single pieces for counts.
+func Metric(a Amount, unit string) (Amount, string, error) {
+	u, err := Lookup(unit)
+	if err != nil {
+		return Amount{}, "", err
+	}
+
+	var small, large string
+	switch u.Dimension {
+	case Mass:
+		small, large = "g", "kg"
+	case Volume:
+		small, large = "ml", "l"
+	default:
+		c, err := Convert(a, u.Symbol, "")
+		return c, "", err
+	}
+
+	c, err := Convert(a, u.Symbol, small)
+	if err != nil {
+		return Amount{}, "", err
+	}
+
+	if c.Cmp(Int(1000)) < 0 && c.Cmp(Int(-1000)) > 0 {
+		return c, small, nil
+	}
+
+	c, err = Convert(a, u.Symbol, large)
+	return c, large, err
+}
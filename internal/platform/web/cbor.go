@@ -0,0 +1,69 @@
+package web
+
+import (
+	"github.com/fxamacker/cbor/v2"
+	"github.com/pkg/errors"
+)
+
+// cborCodec is the codec of CBOR, read and written by github.com/fxamacker/cbor.
+// Maps are written in canonical order. Byte strings are decoded as base64 strings
+// and date-times as RFC 3339 strings, which is how JSON holds them.
+type cborCodec struct{}
+
+// cborEncMode writes maps sorted and floats in as few bytes as hold them, and
+// cborDecMode refuses duplicate map keys and nesting deeper than maxDepth.
+var (
+	cborEncMode cbor.EncMode
+	cborDecMode cbor.DecMode
+)
+
+func init() {
+	var err error
+
+	cborEncMode, err = cbor.EncOptions{
+		Sort:          cbor.SortCanonical,
+		ShortestFloat: cbor.ShortestFloat16,
+	}.EncMode()
+	if err != nil {
+		panic(err)
+	}
+
+	cborDecMode, err = cbor.DecOptions{
+		DupMapKey:       cbor.DupMapKeyEnforcedAPF,
+		MaxNestedLevels: maxDepth,
+	}.DecMode()
+	if err != nil {
+		panic(err)
+	}
+}
+
+// Marshal implements the Codec interface for the cborCodec type.
+func (cborCodec) Marshal(v interface{}) ([]byte, error) {
+	tree, err := toTree(v)
+	if err != nil {
+		return nil, err
+	}
+
+	return cborEncMode.Marshal(tree)
+}
+
+// Unmarshal implements the Codec interface for the cborCodec type.
+func (cborCodec) Unmarshal(data []byte, v interface{}) error {
+	var tree interface{}
+	if err := cborDecMode.Unmarshal(data, &tree); err != nil {
+		return errors.Wrap(err, "decode cbor")
+	}
+
+	return fromTree(tree, v)
+}
+
+// MarshalCBOR implements the cbor.Marshaler interface for the object type. CBOR
+// maps are written in canonical order, so the order of the members is lost.
+func (o object) MarshalCBOR() ([]byte, error) {
+	m := make(map[string]interface{}, len(o))
+	for _, mb := range o {
+		m[mb.key] = mb.value
+	}
+
+	return cborEncMode.Marshal(m)
+}
@@ -0,0 +1,346 @@
+package web
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math"
+	"mime"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Codec encodes responses into and decodes requests from a wire format.
+type Codec interface {
+	Marshal(v interface{}) ([]byte, error)
+	Unmarshal(data []byte, v interface{}) error
+}
+
+// codecEntry is a media type along with the codec of its wire format.
+type codecEntry struct {
+	mediaType string
+	codec     Codec
+}
+
+// codecs are the registered wire formats, in the order the server prefers them
+// when the client has no preference. JSON is always first, so that clients which
+// don't send an Accept header keep getting it.
+var codecs = []codecEntry{
+	{mediaType: "application/json", codec: jsonCodec{}},
+	{mediaType: "application/msgpack", codec: msgpackCodec{}},
+	{mediaType: "application/x-msgpack", codec: msgpackCodec{}},
+	{mediaType: "application/yaml", codec: yamlCodec{}},
+	{mediaType: "application/x-yaml", codec: yamlCodec{}},
+	{mediaType: "text/yaml", codec: yamlCodec{}},
+	{mediaType: "application/cbor", codec: cborCodec{}},
+}
+
+// Register registers a codec for a media type, replacing the codec registered for
+// it, if any. It is meant to be called on start up, before any request is served.
+func Register(mediaType string, c Codec) {
+	for i := range codecs {
+		if codecs[i].mediaType == mediaType {
+			codecs[i].codec = c
+			return
+		}
+	}
+
+	codecs = append(codecs, codecEntry{mediaType: mediaType, codec: c})
+}
+
+// MediaTypes returns the media types of the registered codecs, in the order the
+// server prefers them.
+func MediaTypes() []string {
+	types := make([]string, len(codecs))
+	for i, c := range codecs {
+		types[i] = c.mediaType
+	}
+
+	return types
+}
+
+// Lookup returns the codec registered for a media type.
+func Lookup(mediaType string) (Codec, bool) {
+	for _, c := range codecs {
+		if c.mediaType == mediaType {
+			return c.codec, true
+		}
+	}
+
+	return nil, false
+}
+
+// StatusCoder is implemented by errors that carry the status code they are to be
+// responded with.
+type StatusCoder interface {
+	StatusCode() int
+}
+
+// MediaTypeError is returned by Decode for request bodies of a media type no codec
+// is registered for.
+type MediaTypeError string
+
+// Error implements the error interface.
+func (m MediaTypeError) Error() string {
+	return fmt.Sprintf("unsupported content type %q", string(m))
+}
+
+// StatusCode implements the StatusCoder interface.
+func (m MediaTypeError) StatusCode() int {
+	return http.StatusUnsupportedMediaType
+}
+
+// maxDecodeSize is the largest request body Decode reads into memory before
+// decoding it, which it does for every format but JSON.
+const maxDecodeSize = 4 << 20
+
+// BodySizeError is returned by Decode for request bodies larger than it reads.
+type BodySizeError int64
+
+// Error implements the error interface.
+func (b BodySizeError) Error() string {
+	return fmt.Sprintf("request body must not be larger than %d bytes", int64(b))
+}
+
+// StatusCode implements the StatusCoder interface.
+func (b BodySizeError) StatusCode() int {
+	return http.StatusRequestEntityTooLarge
+}
+
+// Decode decodes the body of r into v using the codec registered for its
+// Content-Type header. Bodies without a Content-Type are decoded as JSON. A
+// MediaTypeError is returned when no codec is registered for the Content-Type,
+// and a BodySizeError when a body that isn't JSON is larger than maxDecodeSize.
+func Decode(r *http.Request, v interface{}) error {
+	mediaType := "application/json"
+	if ct := r.Header.Get("Content-Type"); ct != "" {
+		mt, _, err := mime.ParseMediaType(ct)
+		if err != nil {
+			return MediaTypeError(ct)
+		}
+		mediaType = mt
+	}
+
+	c, ok := Lookup(mediaType)
+	if !ok {
+		return MediaTypeError(mediaType)
+	}
+
+	// JSON is decoded as it is streamed, as before codecs were registered.
+	if _, ok := c.(jsonCodec); ok {
+		return json.NewDecoder(r.Body).Decode(v)
+	}
+
+	data, err := ioutil.ReadAll(io.LimitReader(r.Body, maxDecodeSize+1))
+	if err != nil {
+		return errors.Wrap(err, "read request body")
+	}
+
+	if len(data) > maxDecodeSize {
+		return BodySizeError(maxDecodeSize)
+	}
+
+	return c.Unmarshal(data, v)
+}
+
+// jsonCodec is the codec of JSON.
+type jsonCodec struct{}
+
+// Marshal implements the Codec interface for the jsonCodec type.
+func (jsonCodec) Marshal(v interface{}) ([]byte, error) {
+	return json.Marshal(v)
+}
+
+// Unmarshal implements the Codec interface for the jsonCodec type.
+func (jsonCodec) Unmarshal(data []byte, v interface{}) error {
+	return json.Unmarshal(data, v)
+}
+
+// The codecs of the other wire formats go through JSON, so that every type is
+// encoded the same way in all of them, following its JSON struct tags and
+// json.Marshaler implementation. A value is marshaled to JSON and read into a
+// tree, which the library of the wire format then writes. Decoding does the
+// reverse: the library reads the body into a value, which is checked to hold only
+// what JSON can and then unmarshaled from its JSON encoding.
+//
+// A tree is built out of nil, bool, int64, uint64, float64, string, []interface{}
+// and object values. Objects keep their members in order, so that the fields of a
+// struct are written in the order they are declared in.
+
+// object is a JSON object, holding its members in order.
+type object []member
+
+// member is a member of a JSON object.
+type member struct {
+	key   string
+	value interface{}
+}
+
+// maxDepth is the maximum nesting of arrays and objects that is decoded, so that
+// malicious bodies can't exhaust the stack.
+const maxDepth = 256
+
+// errTooDeep is returned when decoding bodies nested deeper than maxDepth.
+var errTooDeep = errors.Errorf("body is nested deeper than %d levels", maxDepth)
+
+// toTree returns the tree of the JSON encoding of v.
+func toTree(v interface{}) (interface{}, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	d := json.NewDecoder(bytes.NewReader(b))
+	d.UseNumber()
+
+	return readTree(d)
+}
+
+// readTree reads the next value of d as a tree.
+func readTree(d *json.Decoder) (interface{}, error) {
+	t, err := d.Token()
+	if err != nil {
+		return nil, err
+	}
+
+	switch t := t.(type) {
+	case json.Delim:
+		if t == '[' {
+			a := make([]interface{}, 0)
+			for d.More() {
+				v, err := readTree(d)
+				if err != nil {
+					return nil, err
+				}
+				a = append(a, v)
+			}
+
+			_, err := d.Token()
+			return a, err
+		}
+
+		o := make(object, 0)
+		for d.More() {
+			k, err := d.Token()
+			if err != nil {
+				return nil, err
+			}
+
+			v, err := readTree(d)
+			if err != nil {
+				return nil, err
+			}
+			o = append(o, member{key: k.(string), value: v})
+		}
+
+		_, err := d.Token()
+		return o, err
+	case json.Number:
+		return parseNumber(t)
+	}
+
+	return t, nil
+}
+
+// parseNumber parses a json.Number as the narrowest of int64, uint64 or float64
+// able to hold it.
+func parseNumber(n json.Number) (interface{}, error) {
+	if i, err := strconv.ParseInt(string(n), 10, 64); err == nil {
+		return i, nil
+	}
+
+	if u, err := strconv.ParseUint(string(n), 10, 64); err == nil {
+		return u, nil
+	}
+
+	f, err := strconv.ParseFloat(string(n), 64)
+	if err != nil {
+		return nil, errors.Errorf("invalid number %q", string(n))
+	}
+
+	return f, nil
+}
+
+// fromTree decodes into v the JSON encoding of a value decoded by the library of a
+// wire format.
+func fromTree(tree interface{}, v interface{}) error {
+	tree, err := jsonValue(tree, 0)
+	if err != nil {
+		return err
+	}
+
+	b, err := json.Marshal(tree)
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(b, v)
+}
+
+// jsonValue returns a value decoded by the library of a wire format, nested depth
+// levels deep, with its maps keyed by strings, its binary values as base64 strings
+// and its timestamps as RFC 3339 strings, which is how JSON holds them. An error
+// is returned for values JSON has no counterpart for.
+func jsonValue(v interface{}, depth int) (interface{}, error) {
+	switch t := v.(type) {
+	case nil, bool, string, int, int64, uint64:
+		return t, nil
+	case float32:
+		return jsonValue(float64(t), depth)
+	case float64:
+		if math.IsNaN(t) || math.IsInf(t, 0) {
+			return nil, errors.New("numbers must be finite")
+		}
+		return t, nil
+	case []byte:
+		return base64.StdEncoding.EncodeToString(t), nil
+	case time.Time:
+		return t.Format(time.RFC3339Nano), nil
+	case []interface{}:
+		if depth >= maxDepth {
+			return nil, errTooDeep
+		}
+
+		a := make([]interface{}, len(t))
+		for i := range t {
+			e, err := jsonValue(t[i], depth+1)
+			if err != nil {
+				return nil, err
+			}
+			a[i] = e
+		}
+		return a, nil
+	case map[string]interface{}:
+		if depth >= maxDepth {
+			return nil, errTooDeep
+		}
+
+		m := make(map[string]interface{}, len(t))
+		for k, e := range t {
+			e, err := jsonValue(e, depth+1)
+			if err != nil {
+				return nil, err
+			}
+			m[k] = e
+		}
+		return m, nil
+	case map[interface{}]interface{}:
+		m := make(map[string]interface{}, len(t))
+		for k, e := range t {
+			s, ok := k.(string)
+			if !ok {
+				return nil, errors.Errorf("map keys must be strings, got %T", k)
+			}
+			m[s] = e
+		}
+		return jsonValue(m, depth)
+	}
+
+	return nil, errors.Errorf("unsupported value of type %T", v)
+}
@@ -0,0 +1,194 @@
+package web
+
+import (
+	"bytes"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/pkg/errors"
+)
+
+// codecDecodeTests are inputs that codecs must decode into the expected value, or
+// fail to decode when ExpectedErr is set. They also seed the fuzz tests.
+var codecDecodeTests = []struct {
+	Name        string
+	MediaType   string
+	Data        string
+	Expected    interface{}
+	ExpectedErr bool
+}{
+	{Name: "MessagePackMap", MediaType: "application/msgpack", Data: "\x82\xa4name\xa4Milk\xa4tags\x92\xa5dairy\xca\x40\x20\x00\x00", Expected: map[string]interface{}{"name": "Milk", "tags": []interface{}{"dairy", 2.5}}},
+	{Name: "MessagePackNil", MediaType: "application/msgpack", Data: "\xc0", Expected: nil},
+	{Name: "MessagePackEmpty", MediaType: "application/msgpack", Data: "", ExpectedErr: true},
+	{Name: "MessagePackTruncated", MediaType: "application/msgpack", Data: "\x82\xa4name", ExpectedErr: true},
+	{Name: "MessagePackKey", MediaType: "application/msgpack", Data: "\x81\xc0\x01", ExpectedErr: true},
+	{Name: "MessagePackDuplicateKey", MediaType: "application/msgpack", Data: "\x82\xa1a\x01\xa1a\x02", ExpectedErr: true},
+	{Name: "MessagePackTrailing", MediaType: "application/msgpack", Data: "\x80\x80", ExpectedErr: true},
+	{Name: "MessagePackHugeArray", MediaType: "application/msgpack", Data: "\xdd\xff\xff\xff\xff", ExpectedErr: true},
+	{Name: "CBORIndefinite", MediaType: "application/cbor", Data: "\xbf\x64name\x7f\x62Mi\x62lk\xff\x64tags\x9f\x65dairy\xf9\x41\x00\xff\xff", Expected: map[string]interface{}{"name": "Milk", "tags": []interface{}{"dairy", 2.5}}},
+	{Name: "CBOREmpty", MediaType: "application/cbor", Data: "", ExpectedErr: true},
+	{Name: "CBORTruncated", MediaType: "application/cbor", Data: "\x9a\xff\xff\xff\xff", ExpectedErr: true},
+	{Name: "CBORInfinity", MediaType: "application/cbor", Data: "\xf9\x7c\x00", ExpectedErr: true},
+	{Name: "CBORDuplicateKey", MediaType: "application/cbor", Data: "\xa2\x61a\x01\x61a\x02", ExpectedErr: true},
+	{Name: "CBORBreak", MediaType: "application/cbor", Data: "\xff", ExpectedErr: true},
+	{Name: "YAMLBlock", MediaType: "application/yaml", Data: "---\n# A list\nname: Milk\ntags:\n  - dairy\n  - 2.5 # litres\n...\n", Expected: map[string]interface{}{"name": "Milk", "tags": []interface{}{"dairy", 2.5}}},
+	{Name: "YAMLFlow", MediaType: "application/yaml", Data: "{name: 'Milk', tags: [dairy, 2.5]}", Expected: map[string]interface{}{"name": "Milk", "tags": []interface{}{"dairy", 2.5}}},
+	{Name: "YAMLEmptyQuotedKey", MediaType: "application/yaml", Data: "\"\": 1\n", Expected: map[string]interface{}{"": 1.0}},
+	{Name: "YAMLColonScalar", MediaType: "application/yaml", Data: ":x\n", Expected: ":x"},
+	{Name: "YAMLEmptyKey", MediaType: "application/yaml", Data: ": x\n", ExpectedErr: true},
+	{Name: "YAMLEmptyKeyWithoutValue", MediaType: "application/yaml", Data: ":\n", ExpectedErr: true},
+	{Name: "YAMLEmptyKeyInSequence", MediaType: "application/yaml", Data: "- : x\n", ExpectedErr: true},
+	{Name: "YAMLEmptyKeyAfterEntry", MediaType: "application/yaml", Data: "name: Milk\n: 1\n", ExpectedErr: true},
+	{Name: "YAMLEmptyFlowKey", MediaType: "application/yaml", Data: "{: 1}", ExpectedErr: true},
+	{Name: "YAMLTabs", MediaType: "application/yaml", Data: "a:\n\tb: 1", ExpectedErr: true},
+	{Name: "YAMLDuplicateKey", MediaType: "application/yaml", Data: "a: 1\na: 2", ExpectedErr: true},
+	{Name: "YAMLAlias", MediaType: "application/yaml", Data: "a: &x 1\nb: *x", Expected: map[string]interface{}{"a": 1.0, "b": 1.0}},
+	{Name: "YAMLIntegerKey", MediaType: "application/yaml", Data: "1: a\n", ExpectedErr: true},
+	{Name: "YAMLDocuments", MediaType: "application/yaml", Data: "a: 1\n---\nb: 2", ExpectedErr: true},
+	{Name: "YAMLUnterminatedFlow", MediaType: "application/yaml", Data: "[a, {b: 1", ExpectedErr: true},
+	{Name: "YAMLUnterminatedFlowMapping", MediaType: "application/yaml", Data: "{", ExpectedErr: true},
+}
+
+func Test_codecDecode(t *testing.T) {
+	for _, test := range codecDecodeTests {
+		fn := func(t *testing.T) {
+			c, ok := Lookup(test.MediaType)
+			if !ok {
+				t.Fatalf("expected a codec registered for %q", test.MediaType)
+			}
+
+			var v interface{}
+			err := c.Unmarshal([]byte(test.Data), &v)
+
+			if test.ExpectedErr {
+				if err == nil {
+					t.Errorf("expected an error unmarshaling data, got: %#v", v)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("error unmarshaling data: %v", err)
+			}
+
+			if d := cmp.Diff(test.Expected, v); d != "" {
+				t.Errorf("unexpected difference in decoded value:\n%v", d)
+			}
+		}
+
+		t.Run(test.Name, fn)
+	}
+}
+
+func Test_codecDepth(t *testing.T) {
+	tests := []struct {
+		Name      string
+		MediaType string
+		Data      string
+	}{
+		{Name: "MessagePack", MediaType: "application/msgpack", Data: strings.Repeat("\x91", maxDepth+1) + "\xc0"},
+		{Name: "CBOR", MediaType: "application/cbor", Data: strings.Repeat("\x81", maxDepth+1) + "\xf6"},
+		{Name: "YAML", MediaType: "application/yaml", Data: strings.Repeat("[", maxDepth+1) + "1" + strings.Repeat("]", maxDepth+1)},
+	}
+
+	for _, test := range tests {
+		fn := func(t *testing.T) {
+			c, _ := Lookup(test.MediaType)
+
+			var v interface{}
+			if err := c.Unmarshal([]byte(test.Data), &v); err == nil {
+				t.Errorf("expected an error unmarshaling data nested %d deep", maxDepth+1)
+			}
+		}
+
+		t.Run(test.Name, fn)
+	}
+}
+
+func Test_Decode(t *testing.T) {
+	tests := []struct {
+		Name         string
+		ContentType  string
+		Body         []byte
+		ExpectedCode int
+	}{
+		{
+			Name:         "JSON",
+			ContentType:  "",
+			Body:         []byte(`{"name":"Milk"}`),
+			ExpectedCode: 0,
+		},
+		{
+			Name:         "YAML",
+			ContentType:  "application/yaml; charset=utf-8",
+			Body:         []byte("name: Milk\n"),
+			ExpectedCode: 0,
+		},
+		{
+			Name:         "LargestYAML",
+			ContentType:  "application/yaml",
+			Body:         append([]byte("name: Milk\n"), bytes.Repeat([]byte("#"), maxDecodeSize-len("name: Milk\n"))...),
+			ExpectedCode: 0,
+		},
+		{
+			Name:         "TooLargeYAML",
+			ContentType:  "application/yaml",
+			Body:         append([]byte("name: Milk\n"), bytes.Repeat([]byte("#"), maxDecodeSize)...),
+			ExpectedCode: http.StatusRequestEntityTooLarge,
+		},
+		{
+			Name:         "UnsupportedContentType",
+			ContentType:  "text/xml",
+			Body:         []byte("<name>Milk</name>"),
+			ExpectedCode: http.StatusUnsupportedMediaType,
+		},
+		{
+			Name:         "InvalidContentType",
+			ContentType:  "application/",
+			Body:         []byte("name: Milk\n"),
+			ExpectedCode: http.StatusUnsupportedMediaType,
+		},
+	}
+
+	for _, test := range tests {
+		fn := func(t *testing.T) {
+			r, err := http.NewRequest(http.MethodPost, "/list", bytes.NewReader(test.Body))
+			if err != nil {
+				t.Fatalf("error creating request: %v", err)
+			}
+
+			if test.ContentType != "" {
+				r.Header.Set("Content-Type", test.ContentType)
+			}
+
+			var v struct {
+				Name string `json:"name"`
+			}
+			err = Decode(r, &v)
+
+			if test.ExpectedCode == 0 {
+				if err != nil {
+					t.Fatalf("error decoding request: %v", err)
+				}
+
+				if e, a := "Milk", v.Name; e != a {
+					t.Errorf("expected name: %q, got name: %q", e, a)
+				}
+				return
+			}
+
+			sc, ok := errors.Cause(err).(StatusCoder)
+			if !ok {
+				t.Fatalf("expected an error with a status code, got: %v", err)
+			}
+
+			if e, a := test.ExpectedCode, sc.StatusCode(); e != a {
+				t.Errorf("expected status code: %v, got status code: %v", e, a)
+			}
+		}
+
+		t.Run(test.Name, fn)
+	}
+}
@@ -0,0 +1,59 @@
+//go:build go1.18
+// +build go1.18
+
+package web
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+// fuzzCodec checks that the codec registered for mediaType returns an error rather
+// than panicking on any input, and that whatever it decodes survives being
+// encoded and decoded again unchanged.
+func fuzzCodec(f *testing.F, mediaType string) {
+	c, ok := Lookup(mediaType)
+	if !ok {
+		f.Fatalf("expected a codec registered for %q", mediaType)
+	}
+
+	for _, test := range codecDecodeTests {
+		if test.MediaType == mediaType {
+			f.Add([]byte(test.Data))
+		}
+	}
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		var v interface{}
+		if err := c.Unmarshal(data, &v); err != nil {
+			return
+		}
+
+		b, err := c.Marshal(v)
+		if err != nil {
+			t.Fatalf("error marshaling decoded value %#v: %v", v, err)
+		}
+
+		var decoded interface{}
+		if err := c.Unmarshal(b, &decoded); err != nil {
+			t.Fatalf("error unmarshaling %q, encoded from %#v: %v", b, v, err)
+		}
+
+		if d := cmp.Diff(v, decoded); d != "" {
+			t.Errorf("unexpected difference in value decoded again:\n%v", d)
+		}
+	})
+}
+
+func FuzzMessagePack(f *testing.F) {
+	fuzzCodec(f, "application/msgpack")
+}
+
+func FuzzCBOR(f *testing.F) {
+	fuzzCodec(f, "application/cbor")
+}
+
+func FuzzYAML(f *testing.F) {
+	fuzzCodec(f, "application/yaml")
+}
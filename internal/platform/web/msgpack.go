@@ -0,0 +1,141 @@
+package web
+
+import (
+	"bytes"
+
+	"github.com/pkg/errors"
+	"github.com/vmihailenco/msgpack/v5"
+	"github.com/vmihailenco/msgpack/v5/msgpcode"
+)
+
+// msgpackCodec is the codec of MessagePack, read and written by
+// github.com/vmihailenco/msgpack. Binary values are decoded as base64 strings and
+// timestamps as RFC 3339 strings, which is how JSON holds them.
+type msgpackCodec struct{}
+
+// Marshal implements the Codec interface for the msgpackCodec type.
+func (msgpackCodec) Marshal(v interface{}) ([]byte, error) {
+	tree, err := toTree(v)
+	if err != nil {
+		return nil, err
+	}
+
+	var b bytes.Buffer
+	e := msgpack.NewEncoder(&b)
+	e.UseCompactInts(true)
+
+	if err := e.Encode(tree); err != nil {
+		return nil, err
+	}
+
+	return b.Bytes(), nil
+}
+
+// Unmarshal implements the Codec interface for the msgpackCodec type.
+func (msgpackCodec) Unmarshal(data []byte, v interface{}) error {
+	r := bytes.NewReader(data)
+
+	tree, err := decodeMsgpack(msgpack.NewDecoder(r), 0)
+	if err != nil {
+		return errors.Wrap(err, "decode msgpack")
+	}
+
+	if r.Len() != 0 {
+		return errors.New("decode msgpack: unexpected data after the value")
+	}
+
+	return fromTree(tree, v)
+}
+
+// decodeMsgpack decodes the next value of d, nested depth levels deep. Arrays and
+// maps are decoded here rather than by the library, so that their nesting is
+// bounded by maxDepth and their elements are only allocated as they are read.
+func decodeMsgpack(d *msgpack.Decoder, depth int) (interface{}, error) {
+	c, err := d.PeekCode()
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case msgpcode.IsFixedArray(c) || c == msgpcode.Array16 || c == msgpcode.Array32:
+		if depth >= maxDepth {
+			return nil, errTooDeep
+		}
+
+		n, err := d.DecodeArrayLen()
+		if err != nil {
+			return nil, err
+		}
+
+		a := make([]interface{}, 0)
+		for i := 0; i < n; i++ {
+			e, err := decodeMsgpack(d, depth+1)
+			if err != nil {
+				return nil, err
+			}
+			a = append(a, e)
+		}
+
+		return a, nil
+	case msgpcode.IsFixedMap(c) || c == msgpcode.Map16 || c == msgpcode.Map32:
+		if depth >= maxDepth {
+			return nil, errTooDeep
+		}
+
+		n, err := d.DecodeMapLen()
+		if err != nil {
+			return nil, err
+		}
+
+		m := make(map[string]interface{})
+		for i := 0; i < n; i++ {
+			c, err := d.PeekCode()
+			if err != nil {
+				return nil, err
+			}
+
+			if !msgpcode.IsString(c) {
+				return nil, errors.New("map keys must be strings")
+			}
+
+			k, err := d.DecodeString()
+			if err != nil {
+				return nil, err
+			}
+
+			if _, ok := m[k]; ok {
+				return nil, errors.Errorf("duplicate map key %q", k)
+			}
+
+			e, err := decodeMsgpack(d, depth+1)
+			if err != nil {
+				return nil, err
+			}
+			m[k] = e
+		}
+
+		return m, nil
+	}
+
+	return d.DecodeInterfaceLoose()
+}
+
+// EncodeMsgpack implements the msgpack.CustomEncoder interface for the object type,
+// writing its members in order.
+func (o object) EncodeMsgpack(e *msgpack.Encoder) error {
+	if err := e.EncodeMapLen(len(o)); err != nil {
+		return err
+	}
+
+	for _, m := range o {
+		if err := e.EncodeString(m.key); err != nil {
+			return err
+		}
+
+		if err := e.Encode(m.value); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
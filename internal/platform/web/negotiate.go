@@ -0,0 +1,103 @@
+package web
+
+import (
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// Negotiate returns the media type among offers that the client prefers according
+// to the Accept header of r. Offers are given in the order the server prefers them,
+// which breaks ties. The first offer is returned when r has no Accept header, and an
+// empty string when none of the offers are acceptable.
+func Negotiate(r *http.Request, offers ...string) string {
+	header := strings.Join(r.Header["Accept"], ",")
+	if strings.TrimSpace(header) == "" {
+		if len(offers) == 0 {
+			return ""
+		}
+
+		return offers[0]
+	}
+
+	var ranges []mediaRange
+	for _, part := range strings.Split(header, ",") {
+		if mr, ok := parseMediaRange(part); ok {
+			ranges = append(ranges, mr)
+		}
+	}
+
+	best, bestQ := "", 0.0
+	for _, offer := range offers {
+		if q := quality(ranges, strings.ToLower(offer)); q > bestQ {
+			best, bestQ = offer, q
+		}
+	}
+
+	return best
+}
+
+// mediaRange is a media range of an Accept header along with its quality.
+type mediaRange struct {
+	typ, subtype string
+	q            float64
+}
+
+// parseMediaRange parses a media range of an Accept header, such as text/*;q=0.5.
+func parseMediaRange(s string) (mediaRange, bool) {
+	mt, params, err := mime.ParseMediaType(strings.TrimSpace(s))
+	if err != nil {
+		return mediaRange{}, false
+	}
+
+	slash := strings.IndexByte(mt, '/')
+	if slash < 0 {
+		return mediaRange{}, false
+	}
+
+	mr := mediaRange{
+		typ:     mt[:slash],
+		subtype: mt[slash+1:],
+		q:       1,
+	}
+
+	if q, ok := params["q"]; ok {
+		if mr.q, err = strconv.ParseFloat(q, 64); err != nil || mr.q < 0 || mr.q > 1 {
+			return mediaRange{}, false
+		}
+	}
+
+	return mr, true
+}
+
+// quality returns the quality of the most specific of ranges matching the media type
+// mt, or 0 when none of them match.
+func quality(ranges []mediaRange, mt string) float64 {
+	slash := strings.IndexByte(mt, '/')
+	if slash < 0 {
+		return 0
+	}
+	typ, subtype := mt[:slash], mt[slash+1:]
+
+	q, specificity := 0.0, -1
+	for _, mr := range ranges {
+		var s int
+		switch {
+		case mr.typ == typ && mr.subtype == subtype:
+			s = 2
+		case mr.typ == typ && mr.subtype == "*":
+			s = 1
+		case mr.typ == "*" && mr.subtype == "*":
+			s = 0
+		default:
+			continue
+		}
+
+		if s > specificity {
+			q, specificity = mr.q, s
+		}
+	}
+
+	return q
+}
@@ -1,8 +1,8 @@
 package web
 
 import (
-	"encoding/json"
 	"net/http"
+	"strings"
 
 	"github.com/pkg/errors"
 	log "github.com/sirupsen/logrus"
@@ -53,6 +53,10 @@ func RespondError(w http.ResponseWriter, r *http.Request, code int, err error) {
 		"error": err,
 	}).Error("error while serving request")
 
+	if sc, ok := errors.Cause(err).(StatusCoder); ok {
+		code = sc.StatusCode()
+	}
+
 	if code >= http.StatusInternalServerError && code != http.StatusServiceUnavailable && code != http.StatusNotImplemented {
 
 		// Respond with generic error. Error messages and and codes may potentially contain
@@ -72,24 +76,52 @@ func RespondError(w http.ResponseWriter, r *http.Request, code int, err error) {
 	writeResponse(w, r, code, &resp)
 }
 
-// writeResponse marshals the response to json and writes it to the response writer.
+// writeResponse marshals the response in the wire format negotiated using the Accept
+// header of the request and writes it to the response writer. When none of the
+// registered wire formats are acceptable, 406 is responded in place of successful
+// responses, while errors are still responded as JSON.
 func writeResponse(w http.ResponseWriter, r *http.Request, code int, resp *Response) {
+	varyAccept(w.Header())
+
 	if code == http.StatusNoContent || resp == nil {
 		w.Header().Set("Content-Type", "application/json")
 		w.WriteHeader(code)
 		return
 	}
 
-	b, err := json.Marshal(resp)
+	mediaType := Negotiate(r, MediaTypes()...)
+	if mediaType == "" {
+		if code < http.StatusBadRequest {
+			RespondError(w, r, http.StatusNotAcceptable, errors.Errorf("none of the content types %s are acceptable", strings.Join(MediaTypes(), ", ")))
+			return
+		}
+
+		mediaType = "application/json"
+	}
+
+	c, _ := Lookup(mediaType)
+
+	b, err := c.Marshal(resp)
 	if err != nil {
 		RespondError(w, r, http.StatusInternalServerError, err)
 		return
 	}
 
-	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Content-Type", mediaType)
 	w.WriteHeader(code)
 
 	if _, err := w.Write(b); err != nil {
 		log.WithError(errors.Wrap(err, "write response body"))
 	}
 }
+
+// varyAccept adds Accept to the Vary header, unless it is already there.
+func varyAccept(h http.Header) {
+	for _, v := range h["Vary"] {
+		if v == "Accept" {
+			return
+		}
+	}
+
+	h.Add("Vary", "Accept")
+}
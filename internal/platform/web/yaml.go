@@ -0,0 +1,53 @@
+package web
+
+import (
+	"bytes"
+	"io"
+
+	"github.com/pkg/errors"
+	yaml "gopkg.in/yaml.v2"
+)
+
+// yamlCodec is the codec of YAML, read and written by gopkg.in/yaml.v2. Requests
+// must hold a single document, whose mappings are keyed by strings without
+// duplicates.
+type yamlCodec struct{}
+
+// Marshal implements the Codec interface for the yamlCodec type.
+func (yamlCodec) Marshal(v interface{}) ([]byte, error) {
+	tree, err := toTree(v)
+	if err != nil {
+		return nil, err
+	}
+
+	return yaml.Marshal(tree)
+}
+
+// Unmarshal implements the Codec interface for the yamlCodec type.
+func (yamlCodec) Unmarshal(data []byte, v interface{}) error {
+	d := yaml.NewDecoder(bytes.NewReader(data))
+	d.SetStrict(true)
+
+	var tree interface{}
+	if err := d.Decode(&tree); err != nil {
+		return errors.Wrap(err, "decode yaml")
+	}
+
+	var next interface{}
+	if err := d.Decode(&next); err != io.EOF {
+		return errors.New("decode yaml: body must hold a single document")
+	}
+
+	return fromTree(tree, v)
+}
+
+// MarshalYAML implements the yaml.Marshaler interface for the object type, writing
+// its members in order.
+func (o object) MarshalYAML() (interface{}, error) {
+	ms := make(yaml.MapSlice, len(o))
+	for i, m := range o {
+		ms[i] = yaml.MapItem{Key: m.key, Value: m.value}
+	}
+
+	return ms, nil
+}